@@ -15,38 +15,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
-const (
-	envDev  = "dev"
-	envProd = "prod"
-	envFile = ".env"
-)
-
-func initConfig() error {
-	viper.AddConfigPath(os.Getenv("CONFIG_PATH"))
-	viper.SetConfigName(os.Getenv("APP_ENV"))
-	return viper.ReadInConfig()
-}
-
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
-	default:
-		logfatal.Fatalf("Wrong env specified, env='%s', expected '%s' or '%s'", env, envDev, envProd)
-	}
-
-	return log
-}
+const envFile = ".env"
 
 // @title			Фильмотека
 // @version		1.0
@@ -61,28 +33,20 @@ func main() {
 	//if err := godotenv.Load(envFile); err != nil {
 	//	logfatal.Fatalf("Ошибка чтения переменных окружения: %s", err.Error())
 	//}
-	if err := initConfig(); err != nil {
+	if err := app.InitConfig(); err != nil {
 		logfatal.Fatalf("Ошибка чтения конфигурации: %s", err.Error())
 	}
 
-	log := setupLogger(viper.GetString("env"))
+	log := app.SetupLogger(viper.GetString("env"), os.Stdout)
 
-	pgCfg := postgres.Config{
-		Host:     viper.GetString("db.host"),
-		Port:     viper.GetString("db.port"),
-		Username: viper.GetString("db.username"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   viper.GetString("db.dbname"),
-		SSLMode:  viper.GetString("db.sslmode"),
-	}
-	db, err := postgres.NewPostgresDB(pgCfg)
+	db, err := postgres.NewPostgresDB(app.PostgresConfig())
 	if err != nil {
-		log.Error("Ошибка подключения к базе данных: %s", err.Error())
+		log.With(slog.String("err", err.Error())).Error("Ошибка подключения к базе данных")
 		return
 	}
 
 	if err != nil {
-		log.Error("Ошибка подключения к кэшу: %s", err.Error())
+		log.With(slog.String("err", err.Error())).Error("Ошибка подключения к кэшу")
 		return
 	}
 
@@ -94,22 +58,32 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 	go func() {
-		err = serv.Run(viper.GetString("port"), httpserver.NewLogger(log, handlers.InitRoutes()))
+		err = serv.Run(viper.GetString("port"), httpserver.NewLogger(log,
+			httpserver.NewTimeout(app.RequestTimeouts(), handlers.InitRoutes())))
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			log.Error("Ошибка запуска http сервера: %s", err.Error())
+			log.With(slog.String("err", err.Error())).Error("Ошибка запуска http сервера")
 			panic(err.Error())
 		}
 	}()
 	log.Info("server started")
+
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	go app.RunPurge(purgeCtx, log, services, viper.GetDuration("trash.purge_interval"),
+		time.Duration(viper.GetInt("trash.retention_days"))*24*time.Hour)
+	go app.RunAuditPurge(purgeCtx, log, services, viper.GetDuration("audit.purge_interval"),
+		time.Duration(viper.GetInt("audit.retention_days"))*24*time.Hour)
 	<-quit
 
 	log.Info("trying to gracefull shutdown")
-	if err = serv.Shutdown(context.Background()); err != nil {
+	stopPurge()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("timeouts.shutdown"))
+	defer cancel()
+	if err = serv.Shutdown(shutdownCtx); err != nil {
 		log.With(slog.String("err", err.Error())).Error("error occured on server shutting down:")
 	}
 
 	if err = db.Close(); err != nil {
-		log.Error("error occured on db connection close: %s", err.Error())
+		log.With(slog.String("err", err.Error())).Error("error occured on db connection close")
 		return
 	}
 
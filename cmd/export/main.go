@@ -0,0 +1,80 @@
+// Command export writes the films with their cast or the actors with their filmography
+// as NDJSON, CSV or a JSON document, the same as the admin export endpoints:
+//
+//	export -kind films -format csv -out films.csv
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/app"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/spf13/viper"
+	"io"
+	logfatal "log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	kind := flag.String("kind", "", "what to export: actors or films")
+	format := flag.String("format", service.ExportFormatNDJSON, "ndjson, csv or json")
+	out := flag.String("out", "-", "file to write, - for stdout")
+	actorId := flag.Int("actor-id", -1, "films: only the films of this actor")
+	filmId := flag.Int("film-id", -1, "actors: only the cast of this film")
+	timeout := flag.Duration("timeout", 0, "give up after this long, 0 for no limit")
+	flag.Parse()
+
+	if err := app.InitConfig(); err != nil {
+		logfatal.Fatalf("Ошибка чтения конфигурации: %s", err.Error())
+	}
+	// the export may go to stdout, so the logs go to stderr
+	log := app.SetupLogger(viper.GetString("env"), os.Stderr)
+
+	var dst io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			logfatal.Fatalf("Ошибка создания файла: %s", err.Error())
+		}
+		defer f.Close()
+		dst = f
+	}
+	buf := bufio.NewWriter(dst)
+
+	db, err := postgres.NewPostgresDB(app.PostgresConfig())
+	if err != nil {
+		logfatal.Fatalf("Ошибка подключения к базе данных: %s", err.Error())
+	}
+	defer db.Close()
+
+	// an interrupt stops the queries
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	services := service.NewExportService(repository.NewRepository(db, log), log)
+	switch *kind {
+	case "films":
+		err = services.ExportFilms(ctx, buf, *format, domain.FilmFilter{}, *actorId)
+	case "actors":
+		err = services.ExportActors(ctx, buf, *format, *filmId)
+	default:
+		logfatal.Fatalf("Неизвестный вид записей %q, ожидается actors или films", *kind)
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		logfatal.Fatalf("Ошибка выгрузки: %s", err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+// Command import loads actors or films from a CSV or NDJSON file straight into the database,
+// with the same rules as the admin import endpoints, or the whole catalog from IMDb datasets:
+//
+//	import -kind films -file films.csv -mode best_effort -dry-run
+//	import -kind imdb -titles title.basics.tsv.gz -principals title.principals.tsv.gz -names name.basics.tsv.gz
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/app"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/spf13/viper"
+	"io"
+	logfatal "log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+func openFile(name string) io.Reader {
+	if name == "-" {
+		return os.Stdin
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		logfatal.Fatalf("Ошибка открытия файла: %s", err.Error())
+	}
+	return f
+}
+
+// splitList splits a comma separated flag value
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func main() {
+	kind := flag.String("kind", "", "what to import: actors, films or imdb")
+	file := flag.String("file", "-", "file to import, - for stdin")
+	format := flag.String("format", "", "csv or ndjson, by default taken from the file extension")
+	mode := flag.String("mode", domain.ImportAtomic, "atomic or best_effort")
+	dryRun := flag.Bool("dry-run", false, "check the file and roll everything back")
+	titles := flag.String("titles", "", "imdb: title.basics dataset, plain or gzipped")
+	principals := flag.String("principals", "", "imdb: title.principals dataset, plain or gzipped")
+	names := flag.String("names", "", "imdb: name.basics dataset, plain or gzipped")
+	titleTypes := flag.String("title-types", "movie,tvMovie", "imdb: title types to import")
+	categories := flag.String("categories", "actor,actress", "imdb: principal categories imported as cast")
+	timeout := flag.Duration("timeout", 0, "give up after this long, 0 for no limit")
+	userId := flag.Int("user", 0, "id of the admin the revisions of imported records are recorded for, 0 for none")
+	flag.Parse()
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		if *format == "jsonl" {
+			*format = service.ImportFormatNDJSON
+		}
+	}
+
+	if err := app.InitConfig(); err != nil {
+		logfatal.Fatalf("Ошибка чтения конфигурации: %s", err.Error())
+	}
+	// the result goes to stdout, so the logs go to stderr
+	log := app.SetupLogger(viper.GetString("env"), os.Stderr)
+
+	db, err := postgres.NewPostgresDB(app.PostgresConfig())
+	if err != nil {
+		logfatal.Fatalf("Ошибка подключения к базе данных: %s", err.Error())
+	}
+	defer db.Close()
+
+	// an interrupt stops the queries and rolls the transaction back
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	services := service.NewImportService(repository.NewRepository(db, log), nil, log)
+	opts := domain.ImportOptions{DryRun: *dryRun, Mode: *mode}
+
+	var result domain.ImportResult
+	switch *kind {
+	case "actors":
+		result, err = services.ImportActors(ctx, *userId, openFile(*file), *format, opts)
+	case "films":
+		result, err = services.ImportFilms(ctx, *userId, openFile(*file), *format, opts)
+	case "imdb":
+		if *titles == "" || *principals == "" || *names == "" {
+			logfatal.Fatalf("Для импорта IMDb нужны файлы -titles, -principals и -names")
+		}
+		files := domain.IMDbFiles{Titles: openFile(*titles), Principals: openFile(*principals), Names: openFile(*names)}
+		var imdbResult domain.IMDbResult
+		imdbResult, err = services.ImportIMDb(ctx, *userId, files, domain.IMDbOptions{
+			TitleTypes: splitList(*titleTypes), Categories: splitList(*categories)})
+		if err != nil {
+			logfatal.Fatalf("Ошибка импорта: %s", err.Error())
+		}
+		printResult(imdbResult)
+		return
+	default:
+		logfatal.Fatalf("Неизвестный вид записей %q, ожидается actors, films или imdb", *kind)
+	}
+	if err != nil {
+		logfatal.Fatalf("Ошибка импорта: %s", err.Error())
+	}
+
+	printResult(result)
+	if result.Failed > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+func printResult(result any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+}
@@ -2,21 +2,33 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
-	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
 	"strconv"
 )
 
+func actorErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound:     "Specified actor not found",
+		apperr.CodeConflict:     "Such actor already exists",
+		apperr.CodePrecondition: "Actor was changed since it was read. Please, fetch it again and retry",
+	})
+}
+
 // ListActors godoc
 //
 //	@Summary		Список актеров
-//	@Description	Возвращает всех актеров
+//	@Description	Возвращает всех актеров. JSON и NDJSON (application/x-ndjson) отдаются по мере чтения из базы
 //	@Tags			actors
 //	@Accept			json
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Param			lang query string false "Язык имен и названий (ISO 639-1)" example(en)
+//	@Param			Accept-Language header string false "Язык, если не задан lang"
 //	@Success		200	{array}		domain.Actor
 //	@Failure		400	{object}	errorResponse
 //	@Failure		500	{object}	errorResponse
@@ -27,34 +39,19 @@ func (h *Handler) ListActors(w http.ResponseWriter, r *http.Request) {
 		slog.String("method", method),
 	)
 
-	actors, err := h.services.ListActors(-1)
+	localizer, err := h.services.Localizer(r.Context(), requestLang(r))
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "server error",
-			"Failed to get actors list. Please, try again later", err.Error())
-		return
-	}
-
-	for i := range actors {
-		actors[i].Films, err = h.services.ListFilms(sortRating, descSort, actors[i].Id)
-		if err != nil {
-			newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "server error",
-				"Failed to get actors list. Please, try again later", err.Error())
-			return
-		}
-		if actors[i].Films == nil {
-			actors[i].Films = []domain.Film{}
-		}
-	}
-
-	if actors == nil {
-		w.WriteHeader(http.StatusNotFound)
-		resp, _ := json.Marshal([]domain.Actor{})
-		w.Write(resp)
+		actorErrResponse(log, w, r, err)
 		return
 	}
+	w.Header().Set("Vary", "Accept-Language")
 
-	resp, _ := json.Marshal(actors)
-	w.Write(resp)
+	h.streamList(log, w, r, http.StatusNotFound, func(send func(v any) error) error {
+		return h.services.StreamActors(r.Context(), func(actor domain.Actor) error {
+			localizer.Actor(&actor)
+			return send(actor)
+		})
+	}, actorErrResponse)
 }
 
 // CreateActor godoc
@@ -65,6 +62,7 @@ func (h *Handler) ListActors(w http.ResponseWriter, r *http.Request) {
 //	@Produce		json
 //	@Param	actor	body			domain.Actor	true "Информация об актере"
 //	@Success		201	{object}	domain.Actor
+//	@Header			201	{string}	ETag	"Версия актера"
 //	@Failure		400	{object}	errorResponse
 //	@Failure		500	{object}	errorResponse
 //	@Router			/actors/ [post]
@@ -76,41 +74,97 @@ func (h *Handler) CreateActor(w http.ResponseWriter, r *http.Request) {
 	var actor domain.Actor
 	err := json.NewDecoder(r.Body).Decode(&actor)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "json parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
 			"Failed to parse json. Please, check your input", err.Error())
 		return
 	}
-	validate := validator.New()
-	err = validate.Struct(actor)
+	err = newValidator().Struct(actor)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "Validation error",
-			"Couldn't validate input fields. Please, fix input and try again", vErr.Error())
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
-	actor.Id, err = h.services.CreateActor(actor)
+	userId, _ := getUserId(r)
+	actor.Id, err = h.services.CreateActor(r.Context(), userId, actor)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "Create actor error",
-			"Failed to create new actor. Please, try again later", err.Error())
+		actorErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, _ := json.Marshal(actor)
+	setETag(w, newRowVersion)
 	w.WriteHeader(http.StatusCreated)
 	w.Write(resp)
 }
 
+// GetActor godoc
+//
+//		@Summary		Информация об актере
+//		@Description	Возвращает актера вместе с фильмами. ETag зависит от версии актера, фильмов, языка и формата,
+//		@Description	в If-Match при изменении из него берется только версия
+//		@Tags			actors
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param	actor_id path int true "ИД актера"
+//	 	@Param	lang query string false "Язык имен и названий (ISO 639-1)" example(en)
+//	 	@Param	Accept-Language header string false "Язык, если не задан lang"
+//	 	@Param	If-None-Match header string false "ETag, полученный ранее"
+//		@Success		200	{object}	domain.Actor
+//		@Header			200	{string}	ETag	"Версия и хеш ответа"
+//		@Success		304
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/{actor_id}/ [get]
+func (h *Handler) GetActor(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Actor.GetActor"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	id, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+
+	actor, err := h.services.GetActor(r.Context(), id)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+	actor.Films, err = h.services.ListFilms(r.Context(), sortRating, descSort, actor.Id, domain.FilmFilter{})
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+	if actor.Films == nil {
+		actor.Films = []domain.Film{}
+	}
+	actors := []domain.Actor{actor}
+	if err = h.services.LocalizeActors(r.Context(), requestLang(r), actors); err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respondRecord(log, w, r, actor.Version, actors[0])
+}
+
 // DeleteActor godoc
 //
 //		@Summary		Удалить информацию об актере
+//		@Description	Перемещает актера в корзину, откуда его можно восстановить до очистки
 //		@Tags			actors
 //		@Accept			json
 //		@Produce		json
 //	 	@Param	actor_id path int true "ИД актера"
+//	 	@Param	If-Match header string false "ETag, полученный при чтении актера"
 //		@Success		200
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
 //		@Router			/actors/ [delete]
 func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 	const method = "Handlers.Actor.DeleteActor"
@@ -119,16 +173,21 @@ func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 	)
 	id, err := strconv.Atoi(r.PathValue("actor_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "param error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
 			"Failed to get actor id. Please, check your input", err.Error())
 		return
 	}
 	log = h.log.With(slog.Int("actor id", id))
+	version, err := ifMatch(r)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
 
-	err = h.services.DeleteActor(id)
+	userId, _ := getUserId(r)
+	err = h.services.DeleteActor(r.Context(), userId, id, version)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "delete error",
-			"Specified actor not found", err.Error())
+		actorErrResponse(log, w, r, err)
 		return
 	}
 }
@@ -140,8 +199,12 @@ func (h *Handler) DeleteActor(w http.ResponseWriter, r *http.Request) {
 //		@Accept			json
 //		@Produce		json
 //	 	@Param	actorInput	body	domain.ActorInput	true "Данные для создания актера"
+//	 	@Param	If-Match header string false "ETag, полученный при чтении актера"
 //		@Success		200	{object}	domain.Actor
+//		@Header			200	{string}	ETag	"Новая версия актера"
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
 //		@Failure		500	{object}	errorResponse
 //		@Router			/actors/ [patch]
 func (h *Handler) PatchActor(w http.ResponseWriter, r *http.Request) {
@@ -152,36 +215,38 @@ func (h *Handler) PatchActor(w http.ResponseWriter, r *http.Request) {
 	var input domain.ActorInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "data parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
 			"Failed to parse data. Please, check your input", err.Error())
 		return
 	}
 
-	validate := validator.New()
-	err = validate.Struct(input)
+	err = newValidator().Struct(input)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "validation error",
-			"Couldn't validate input fields. Please, fix input and try again", vErr.Error())
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
 	input.Id, err = strconv.Atoi(r.PathValue("actor_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "error getting actor id",
+		newErrResponse(log, w, r, http.StatusBadRequest, "error getting actor id",
 			"failed to get user id. Please, check your input and try again", err.Error())
 		return
 	}
+	input.Version, err = ifMatch(r)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
 
-	actor, err := h.services.PatchActor(input)
+	userId, _ := getUserId(r)
+	actor, err := h.services.PatchActor(r.Context(), userId, input)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "patch error",
-			"failed to save data, try again later", err.Error())
+		actorErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, err := json.Marshal(actor)
+	setETag(w, actor.Version)
 	w.WriteHeader(http.StatusOK)
 	w.Write(resp)
 }
@@ -193,8 +258,12 @@ func (h *Handler) PatchActor(w http.ResponseWriter, r *http.Request) {
 //		@Accept			json
 //		@Produce		json
 //	    @Param	actor body domain.Actor true "Updated actor info"
+//	 	@Param	If-Match header string false "ETag, полученный при чтении актера"
 //		@Success		200	{object}	domain.Actor
+//		@Header			200	{string}	ETag	"Новая версия актера"
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
 //		@Failure		500	{object}	errorResponse
 //		@Router			/actors/ [put]
 func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
@@ -207,34 +276,37 @@ func (h *Handler) UpdateActor(w http.ResponseWriter, r *http.Request) {
 	var err error
 	actor.Id, err = strconv.Atoi(r.PathValue("actor_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "error getting actor id",
+		newErrResponse(log, w, r, http.StatusBadRequest, "error getting actor id",
 			"failed to get user id. Please, check your input and try again", err.Error())
 		return
 	}
 	err = json.NewDecoder(r.Body).Decode(&actor)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "json parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
 			"Failed to parse json. Please, check your input", err.Error())
 		return
 	}
-	validate := validator.New()
-	err = validate.Struct(actor)
+	err = newValidator().Struct(actor)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	actor.Version, err = ifMatch(r)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "validation error",
-			"Couldn't validate input fields. Please, fix input and try again", vErr.Error())
+		actorErrResponse(log, w, r, err)
 		return
 	}
 
-	err = h.services.UpdateActor(actor)
+	userId, _ := getUserId(r)
+	version, err := h.services.UpdateActor(r.Context(), userId, actor)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "error updating actor",
-			"Failed to save data, try again later", err.Error())
+		actorErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, _ := json.Marshal(actor)
+	setETag(w, version)
 	w.Write(resp)
 	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultAuditLimit = 100
+
+// clientIP is the address the request came from. Forwarding headers are ignored since clients can forge them
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// audit records a security event with the client address and user agent of the request.
+// A failure is only logged, it must not change the outcome of the request.
+// The event is kept even if the client has already gone away
+func (h *Handler) audit(r *http.Request, event string, userId *int, username *string, details map[string]any) {
+	e := domain.AuditEvent{
+		Event:     event,
+		UserId:    userId,
+		Username:  username,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if details != nil {
+		e.Details, _ = json.Marshal(details)
+	}
+	if err := h.services.RecordEvent(context.WithoutCancel(r.Context()), e); err != nil {
+		h.log.With(slog.String("event", event), slog.String("err", err.Error())).
+			Error("failed to record audit event")
+	}
+}
+
+// parseAuditFilter reads audit log filters from the query string, from and to are RFC 3339 times
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{Limit: defaultAuditLimit}
+	strs := map[string]**string{
+		"event":    &filter.Event,
+		"username": &filter.Username,
+		"ip":       &filter.IP,
+	}
+	for name, dst := range strs {
+		if v := query.Get(name); v != "" {
+			*dst = &v
+		}
+	}
+	if v := query.Get("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return filter, fmt.Errorf("incorrect user_id value %q", v)
+		}
+		filter.UserId = &id
+	}
+
+	times := map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}
+	for name, dst := range times {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("incorrect %s value %q, expected RFC 3339 time", name, v)
+			}
+			*dst = &t
+		}
+	}
+
+	ints := map[string]*int{
+		"limit":  &filter.Limit,
+		"offset": &filter.Offset,
+	}
+	for name, dst := range ints {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return filter, fmt.Errorf("incorrect %s value %q", name, v)
+			}
+			*dst = n
+		}
+	}
+
+	return filter, nil
+}
+
+// ListAuditEvents godoc
+//
+//		@Summary		Журнал безопасности
+//		@Description	События безопасности, от новых к старым: входы, регистрации, смена ролей, отказы в доступе
+//		@Tags			audit
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			event query string false "Тип события" Enums(sign_in, sign_in_failed, sign_up, sign_up_failed, role_change, admin_denied)
+//	 	@Param			user_id query int false "ИД пользователя"
+//	 	@Param			username query string false "Имя пользователя"
+//	 	@Param			ip query string false "IP адрес клиента"
+//	 	@Param			from query string false "Начало периода (RFC 3339)" example(2024-05-01T00:00:00Z)
+//	 	@Param			to query string false "Конец периода, не включая (RFC 3339)"
+//	 	@Param			limit query int false "Количество событий, до 1000" default(100)
+//	 	@Param			offset query int false "Смещение"
+//		@Success		200	{array}		domain.AuditEvent
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/audit/ [get]
+func (h *Handler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Audit.ListAuditEvents"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error", err.Error(), err.Error())
+		return
+	}
+	if err = newValidator().Struct(filter); err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	events, err := h.services.ListAuditEvents(r.Context(), filter)
+	if err != nil {
+		errResponse(log, w, r, err, nil)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, events)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_Audit(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("SignInFailed", func(t *testing.T) {
+		auth, audit := mocks.NewAuthorization(t), mocks.NewAudit(t)
+		auth.On("SignIn", mock.Anything, "nikita", "password1").Return("", 0, service.ErrUnauthorized)
+		audit.On("RecordEvent", mock.Anything, mock.MatchedBy(func(e domain.AuditEvent) bool {
+			return e.Event == domain.AuditSignInFailed && *e.Username == "nikita" &&
+				e.IP == "10.0.0.7" && e.UserAgent == "curl/8.0"
+		})).Return(nil)
+		h := NewHandler(&service.Service{Authorization: auth, Audit: audit}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/",
+			strings.NewReader(`{"username":"nikita","password":"password1"}`))
+		r.RemoteAddr = "10.0.0.7:51234"
+		r.Header.Set("User-Agent", "curl/8.0")
+		rec := httptest.NewRecorder()
+		h.SignIn(rec, r)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+	t.Run("SignIn", func(t *testing.T) {
+		auth, audit := mocks.NewAuthorization(t), mocks.NewAudit(t)
+		auth.On("SignIn", mock.Anything, "nikita", "password1").Return("token", 3, nil)
+		audit.On("RecordEvent", mock.Anything, mock.MatchedBy(func(e domain.AuditEvent) bool {
+			return e.Event == domain.AuditSignIn && e.UserId != nil && *e.UserId == 3 &&
+				*e.Username == "nikita"
+		})).Return(nil)
+		h := NewHandler(&service.Service{Authorization: auth, Audit: audit}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/",
+			strings.NewReader(`{"username":"nikita","password":"password1"}`))
+		rec := httptest.NewRecorder()
+		h.SignIn(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+	t.Run("AdminDenied", func(t *testing.T) {
+		auth, audit := mocks.NewAuthorization(t), mocks.NewAudit(t)
+		auth.On("GetUserById", mock.Anything, 3).Return(domain.User{Id: 3, Username: "nikita", Role: ROLE_CLIENT}, nil)
+		audit.On("RecordEvent", mock.Anything, mock.MatchedBy(func(e domain.AuditEvent) bool {
+			return e.Event == domain.AuditAdminDenied && *e.UserId == 3 &&
+				strings.Contains(e.Details.String(), `"path":"/api/v1/trash/"`)
+		})).Return(nil)
+		h := NewHandler(&service.Service{Authorization: auth, Audit: audit}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/trash/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "user", 3))
+		rec := httptest.NewRecorder()
+		h.CheckAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler must not be reached")
+		})).ServeHTTP(rec, r)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	t.Run("Range", func(t *testing.T) {
+		filter, err := parseAuditFilter(url.Values{
+			"event": {"sign_in_failed"}, "from": {"2024-05-01T00:00:00Z"}, "limit": {"20"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "sign_in_failed", *filter.Event)
+		assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), *filter.From)
+		assert.Nil(t, filter.To)
+		assert.Equal(t, 20, filter.Limit)
+		assert.NoError(t, newValidator().Struct(filter))
+	})
+	t.Run("Defaults", func(t *testing.T) {
+		filter, err := parseAuditFilter(url.Values{})
+		assert.NoError(t, err)
+		assert.Equal(t, defaultAuditLimit, filter.Limit)
+	})
+	t.Run("BadTime", func(t *testing.T) {
+		_, err := parseAuditFilter(url.Values{"to": {"2024-05-01"}})
+		assert.Error(t, err)
+	})
+	t.Run("UnknownEvent", func(t *testing.T) {
+		filter, err := parseAuditFilter(url.Values{"event": {"token_refresh"}})
+		assert.NoError(t, err)
+		assert.Error(t, newValidator().Struct(filter))
+	})
+}
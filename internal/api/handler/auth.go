@@ -2,13 +2,12 @@ package handler
 
 import (
 	"encoding/json"
-	"errors"
-	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
-	"github.com/go-playground/validator/v10"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 )
 
 type AuthRequest struct {
@@ -39,28 +38,26 @@ func (h *Handler) SignIn(w http.ResponseWriter, r *http.Request) {
 	var auth AuthRequest
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "Server error",
+		newErrResponse(log, w, r, http.StatusInternalServerError, "Server error",
 			"Server error. Please, try again or later", err.Error())
 		return
 	}
 	err = json.Unmarshal(body, &auth)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest,
-			r.Host+r.RequestURI, "Wrong input", "Error parsing body. Please, check your input", err.Error())
+		newErrResponse(log, w, r, http.StatusBadRequest, "Wrong input",
+			"Error parsing body. Please, check your input", err.Error())
 		return
 	}
-	token, err := h.services.SignIn(auth.Username, auth.Password)
+	token, userId, err := h.services.SignIn(r.Context(), auth.Username, auth.Password)
 	if err != nil {
-		if errors.Is(err, service.ErrUnauthorized) || errors.Is(err, service.ErrUserNotFound) {
-			newErrResponse(log, w, http.StatusUnauthorized,
-				r.Host+r.RequestURI, "Wrong auth credentials",
-				"Incorrect login or password. Please, check your credentials", err.Error())
-		} else {
-			newErrResponse(log, w, http.StatusInternalServerError,
-				r.Host+r.RequestURI, "Server error", "Please, try again or later", err.Error())
-		}
+		h.audit(r, domain.AuditSignInFailed, nil, &auth.Username, map[string]any{"error": err.Error()})
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeUnauthorized: "Incorrect login or password. Please, check your credentials",
+		})
 		return
 	}
+	h.audit(r, domain.AuditSignIn, &userId, &auth.Username, nil)
+
 	response, err := json.Marshal(SignInResponse{Token: token})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -88,36 +85,97 @@ type SignUpResponse struct {
 //		@Failure		401	{object}	errorResponse
 //		@Router			/signup/ [post]
 func (h *Handler) SignUp(w http.ResponseWriter, r *http.Request) {
+	const op = "Handlers.Auth.SignUp"
+	log := h.log.With(slog.String("op", op))
+
 	var user domain.User
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		newErrResponse(log, w, r, http.StatusInternalServerError, "Server error",
+			"Server error. Please, try again or later", err.Error())
 		return
 	}
 
 	err = json.Unmarshal(body, &user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		newErrResponse(log, w, r, http.StatusBadRequest, "Wrong input",
+			"Error parsing body. Please, check your input", err.Error())
 		return
 	}
 
-	validate := validator.New()
-	err = validate.Struct(user)
+	err = newValidator().Struct(user)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
-	err = h.services.SignUp(user)
+	err = h.services.SignUp(r.Context(), user)
 	if err != nil {
-		if errors.Is(err, service.ErrBadRequest) {
-			w.WriteHeader(http.StatusBadRequest)
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
+		h.audit(r, domain.AuditSignUpFailed, nil, &user.Username, map[string]any{"error": err.Error()})
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeInvalid: "User can't be created. Please, check your input",
+		})
 		return
 	}
 
+	h.audit(r, domain.AuditSignUp, nil, &user.Username, nil)
+
 	resp, _ := json.Marshal(SignUpResponse{Status: http.StatusCreated})
 	w.Write(resp)
 }
+
+type SetUserRoleResponse struct {
+	UserId int  `json:"userId"`
+	Role   int8 `json:"role"`
+}
+
+// SetUserRole godoc
+//
+//		@Summary		Роль пользователя
+//		@Description	Назначить пользователю роль: 1 - клиент, 2 - администратор
+//		@Tags			auth
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			user_id path int true "ИД пользователя"
+//	 	@Param			input body domain.RoleInput true "Новая роль"
+//		@Success		200 {object}	SetUserRoleResponse
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/users/{user_id}/role/ [put]
+func (h *Handler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	const op = "Handlers.Auth.SetUserRole"
+	log := h.log.With(slog.String("op", op))
+
+	targetId, err := strconv.Atoi(r.PathValue("user_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get user id. Please, check your input", err.Error())
+		return
+	}
+	var input domain.RoleInput
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "Wrong input",
+			"Error parsing body. Please, check your input", err.Error())
+		return
+	}
+	if err = newValidator().Struct(input); err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	previous, err := h.services.SetUserRole(r.Context(), targetId, input.Role)
+	if err != nil {
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeNotFound: "Specified user not found",
+		})
+		return
+	}
+	userId, _ := getUserId(r)
+	h.audit(r, domain.AuditRoleChange, &userId, nil, map[string]any{
+		"targetUserId": targetId, "from": previous, "to": input.Role,
+	})
+
+	resp, _ := json.Marshal(SetUserRoleResponse{UserId: targetId, Role: input.Role})
+	w.Write(resp)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// CoStars godoc
+//
+//		@Summary		Партнеры актера
+//		@Description	Актеры, снимавшиеся вместе с указанным, по убыванию числа общих фильмов
+//		@Tags			actors
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			actor_id path int true "ИД актера"
+//	 	@Param			limit query int false "Количество актеров" example(10)
+//		@Success		200	{array}		domain.CoStar
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/{actor_id}/costars/ [get]
+func (h *Handler) CoStars(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collaboration.CoStars"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+	limit, err := parseLimit(r)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error", err.Error(), err.Error())
+		return
+	}
+
+	coStars, err := h.services.CoStars(r.Context(), actorId, limit)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, coStars)
+}
+
+// ActorPath godoc
+//
+//		@Summary		Степени разделения
+//		@Description	Кратчайшая цепочка партнеров между двумя актерами с соединяющими фильмами
+//		@Tags			actors
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			from query int true "ИД первого актера"
+//	 	@Param			to query int true "ИД второго актера"
+//		@Success		200	{object}	domain.ActorPath
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/path/ [get]
+func (h *Handler) ActorPath(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collaboration.ActorPath"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	fromId, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get first actor id. Please, check your input", err.Error())
+		return
+	}
+	toId, err := strconv.Atoi(r.URL.Query().Get("to"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get second actor id. Please, check your input", err.Error())
+		return
+	}
+
+	path, err := h.services.ActorPath(r.Context(), fromId, toId)
+	if err != nil {
+		if errors.Is(err, service.ErrNoPath) {
+			errResponse(log, w, r, err, errDetails{
+				apperr.CodeNotFound: "Actors are not connected through any films",
+			})
+			return
+		}
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, path)
+}
+
+// ExportActorsGraph godoc
+//
+//		@Summary		Граф совместных работ
+//		@Description	Выгрузка графа актеров (вес ребра - число общих фильмов) в формате GraphML или DOT
+//		@Tags			actors
+//		@Produce		xml
+//		@Produce		plain
+//	 	@Param			format query string false "Формат выгрузки" Enums(graphml, dot)
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/graph/ [get]
+func (h *Handler) ExportActorsGraph(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collaboration.ExportActorsGraph"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.GraphFormatGraphML
+	}
+
+	var buf bytes.Buffer
+	err := h.services.ExportGraph(r.Context(), &buf, format)
+	if err != nil {
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeInvalid: "Unsupported format. Please, use graphml or dot",
+		})
+		return
+	}
+
+	if format == service.GraphFormatDOT {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+	} else {
+		w.Header().Set("Content-Type", "application/graphml+xml")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=collaborations."+format)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,441 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func collectionErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound:  "Specified collection not found",
+		apperr.CodeForbidden: "Only the owner can modify this collection",
+		apperr.CodeInvalid:   "Collection contains unknown or repeated films. Please, check your input",
+	})
+}
+
+// ListCollections godoc
+//
+//	@Summary		Мои подборки
+//	@Description	Список подборок текущего пользователя
+//	@Tags			collections
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Collection
+//	@Failure		500	{object}	errorResponse
+//	@Router			/collections/ [get]
+func (h *Handler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.ListCollections"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	userId, _ := getUserId(r)
+	collections, err := h.services.ListCollections(r.Context(), userId)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+	if collections == nil {
+		collections = []domain.Collection{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, collections)
+}
+
+// ListFollowedCollections godoc
+//
+//	@Summary		Отслеживаемые подборки
+//	@Description	Список публичных подборок, на которые подписан пользователь
+//	@Tags			collections
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Collection
+//	@Failure		500	{object}	errorResponse
+//	@Router			/collections/followed/ [get]
+func (h *Handler) ListFollowedCollections(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.ListFollowedCollections"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	userId, _ := getUserId(r)
+	collections, err := h.services.ListFollowedCollections(r.Context(), userId)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+	if collections == nil {
+		collections = []domain.Collection{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, collections)
+}
+
+// GetCollection godoc
+//
+//		@Summary		Подборка
+//		@Description	Получить свою или публичную подборку
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200	{object}	domain.Collection
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/ [get]
+func (h *Handler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.GetCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	collection, err := h.services.GetCollection(r.Context(), userId, id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, collection)
+}
+
+// GetSharedCollection godoc
+//
+//		@Summary		Публичная ссылка на подборку
+//		@Description	Получить публичную подборку по ссылке без авторизации
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			token path string true "Токен ссылки"
+//		@Success		200	{object}	domain.Collection
+//		@Failure		404	{object}	errorResponse
+//		@Router			/shared/collections/{token}/ [get]
+func (h *Handler) GetSharedCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.GetSharedCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	collection, err := h.services.GetSharedCollection(r.Context(), r.PathValue("token"))
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, collection)
+}
+
+// CreateCollection godoc
+//
+//		@Summary		Создать подборку
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection body domain.Collection true "Подборка с упорядоченным списком фильмов"
+//		@Success		201	{object}	domain.Collection
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/collections/ [post]
+func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.CreateCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var collection domain.Collection
+	err := json.NewDecoder(r.Body).Decode(&collection)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(collection)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	collection.Id, err = h.services.CreateCollection(r.Context(), userId, collection)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	collection, err = h.services.GetCollection(r.Context(), userId, collection.Id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusCreated, collection)
+}
+
+// UpdateCollection godoc
+//
+//		@Summary		Обновить подборку
+//		@Description	Полная замена подборки вместе со списком фильмов
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection body domain.Collection true "Данные подборки"
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200	{object}	domain.Collection
+//		@Failure		400	{object}	errorResponse
+//		@Failure		403	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/ [put]
+func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.UpdateCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var collection domain.Collection
+	err := json.NewDecoder(r.Body).Decode(&collection)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	collection.Id, err = strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(collection)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.UpdateCollection(r.Context(), userId, collection)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	collection, err = h.services.GetCollection(r.Context(), userId, collection.Id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, collection)
+}
+
+// PatchCollection godoc
+//
+//		@Summary		Редактировать подборку
+//		@Description	Частичное изменение подборки, в т.ч. переключение публичности
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			input body domain.CollectionInput true "Данные для обновления"
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200	{object}	domain.Collection
+//		@Failure		400	{object}	errorResponse
+//		@Failure		403	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/ [patch]
+func (h *Handler) PatchCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.PatchCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var input domain.CollectionInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	input.Id, err = strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	collection, err := h.services.PatchCollection(r.Context(), userId, input)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, collection)
+}
+
+// DeleteCollection godoc
+//
+//		@Summary		Удалить подборку
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		403	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/ [delete]
+func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.DeleteCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.DeleteCollection(r.Context(), userId, id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// FollowCollection godoc
+//
+//		@Summary		Подписаться на подборку
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/follow/ [post]
+func (h *Handler) FollowCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.FollowCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.FollowCollection(r.Context(), userId, id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// UnfollowCollection godoc
+//
+//		@Summary		Отписаться от подборки
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/follow/ [delete]
+func (h *Handler) UnfollowCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.UnfollowCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.UnfollowCollection(r.Context(), userId, id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// CloneCollection godoc
+//
+//		@Summary		Скопировать подборку
+//		@Description	Создает приватную копию своей или публичной подборки
+//		@Tags			collections
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			collection_id path int true "ИД подборки"
+//		@Success		201	{object}	domain.Collection
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/collections/{collection_id}/clone/ [post]
+func (h *Handler) CloneCollection(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Collection.CloneCollection"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("collection_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get collection id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	cloneId, err := h.services.CloneCollection(r.Context(), userId, id)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	collection, err := h.services.GetCollection(r.Context(), userId, cloneId)
+	if err != nil {
+		collectionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusCreated, collection)
+}
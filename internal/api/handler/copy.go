@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func copyErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified copy or film not found",
+		apperr.CodeConflict: "Copy is already lent. Please, return it first",
+		apperr.CodeInvalid:  "Incorrect film, borrower or due date. Please, check your input",
+	})
+}
+
+// ListCopies godoc
+//
+//		@Summary		Экземпляры фильма
+//		@Description	Список физических носителей фильма, кроме фильмов в корзине
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{array}		domain.Copy
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/copies/ [get]
+func (h *Handler) ListCopies(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.ListCopies"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	copies, err := h.services.ListCopies(r.Context(), filmId)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+	if copies == nil {
+		copies = []domain.Copy{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, copies)
+}
+
+// CreateCopy godoc
+//
+//		@Summary		Добавить экземпляр
+//		@Description	Добавить физический носитель фильма
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			copy body domain.Copy true "Данные носителя"
+//		@Success		201	{object}	domain.Copy
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/copies/ [post]
+func (h *Handler) CreateCopy(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.CreateCopy"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var c domain.Copy
+	err := json.NewDecoder(r.Body).Decode(&c)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	c.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(c)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	c.Id, err = h.services.CreateCopy(r.Context(), c)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(c)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// UpdateCopy godoc
+//
+//		@Summary		Обновить экземпляр
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			copy_id path int true "ИД экземпляра"
+//	 	@Param			copy body domain.Copy true "Данные носителя"
+//		@Success		200	{object}	domain.Copy
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/copies/{copy_id}/ [put]
+func (h *Handler) UpdateCopy(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.UpdateCopy"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var c domain.Copy
+	err := json.NewDecoder(r.Body).Decode(&c)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	c.Id, err = strconv.Atoi(r.PathValue("copy_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect copy id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(c)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.UpdateCopy(r.Context(), c)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(c)
+	w.Write(resp)
+}
+
+// DeleteCopy godoc
+//
+//		@Summary		Удалить экземпляр
+//		@Description	Экземпляр, который выдавался, не удаляется, чтобы сохранить историю выдач
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			copy_id path int true "ИД экземпляра"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/copies/{copy_id}/ [delete]
+func (h *Handler) DeleteCopy(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.DeleteCopy"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("copy_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect copy id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteCopy(r.Context(), id)
+	if err != nil {
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeNotFound: "Specified copy not found",
+			apperr.CodeConflict: "Copy has been lent and keeps its loan history",
+		})
+		return
+	}
+}
+
+// LendCopy godoc
+//
+//		@Summary		Выдать экземпляр
+//		@Description	Выдать носитель пользователю (userId) или стороннему лицу (borrower)
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			copy_id path int true "ИД экземпляра"
+//	 	@Param			loan body domain.Loan true "Данные выдачи"
+//		@Success		201	{object}	domain.Loan
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/copies/{copy_id}/lend/ [post]
+func (h *Handler) LendCopy(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.LendCopy"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var loan domain.Loan
+	err := json.NewDecoder(r.Body).Decode(&loan)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	loan.CopyId, err = strconv.Atoi(r.PathValue("copy_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect copy id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(loan)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	loan.Id, err = h.services.LendCopy(r.Context(), loan)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(loan)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// ReturnCopy godoc
+//
+//		@Summary		Вернуть экземпляр
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			copy_id path int true "ИД экземпляра"
+//		@Success		200	{object}	domain.Loan
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/copies/{copy_id}/return/ [post]
+func (h *Handler) ReturnCopy(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.ReturnCopy"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("copy_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect copy id. Please, check your input", err.Error())
+		return
+	}
+
+	loan, err := h.services.ReturnCopy(r.Context(), id)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(loan)
+	w.Write(resp)
+}
+
+// ListLoans godoc
+//
+//		@Summary		История выдач
+//		@Description	История выдач экземпляра, начиная с последней
+//		@Tags			copies
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			copy_id path int true "ИД экземпляра"
+//		@Success		200	{array}		domain.Loan
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/copies/{copy_id}/loans/ [get]
+func (h *Handler) ListLoans(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.ListLoans"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("copy_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect copy id. Please, check your input", err.Error())
+		return
+	}
+
+	loans, err := h.services.ListLoans(r.Context(), id)
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+	if loans == nil {
+		loans = []domain.Loan{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, loans)
+}
+
+// ListOverdueLoans godoc
+//
+//	@Summary		Просроченные выдачи
+//	@Description	Невозвращенные экземпляры с истекшим сроком возврата
+//	@Tags			copies
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Loan
+//	@Failure		500	{object}	errorResponse
+//	@Router			/loans/overdue/ [get]
+func (h *Handler) ListOverdueLoans(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Copy.ListOverdueLoans"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	loans, err := h.services.ListOverdueLoans(r.Context())
+	if err != nil {
+		copyErrResponse(log, w, r, err)
+		return
+	}
+	if loans == nil {
+		loans = []domain.Loan{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, loans)
+}
@@ -0,0 +1,349 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"log/slog"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatCSV    = "csv"
+	formatXML    = "xml"
+)
+
+var formatContentTypes = map[string]string{
+	formatJSON:   "application/json",
+	formatNDJSON: "application/x-ndjson",
+	formatCSV:    "text/csv; charset=utf-8",
+	formatXML:    "application/xml; charset=utf-8",
+}
+
+// mediaFormats maps the accepted media ranges to response formats, json is the default
+var mediaFormats = map[string]string{
+	"*/*":                  formatJSON,
+	"application/*":        formatJSON,
+	"application/json":     formatJSON,
+	"application/x-ndjson": formatNDJSON,
+	"application/ndjson":   formatNDJSON,
+	"text/csv":             formatCSV,
+	"application/xml":      formatXML,
+	"text/xml":             formatXML,
+}
+
+// responseFormat picks the response format. The format query parameter wins over the Accept header,
+// whose media ranges are tried from the highest quality. No Accept header means json
+func responseFormat(r *http.Request) (string, bool) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		_, ok := formatContentTypes[format]
+		return format, ok
+	}
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return formatJSON, true
+	}
+
+	type mediaRange struct {
+		media   string
+		quality float64
+	}
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		media, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, mediaRange{media: media, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	for _, rng := range ranges {
+		if format, ok := mediaFormats[rng.media]; ok {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+func notAcceptable(log *slog.Logger, w http.ResponseWriter, r *http.Request) {
+	newErrResponse(log, w, r, http.StatusNotAcceptable, "not acceptable",
+		"Unsupported response type. Please, accept application/json, application/x-ndjson, text/csv or application/xml",
+		"can't satisfy Accept "+r.Header.Get("Accept"))
+}
+
+// respond encodes v in the format the client asked for: json, a line per element of a list for ndjson,
+// or csv and xml built from the json of v, so that every format has the same field names
+func (h *Handler) respond(log *slog.Logger, w http.ResponseWriter, r *http.Request, status int, v any) {
+	format, ok := responseFormat(r)
+	if !ok {
+		notAcceptable(log, w, r)
+		return
+	}
+
+	body, err := encode(format, v)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusInternalServerError, "server error",
+			"Internal error. Please, try again later", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// encode renders v in one of the response formats
+func encode(format string, v any) ([]byte, error) {
+	switch format {
+	case formatNDJSON:
+		return encodeNDJSON(v)
+	case formatCSV:
+		return encodeCSV(v)
+	case formatXML:
+		return encodeXML(v)
+	default:
+		return json.Marshal(v)
+	}
+}
+
+// encodeNDJSON writes the elements of a list, or v itself, a json document per line
+func encodeNDJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if list := reflect.ValueOf(v); list.Kind() == reflect.Slice {
+		for i := 0; i < list.Len(); i++ {
+			if err := enc.Encode(list.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		return buf.Bytes(), nil
+	}
+	err := enc.Encode(v)
+	return buf.Bytes(), err
+}
+
+// jsonObject is a decoded json object that keeps the order of its keys
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// jsonTree turns v into its json: objects, slices and scalars (strings, numbers, booleans and nils)
+func jsonTree(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+
+	if delim == '{' {
+		obj := jsonObject{values: make(map[string]any)}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyTok.(string)
+			if obj.values[key], err = decodeOrdered(dec); err != nil {
+				return nil, err
+			}
+			obj.keys = append(obj.keys, key)
+		}
+		_, err = dec.Token()
+		return obj, err
+	}
+
+	items := make([]any, 0)
+	for dec.More() {
+		item, err := decodeOrdered(dec)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	_, err = dec.Token()
+	return items, err
+}
+
+func scalarString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case json.Number:
+		return t.String()
+	default:
+		return ""
+	}
+}
+
+// csvRecord collects the values of one row by column
+type csvRecord struct {
+	columns *[]string
+	seen    map[string]struct{}
+	cells   map[string][]string
+}
+
+// add flattens v under the column name: object fields become name.field columns,
+// the values of lists are joined, so the actors of a film give actors.name with all their names
+func (rec csvRecord) add(name string, v any) {
+	switch t := v.(type) {
+	case jsonObject:
+		for _, key := range t.keys {
+			column := key
+			if name != "" {
+				column = name + "." + key
+			}
+			rec.add(column, t.values[key])
+		}
+	case []any:
+		for _, item := range t {
+			rec.add(name, item)
+		}
+	default:
+		if name == "" {
+			name = "value"
+		}
+		if _, ok := rec.seen[name]; !ok {
+			rec.seen[name] = struct{}{}
+			*rec.columns = append(*rec.columns, name)
+		}
+		rec.cells[name] = append(rec.cells[name], scalarString(t))
+	}
+}
+
+// encodeCSV writes a row per element of a list, or a single row. Columns are in the order of first appearance
+func encodeCSV(v any) ([]byte, error) {
+	tree, err := jsonTree(v)
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := tree.([]any)
+	if !ok {
+		rows = []any{tree}
+	}
+
+	columns := make([]string, 0)
+	seen := make(map[string]struct{})
+	records := make([]csvRecord, len(rows))
+	for i, row := range rows {
+		records[i] = csvRecord{columns: &columns, seen: seen, cells: make(map[string][]string)}
+		records[i].add("", row)
+	}
+
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	out.Write(columns)
+	for _, rec := range records {
+		line := make([]string, len(columns))
+		for i, column := range columns {
+			line[i] = strings.Join(rec.cells[column], ";")
+		}
+		out.Write(line)
+	}
+	out.Flush()
+	return buf.Bytes(), out.Error()
+}
+
+// isXMLName tells whether a json key can be used as an element name
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, c := range name {
+		if c == '_' || unicode.IsLetter(c) || i > 0 && (unicode.IsDigit(c) || c == '-' || c == '.') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func writeXML(enc *xml.Encoder, name string, v any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}}}
+	}
+	if v == nil {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "nil"}, Value: "true"})
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch t := v.(type) {
+	case jsonObject:
+		for _, key := range t.keys {
+			if err := writeXML(enc, key, t.values[key]); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range t {
+			if err := writeXML(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(scalarString(t))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// encodeXML writes the json of v as elements under a response root: object fields by their names
+// and list elements as item. Keys that can't be element names go to an entry with a key attribute
+func encodeXML(v any) ([]byte, error) {
+	tree, err := jsonTree(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err = writeXML(enc, "response", tree); err != nil {
+		return nil, err
+	}
+	if err = enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+type encodeActor struct {
+	Name   string `json:"name"`
+	Gender *int   `json:"gender"`
+}
+
+type encodeFilm struct {
+	Id        int           `json:"id"`
+	Title     string        `json:"title"`
+	Countries []string      `json:"countries"`
+	Actors    []encodeActor `json:"actors,omitempty"`
+}
+
+func TestResponseFormat(t *testing.T) {
+	testTable := []struct {
+		name   string
+		query  string
+		accept string
+		format string
+		ok     bool
+	}{
+		{name: "NoAccept", format: formatJSON, ok: true},
+		{name: "Any", accept: "*/*", format: formatJSON, ok: true},
+		{name: "CSV", accept: "text/csv", format: formatCSV, ok: true},
+		{name: "TextXML", accept: "text/xml", format: formatXML, ok: true},
+		{name: "NDJSON", accept: "application/x-ndjson", format: formatNDJSON, ok: true},
+		{name: "Quality", accept: "application/json;q=0.5, application/xml", format: formatXML, ok: true},
+		{name: "SkipUnknown", accept: "text/html, text/csv;q=0.1", format: formatCSV, ok: true},
+		{name: "Refused", accept: "text/csv;q=0, application/json", format: formatJSON, ok: true},
+		{name: "QueryWins", query: "?format=csv", accept: "application/xml", format: formatCSV, ok: true},
+		{name: "Unsupported", accept: "text/html", ok: false},
+		{name: "UnsupportedQuery", query: "?format=yaml", format: "yaml", ok: false},
+	}
+
+	for _, tc := range testTable {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/films/"+tc.query, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			format, ok := responseFormat(r)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.format, format)
+		})
+	}
+}
+
+func TestEncodeCSV(t *testing.T) {
+	gender := 1
+	films := []encodeFilm{
+		{Id: 1, Title: "Heat, 1995", Countries: []string{"US"}, Actors: []encodeActor{
+			{Name: "Al Pacino", Gender: &gender}, {Name: "Val Kilmer"},
+		}},
+		{Id: 2, Title: "Solaris", Countries: []string{"SU", "DE"}},
+	}
+
+	body, err := encodeCSV(films)
+	require.NoError(t, err)
+	assert.Equal(t, "id,title,countries,actors.name,actors.gender\n"+
+		"1,\"Heat, 1995\",US,Al Pacino;Val Kilmer,1;\n"+
+		"2,Solaris,SU;DE,,\n", string(body))
+
+	body, err = encodeCSV(films[1])
+	require.NoError(t, err)
+	assert.Equal(t, "id,title,countries\n2,Solaris,SU;DE\n", string(body))
+}
+
+func TestEncodeXML(t *testing.T) {
+	body, err := encodeXML([]encodeFilm{{Id: 1, Title: "Heat & Co", Actors: []encodeActor{{Name: "Al Pacino"}}}})
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<response><item><id>1</id><title>Heat &amp; Co</title><countries nil="true"></countries>`+
+		`<actors><item><name>Al Pacino</name><gender nil="true"></gender></item></actors></item></response>`, string(body))
+
+	body, err = encodeXML(map[string]int{"1st": 1})
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `<response><entry key="1st">1</entry></response>`)
+}
+
+func TestHandler_respond(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	h := NewHandler(&service.Service{}, log)
+	film := encodeFilm{Id: 1, Title: "Solaris"}
+
+	t.Run("JSON", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/1", nil)
+		rec := httptest.NewRecorder()
+		h.respond(log, rec, r, http.StatusOK, film)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var got encodeFilm
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, film, got)
+	})
+	t.Run("CSV", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/1", nil)
+		r.Header.Set("Accept", "text/csv")
+		rec := httptest.NewRecorder()
+		h.respond(log, rec, r, http.StatusNotFound, []encodeFilm{})
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+		assert.Equal(t, "\n", rec.Body.String())
+	})
+	t.Run("XML", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/1?format=xml", nil)
+		rec := httptest.NewRecorder()
+		h.respond(log, rec, r, http.StatusOK, film)
+
+		assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.True(t, strings.HasSuffix(rec.Body.String(), "<title>Solaris</title><countries nil=\"true\"></countries></response>"))
+	})
+	t.Run("NDJSON", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/?format=ndjson", nil)
+		rec := httptest.NewRecorder()
+		h.respond(log, rec, r, http.StatusOK, []encodeFilm{film, {Id: 2, Title: "Stalker"}})
+
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		assert.Equal(t, `{"id":1,"title":"Solaris","countries":null}`+"\n"+
+			`{"id":2,"title":"Stalker","countries":null}`+"\n", rec.Body.String())
+	})
+	t.Run("NotAcceptable", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/1", nil)
+		r.Header.Set("Accept", "text/html")
+		rec := httptest.NewRecorder()
+		h.respond(log, rec, r, http.StatusOK, film)
+
+		assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// newRowVersion is the version the database gives to a freshly inserted film or actor
+const newRowVersion = 1
+
+// etag turns a row version into a strong entity tag
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// representationTag is the entity tag of a film or an actor as read: the row version and a digest
+// of the body, which also depends on the cast or the films, the language and the format
+func representationTag(version int, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.Itoa(version) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ifMatch returns the version a write is conditioned on. 0 means any version: If-Match is absent or "*".
+// Only a single strong tag is supported, weak tags never match. A representation tag is taken
+// for its version: any representation of the current version may be written over
+func ifMatch(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return 0, service.ErrPrecondition
+	}
+
+	unquoted := strings.TrimSuffix(strings.TrimPrefix(header, `"`), `"`)
+	if len(unquoted)+2 != len(header) {
+		return 0, apperr.New(apperr.CodeInvalid, "malformed If-Match header "+header)
+	}
+	unquoted, digest, tagged := strings.Cut(unquoted, "-")
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 || (tagged && digest == "") {
+		return 0, apperr.New(apperr.CodeInvalid, "malformed If-Match header "+header)
+	}
+	return version, nil
+}
+
+// notModified reports whether If-None-Match lists the current tag. Tags are compared weakly
+func notModified(r *http.Request, current string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// respondRecord answers a read of a film or an actor with v tagged by representationTag,
+// or with 304 while If-None-Match still lists the tag. Both vary on the language and the format
+func (h *Handler) respondRecord(log *slog.Logger, w http.ResponseWriter, r *http.Request, version int, v any) {
+	w.Header().Set("Vary", "Accept-Language")
+	w.Header().Add("Vary", "Accept")
+	format, ok := responseFormat(r)
+	if !ok {
+		notAcceptable(log, w, r)
+		return
+	}
+	body, err := encode(format, v)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusInternalServerError, "server error",
+			"Internal error. Please, try again later", err.Error())
+		return
+	}
+
+	tag := representationTag(version, body)
+	w.Header().Set("ETag", tag)
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", formatContentTypes[format])
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		Label   string
+		header  string
+		version int
+		code    string
+	}{
+		{Label: "Absent", version: 0},
+		{Label: "Any", header: "*", version: 0},
+		{Label: "Strong", header: `"3"`, version: 3},
+		{Label: "Representation", header: `"3-5d41402abc4b2a76"`, version: 3},
+		{Label: "EmptyDigest", header: `"3-"`, code: "invalid_input"},
+		{Label: "Weak", header: `W/"3"`, code: "precondition_failed"},
+		{Label: "Unquoted", header: "3", code: "invalid_input"},
+		{Label: "List", header: `"3", "4"`, code: "invalid_input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/api/v1/films/5/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+			version, err := ifMatch(r)
+			if tt.code != "" {
+				rec := httptest.NewRecorder()
+				errResponse(slog.New(slog.NewJSONHandler(os.Stdout, nil)), rec, r, err, nil)
+				assert.Equal(t, tt.code, decodeErrResponse(t, rec).Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
+
+func TestHandler_Conditional(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	getFilm := func(t *testing.T, cast []domain.Actor, query string, header http.Header) *httptest.ResponseRecorder {
+		films, actors, translations := mocks.NewFilm(t), mocks.NewActor(t), mocks.NewTranslation(t)
+		films.On("GetFilm", mock.Anything, 5).Return(domain.Film{Id: 5, Title: "Matrix", Version: 3}, nil)
+		actors.On("ListActors", mock.Anything, 5).Return(cast, nil)
+		translations.On("LocalizeFilms", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		h := NewHandler(&service.Service{Film: films, Actor: actors, Translation: translations}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/5/"+query, nil)
+		r.SetPathValue("film_id", "5")
+		for key := range header {
+			r.Header.Set(key, header.Get(key))
+		}
+		rec := httptest.NewRecorder()
+		h.GetFilm(rec, r)
+		return rec
+	}
+	cast := []domain.Actor{{Id: 1, Name: "Keanu Reeves"}}
+
+	t.Run("GetFilm", func(t *testing.T) {
+		rec := getFilm(t, cast, "", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Regexp(t, `^"3-[0-9a-f]{16}"$`, rec.Header().Get("ETag"))
+		assert.Equal(t, []string{"Accept-Language", "Accept"}, rec.Header().Values("Vary"))
+		assert.Contains(t, rec.Body.String(), `"title":"Matrix"`)
+	})
+	t.Run("GetFilmNotModified", func(t *testing.T) {
+		tag := getFilm(t, cast, "", nil).Header().Get("ETag")
+		rec := getFilm(t, cast, "", http.Header{"If-None-Match": {`"2", W/` + tag}})
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Equal(t, tag, rec.Header().Get("ETag"))
+		assert.Equal(t, []string{"Accept-Language", "Accept"}, rec.Header().Values("Vary"))
+		assert.Empty(t, rec.Body.String())
+	})
+	t.Run("GetFilmCastChanged", func(t *testing.T) {
+		tag := getFilm(t, cast, "", nil).Header().Get("ETag")
+		rec := getFilm(t, append(cast, domain.Actor{Id: 2, Name: "Carrie-Anne Moss"}), "",
+			http.Header{"If-None-Match": {tag}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+		assert.Contains(t, rec.Body.String(), "Carrie-Anne Moss")
+	})
+	t.Run("GetFilmOtherFormat", func(t *testing.T) {
+		tag := getFilm(t, cast, "", nil).Header().Get("ETag")
+		rec := getFilm(t, cast, "?format=csv", http.Header{"If-None-Match": {tag}})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEqual(t, tag, rec.Header().Get("ETag"))
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	})
+	t.Run("PatchFilmStale", func(t *testing.T) {
+		films, actors := mocks.NewFilm(t), mocks.NewActor(t)
+		films.On("PatchFilm", mock.Anything, 0, mock.MatchedBy(func(input domain.NullableFilm) bool {
+			return input.Id == 5 && input.Version == 2
+		}), []int(nil)).Return(domain.Film{}, service.ErrPrecondition)
+		h := NewHandler(&service.Service{Film: films, Actor: actors}, log)
+
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/films/5/", strings.NewReader(`{"film":{"rating":7}}`))
+		r.SetPathValue("film_id", "5")
+		r.Header.Set("If-Match", `"2"`)
+		rec := httptest.NewRecorder()
+		h.PatchFilm(rec, r)
+
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+		assert.Equal(t, "precondition_failed", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("UpdateActor", func(t *testing.T) {
+		actors := mocks.NewActor(t)
+		actors.On("UpdateActor", mock.Anything, 0, mock.MatchedBy(func(actor domain.Actor) bool {
+			return actor.Id == 7 && actor.Version == 4
+		})).Return(5, nil)
+		h := NewHandler(&service.Service{Actor: actors}, log)
+
+		body := `{"name":"Keanu Reeves","gender":1,"birthday":"1964-09-02"}`
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/actors/7/", strings.NewReader(body))
+		r.SetPathValue("actor_id", "7")
+		r.Header.Set("If-Match", `"4"`)
+		rec := httptest.NewRecorder()
+		h.UpdateActor(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+	})
+}
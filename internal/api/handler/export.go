@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var exportContentTypes = map[string]string{
+	service.ExportFormatNDJSON: "application/x-ndjson",
+	service.ExportFormatCSV:    "text/csv; charset=utf-8",
+	service.ExportFormatJSON:   "application/json",
+}
+
+// exportResponse notes whether anything has been sent, until then an error can still get its own response
+type exportResponse struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *exportResponse) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// exportFormat reads the format query parameter, ndjson by default
+func exportFormat(r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.ExportFormatNDJSON
+	}
+	_, ok := exportContentTypes[format]
+	return format, ok
+}
+
+// optionalId reads an id query parameter, -1 when it is missing
+func optionalId(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return -1, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// stream sends the export as it is written. Exports take longer than the server write timeout, so it is lifted.
+// Once the data has started there is no way to report an error but to break the connection
+func (h *Handler) stream(log *slog.Logger, w http.ResponseWriter, r *http.Request, name, format string,
+	export func(w *exportResponse) error) {
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", "attachment; filename="+name+"."+format)
+
+	resp := &exportResponse{ResponseWriter: w}
+	if err := export(resp); err != nil {
+		if resp.started {
+			log.Error("export interrupted: " + err.Error())
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Del("Content-Disposition")
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeInvalid: "Unsupported format. Please, use ndjson, csv or json",
+		})
+	}
+}
+
+// ExportFilms godoc
+//
+//		@Summary		Выгрузка фильмов
+//		@Description	Выгрузка каталога фильмов вместе с актерами в NDJSON, CSV или одним JSON документом.
+//		@Description	Принимает те же фильтры, что и список фильмов. Фильмы отдаются по мере чтения из базы.
+//		@Description	В CSV актеры записываются как "имя|день рождения" через точку с запятой, как при импорте
+//		@Tags			export
+//		@Produce		json
+//		@Produce		plain
+//	 	@Param			format query string false "Формат выгрузки" Enums(ndjson, csv, json)
+//	 	@Param			actor_id query int false "Только фильмы с этим актером"
+//	 	@Param			country query string false "Страна производства (ISO 3166-1 alpha-2)"
+//	 	@Param			language query string false "Язык (ISO 639-1)"
+//	 	@Param			age_rating query string false "Возрастной рейтинг"
+//	 	@Param			runtime_min query int false "Минимальная длительность"
+//	 	@Param			runtime_max query int false "Максимальная длительность"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/export/films/ [get]
+func (h *Handler) ExportFilms(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Export.ExportFilms"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	format, ok := exportFormat(r)
+	if !ok {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Unsupported format. Please, use ndjson, csv or json", "unsupported export format "+format)
+		return
+	}
+	actorId, err := optionalId(r, "actor_id")
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect actor id. Please, check your input", err.Error())
+		return
+	}
+	filter, err := parseFilmFilter(r.URL.Query())
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	h.stream(log, w, r, "films", format, func(w *exportResponse) error {
+		return h.services.ExportFilms(r.Context(), w, format, filter, actorId)
+	})
+}
+
+// ExportActors godoc
+//
+//		@Summary		Выгрузка актеров
+//		@Description	Выгрузка актеров вместе с фильмографией в NDJSON, CSV или одним JSON документом.
+//		@Description	Актеры отдаются по мере чтения из базы. В CSV фильмы записываются как "название|дата выхода"
+//		@Tags			export
+//		@Produce		json
+//		@Produce		plain
+//	 	@Param			format query string false "Формат выгрузки" Enums(ndjson, csv, json)
+//	 	@Param			film_id query int false "Только актеры этого фильма"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/export/actors/ [get]
+func (h *Handler) ExportActors(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Export.ExportActors"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	format, ok := exportFormat(r)
+	if !ok {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Unsupported format. Please, use ndjson, csv or json", "unsupported export format "+format)
+		return
+	}
+	filmId, err := optionalId(r, "film_id")
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	h.stream(log, w, r, "actors", format, func(w *exportResponse) error {
+		return h.services.ExportActors(r.Context(), w, format, filmId)
+	})
+}
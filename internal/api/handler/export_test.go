@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestHandler_Export(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("ExportFilms", func(t *testing.T) {
+		exports := mocks.NewExport(t)
+		country := "RU"
+		exports.On("ExportFilms", mock.Anything, mock.Anything, service.ExportFormatCSV, domain.FilmFilter{Country: &country}, 3).
+			Run(func(args mock.Arguments) {
+				io.WriteString(args.Get(1).(io.Writer), "id,title\n")
+			}).Return(nil)
+		h := NewHandler(&service.Service{Export: exports}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/export/films/?format=csv&country=ru&actor_id=3", nil)
+		rec := httptest.NewRecorder()
+		h.ExportFilms(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=films.csv", rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "id,title\n", rec.Body.String())
+	})
+	t.Run("FailedBeforeData", func(t *testing.T) {
+		exports := mocks.NewExport(t)
+		exports.On("ExportActors", mock.Anything, mock.Anything, service.ExportFormatNDJSON, -1).Return(service.ErrInternal)
+		h := NewHandler(&service.Service{Export: exports}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/export/actors/", nil)
+		rec := httptest.NewRecorder()
+		h.ExportActors(rec, r)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	})
+	t.Run("FailedWithData", func(t *testing.T) {
+		exports := mocks.NewExport(t)
+		exports.On("ExportActors", mock.Anything, mock.Anything, service.ExportFormatNDJSON, -1).Run(func(args mock.Arguments) {
+			io.WriteString(args.Get(1).(io.Writer), "{}\n")
+		}).Return(service.ErrInternal)
+		h := NewHandler(&service.Service{Export: exports}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/export/actors/", nil)
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.ExportActors(httptest.NewRecorder(), r)
+		})
+	})
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		h := NewHandler(&service.Service{Export: mocks.NewExport(t)}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/export/actors/?format=xml", nil)
+		rec := httptest.NewRecorder()
+		h.ExportActors(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
-	"github.com/go-playground/validator/v10"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -30,14 +32,75 @@ func validateSortParams(sortParams []string) ([]string, error) {
 	return sortParams, nil
 }
 
+// parseFilmFilter reads metadata filters from the query string
+func parseFilmFilter(query url.Values) (domain.FilmFilter, error) {
+	var filter domain.FilmFilter
+	if v := query.Get("country"); v != "" {
+		v = strings.ToUpper(v)
+		filter.Country = &v
+	}
+	if v := query.Get("language"); v != "" {
+		v = strings.ToLower(v)
+		filter.Language = &v
+	}
+	if v := query.Get("age_rating"); v != "" {
+		filter.AgeRating = &v
+	}
+
+	ints := map[string]**int{
+		"runtime_min": &filter.RuntimeMin,
+		"runtime_max": &filter.RuntimeMax,
+	}
+	for name, dst := range ints {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return filter, fmt.Errorf("incorrect %s value %q", name, v)
+			}
+			*dst = &n
+		}
+	}
+	int64s := map[string]**int64{
+		"budget_min":     &filter.BudgetMin,
+		"budget_max":     &filter.BudgetMax,
+		"box_office_min": &filter.BoxOfficeMin,
+		"box_office_max": &filter.BoxOfficeMax,
+	}
+	for name, dst := range int64s {
+		if v := query.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("incorrect %s value %q", name, v)
+			}
+			*dst = &n
+		}
+	}
+
+	return filter, newValidator().Struct(filter)
+}
+
 // ListFilms godoc
 //
 //		@Summary		Список фильмов
-//		@Description	Получить список фильмов
+//		@Description	Получить список фильмов. JSON и NDJSON (application/x-ndjson) отдаются по мере чтения из базы
 //		@Tags			films
 //		@Accept			json
 //		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
 //	 	@Param			sortby query string true "Поле и направление сортировки" example(rating.desc)
+//	 	@Param			country query string false "Страна производства (ISO 3166-1 alpha-2)" example(US)
+//	 	@Param			language query string false "Язык оригинала или озвучки (ISO 639-1)" example(en)
+//	 	@Param			age_rating query string false "Возрастной рейтинг" example(16+)
+//	 	@Param			runtime_min query int false "Минимальная длительность, мин"
+//	 	@Param			runtime_max query int false "Максимальная длительность, мин"
+//	 	@Param			budget_min query int false "Минимальный бюджет, USD"
+//	 	@Param			budget_max query int false "Максимальный бюджет, USD"
+//	 	@Param			box_office_min query int false "Минимальные кассовые сборы, USD"
+//	 	@Param			box_office_max query int false "Максимальные кассовые сборы, USD"
+//	 	@Param			lang query string false "Язык названий и описаний (ISO 639-1)" example(en)
+//	 	@Param			Accept-Language header string false "Язык, если не задан lang"
 //		@Success		200	{array}		domain.Film
 //		@Failure		400	{object}	errorResponse
 //		@Failure		500	{object}	errorResponse
@@ -51,38 +114,30 @@ func (h *Handler) ListFilms(w http.ResponseWriter, r *http.Request) {
 	sortParams := strings.Split(r.URL.Query().Get("sortby"), ".")
 	sortParams, err := validateSortParams(sortParams)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "sort error", err.Error(), err.Error())
+		newErrResponse(log, w, r, http.StatusBadRequest, "sort error", err.Error(), err.Error())
 		return
 	}
 
-	films, err := h.services.ListFilms(sortParams[0], sortParams[1], -1)
+	filter, err := parseFilmFilter(r.URL.Query())
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "sort error",
-			"Failed to get films. Please, try again later", err.Error())
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
-	for i := range films {
-		films[i].Actors, err = h.services.ListActors(films[i].Id)
-		if err != nil {
-			newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "server error",
-				"Failed to get data. Please, try again later", err.Error())
-			return
-		}
-		if films[i].Actors == nil {
-			films[i].Actors = []domain.Actor{}
-		}
-	}
-
-	if films == nil {
-		w.WriteHeader(http.StatusNotFound)
-		resp, _ := json.Marshal([]domain.Film{})
-		w.Write(resp)
+	localizer, err := h.services.Localizer(r.Context(), requestLang(r))
+	if err != nil {
+		filmErrResponse(log, w, r, err)
 		return
 	}
-
-	resp, _ := json.Marshal(films)
-	w.Write(resp)
+	w.Header().Set("Vary", "Accept-Language")
+
+	h.streamList(log, w, r, http.StatusNotFound, func(send func(v any) error) error {
+		return h.services.StreamFilms(r.Context(), sortParams[0], sortParams[1], -1, filter,
+			func(film domain.Film) error {
+				localizer.Film(&film)
+				return send(film)
+			})
+	}, filmErrResponse)
 }
 
 // SearchFilm godoc
@@ -92,7 +147,12 @@ func (h *Handler) ListFilms(w http.ResponseWriter, r *http.Request) {
 //		@Tags			films
 //		@Accept			json
 //		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
 //	 	@Param			query query string true "Поисковый запрос" example("Avatar")
+//	 	@Param			lang query string false "Язык названий и описаний (ISO 639-1)" example(en)
+//	 	@Param			Accept-Language header string false "Язык, если не задан lang"
 //		@Success		200	{array}		domain.Film
 //		@Failure		400	{object}	errorResponse
 //		@Failure		404	{object}	errorResponse
@@ -106,22 +166,20 @@ func (h *Handler) SearchFilm(w http.ResponseWriter, r *http.Request) {
 
 	query := r.URL.Query().Get("query")
 	if query == "" {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "input error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
 			"Search query is empty", "Search query is empty")
 		return
 	}
-	films, err := h.services.SearchFilm(query)
+	films, err := h.services.SearchFilm(r.Context(), query)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "server error",
-			"Failed to get data. Please, try again later", err.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 
 	for i := range films {
-		films[i].Actors, err = h.services.ListActors(films[i].Id)
+		films[i].Actors, err = h.services.ListActors(r.Context(), films[i].Id)
 		if err != nil {
-			newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "server error",
-				"Failed to get data. Please, try again later", err.Error())
+			filmErrResponse(log, w, r, err)
 			return
 		}
 		if films[i].Actors == nil {
@@ -129,15 +187,40 @@ func (h *Handler) SearchFilm(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if err = h.services.LocalizeFilms(r.Context(), requestLang(r), films); err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+	w.Header().Set("Vary", "Accept-Language")
+
 	if films == nil {
-		w.WriteHeader(http.StatusNotFound)
-		resp, _ := json.Marshal([]domain.Film{})
-		w.Write(resp)
+		h.respond(log, w, r, http.StatusNotFound, []domain.Film{})
 		return
 	}
 
-	resp, _ := json.Marshal(films)
-	w.Write(resp)
+	h.respond(log, w, r, http.StatusOK, films)
+}
+
+func joinIds(ids []int) string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = strconv.Itoa(id)
+	}
+	return strings.Join(result, ", ")
+}
+
+func filmErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	details := errDetails{
+		apperr.CodeNotFound:      "Specified film not found",
+		apperr.CodeUnprocessable: "Some of actors don't exist. Please, check your input",
+		apperr.CodePrecondition:  "Film was changed since it was read. Please, fetch it again and retry",
+	}
+	var unknownErr *service.UnknownActorsError
+	if errors.As(err, &unknownErr) {
+		details[apperr.CodeUnprocessable] = fmt.Sprintf("Actors with ids %s don't exist. Please, check your input",
+			joinIds(unknownErr.Ids))
+	}
+	errResponse(log, w, r, err, details)
 }
 
 type filmInput struct {
@@ -154,9 +237,11 @@ type filmInput struct {
 //		@Produce		json
 //	 	@Param			input body filmInput true "Информация о фильму" example("Avatar")
 //		@Success		200	{object}	domain.Film
+//		@Header			200	{string}	ETag	"Версия фильма"
 //		@Failure		400	{object}	errorResponse
 //		@Failure		404	{object}	errorResponse
 //		@Failure		500	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
 //		@Router			/films/ [post]
 func (h *Handler) CreateFilm(w http.ResponseWriter, r *http.Request) {
 	const method = "Handlers.Film.CreateFilm"
@@ -167,42 +252,98 @@ func (h *Handler) CreateFilm(w http.ResponseWriter, r *http.Request) {
 	var input filmInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "data parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
 			"Failed to parse data. Please, check your input", err.Error())
 		return
 	}
 
-	validate := validator.New()
-	err = validate.Struct(input)
+	err = newValidator().Struct(input)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "validation error",
-			"Couldn't validate input fields. Please, fix input and try again", vErr.Error())
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
-	input.Id, err = h.services.CreateFilm(input.Film, input.ActorIds)
+	userId, _ := getUserId(r)
+	input.Id, err = h.services.CreateFilm(r.Context(), userId, input.Film, input.ActorIds)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "save film error",
-			"Failed to save film. Please, try again later", err.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, _ := json.Marshal(input)
+	setETag(w, newRowVersion)
 	w.WriteHeader(http.StatusCreated)
 	w.Write(resp)
 }
 
+// GetFilm godoc
+//
+//		@Summary		Фильм
+//		@Description	Получить фильм вместе с актерами. ETag зависит от версии фильма, актеров, языка и формата,
+//		@Description	в If-Match при изменении из него берется только версия
+//		@Tags			films
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма" example(10)
+//	 	@Param			lang query string false "Язык названий и описаний (ISO 639-1)" example(en)
+//	 	@Param			Accept-Language header string false "Язык, если не задан lang"
+//	 	@Param			If-None-Match header string false "ETag, полученный ранее"
+//		@Success		200	{object}	domain.Film
+//		@Header			200	{string}	ETag	"Версия и хеш ответа"
+//		@Success		304
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/ [get]
+func (h *Handler) GetFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Film.GetFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	film, err := h.services.GetFilm(r.Context(), filmId)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+	film.Actors, err = h.services.ListActors(r.Context(), film.Id)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+	if film.Actors == nil {
+		film.Actors = []domain.Actor{}
+	}
+	films := []domain.Film{film}
+	if err = h.services.LocalizeFilms(r.Context(), requestLang(r), films); err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respondRecord(log, w, r, film.Version, films[0])
+}
+
 // DeleteFilm godoc
 //
 //		@Summary		Удалить фильм
+//		@Description	Перемещает фильм в корзину, откуда его можно восстановить до очистки
 //		@Tags			films
 //		@Accept			json
 //		@Produce		json
 //	 	@Param			film_id path int true "ИД фильма" example(10)
+//	 	@Param			If-Match header string false "ETag, полученный при чтении фильма"
 //		@Success		200
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
 //		@Router			/films/{film_id}/ [delete]
 func (h *Handler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 	const method = "Handlers.Film.PatchFilm"
@@ -211,16 +352,21 @@ func (h *Handler) DeleteFilm(w http.ResponseWriter, r *http.Request) {
 	)
 	filmId, err := strconv.Atoi(r.PathValue("film_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "input error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
 			"Incorrect film id. Please, check your input", err.Error())
 		return
 	}
 
-	err = h.services.DeleteFilm(filmId)
+	version, err := ifMatch(r)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.DeleteFilm(r.Context(), userId, filmId, version)
 	if err != nil {
-		// TODO: улучшить обработку ошибок
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "server error",
-			"Internal error. Please, try again later", err.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 }
@@ -238,8 +384,13 @@ type PatchFilmInput struct {
 //		@Produce		json
 //	 	@Param			filmInput body PatchFilmInput true "Данные для обновления"
 //	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			If-Match header string false "ETag, полученный при чтении фильма"
 //		@Success		200 {object}	domain.Film
+//		@Header			200	{string}	ETag	"Новая версия фильма"
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
 //		@Router			/films/{film_id}/ [patch]
 func (h *Handler) PatchFilm(w http.ResponseWriter, r *http.Request) {
 	const method = "Handlers.Film.PatchFilm"
@@ -249,36 +400,38 @@ func (h *Handler) PatchFilm(w http.ResponseWriter, r *http.Request) {
 	var input PatchFilmInput
 	err := json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "data parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
 			"Failed to parse data. Please, check your input", err.Error())
 		return
 	}
 
-	validate := validator.New()
-	err = validate.Struct(input)
+	err = newValidator().Struct(input)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "validation error",
-			"Couldn't validate input fields. Please, fix input and try again", vErr.Error())
+		validationErrResponse(log, w, r, err)
 		return
 	}
 
 	input.Id, err = strconv.Atoi(r.PathValue("film_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "param error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
 			"Fsailed to get film id. Please, check your input and try again", err.Error())
 		return
 	}
+	input.Version, err = ifMatch(r)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
 
-	film, err := h.services.PatchFilm(input.NullableFilm, input.ActorIds)
+	userId, _ := getUserId(r)
+	film, err := h.services.PatchFilm(r.Context(), userId, input.NullableFilm, input.ActorIds)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "save error",
-			"failed to save data, try again later", err.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, err := json.Marshal(map[string]interface{}{"film": film, "actorIds": input.ActorIds})
+	setETag(w, film.Version)
 	w.WriteHeader(http.StatusOK)
 	w.Write(resp)
 }
@@ -292,11 +445,15 @@ func (h *Handler) PatchFilm(w http.ResponseWriter, r *http.Request) {
 //		@Produce		json
 //	 	@Param			film body domain.Film true "Данные фильма"
 //	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			If-Match header string false "ETag, полученный при чтении фильма"
 //		@Success		200 {object}	domain.Film
+//		@Header			200	{string}	ETag	"Новая версия фильма"
 //		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
 //		@Router			/films/{film_id}/ [put]
 func (h *Handler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
-	// TODO: разобраться с вводом несуществующих Id актеров
 	const method = "Handlers.Film.UpdateFilm"
 	log := h.log.With(
 		slog.String("method", method),
@@ -306,34 +463,37 @@ func (h *Handler) UpdateFilm(w http.ResponseWriter, r *http.Request) {
 	var err error
 	input.Film.Id, err = strconv.Atoi(r.PathValue("film_id"))
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "param error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
 			"failed to get user id. Please, check your input and try again", err.Error())
 		return
 	}
 	err = json.NewDecoder(r.Body).Decode(&input)
 	if err != nil {
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "json parse error",
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
 			"Failed to parse json. Please, check your input", err.Error())
 		return
 	}
-	validate := validator.New()
-	err = validate.Struct(input)
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	input.Film.Version, err = ifMatch(r)
 	if err != nil {
-		var vErr validator.ValidationErrors
-		errors.As(err, &vErr)
-		newErrResponse(log, w, http.StatusBadRequest, r.Host+r.RequestURI, "validation error",
-			"Couldn't validate input fields. Please, check input and try again", vErr.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 
-	err = h.services.UpdateFilm(input.Film, input.ActorIds)
+	userId, _ := getUserId(r)
+	version, err := h.services.UpdateFilm(r.Context(), userId, input.Film, input.ActorIds)
 	if err != nil {
-		newErrResponse(log, w, http.StatusInternalServerError, r.Host+r.RequestURI, "save error",
-			"Failed to save data, try again later", err.Error())
+		filmErrResponse(log, w, r, err)
 		return
 	}
 
 	resp, _ := json.Marshal(input.Film)
+	setETag(w, version)
 	w.Write(resp)
 	w.WriteHeader(http.StatusOK)
 }
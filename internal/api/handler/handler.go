@@ -39,11 +39,14 @@ func (h *Handler) InitRoutes() *http.ServeMux {
 
 	router.HandleFunc("POST /api/v1/signup/", h.SignUp)
 	router.HandleFunc("POST /api/v1/auth/", h.SignIn)
+	router.Handle("PUT /api/v1/users/{user_id}/role/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.SetUserRole))))
+	router.Handle("GET /api/v1/audit/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ListAuditEvents))))
 
 	router.Handle("POST /api/v1/films/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateFilm))))
 	router.Handle("GET /api/v1/films/", h.CheckAuth(http.HandlerFunc(h.ListFilms)))
-	router.Handle("GET /api/v1/films/search/", h.CheckAuth(http.HandlerFunc(h.SearchFilm)))
+	router.Handle("GET /api/v1/films/search/{$}", h.CheckAuth(http.HandlerFunc(h.SearchFilm)))
 
+	router.Handle("GET /api/v1/films/{film_id}/{$}", h.CheckAuth(http.HandlerFunc(h.GetFilm)))
 	router.Handle("PUT /api/v1/films/{film_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.UpdateFilm))))
 	router.Handle("PATCH /api/v1/films/{film_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.PatchFilm))))
 	router.Handle("DELETE /api/v1/films/{film_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteFilm))))
@@ -51,9 +54,106 @@ func (h *Handler) InitRoutes() *http.ServeMux {
 	router.Handle("GET /api/v1/actors/", h.CheckAuth(http.HandlerFunc(h.ListActors)))
 	router.Handle("POST /api/v1/actors/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateActor))))
 
+	router.Handle("GET /api/v1/actors/{actor_id}/{$}", h.CheckAuth(http.HandlerFunc(h.GetActor)))
 	router.Handle("PUT /api/v1/actors/{actor_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.UpdateActor))))
 	router.Handle("PATCH /api/v1/actors/{actor_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.PatchActor))))
 	router.Handle("DELETE /api/v1/actors/{actor_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteActor))))
 
+	router.Handle("GET /api/v1/films/{film_id}/translations/", h.CheckAuth(http.HandlerFunc(h.ListFilmTranslations)))
+	router.Handle("PUT /api/v1/films/{film_id}/translations/{lang}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.SetFilmTranslation))))
+	router.Handle("DELETE /api/v1/films/{film_id}/translations/{lang}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteFilmTranslation))))
+	router.Handle("GET /api/v1/actors/{actor_id}/translations/", h.CheckAuth(http.HandlerFunc(h.ListActorTranslations)))
+	router.Handle("PUT /api/v1/actors/{actor_id}/translations/{lang}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.SetActorTranslation))))
+	router.Handle("DELETE /api/v1/actors/{actor_id}/translations/{lang}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteActorTranslation))))
+
+	router.Handle("GET /api/v1/films/{film_id}/aliases/", h.CheckAuth(http.HandlerFunc(h.ListAliases)))
+	router.Handle("POST /api/v1/films/{film_id}/aliases/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateAlias))))
+	router.Handle("DELETE /api/v1/aliases/{alias_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteAlias))))
+	router.Handle("GET /api/v1/films/{film_id}/relations/", h.CheckAuth(http.HandlerFunc(h.ListRelations)))
+	router.Handle("POST /api/v1/films/{film_id}/relations/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateRelation))))
+	router.Handle("DELETE /api/v1/films/{film_id}/relations/{related_id}/{kind}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteRelation))))
+
+	router.Handle("GET /api/v1/franchises/", h.CheckAuth(http.HandlerFunc(h.ListFranchises)))
+	router.Handle("POST /api/v1/franchises/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateFranchise))))
+	router.Handle("GET /api/v1/franchises/{franchise_id}/", h.CheckAuth(http.HandlerFunc(h.GetFranchise)))
+	router.Handle("DELETE /api/v1/franchises/{franchise_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteFranchise))))
+	router.Handle("PUT /api/v1/franchises/{franchise_id}/films/{film_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.SetFranchiseFilm))))
+	router.Handle("DELETE /api/v1/franchises/{franchise_id}/films/{film_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RemoveFranchiseFilm))))
+
+	router.Handle("GET /api/v1/duplicates/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.FindDuplicates))))
+	router.Handle("GET /api/v1/merges/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ListMerges))))
+	router.Handle("POST /api/v1/actors/{actor_id}/merge/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.MergeActors))))
+	router.Handle("POST /api/v1/films/{film_id}/merge/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.MergeFilms))))
+
+	router.Handle("GET /api/v1/trash/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ListTrash))))
+	router.Handle("POST /api/v1/films/{film_id}/restore/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RestoreFilm))))
+	router.Handle("POST /api/v1/actors/{actor_id}/restore/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RestoreActor))))
+
+	router.Handle("GET /api/v1/films/{film_id}/revisions/", h.CheckAuth(http.HandlerFunc(h.ListFilmRevisions)))
+	router.Handle("POST /api/v1/films/{film_id}/revisions/{revision_id}/revert/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RevertFilm))))
+	router.Handle("GET /api/v1/actors/{actor_id}/revisions/", h.CheckAuth(http.HandlerFunc(h.ListActorRevisions)))
+	router.Handle("POST /api/v1/actors/{actor_id}/revisions/{revision_id}/revert/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RevertActor))))
+
+	router.Handle("POST /api/v1/films/{film_id}/suggestions/", h.CheckAuth(http.HandlerFunc(h.CreateFilmSuggestion)))
+	router.Handle("POST /api/v1/actors/{actor_id}/suggestions/", h.CheckAuth(http.HandlerFunc(h.CreateActorSuggestion)))
+	router.Handle("GET /api/v1/me/suggestions/", h.CheckAuth(http.HandlerFunc(h.ListMySuggestions)))
+	router.Handle("GET /api/v1/suggestions/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ListSuggestions))))
+	router.Handle("GET /api/v1/suggestions/{suggestion_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.GetSuggestion))))
+	router.Handle("POST /api/v1/suggestions/{suggestion_id}/approve/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ApproveSuggestion))))
+	router.Handle("POST /api/v1/suggestions/{suggestion_id}/reject/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.RejectSuggestion))))
+
+	router.Handle("POST /api/v1/import/actors/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ImportActors))))
+	router.Handle("POST /api/v1/import/films/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ImportFilms))))
+	router.Handle("GET /api/v1/export/actors/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ExportActors))))
+	router.Handle("GET /api/v1/export/films/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ExportFilms))))
+
+	router.Handle("GET /api/v1/catalog/", h.CheckAuth(http.HandlerFunc(h.ListCatalog)))
+	router.Handle("GET /api/v1/catalog/search/", h.CheckAuth(http.HandlerFunc(h.SearchCatalog)))
+
+	router.Handle("GET /api/v1/series/", h.CheckAuth(http.HandlerFunc(h.ListSeries)))
+	router.Handle("POST /api/v1/series/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateSeries))))
+	router.Handle("GET /api/v1/series/{series_id}/", h.CheckAuth(http.HandlerFunc(h.GetSeries)))
+	router.Handle("PUT /api/v1/series/{series_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.UpdateSeries))))
+	router.Handle("DELETE /api/v1/series/{series_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteSeries))))
+	router.Handle("POST /api/v1/series/{series_id}/seasons/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateSeason))))
+	router.Handle("DELETE /api/v1/seasons/{season_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteSeason))))
+	router.Handle("POST /api/v1/seasons/{season_id}/episodes/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateEpisode))))
+	router.Handle("DELETE /api/v1/episodes/{episode_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteEpisode))))
+
+	router.Handle("GET /api/v1/actors/path/{$}", h.CheckAuth(http.HandlerFunc(h.ActorPath)))
+	router.Handle("GET /api/v1/actors/graph/{$}", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ExportActorsGraph))))
+	router.Handle("GET /api/v1/actors/{actor_id}/costars/", h.CheckAuth(http.HandlerFunc(h.CoStars)))
+
+	router.Handle("GET /api/v1/films/{film_id}/similar/", h.CheckAuth(http.HandlerFunc(h.SimilarFilms)))
+	router.Handle("GET /api/v1/me/recommendations/", h.CheckAuth(http.HandlerFunc(h.Recommendations)))
+	router.Handle("GET /api/v1/me/films/", h.CheckAuth(http.HandlerFunc(h.ListUserFilms)))
+	router.Handle("PUT /api/v1/me/films/{film_id}/", h.CheckAuth(http.HandlerFunc(h.SetUserFilm)))
+	router.Handle("DELETE /api/v1/me/films/{film_id}/", h.CheckAuth(http.HandlerFunc(h.DeleteUserFilm)))
+	router.Handle("POST /api/v1/me/letterboxd/", h.CheckAuth(http.HandlerFunc(h.ImportLetterboxd)))
+	router.Handle("GET /api/v1/me/letterboxd/", h.CheckAuth(http.HandlerFunc(h.ExportLetterboxd)))
+
+	router.Handle("GET /api/v1/films/{film_id}/copies/", h.CheckAuth(http.HandlerFunc(h.ListCopies)))
+	router.Handle("POST /api/v1/films/{film_id}/copies/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.CreateCopy))))
+	router.Handle("PUT /api/v1/copies/{copy_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.UpdateCopy))))
+	router.Handle("DELETE /api/v1/copies/{copy_id}/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.DeleteCopy))))
+	router.Handle("POST /api/v1/copies/{copy_id}/lend/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.LendCopy))))
+	router.Handle("POST /api/v1/copies/{copy_id}/return/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ReturnCopy))))
+	router.Handle("GET /api/v1/copies/{copy_id}/loans/", h.CheckAuth(http.HandlerFunc(h.ListLoans)))
+	router.Handle("GET /api/v1/loans/overdue/", h.CheckAuth(h.CheckAdmin(http.HandlerFunc(h.ListOverdueLoans))))
+
+	router.Handle("GET /api/v1/collections/", h.CheckAuth(http.HandlerFunc(h.ListCollections)))
+	router.Handle("POST /api/v1/collections/", h.CheckAuth(http.HandlerFunc(h.CreateCollection)))
+	router.Handle("GET /api/v1/collections/followed/", h.CheckAuth(http.HandlerFunc(h.ListFollowedCollections)))
+
+	router.Handle("GET /api/v1/collections/{collection_id}/", h.CheckAuth(http.HandlerFunc(h.GetCollection)))
+	router.Handle("PUT /api/v1/collections/{collection_id}/", h.CheckAuth(http.HandlerFunc(h.UpdateCollection)))
+	router.Handle("PATCH /api/v1/collections/{collection_id}/", h.CheckAuth(http.HandlerFunc(h.PatchCollection)))
+	router.Handle("DELETE /api/v1/collections/{collection_id}/", h.CheckAuth(http.HandlerFunc(h.DeleteCollection)))
+	router.Handle("POST /api/v1/collections/{collection_id}/follow/", h.CheckAuth(http.HandlerFunc(h.FollowCollection)))
+	router.Handle("DELETE /api/v1/collections/{collection_id}/follow/", h.CheckAuth(http.HandlerFunc(h.UnfollowCollection)))
+	router.Handle("POST /api/v1/collections/{collection_id}/clone/", h.CheckAuth(http.HandlerFunc(h.CloneCollection)))
+
+	router.HandleFunc("GET /api/v1/shared/collections/{token}/", h.GetSharedCollection)
+
 	return router
 }
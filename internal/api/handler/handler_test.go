@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestHandler_InitRoutes(t *testing.T) {
+	h := NewHandler(nil, slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	assert.NotPanics(t, func() { h.InitRoutes() })
+}
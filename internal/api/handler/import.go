@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// maxImportSize limits the size of an imported file
+const maxImportSize = 64 << 20
+
+type importFunc func(ctx context.Context, userId int, r io.Reader, format string,
+	opts domain.ImportOptions) (domain.ImportResult, error)
+
+// importFormat picks the file format by the Content-Type
+func importFormat(r *http.Request) (string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return service.ImportFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return service.ImportFormatNDJSON, true
+	}
+	return "", false
+}
+
+// parseImportOptions reads the dry_run and mode query parameters, imports are atomic by default
+func parseImportOptions(query url.Values) (domain.ImportOptions, error) {
+	opts := domain.ImportOptions{Mode: domain.ImportAtomic}
+	if v := query.Get("mode"); v != "" {
+		opts.Mode = v
+	}
+	if v := query.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("incorrect dry_run value %q", v)
+		}
+		opts.DryRun = dryRun
+	}
+	return opts, nil
+}
+
+func (h *Handler) importRecords(log *slog.Logger, w http.ResponseWriter, r *http.Request, importFn importFunc) {
+	format, ok := importFormat(r)
+	if !ok {
+		newErrResponse(log, w, r, http.StatusUnsupportedMediaType, "input error",
+			"Unsupported file type. Please, send text/csv or application/x-ndjson",
+			"unsupported content type "+r.Header.Get("Content-Type"))
+		return
+	}
+	opts, err := parseImportOptions(r.URL.Query())
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect import options. Please, check your input", err.Error())
+		return
+	}
+	err = newValidator().Struct(opts)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	result, err := importFn(r.Context(), userId, http.MaxBytesReader(w, r.Body, maxImportSize), format, opts)
+	if err != nil {
+		var appErr *apperr.Error
+		if apperr.CodeOf(err) == apperr.CodeInvalid && errors.As(err, &appErr) {
+			newErrResponse(log, w, r, http.StatusBadRequest, "import error",
+				"Couldn't read the file: "+appErr.Message, err.Error())
+			return
+		}
+		errResponse(log, w, r, err, errDetails{})
+		return
+	}
+
+	log.With(slog.Int("rows", result.Rows), slog.Int("failed", result.Failed),
+		slog.Bool("committed", result.Committed)).Info("import finished")
+	resp, _ := json.Marshal(result)
+	w.Write(resp)
+}
+
+// ImportActors godoc
+//
+//	@Summary		Импорт актеров
+//	@Description	Массовая загрузка актеров из CSV (колонки key, name, gender, birthday) или NDJSON.
+//	@Description	Актер ищется по ключу key, затем по имени и дню рождения: найденный обновляется, иначе создается.
+//	@Description	Строки проверяются так же, как при создании актера, ошибки возвращаются по каждой строке.
+//	@Description	В режиме atomic ничего не сохраняется, если хотя бы одна строка не прошла, в best_effort сохраняются все прошедшие.
+//	@Description	Каждый сохраненный актер получает в истории ревизию import от имени администратора
+//	@Tags			import
+//	@Accept			plain
+//	@Produce		json
+//	@Param			dry_run query bool false "Только проверить, ничего не сохраняя"
+//	@Param			mode query string false "Режим транзакции" Enums(atomic, best_effort)
+//	@Success		200	{object}	domain.ImportResult
+//	@Failure		400	{object}	errorResponse
+//	@Failure		415	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/import/actors/ [post]
+func (h *Handler) ImportActors(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Import.ImportActors"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	h.importRecords(log, w, r, h.services.ImportActors)
+}
+
+// ImportFilms godoc
+//
+//	@Summary		Импорт фильмов
+//	@Description	Массовая загрузка фильмов из CSV или NDJSON. Колонки CSV: key, title, description, released, rating,
+//	@Description	runtime, countries, original_language, languages, age_rating, budget, box_office, genres, cast.
+//	@Description	Списки разделяются точкой с запятой, актер в cast - это его ключ или "имя|день рождения".
+//	@Description	Фильм ищется по ключу key, затем по названию и дате выхода: найденный обновляется, иначе создается.
+//	@Description	Строки проверяются так же, как при создании фильма, ошибки возвращаются по каждой строке.
+//	@Description	Каждый сохраненный фильм получает в истории ревизию import от имени администратора
+//	@Tags			import
+//	@Accept			plain
+//	@Produce		json
+//	@Param			dry_run query bool false "Только проверить, ничего не сохраняя"
+//	@Param			mode query string false "Режим транзакции" Enums(atomic, best_effort)
+//	@Success		200	{object}	domain.ImportResult
+//	@Failure		400	{object}	errorResponse
+//	@Failure		415	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/import/films/ [post]
+func (h *Handler) ImportFilms(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Import.ImportFilms"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	h.importRecords(log, w, r, h.services.ImportFilms)
+}
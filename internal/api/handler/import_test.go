@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Import(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("ImportFilms", func(t *testing.T) {
+		imports := mocks.NewImport(t)
+		imports.On("ImportFilms", mock.Anything, 3, mock.Anything, service.ImportFormatNDJSON,
+			domain.ImportOptions{DryRun: true, Mode: domain.ImportBestEffort}).
+			Return(domain.ImportResult{Rows: 1, Created: 1, Errors: make([]domain.ImportRowError, 0)}, nil)
+		h := NewHandler(&service.Service{Import: imports}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/import/films/?dry_run=true&mode=best_effort",
+			strings.NewReader(`{"title":"Brother"}`))
+		r = r.WithContext(context.WithValue(r.Context(), "user", 3))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		h.ImportFilms(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"created":1`)
+	})
+	t.Run("UnsupportedType", func(t *testing.T) {
+		h := NewHandler(&service.Service{Import: mocks.NewImport(t)}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/import/actors/", strings.NewReader(`[]`))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ImportActors(rec, r)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+	t.Run("UnknownMode", func(t *testing.T) {
+		h := NewHandler(&service.Service{Import: mocks.NewImport(t)}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/import/actors/?mode=some", strings.NewReader(""))
+		r.Header.Set("Content-Type", "text/csv; charset=utf-8")
+		rec := httptest.NewRecorder()
+		h.ImportActors(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_input", decodeErrResponse(t, rec).Code)
+	})
+}
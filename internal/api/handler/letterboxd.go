@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// ImportLetterboxd godoc
+//
+//	@Summary		Импорт из Letterboxd
+//	@Description	Загрузка оценок и истории просмотров из CSV файла Letterboxd (дневник, оценки или список к просмотру).
+//	@Description	Читаются колонки Name или Title, Year, Rating (звезды от 0.5 до 5) или Rating10 и Watched Date, остальные пропускаются.
+//	@Description	Фильм ищется по похожему названию среди фильмов того же года, затем соседних лет.
+//	@Description	В ответе - строки, сопоставленные с неточным названием, и строки, для которых фильм не нашелся
+//	@Tags			me
+//	@Accept			plain
+//	@Produce		json
+//	@Param			dry_run query bool false "Только сопоставить, ничего не сохраняя"
+//	@Success		200	{object}	domain.LetterboxdResult
+//	@Failure		400	{object}	errorResponse
+//	@Failure		415	{object}	errorResponse
+//	@Failure		500	{object}	errorResponse
+//	@Router			/me/letterboxd/ [post]
+func (h *Handler) ImportLetterboxd(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.UserFilm.ImportLetterboxd"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "text/csv" {
+		newErrResponse(log, w, r, http.StatusUnsupportedMediaType, "input error",
+			"Unsupported file type. Please, send text/csv",
+			"unsupported content type "+r.Header.Get("Content-Type"))
+		return
+	}
+	var dryRun bool
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+				"Incorrect dry_run value. Please, check your input", err.Error())
+			return
+		}
+	}
+
+	userId, _ := getUserId(r)
+	result, err := h.services.ImportLetterboxd(r.Context(), userId, http.MaxBytesReader(w, r.Body, maxImportSize), dryRun)
+	if err != nil {
+		var appErr *apperr.Error
+		if apperr.CodeOf(err) == apperr.CodeInvalid && errors.As(err, &appErr) {
+			newErrResponse(log, w, r, http.StatusBadRequest, "import error",
+				"Couldn't read the file: "+appErr.Message, err.Error())
+			return
+		}
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeNotFound: "A matched film has been deleted. Please, try again",
+		})
+		return
+	}
+
+	log.With(slog.Int("rows", result.Rows), slog.Int("unmatched", len(result.Unmatched)),
+		slog.Bool("committed", result.Committed)).Info("letterboxd import finished")
+	h.respond(log, w, r, http.StatusOK, result)
+}
+
+// ExportLetterboxd godoc
+//
+//	@Summary		Выгрузка для Letterboxd
+//	@Description	Оценки и история просмотров текущего пользователя в CSV формате импорта Letterboxd
+//	@Description	(колонки Title, Year, Rating10, WatchedDate). Фильмы без оценки и даты просмотра попадают в список к просмотру
+//	@Tags			me
+//	@Produce		plain
+//	@Success		200	{string}	string
+//	@Failure		500	{object}	errorResponse
+//	@Router			/me/letterboxd/ [get]
+func (h *Handler) ExportLetterboxd(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.UserFilm.ExportLetterboxd"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	userId, _ := getUserId(r)
+	h.stream(log, w, r, "letterboxd", service.ExportFormatCSV, func(w *exportResponse) error {
+		return h.services.ExportLetterboxd(r.Context(), userId, w)
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Letterboxd(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("Import", func(t *testing.T) {
+		marks := mocks.NewUserFilm(t)
+		marks.On("ImportLetterboxd", mock.Anything, 3, mock.Anything, true).
+			Return(domain.LetterboxdResult{Rows: 1, Matched: 1, Marked: 1}, nil)
+		h := NewHandler(&service.Service{UserFilm: marks}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/me/letterboxd/?dry_run=true",
+			strings.NewReader("Name,Year\nSolaris,1972\n"))
+		r.Header.Set("Content-Type", "text/csv")
+		r = r.WithContext(context.WithValue(r.Context(), "user", 3))
+		rec := httptest.NewRecorder()
+		h.ImportLetterboxd(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"marked":1`)
+	})
+	t.Run("NotCSV", func(t *testing.T) {
+		h := NewHandler(&service.Service{UserFilm: mocks.NewUserFilm(t)}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/me/letterboxd/", strings.NewReader("{}"))
+		r.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		h.ImportLetterboxd(rec, r)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+	t.Run("Export", func(t *testing.T) {
+		marks := mocks.NewUserFilm(t)
+		marks.On("ExportLetterboxd", mock.Anything, 3, mock.Anything).Run(func(args mock.Arguments) {
+			io.WriteString(args.Get(2).(io.Writer), "Title,Year,Rating10,WatchedDate\n")
+		}).Return(nil)
+		h := NewHandler(&service.Service{UserFilm: marks}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/me/letterboxd/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "user", 3))
+		rec := httptest.NewRecorder()
+		h.ExportLetterboxd(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "attachment; filename=letterboxd.csv", rec.Header().Get("Content-Disposition"))
+		assert.Equal(t, "Title,Year,Rating10,WatchedDate\n", rec.Body.String())
+	})
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func mergeErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Surviving record or one of duplicates not found",
+		apperr.CodeInvalid:  "Record can't be merged into itself",
+	})
+}
+
+// FindDuplicates godoc
+//
+//	@Summary		Поиск дубликатов
+//	@Description	Вероятные дубликаты: актеры с одной датой рождения и фильмы с одной датой выхода со схожими названиями. Записи без полной даты не сравниваются
+//	@Tags			merges
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{object}	domain.Duplicates
+//	@Failure		500	{object}	errorResponse
+//	@Router			/duplicates/ [get]
+func (h *Handler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Merge.FindDuplicates"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	duplicates, err := h.services.FindDuplicates(r.Context())
+	if err != nil {
+		mergeErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, duplicates)
+}
+
+// MergeActors godoc
+//
+//		@Summary		Объединить актеров
+//		@Description	Перенести связи дубликатов на актера и удалить дубликаты в одной транзакции.
+//		@Description	Актер и каждый дубликат получают в истории ревизию merge, у дубликата в ней mergedInto
+//		@Description	История и предложения дубликата остаются за ним, ожидающие предложения отклоняются. Записи в корзине не объединяются
+//		@Tags			merges
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД сохраняемого актера"
+//	 	@Param			input body domain.MergeInput true "ИД дубликатов"
+//		@Success		200	{array}		domain.Merge
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/actors/{actor_id}/merge/ [post]
+func (h *Handler) MergeActors(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Merge.MergeActors"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	survivorId, input, ok := h.parseMergeInput(log, w, r, "actor_id")
+	if !ok {
+		return
+	}
+
+	userId, _ := getUserId(r)
+	merges, err := h.services.MergeActors(r.Context(), userId, survivorId, input.DuplicateIds)
+	if err != nil {
+		mergeErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(merges)
+	w.Write(resp)
+}
+
+// MergeFilms godoc
+//
+//		@Summary		Объединить фильмы
+//		@Description	Перенести актеров, оценки, подборки, экземпляры и прочие связи дубликатов на фильм и удалить дубликаты в одной транзакции.
+//		@Description	Фильм и каждый дубликат получают в истории ревизию merge, у дубликата в ней mergedInto
+//		@Description	История и предложения дубликата остаются за ним, ожидающие предложения отклоняются. Записи в корзине не объединяются
+//		@Tags			merges
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД сохраняемого фильма"
+//	 	@Param			input body domain.MergeInput true "ИД дубликатов"
+//		@Success		200	{array}		domain.Merge
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/films/{film_id}/merge/ [post]
+func (h *Handler) MergeFilms(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Merge.MergeFilms"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	survivorId, input, ok := h.parseMergeInput(log, w, r, "film_id")
+	if !ok {
+		return
+	}
+
+	userId, _ := getUserId(r)
+	merges, err := h.services.MergeFilms(r.Context(), userId, survivorId, input.DuplicateIds)
+	if err != nil {
+		mergeErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(merges)
+	w.Write(resp)
+}
+
+func (h *Handler) parseMergeInput(log *slog.Logger, w http.ResponseWriter, r *http.Request,
+	param string) (int, domain.MergeInput, bool) {
+	var input domain.MergeInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return 0, input, false
+	}
+	survivorId, err := strconv.Atoi(r.PathValue(param))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect id. Please, check your input", err.Error())
+		return 0, input, false
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return 0, input, false
+	}
+
+	return survivorId, input, true
+}
+
+// ListMerges godoc
+//
+//	@Summary		История объединений
+//	@Description	Удаленные при объединении записи, начиная с последних
+//	@Tags			merges
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Merge
+//	@Failure		500	{object}	errorResponse
+//	@Router			/merges/ [get]
+func (h *Handler) ListMerges(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Merge.ListMerges"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	merges, err := h.services.ListMerges(r.Context())
+	if err != nil {
+		mergeErrResponse(log, w, r, err)
+		return
+	}
+	if merges == nil {
+		merges = []domain.Merge{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, merges)
+}
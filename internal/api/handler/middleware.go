@@ -2,8 +2,11 @@ package handler
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -20,20 +23,49 @@ const (
 	ROLE_CLIENT = 1
 )
 
+const requestIdHeader = "X-Request-Id"
+
 func (l *Logger) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
+	requestId := r.Header.Get(requestIdHeader)
+	if requestId == "" || len(requestId) > 64 {
+		requestId = newRequestId()
+	}
+	w.Header().Set(requestIdHeader, requestId)
+	r = r.WithContext(context.WithValue(r.Context(), "requestId", requestId))
+
 	l.handler.ServeHTTP(w, r)
 	l.log.With(
 		slog.String("method", r.Method),
 		slog.String("path", r.URL.Path),
+		slog.String("request_id", requestId),
 		slog.String("since", time.Since(start).String())).
 		Info(fmt.Sprintf("%s %s %v", r.Method, r.URL.Path, time.Since(start)))
 }
 
+func newRequestId() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// getRequestId returns the id assigned by Logger. Requests that bypassed it get a fresh one
+func getRequestId(r *http.Request) string {
+	if id, ok := r.Context().Value("requestId").(string); ok {
+		return id
+	}
+	return newRequestId()
+}
+
 func NewLogger(log *slog.Logger, handlerToWrap http.Handler) *Logger {
 	return &Logger{log, handlerToWrap}
 }
 
+func getUserId(r *http.Request) (int, bool) {
+	id, ok := r.Context().Value("user").(int)
+	return id, ok
+}
+
 func (h *Handler) CheckAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var token string
@@ -42,14 +74,14 @@ func (h *Handler) CheckAuth(next http.Handler) http.Handler {
 		if len(headSplit) == 2 {
 			token = headSplit[1]
 		} else {
-			newErrResponse(h.log, w, http.StatusForbidden, r.Host+r.RequestURI, "Forbidden",
+			newErrResponse(h.log, w, r, http.StatusForbidden, "Forbidden",
 				"No Bearer token provided. Please, authorize first to access resource", "Forbidden")
 			return
 		}
 		id, err := service.CheckJWT(token)
 
 		if err != nil {
-			newErrResponse(h.log, w, http.StatusForbidden, r.Host+r.RequestURI, "Forbidden",
+			newErrResponse(h.log, w, r, http.StatusForbidden, "Forbidden",
 				"Invalid JWT token. Please, sign up if necessary and acquire fresh token", "Forbidden")
 			return
 		}
@@ -62,20 +94,26 @@ func (h *Handler) CheckAuth(next http.Handler) http.Handler {
 
 func (h *Handler) CheckAdmin(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id, ok := r.Context().Value("user").(int)
+		id, ok := getUserId(r)
 		if !ok {
-			newErrResponse(h.log, w, http.StatusForbidden, r.Host+r.RequestURI, "Forbidden",
+			newErrResponse(h.log, w, r, http.StatusForbidden, "Forbidden",
 				"Could not get user id", "Forbidden")
 			return
 		}
-		user, err := h.services.GetUserById(id)
+		user, err := h.services.GetUserById(r.Context(), id)
 		if err != nil {
-			newErrResponse(h.log, w, http.StatusForbidden, r.Host+r.RequestURI, "Forbidden",
+			h.audit(r, domain.AuditAdminDenied, &id, nil, map[string]any{
+				"method": r.Method, "path": r.URL.Path, "error": err.Error(),
+			})
+			newErrResponse(h.log, w, r, http.StatusForbidden, "Forbidden",
 				"Specified user not found", "Forbidden")
 			return
 		}
 		if user.Role != ROLE_ADMIN {
-			newErrResponse(h.log, w, http.StatusForbidden, r.Host+r.RequestURI, "Forbidden",
+			h.audit(r, domain.AuditAdminDenied, &id, &user.Username, map[string]any{
+				"method": r.Method, "path": r.URL.Path,
+			})
+			newErrResponse(h.log, w, r, http.StatusForbidden, "Forbidden",
 				"You have no admin permissions", "Forbidden")
 			return
 		}
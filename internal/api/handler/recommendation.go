@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+func parseLimit(r *http.Request) (int, error) {
+	param := r.URL.Query().Get("limit")
+	if param == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(param)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", maxLimit)
+	}
+	return limit, nil
+}
+
+// SimilarFilms godoc
+//
+//		@Summary		Похожие фильмы
+//		@Description	Фильмы с пересекающимся актерским составом или общими жанрами, ранжированные с учетом близости даты выхода
+//		@Tags			films
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			limit query int false "Количество фильмов" example(10)
+//		@Success		200	{array}		domain.ScoredFilm
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/similar/ [get]
+func (h *Handler) SimilarFilms(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Recommendation.SimilarFilms"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+	limit, err := parseLimit(r)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error", err.Error(), err.Error())
+		return
+	}
+
+	films, err := h.services.SimilarFilms(r.Context(), filmId, limit)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+	if films == nil {
+		films = []domain.ScoredFilm{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, films)
+}
+
+// Recommendations godoc
+//
+//		@Summary		Рекомендации
+//		@Description	Фильмы, похожие на высоко оцененные и просмотренные пользователем
+//		@Tags			me
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			limit query int false "Количество фильмов" example(10)
+//		@Success		200	{array}		domain.ScoredFilm
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/me/recommendations/ [get]
+func (h *Handler) Recommendations(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Recommendation.Recommendations"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error", err.Error(), err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	films, err := h.services.Recommendations(r.Context(), userId, limit)
+	if err != nil {
+		errResponse(log, w, r, err, nil)
+		return
+	}
+	if films == nil {
+		films = []domain.ScoredFilm{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, films)
+}
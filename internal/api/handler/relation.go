@@ -0,0 +1,510 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func relationErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified film, alias, relation or franchise not found",
+		apperr.CodeConflict: "Such alias, relation, franchise or position already exists",
+	})
+}
+
+// ListAliases godoc
+//
+//		@Summary		Альтернативные названия
+//		@Description	Рабочие, региональные названия и транслитерации фильма
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{array}		domain.FilmAlias
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/aliases/ [get]
+func (h *Handler) ListAliases(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.ListAliases"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	aliases, err := h.services.ListAliases(r.Context(), filmId)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+	if aliases == nil {
+		aliases = []domain.FilmAlias{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, aliases)
+}
+
+// CreateAlias godoc
+//
+//		@Summary		Добавить альтернативное название
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			alias body domain.FilmAlias true "Название"
+//		@Success		201	{object}	domain.FilmAlias
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/films/{film_id}/aliases/ [post]
+func (h *Handler) CreateAlias(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.CreateAlias"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var alias domain.FilmAlias
+	err := json.NewDecoder(r.Body).Decode(&alias)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	alias.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(alias)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	alias.Id, err = h.services.CreateAlias(r.Context(), alias)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(alias)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// DeleteAlias godoc
+//
+//		@Summary		Удалить альтернативное название
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			alias_id path int true "ИД названия"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/aliases/{alias_id}/ [delete]
+func (h *Handler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.DeleteAlias"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("alias_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect alias id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteAlias(r.Context(), id)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// ListRelations godoc
+//
+//		@Summary		Связанные фильмы
+//		@Description	Сиквелы, приквелы и ремейки, в которых участвует фильм
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{array}		domain.FilmRelation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/relations/ [get]
+func (h *Handler) ListRelations(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.ListRelations"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	relations, err := h.services.ListRelations(r.Context(), filmId)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+	if relations == nil {
+		relations = []domain.FilmRelation{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, relations)
+}
+
+// CreateRelation godoc
+//
+//		@Summary		Связать фильмы
+//		@Description	Фильм является сиквелом (sequel), приквелом (prequel) или ремейком (remake) связанного фильма
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			relation body domain.FilmRelation true "Связь"
+//		@Success		201	{object}	domain.FilmRelation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/films/{film_id}/relations/ [post]
+func (h *Handler) CreateRelation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.CreateRelation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var relation domain.FilmRelation
+	err := json.NewDecoder(r.Body).Decode(&relation)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	relation.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(relation)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.CreateRelation(r.Context(), relation)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(relation)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// DeleteRelation godoc
+//
+//		@Summary		Удалить связь фильмов
+//		@Tags			relations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			related_id path int true "ИД связанного фильма"
+//	 	@Param			kind path string true "Тип связи" Enums(sequel, prequel, remake)
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/films/{film_id}/relations/{related_id}/{kind}/ [delete]
+func (h *Handler) DeleteRelation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.DeleteRelation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	relation := domain.FilmRelation{Kind: r.PathValue("kind")}
+	var err error
+	relation.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+	relation.RelatedId, err = strconv.Atoi(r.PathValue("related_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect related film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteRelation(r.Context(), relation)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// ListFranchises godoc
+//
+//	@Summary		Список франшиз
+//	@Tags			franchises
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Franchise
+//	@Failure		500	{object}	errorResponse
+//	@Router			/franchises/ [get]
+func (h *Handler) ListFranchises(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.ListFranchises"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	franchises, err := h.services.ListFranchises(r.Context())
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+	if franchises == nil {
+		franchises = []domain.Franchise{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, franchises)
+}
+
+// CreateFranchise godoc
+//
+//	@Summary		Создать франшизу
+//	@Tags			franchises
+//	@Accept			json
+//	@Produce		json
+//	@Param			franchise body domain.Franchise true "Франшиза"
+//	@Success		201	{object}	domain.Franchise
+//	@Failure		400	{object}	errorResponse
+//	@Failure		409	{object}	errorResponse
+//	@Router			/franchises/ [post]
+func (h *Handler) CreateFranchise(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.CreateFranchise"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var franchise domain.Franchise
+	err := json.NewDecoder(r.Body).Decode(&franchise)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	franchise.Films = nil
+
+	err = newValidator().Struct(franchise)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	franchise.Id, err = h.services.CreateFranchise(r.Context(), franchise)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(franchise)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// GetFranchise godoc
+//
+//		@Summary		Франшиза
+//		@Description	Франшиза и ее фильмы по порядку
+//		@Tags			franchises
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			franchise_id path int true "ИД франшизы"
+//		@Success		200	{object}	domain.Franchise
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/franchises/{franchise_id}/ [get]
+func (h *Handler) GetFranchise(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.GetFranchise"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("franchise_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect franchise id. Please, check your input", err.Error())
+		return
+	}
+
+	franchise, err := h.services.GetFranchise(r.Context(), id)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+	if franchise.Films == nil {
+		franchise.Films = []domain.FranchiseFilm{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, franchise)
+}
+
+// DeleteFranchise godoc
+//
+//		@Summary		Удалить франшизу
+//		@Tags			franchises
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			franchise_id path int true "ИД франшизы"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/franchises/{franchise_id}/ [delete]
+func (h *Handler) DeleteFranchise(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.DeleteFranchise"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("franchise_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect franchise id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteFranchise(r.Context(), id)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+}
+
+type franchiseFilmInput struct {
+	Position int `json:"position" validate:"required,gt=0"`
+}
+
+// SetFranchiseFilm godoc
+//
+//		@Summary		Добавить фильм во франшизу
+//		@Description	Добавить фильм во франшизу или изменить его порядковый номер
+//		@Tags			franchises
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			franchise_id path int true "ИД франшизы"
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			position body franchiseFilmInput true "Порядковый номер"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/franchises/{franchise_id}/films/{film_id}/ [put]
+func (h *Handler) SetFranchiseFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.SetFranchiseFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var input franchiseFilmInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	franchiseId, err := strconv.Atoi(r.PathValue("franchise_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect franchise id. Please, check your input", err.Error())
+		return
+	}
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.SetFranchiseFilm(r.Context(), franchiseId, filmId, input.Position)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// RemoveFranchiseFilm godoc
+//
+//		@Summary		Убрать фильм из франшизы
+//		@Tags			franchises
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			franchise_id path int true "ИД франшизы"
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/franchises/{franchise_id}/films/{film_id}/ [delete]
+func (h *Handler) RemoveFranchiseFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Relation.RemoveFranchiseFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	franchiseId, err := strconv.Atoi(r.PathValue("franchise_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect franchise id. Please, check your input", err.Error())
+		return
+	}
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.RemoveFranchiseFilm(r.Context(), franchiseId, filmId)
+	if err != nil {
+		relationErrResponse(log, w, r, err)
+		return
+	}
+}
@@ -1,32 +1,140 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"log/slog"
 	"net/http"
+	"strings"
 )
 
+const (
+	problemContentType = "application/problem+json"
+	problemTypeBase    = "tag:filmotecka,2024:problems/"
+)
+
+// errorResponse is a problem details document (RFC 9457)
 type errorResponse struct {
-	Type    string `json:"type,omitempty" example:"POST localhost:8080/api/v1/actors/1"`
-	Title   string `json:"title,omitempty" example:"input error"`
-	Status  int    `json:"status,omitempty" example:"400"`
-	Detail  string `json:"detail,omitempty" example:"Failed to get film id. Please, check your input"`
-	Message string `json:"-"`
+	Type      string       `json:"type" example:"tag:filmotecka,2024:problems/invalid-input"`
+	Title     string       `json:"title" example:"input error"`
+	Status    int          `json:"status" example:"400"`
+	Detail    string       `json:"detail,omitempty" example:"Failed to get film id. Please, check your input"`
+	Instance  string       `json:"instance" example:"/api/v1/films/abc/"`
+	Code      string       `json:"code" example:"invalid_input"`
+	RequestId string       `json:"requestId" example:"5f0c6a3e9b1d2c47"`
+	Errors    []fieldError `json:"errors,omitempty"`
+	Message   string       `json:"-"`
 }
 
-func newErrResponse(log *slog.Logger, w http.ResponseWriter, status int, errtype, title, detail, logMessage string) {
-	resp := errorResponse{
-		Type:    errtype,
-		Title:   title,
+var codeStatuses = map[apperr.Code]int{
+	apperr.CodeInvalid:       http.StatusBadRequest,
+	apperr.CodeUnauthorized:  http.StatusUnauthorized,
+	apperr.CodeForbidden:     http.StatusForbidden,
+	apperr.CodeNotFound:      http.StatusNotFound,
+	apperr.CodeConflict:      http.StatusConflict,
+	apperr.CodeUnprocessable: http.StatusUnprocessableEntity,
+	apperr.CodePrecondition:  http.StatusPreconditionFailed,
+	apperr.CodeInternal:      http.StatusInternalServerError,
+	apperr.CodeTimeout:       http.StatusServiceUnavailable,
+}
+
+var codeTitles = map[apperr.Code]string{
+	apperr.CodeInvalid:       "input error",
+	apperr.CodeUnauthorized:  "unauthorized",
+	apperr.CodeForbidden:     "forbidden",
+	apperr.CodeNotFound:      "not found",
+	apperr.CodeConflict:      "conflict",
+	apperr.CodeUnprocessable: "unprocessable entity",
+	apperr.CodePrecondition:  "precondition failed",
+	apperr.CodeInternal:      "server error",
+	apperr.CodeTimeout:       "timeout",
+}
+
+var defaultDetails = errDetails{
+	apperr.CodeInvalid:       "Incorrect input. Please, check your input",
+	apperr.CodeUnauthorized:  "Authorization required. Please, sign in",
+	apperr.CodeForbidden:     "Not enough rights to perform this action",
+	apperr.CodeNotFound:      "Specified record not found",
+	apperr.CodeConflict:      "Record conflicts with an existing one",
+	apperr.CodeUnprocessable: "Input references records that don't exist. Please, check your input",
+	apperr.CodePrecondition:  "Record was changed by someone else. Please, fetch it again and retry",
+	apperr.CodeInternal:      "Internal error. Please, try again later",
+	apperr.CodeTimeout:       "The request took too long. Please, try again later",
+}
+
+// errDetails overrides the user-facing detail for some codes
+type errDetails map[apperr.Code]string
+
+// problemType turns a code into the problem type URI, e.g. not_found into .../problems/not-found
+func problemType(code apperr.Code) string {
+	return problemTypeBase + strings.ReplaceAll(string(code), "_", "-")
+}
+
+// statusCode picks the error code for responses written without a typed error
+func statusCode(status int) apperr.Code {
+	for code, s := range codeStatuses {
+		if s == status {
+			return code
+		}
+	}
+	if status < http.StatusInternalServerError {
+		return apperr.CodeInvalid
+	}
+	return apperr.CodeInternal
+}
+
+// errResponse is the single place that turns repository and service errors into responses
+func errResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error, details errDetails) {
+	code := apperr.CodeOf(err)
+	if code == apperr.CodeInternal && errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		// the repositories don't tell a cancelled query from a failed one
+		code = apperr.CodeTimeout
+	}
+	status, ok := codeStatuses[code]
+	if !ok {
+		code, status = apperr.CodeInternal, http.StatusInternalServerError
+	}
+	detail, ok := details[code]
+	if !ok {
+		detail = defaultDetails[code]
+	}
+
+	writeErrResponse(log, w, r, errorResponse{
+		Status:  status,
+		Code:    string(code),
+		Title:   codeTitles[code],
 		Detail:  detail,
+		Message: err.Error(),
+	})
+}
+
+func newErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, status int, title, detail, logMessage string) {
+	writeErrResponse(log, w, r, errorResponse{
 		Status:  status,
+		Code:    string(statusCode(status)),
+		Title:   title,
+		Detail:  detail,
 		Message: logMessage,
-	}
+	})
+}
+
+// writeErrResponse fills in the request dependent members of the problem document and sends it
+func writeErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, resp errorResponse) {
+	resp.Type = problemType(apperr.Code(resp.Code))
+	resp.Instance = r.URL.RequestURI()
+	resp.RequestId = getRequestId(r)
 
 	strResp, _ := json.Marshal(resp)
 
-	log.With(slog.String("response", string(strResp)), slog.String("err", logMessage)).Error(resp.Title)
+	log.With(
+		slog.String("response", string(strResp)),
+		slog.String("err", resp.Message),
+		slog.String("request_id", resp.RequestId),
+	).Error(resp.Title)
 
-	w.WriteHeader(status)
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(resp.Status)
 	w.Write(strResp)
 }
@@ -0,0 +1,217 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func decodeErrResponse(t *testing.T, rec *httptest.ResponseRecorder) errorResponse {
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestErrResponse(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	tests := []struct {
+		Label  string
+		err    error
+		status int
+		code   string
+	}{
+		{"BadRequest", service.ErrBadRequest, http.StatusBadRequest, "invalid_input"},
+		{"UserNotFound", service.ErrUserNotFound, http.StatusUnauthorized, "unauthorized"},
+		{"Forbidden", service.ErrForbidden, http.StatusForbidden, "forbidden"},
+		{"NotFound", service.ErrNotFound, http.StatusNotFound, "not_found"},
+		{"NoPath", service.ErrNoPath, http.StatusNotFound, "not_found"},
+		{"NoRows", postgres.ErrNoRows, http.StatusNotFound, "not_found"},
+		{"WrappedNotFound", fmt.Errorf("film 5: %w", service.ErrNotFound), http.StatusNotFound, "not_found"},
+		{"Conflict", service.ErrConflict, http.StatusConflict, "conflict"},
+		{"Unique", postgres.ErrUnique, http.StatusConflict, "conflict"},
+		{"Foreign", postgres.ErrForeign, http.StatusUnprocessableEntity, "unprocessable"},
+		{"UnknownActors", &service.UnknownActorsError{Ids: []int{3}}, http.StatusUnprocessableEntity, "unprocessable"},
+		{"Version", postgres.ErrVersion, http.StatusPreconditionFailed, "precondition_failed"},
+		{"Internal", postgres.ErrInternal, http.StatusInternalServerError, "internal"},
+		{"Untyped", errors.New("connection reset"), http.StatusInternalServerError, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil)
+			errResponse(log, rec, r, tt.err, nil)
+
+			assert.Equal(t, tt.status, rec.Code)
+			resp := decodeErrResponse(t, rec)
+			assert.Equal(t, tt.status, resp.Status)
+			assert.Equal(t, tt.code, resp.Code)
+		})
+	}
+}
+
+func TestErrResponse_ProblemDocument(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	var requestId string
+	logger := NewLogger(log, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId = getRequestId(r)
+		errResponse(log, w, r, service.ErrNotFound, nil)
+	}))
+
+	t.Run("GeneratedRequestId", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		logger.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/5/?lang=en", nil))
+
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+		resp := decodeErrResponse(t, rec)
+		assert.Equal(t, "tag:filmotecka,2024:problems/not-found", resp.Type)
+		assert.Equal(t, "/api/v1/films/5/?lang=en", resp.Instance)
+		assert.NotEmpty(t, resp.RequestId)
+		assert.Equal(t, requestId, resp.RequestId)
+		assert.Equal(t, requestId, rec.Header().Get("X-Request-Id"))
+	})
+	t.Run("ClientRequestId", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/5/", nil)
+		r.Header.Set("X-Request-Id", "support-42")
+		logger.ServeHTTP(rec, r)
+
+		assert.Equal(t, "support-42", decodeErrResponse(t, rec).RequestId)
+		assert.Equal(t, "support-42", rec.Header().Get("X-Request-Id"))
+	})
+}
+
+func TestHandler_ErrorMapping(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("DeleteMissingFilm", func(t *testing.T) {
+		films := mocks.NewFilm(t)
+		films.On("DeleteFilm", mock.Anything, 0, 5, 0).Return(service.ErrNotFound)
+		h := NewHandler(&service.Service{Film: films}, log)
+
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/films/5/", nil)
+		r.SetPathValue("film_id", "5")
+		rec := httptest.NewRecorder()
+		h.DeleteFilm(rec, r)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		resp := decodeErrResponse(t, rec)
+		assert.Equal(t, "not_found", resp.Code)
+		assert.Equal(t, "Specified film not found", resp.Detail)
+	})
+	t.Run("UpdateMissingActor", func(t *testing.T) {
+		actors := mocks.NewActor(t)
+		actors.On("UpdateActor", mock.Anything, 0, mock.AnythingOfType("domain.Actor")).Return(0, service.ErrNotFound)
+		h := NewHandler(&service.Service{Actor: actors}, log)
+
+		body := `{"name":"Keanu Reeves","gender":1,"birthday":"1964-09-02"}`
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/actors/7/", strings.NewReader(body))
+		r.SetPathValue("actor_id", "7")
+		rec := httptest.NewRecorder()
+		h.UpdateActor(rec, r)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "not_found", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("CreateDuplicateActor", func(t *testing.T) {
+		actors := mocks.NewActor(t)
+		actors.On("CreateActor", mock.Anything, 0, mock.AnythingOfType("domain.Actor")).Return(0, service.ErrConflict)
+		h := NewHandler(&service.Service{Actor: actors}, log)
+
+		body := `{"name":"Keanu Reeves","gender":1,"birthday":"1964-09-02"}`
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/actors/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateActor(rec, r)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "conflict", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("CreateFilmWithUnknownActors", func(t *testing.T) {
+		films := mocks.NewFilm(t)
+		films.On("CreateFilm", mock.Anything, 0, mock.AnythingOfType("domain.Film"), []int{3, 7}).
+			Return(0, &service.UnknownActorsError{Ids: []int{3, 7}})
+		h := NewHandler(&service.Service{Film: films}, log)
+
+		body := `{"film":{"title":"Matrix","description":"Neo","released":"1999-03-31","rating":9},
+			"actorIds":[3,7]}`
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/films/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateFilm(rec, r)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		resp := decodeErrResponse(t, rec)
+		assert.Equal(t, "unprocessable", resp.Code)
+		assert.Contains(t, resp.Detail, "3, 7")
+	})
+	t.Run("InvalidFilmFields", func(t *testing.T) {
+		h := NewHandler(&service.Service{}, log)
+
+		body := `{"film":{"title":"","description":"Neo","released":"1999-03-31","rating":11,
+			"countries":["US","usa"]}}`
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/films/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		h.CreateFilm(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeErrResponse(t, rec)
+		assert.Equal(t, "invalid_input", resp.Code)
+		assert.ElementsMatch(t, []fieldError{
+			{Field: "film.title", Rule: "required", Message: "is required"},
+			{Field: "film.rating", Rule: "lte", Message: "must be at most 10"},
+			{Field: "film.countries[1]", Rule: "iso3166_1_alpha2", Message: "must be an ISO 3166-1 alpha-2 country code"},
+		}, resp.Errors)
+	})
+	t.Run("InvalidFilmFilter", func(t *testing.T) {
+		h := NewHandler(&service.Service{}, log)
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/?age_rating=21%2B", nil)
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		resp := decodeErrResponse(t, rec)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "age_rating", resp.Errors[0].Field)
+		assert.Equal(t, "oneof", resp.Errors[0].Rule)
+	})
+	t.Run("BadFilmId", func(t *testing.T) {
+		h := NewHandler(&service.Service{}, log)
+
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/films/abc/", nil)
+		r.SetPathValue("film_id", "abc")
+		rec := httptest.NewRecorder()
+		h.DeleteFilm(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_input", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("InternalErrorIsHidden", func(t *testing.T) {
+		actors := mocks.NewActor(t)
+		actors.On("DeleteActor", mock.Anything, 0, 7, 0).Return(errors.New("pq: connection reset"))
+		h := NewHandler(&service.Service{Actor: actors}, log)
+
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/actors/7/", nil)
+		r.SetPathValue("actor_id", "7")
+		rec := httptest.NewRecorder()
+		h.DeleteActor(rec, r)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		resp := decodeErrResponse(t, rec)
+		assert.Equal(t, "internal", resp.Code)
+		assert.NotContains(t, resp.Detail, "connection reset")
+	})
+}
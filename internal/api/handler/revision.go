@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func revisionErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified revision not found",
+	})
+}
+
+// ListFilmRevisions godoc
+//
+//		@Summary		История фильма
+//		@Description	Все изменения фильма, от последнего к первому: кто, когда и что поменял
+//		@Tags			revisions
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{array}		domain.Revision
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/revisions/ [get]
+func (h *Handler) ListFilmRevisions(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Revision.ListFilmRevisions"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	revisions, err := h.services.ListFilmRevisions(r.Context(), filmId)
+	if err != nil {
+		revisionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, revisions)
+}
+
+// ListActorRevisions godoc
+//
+//		@Summary		История актера
+//		@Description	Все изменения актера, от последнего к первому: кто, когда и что поменял
+//		@Tags			revisions
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			actor_id path int true "ИД актера"
+//		@Success		200	{array}		domain.Revision
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/{actor_id}/revisions/ [get]
+func (h *Handler) ListActorRevisions(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Revision.ListActorRevisions"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+
+	revisions, err := h.services.ListActorRevisions(r.Context(), actorId)
+	if err != nil {
+		revisionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, revisions)
+}
+
+// RevertFilm godoc
+//
+//		@Summary		Откатить фильм
+//		@Description	Вернуть фильм и его актеров к состоянию после указанного изменения.
+//		@Description	Откат проверяется так же, как обычное обновление, и сам попадает в историю
+//		@Tags			revisions
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			revision_id path int true "ИД изменения"
+//	 	@Param			If-Match header string false "ETag, полученный при чтении фильма"
+//		@Success		200	{object}	filmInput
+//		@Header			200	{string}	ETag	"Новая версия фильма"
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
+//		@Router			/films/{film_id}/revisions/{revision_id}/revert/ [post]
+func (h *Handler) RevertFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Revision.RevertFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+	revisionId, err := strconv.Atoi(r.PathValue("revision_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect revision id. Please, check your input", err.Error())
+		return
+	}
+
+	snapshot, err := h.services.FilmRevision(r.Context(), filmId, revisionId)
+	if err != nil {
+		revisionErrResponse(log, w, r, err)
+		return
+	}
+	if snapshot.DeletedAt != nil {
+		newErrResponse(log, w, r, http.StatusUnprocessableEntity, "revert error",
+			"Film was deleted by this revision. Please, restore it from the trash instead",
+			"revision deletes the film")
+		return
+	}
+
+	input := filmInput{Film: snapshot.Film, ActorIds: snapshot.ActorIds}
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+	input.Film.Version, err = ifMatch(r)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	version, err := h.services.RevertFilm(r.Context(), userId, input.Film, input.ActorIds)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(input)
+	setETag(w, version)
+	w.Write(resp)
+}
+
+// RevertActor godoc
+//
+//		@Summary		Откатить актера
+//		@Description	Вернуть актера к состоянию после указанного изменения.
+//		@Description	Откат проверяется так же, как обычное обновление, и сам попадает в историю
+//		@Tags			revisions
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД актера"
+//	 	@Param			revision_id path int true "ИД изменения"
+//	 	@Param			If-Match header string false "ETag, полученный при чтении актера"
+//		@Success		200	{object}	domain.Actor
+//		@Header			200	{string}	ETag	"Новая версия актера"
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		412	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
+//		@Router			/actors/{actor_id}/revisions/{revision_id}/revert/ [post]
+func (h *Handler) RevertActor(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Revision.RevertActor"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+	revisionId, err := strconv.Atoi(r.PathValue("revision_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect revision id. Please, check your input", err.Error())
+		return
+	}
+
+	actor, err := h.services.ActorRevision(r.Context(), actorId, revisionId)
+	if err != nil {
+		revisionErrResponse(log, w, r, err)
+		return
+	}
+	if actor.DeletedAt != nil {
+		newErrResponse(log, w, r, http.StatusUnprocessableEntity, "revert error",
+			"Actor was deleted by this revision. Please, restore it from the trash instead",
+			"revision deletes the actor")
+		return
+	}
+
+	err = newValidator().Struct(actor)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+	actor.Version, err = ifMatch(r)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	version, err := h.services.RevertActor(r.Context(), userId, actor)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(actor)
+	setETag(w, version)
+	w.Write(resp)
+}
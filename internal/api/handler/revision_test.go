@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHandler_RevertFilm(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/films/5/revisions/9/revert/", nil)
+		r.SetPathValue("film_id", "5")
+		r.SetPathValue("revision_id", "9")
+		return r
+	}
+
+	t.Run("Revert", func(t *testing.T) {
+		films, revisions := mocks.NewFilm(t), mocks.NewRevision(t)
+		description := "Danila comes back from the army"
+		released := domain.NewDate(time.Date(1997, 5, 17, 0, 0, 0, 0, time.UTC))
+		film := domain.Film{Id: 5, Title: "Brother", Description: &description, Released: released}
+		revisions.On("FilmRevision", mock.Anything, 5, 9).Return(domain.FilmSnapshot{Film: film, ActorIds: []int{3}}, nil)
+		films.On("RevertFilm", mock.Anything, 0, film, []int{3}).Return(4, nil)
+		h := NewHandler(&service.Service{Film: films, Revision: revisions}, log)
+
+		rec := httptest.NewRecorder()
+		h.RevertFilm(rec, newRequest())
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+		assert.Contains(t, rec.Body.String(), `"actorIds":[3]`)
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		revisions := mocks.NewRevision(t)
+		revisions.On("FilmRevision", mock.Anything, 5, 9).Return(domain.FilmSnapshot{Film: domain.Film{Id: 5}}, nil)
+		h := NewHandler(&service.Service{Revision: revisions}, log)
+
+		rec := httptest.NewRecorder()
+		h.RevertFilm(rec, newRequest())
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_input", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("Deleted", func(t *testing.T) {
+		revisions := mocks.NewRevision(t)
+		deletedAt := time.Now()
+		revisions.On("FilmRevision", mock.Anything, 5, 9).Return(domain.FilmSnapshot{
+			Film: domain.Film{Id: 5, Title: "Brother", DeletedAt: &deletedAt}}, nil)
+		h := NewHandler(&service.Service{Revision: revisions}, log)
+
+		rec := httptest.NewRecorder()
+		h.RevertFilm(rec, newRequest())
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+}
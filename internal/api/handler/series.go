@@ -0,0 +1,467 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func seriesErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified series, season or episode not found",
+		apperr.CodeConflict: "Season or episode with this number already exists",
+		apperr.CodeInvalid:  "Unknown series, season or actor. Please, check your input",
+	})
+}
+
+// ListCatalog godoc
+//
+//		@Summary		Каталог
+//		@Description	Фильмы и сериалы одним списком с признаком kind
+//		@Tags			catalog
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			sortby query string false "Поле и направление сортировки" example(rating.desc)
+//		@Success		200	{array}		domain.CatalogItem
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/catalog/ [get]
+func (h *Handler) ListCatalog(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.ListCatalog"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	sortParams := strings.Split(r.URL.Query().Get("sortby"), ".")
+	sortParams, err := validateSortParams(sortParams)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "sort error", err.Error(), err.Error())
+		return
+	}
+
+	items, err := h.services.ListCatalog(r.Context(), sortParams[0], sortParams[1])
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+	if items == nil {
+		items = []domain.CatalogItem{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, items)
+}
+
+// SearchCatalog godoc
+//
+//		@Summary		Поиск по каталогу
+//		@Description	Поиск фильмов и сериалов по фрагменту названия или имени актера
+//		@Tags			catalog
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			query query string true "Поисковый запрос" example("Solaris")
+//		@Success		200	{array}		domain.CatalogItem
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/catalog/search/ [get]
+func (h *Handler) SearchCatalog(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.SearchCatalog"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	query := r.URL.Query().Get("query")
+	if query == "" {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Search query is empty", "Search query is empty")
+		return
+	}
+
+	items, err := h.services.SearchCatalog(r.Context(), query)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+	if items == nil {
+		items = []domain.CatalogItem{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, items)
+}
+
+// ListSeries godoc
+//
+//		@Summary		Список сериалов
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			sortby query string false "Поле и направление сортировки" example(rating.desc)
+//		@Success		200	{array}		domain.Series
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/series/ [get]
+func (h *Handler) ListSeries(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.ListSeries"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	sortParams := strings.Split(r.URL.Query().Get("sortby"), ".")
+	sortParams, err := validateSortParams(sortParams)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "sort error", err.Error(), err.Error())
+		return
+	}
+
+	series, err := h.services.ListSeries(r.Context(), sortParams[0], sortParams[1])
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+	if series == nil {
+		series = []domain.Series{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, series)
+}
+
+// GetSeries godoc
+//
+//		@Summary		Сериал
+//		@Description	Сериал с сезонами, эпизодами и актерским составом
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			series_id path int true "ИД сериала"
+//		@Success		200	{object}	domain.Series
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/series/{series_id}/ [get]
+func (h *Handler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.GetSeries"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("series_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect series id. Please, check your input", err.Error())
+		return
+	}
+
+	series, err := h.services.GetSeries(r.Context(), id)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, series)
+}
+
+type seriesInput struct {
+	domain.Series `json:"series"`
+	ActorIds      []int `json:"actorIds,omitempty"`
+}
+
+// CreateSeries godoc
+//
+//		@Summary		Добавить сериал
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			input body seriesInput true "Информация о сериале"
+//		@Success		201	{object}	seriesInput
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/series/ [post]
+func (h *Handler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.CreateSeries"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var input seriesInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	input.Id, err = h.services.CreateSeries(r.Context(), input.Series, input.ActorIds)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(input)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// UpdateSeries godoc
+//
+//		@Summary		Обновить сериал
+//		@Description	Полная замена информации о сериале и его актерского состава
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			series_id path int true "ИД сериала"
+//	 	@Param			input body seriesInput true "Информация о сериале"
+//		@Success		200	{object}	seriesInput
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/series/{series_id}/ [put]
+func (h *Handler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.UpdateSeries"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var input seriesInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	input.Id, err = strconv.Atoi(r.PathValue("series_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect series id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.UpdateSeries(r.Context(), input.Series, input.ActorIds)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(input)
+	w.Write(resp)
+}
+
+// DeleteSeries godoc
+//
+//		@Summary		Удалить сериал
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			series_id path int true "ИД сериала"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/series/{series_id}/ [delete]
+func (h *Handler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.DeleteSeries"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("series_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect series id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteSeries(r.Context(), id)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// CreateSeason godoc
+//
+//		@Summary		Добавить сезон
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			series_id path int true "ИД сериала"
+//	 	@Param			season body domain.Season true "Номер и название сезона"
+//		@Success		201	{object}	domain.Season
+//		@Failure		400	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/series/{series_id}/seasons/ [post]
+func (h *Handler) CreateSeason(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.CreateSeason"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var season domain.Season
+	err := json.NewDecoder(r.Body).Decode(&season)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	season.SeriesId, err = strconv.Atoi(r.PathValue("series_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect series id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(season)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	season.Id, err = h.services.CreateSeason(r.Context(), season)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(season)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// DeleteSeason godoc
+//
+//		@Summary		Удалить сезон
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			season_id path int true "ИД сезона"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/seasons/{season_id}/ [delete]
+func (h *Handler) DeleteSeason(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.DeleteSeason"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("season_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect season id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteSeason(r.Context(), id)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+}
+
+type episodeInput struct {
+	domain.Episode `json:"episode"`
+	ActorIds       []int `json:"actorIds,omitempty"`
+}
+
+// CreateEpisode godoc
+//
+//		@Summary		Добавить эпизод
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			season_id path int true "ИД сезона"
+//	 	@Param			input body episodeInput true "Эпизод и его актерский состав"
+//		@Success		201	{object}	episodeInput
+//		@Failure		400	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/seasons/{season_id}/episodes/ [post]
+func (h *Handler) CreateEpisode(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.CreateEpisode"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var input episodeInput
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	input.SeasonId, err = strconv.Atoi(r.PathValue("season_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect season id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(input)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	input.Id, err = h.services.CreateEpisode(r.Context(), input.Episode, input.ActorIds)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(input)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// DeleteEpisode godoc
+//
+//		@Summary		Удалить эпизод
+//		@Tags			series
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			episode_id path int true "ИД эпизода"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/episodes/{episode_id}/ [delete]
+func (h *Handler) DeleteEpisode(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Series.DeleteEpisode"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	id, err := strconv.Atoi(r.PathValue("episode_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect episode id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteEpisode(r.Context(), id)
+	if err != nil {
+		seriesErrResponse(log, w, r, err)
+		return
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// streamWriteTimeout limits a single write of a streamed list. The server write timeout covers
+// the whole response, which a long list outlives, so the deadline moves along with the stream
+const streamWriteTimeout = 10 * time.Second
+
+// listWriter sends a list an element at a time, as a json array or a line per element.
+// Nothing goes out before the first element, so an empty list or an early error still gets its own status
+type listWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	enc     *json.Encoder
+	format  string
+	started bool
+	count   int
+}
+
+func newListWriter(w http.ResponseWriter, format string) *listWriter {
+	return &listWriter{w: w, rc: http.NewResponseController(w), enc: json.NewEncoder(w), format: format}
+}
+
+func (lw *listWriter) start(status int) error {
+	lw.started = true
+	lw.w.Header().Set("Content-Type", formatContentTypes[lw.format])
+	lw.w.Header().Add("Vary", "Accept")
+	lw.w.WriteHeader(status)
+	if lw.format == formatJSON {
+		_, err := io.WriteString(lw.w, "[")
+		return err
+	}
+	return nil
+}
+
+func (lw *listWriter) write(v any) error {
+	lw.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	var err error
+	switch {
+	case !lw.started:
+		err = lw.start(http.StatusOK)
+	case lw.format == formatJSON:
+		_, err = io.WriteString(lw.w, ",")
+	}
+	if err != nil {
+		return err
+	}
+	lw.count++
+	return lw.enc.Encode(v)
+}
+
+// close ends the list, an empty one is sent with emptyStatus
+func (lw *listWriter) close(emptyStatus int) error {
+	if !lw.started {
+		if err := lw.start(emptyStatus); err != nil {
+			return err
+		}
+	}
+	if lw.format == formatJSON {
+		_, err := io.WriteString(lw.w, "]")
+		return err
+	}
+	return nil
+}
+
+// streamList sends the elements list passes to send as they come. json and ndjson go out one by one,
+// csv and xml need all the columns first and are collected. An empty list is sent with emptyStatus.
+// fail reports an error that comes before anything is sent, after that the connection can only be broken.
+// A client that has gone away stops the list silently
+func (h *Handler) streamList(log *slog.Logger, w http.ResponseWriter, r *http.Request, emptyStatus int,
+	list func(send func(v any) error) error, fail func(*slog.Logger, http.ResponseWriter, *http.Request, error)) {
+	format, ok := responseFormat(r)
+	if !ok {
+		notAcceptable(log, w, r)
+		return
+	}
+
+	if format == formatCSV || format == formatXML {
+		items := make([]any, 0)
+		err := list(func(v any) error {
+			items = append(items, v)
+			return nil
+		})
+		if err != nil {
+			fail(log, w, r, err)
+			return
+		}
+		status := http.StatusOK
+		if len(items) == 0 {
+			status = emptyStatus
+		}
+		h.respond(log, w, r, status, items)
+		return
+	}
+
+	lw := newListWriter(w, format)
+	err := list(func(v any) error {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+		return lw.write(v)
+	})
+	if err == nil {
+		err = lw.close(emptyStatus)
+	}
+	switch {
+	case err == nil:
+	case errors.Is(r.Context().Err(), context.Canceled):
+		log.Info("client went away", slog.Int("sent", lw.count))
+	case !lw.started:
+		fail(log, w, r, err)
+	default:
+		// a cut off list must not look complete, the deadline included
+		log.Error("list interrupted: " + err.Error())
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_ListFilmsStream(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	films := []domain.Film{
+		{Id: 1, Title: "Solaris", Actors: []domain.Actor{{Id: 5, Name: "Donatas Banionis"}}},
+		{Id: 2, Title: "Stalker"},
+	}
+
+	prepare := func(t *testing.T, films []domain.Film, err error) *Handler {
+		filmService := mocks.NewFilm(t)
+		filmService.On("StreamFilms", mock.Anything, sortRating, descSort, -1, domain.FilmFilter{}, mock.Anything).
+			Run(func(args mock.Arguments) {
+				fn := args.Get(5).(func(domain.Film) error)
+				for _, film := range films {
+					if fn(film) != nil {
+						return
+					}
+				}
+			}).Return(err)
+		translations := mocks.NewTranslation(t)
+		translations.On("Localizer", mock.Anything, "").Return(service.Localizer{}, nil)
+		return NewHandler(&service.Service{Film: filmService, Translation: translations}, log)
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		h := prepare(t, films, nil)
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var got []domain.Film
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, films, got)
+	})
+	t.Run("NDJSON", func(t *testing.T) {
+		h := prepare(t, films, nil)
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil)
+		r.Header.Set("Accept", "application/x-ndjson")
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, r)
+
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], `{"id":2,"title":"Stalker"`))
+	})
+	t.Run("CSV", func(t *testing.T) {
+		h := prepare(t, films, nil)
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/?format=csv", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "actors.name")
+		assert.Contains(t, rec.Body.String(), "Donatas Banionis")
+	})
+	t.Run("Empty", func(t *testing.T) {
+		h := prepare(t, nil, nil)
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "[]", rec.Body.String())
+	})
+	t.Run("FailedBeforeData", func(t *testing.T) {
+		h := prepare(t, nil, service.ErrInternal)
+		rec := httptest.NewRecorder()
+		h.ListFilms(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil))
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	})
+	t.Run("FailedWithData", func(t *testing.T) {
+		h := prepare(t, films, service.ErrInternal)
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil)
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.ListFilms(httptest.NewRecorder(), r)
+		})
+	})
+	t.Run("ClientGone", func(t *testing.T) {
+		h := prepare(t, films, context.Canceled)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		assert.NotPanics(t, func() {
+			h.ListFilms(rec, r)
+		})
+		assert.Empty(t, rec.Body.String())
+	})
+	t.Run("DeadlineWithData", func(t *testing.T) {
+		h := NewHandler(&service.Service{}, log)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.streamList(h.log, rec, r, http.StatusNotFound, func(send func(v any) error) error {
+				if err := send(films[0]); err != nil {
+					return err
+				}
+				<-ctx.Done()
+				return send(films[1])
+			}, filmErrResponse)
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type filmSuggestionInput struct {
+	PatchFilmInput
+	Comment string `json:"comment" validate:"lte=1000"`
+}
+
+type actorSuggestionInput struct {
+	domain.ActorInput
+	Comment string `json:"comment" validate:"lte=1000"`
+}
+
+func suggestionErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified suggestion not found",
+		apperr.CodeConflict: "Suggestion has already been reviewed",
+	})
+}
+
+// CreateFilmSuggestion godoc
+//
+//		@Summary		Предложить правку фильма
+//		@Description	Правка в формате PATCH /films/{film_id}/ попадает в очередь модерации
+//		@Tags			suggestions
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			input body filmSuggestionInput true "Правка и комментарий"
+//		@Success		201	{object}	domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/suggestions/ [post]
+func (h *Handler) CreateFilmSuggestion(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.CreateFilmSuggestion"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	var input filmSuggestionInput
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	if err = newValidator().Struct(input); err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+	// the cast is taken from the top level actorIds only, as PatchFilm does
+	input.NullableFilm.ActorIds = nil
+	changes, _ := json.Marshal(input.PatchFilmInput)
+
+	userId, _ := getUserId(r)
+	suggestion := domain.Suggestion{Kind: domain.KindFilm, RecordId: filmId, UserId: &userId,
+		Changes: changes, Comment: input.Comment, Status: domain.SuggestionPending}
+	suggestion.Id, err = h.services.CreateSuggestion(r.Context(), suggestion)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(suggestion)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// CreateActorSuggestion godoc
+//
+//		@Summary		Предложить правку актера
+//		@Description	Правка в формате PATCH /actors/{actor_id}/ попадает в очередь модерации
+//		@Tags			suggestions
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД актера"
+//	 	@Param			input body actorSuggestionInput true "Правка и комментарий"
+//		@Success		201	{object}	domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/{actor_id}/suggestions/ [post]
+func (h *Handler) CreateActorSuggestion(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.CreateActorSuggestion"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+
+	var input actorSuggestionInput
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	if err = newValidator().Struct(input); err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+	changes, _ := json.Marshal(input.ActorInput)
+
+	userId, _ := getUserId(r)
+	suggestion := domain.Suggestion{Kind: domain.KindActor, RecordId: actorId, UserId: &userId,
+		Changes: changes, Comment: input.Comment, Status: domain.SuggestionPending}
+	suggestion.Id, err = h.services.CreateSuggestion(r.Context(), suggestion)
+	if err != nil {
+		actorErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(suggestion)
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// ListSuggestions godoc
+//
+//		@Summary		Очередь модерации
+//		@Description	Предложенные пользователями правки, от старых к новым. Для ожидающих показывается разница с текущими данными
+//		@Tags			suggestions
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			status query string false "Статус" Enums(pending, approved, rejected, all) default(pending)
+//		@Success		200	{array}		domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/suggestions/ [get]
+func (h *Handler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.ListSuggestions"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = domain.SuggestionPending
+	case "all":
+		status = ""
+	case domain.SuggestionPending, domain.SuggestionApproved, domain.SuggestionRejected:
+	default:
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Unknown suggestion status "+strconv.Quote(status), "unknown status")
+		return
+	}
+
+	suggestions, err := h.services.ListSuggestions(r.Context(), status)
+	if err != nil {
+		suggestionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, suggestions)
+}
+
+// ListMySuggestions godoc
+//
+//	@Summary		Мои правки
+//	@Description	Правки, предложенные текущим пользователем, и решения по ним
+//	@Tags			suggestions
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.Suggestion
+//	@Failure		500	{object}	errorResponse
+//	@Router			/me/suggestions/ [get]
+func (h *Handler) ListMySuggestions(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.ListMySuggestions"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	userId, _ := getUserId(r)
+	suggestions, err := h.services.ListUserSuggestions(r.Context(), userId)
+	if err != nil {
+		suggestionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, suggestions)
+}
+
+// GetSuggestion godoc
+//
+//		@Summary		Правка
+//		@Description	Предложенная правка и ее разница с текущими данными
+//		@Tags			suggestions
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			suggestion_id path int true "ИД правки"
+//		@Success		200	{object}	domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/suggestions/{suggestion_id}/ [get]
+func (h *Handler) GetSuggestion(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.GetSuggestion"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	id, err := strconv.Atoi(r.PathValue("suggestion_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect suggestion id. Please, check your input", err.Error())
+		return
+	}
+
+	suggestion, err := h.services.GetSuggestion(r.Context(), id)
+	if err != nil {
+		suggestionErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, suggestion)
+}
+
+// ApproveSuggestion godoc
+//
+//		@Summary		Принять правку
+//		@Description	Правка применяется так же, как PATCH фильма или актера, и попадает в историю
+//		@Tags			suggestions
+//		@Produce		json
+//	 	@Param			suggestion_id path int true "ИД правки"
+//		@Success		200	{object}	domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Failure		422	{object}	errorResponse
+//		@Router			/suggestions/{suggestion_id}/approve/ [post]
+func (h *Handler) ApproveSuggestion(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.ApproveSuggestion"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	id, err := strconv.Atoi(r.PathValue("suggestion_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect suggestion id. Please, check your input", err.Error())
+		return
+	}
+
+	suggestion, err := h.services.GetSuggestion(r.Context(), id)
+	if err != nil {
+		suggestionErrResponse(log, w, r, err)
+		return
+	}
+	if suggestion.Status != domain.SuggestionPending {
+		newErrResponse(log, w, r, http.StatusConflict, "conflict",
+			"Suggestion has already been reviewed", "suggestion is "+suggestion.Status)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	switch suggestion.Kind {
+	case domain.KindFilm:
+		var input PatchFilmInput
+		if err = suggestion.Changes.Unmarshal(&input); err != nil {
+			suggestionErrResponse(log, w, r, err)
+			return
+		}
+		if err = newValidator().Struct(input); err != nil {
+			validationErrResponse(log, w, r, err)
+			return
+		}
+		input.Id = suggestion.RecordId
+		suggestion, err = h.services.ApproveFilmSuggestion(r.Context(), userId, id, input.NullableFilm, input.ActorIds)
+		if errors.Is(err, service.ErrReviewed) {
+			suggestionErrResponse(log, w, r, err)
+			return
+		}
+		if err != nil {
+			filmErrResponse(log, w, r, err)
+			return
+		}
+	case domain.KindActor:
+		var input domain.ActorInput
+		if err = suggestion.Changes.Unmarshal(&input); err != nil {
+			suggestionErrResponse(log, w, r, err)
+			return
+		}
+		if err = newValidator().Struct(input); err != nil {
+			validationErrResponse(log, w, r, err)
+			return
+		}
+		input.Id = suggestion.RecordId
+		suggestion, err = h.services.ApproveActorSuggestion(r.Context(), userId, id, input)
+		if errors.Is(err, service.ErrReviewed) {
+			suggestionErrResponse(log, w, r, err)
+			return
+		}
+		if err != nil {
+			actorErrResponse(log, w, r, err)
+			return
+		}
+	}
+
+	resp, _ := json.Marshal(suggestion)
+	w.Write(resp)
+}
+
+// RejectSuggestion godoc
+//
+//		@Summary		Отклонить правку
+//		@Tags			suggestions
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			suggestion_id path int true "ИД правки"
+//	 	@Param			input body domain.SuggestionRejection true "Причина отказа"
+//		@Success		200	{object}	domain.Suggestion
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Failure		409	{object}	errorResponse
+//		@Router			/suggestions/{suggestion_id}/reject/ [post]
+func (h *Handler) RejectSuggestion(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Suggestion.RejectSuggestion"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	id, err := strconv.Atoi(r.PathValue("suggestion_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect suggestion id. Please, check your input", err.Error())
+		return
+	}
+
+	var input domain.SuggestionRejection
+	if err = json.NewDecoder(r.Body).Decode(&input); err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	if err = newValidator().Struct(input); err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	userId, _ := getUserId(r)
+	suggestion, err := h.services.RejectSuggestion(r.Context(), userId, id, input.Reason)
+	if err != nil {
+		suggestionErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(suggestion)
+	w.Write(resp)
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Suggestions(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("CreateFilmSuggestion", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("CreateSuggestion", mock.Anything, mock.MatchedBy(func(s domain.Suggestion) bool {
+			return s.Kind == domain.KindFilm && s.RecordId == 5 && s.Comment == "typo" &&
+				strings.Contains(s.Changes.String(), `"title":"Brother"`) &&
+				strings.Contains(s.Changes.String(), `"actorIds":[3]`)
+		})).Return(8, nil)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/films/5/suggestions/",
+			strings.NewReader(`{"film":{"title":"Brother"},"actorIds":[3],"comment":"typo"}`))
+		r.SetPathValue("film_id", "5")
+		rec := httptest.NewRecorder()
+		h.CreateFilmSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"id":8`)
+	})
+	t.Run("ApproveFilmSuggestion", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("GetSuggestion", mock.Anything, 8).Return(domain.Suggestion{Id: 8, Kind: domain.KindFilm, RecordId: 5,
+			Status: domain.SuggestionPending, Changes: types.JSONText(`{"film":{"rating":8},"actorIds":[3]}`)}, nil)
+		suggestions.On("ApproveFilmSuggestion", mock.Anything, 0, 8, mock.MatchedBy(func(input domain.NullableFilm) bool {
+			return input.Id == 5 && *input.Rating == 8 && input.Title == nil
+		}), []int{3}).Return(domain.Suggestion{Id: 8, Status: domain.SuggestionApproved}, nil)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/suggestions/8/approve/", nil)
+		r.SetPathValue("suggestion_id", "8")
+		rec := httptest.NewRecorder()
+		h.ApproveSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"approved"`)
+	})
+	t.Run("ApproveTitleOnly", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("GetSuggestion", mock.Anything, 8).Return(domain.Suggestion{Id: 8, Kind: domain.KindFilm, RecordId: 5,
+			Status: domain.SuggestionPending, Changes: types.JSONText(`{"film":{"title":"Brother"}}`)}, nil)
+		suggestions.On("ApproveFilmSuggestion", mock.Anything, 0, 8, mock.MatchedBy(func(input domain.NullableFilm) bool {
+			return input.Id == 5 && *input.Title == "Brother"
+		}), []int(nil)).Return(domain.Suggestion{Id: 8, Status: domain.SuggestionApproved}, nil)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/suggestions/8/approve/", nil)
+		r.SetPathValue("suggestion_id", "8")
+		rec := httptest.NewRecorder()
+		h.ApproveSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+	t.Run("ApproveConcurrently", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("GetSuggestion", mock.Anything, 8).Return(domain.Suggestion{Id: 8, Kind: domain.KindActor, RecordId: 5,
+			Status: domain.SuggestionPending, Changes: types.JSONText(`{"name":"Sergei Bodrov"}`)}, nil)
+		suggestions.On("ApproveActorSuggestion", mock.Anything, 0, 8, mock.AnythingOfType("domain.ActorInput")).
+			Return(domain.Suggestion{}, service.ErrReviewed)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/suggestions/8/approve/", nil)
+		r.SetPathValue("suggestion_id", "8")
+		rec := httptest.NewRecorder()
+		h.ApproveSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "Suggestion has already been reviewed", decodeErrResponse(t, rec).Detail)
+	})
+	t.Run("ApproveInvalidSuggestion", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("GetSuggestion", mock.Anything, 8).Return(domain.Suggestion{Id: 8, Kind: domain.KindFilm, RecordId: 5,
+			Status: domain.SuggestionPending, Changes: types.JSONText(`{"film":{"rating":11}}`)}, nil)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/suggestions/8/approve/", nil)
+		r.SetPathValue("suggestion_id", "8")
+		rec := httptest.NewRecorder()
+		h.ApproveSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_input", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("ApproveReviewed", func(t *testing.T) {
+		suggestions := mocks.NewSuggestion(t)
+		suggestions.On("GetSuggestion", mock.Anything, 8).
+			Return(domain.Suggestion{Id: 8, Kind: domain.KindActor, Status: domain.SuggestionRejected}, nil)
+		h := NewHandler(&service.Service{Suggestion: suggestions}, log)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/suggestions/8/approve/", nil)
+		r.SetPathValue("suggestion_id", "8")
+		rec := httptest.NewRecorder()
+		h.ApproveSuggestion(rec, r)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Timeouts limit how long a request may run its queries. A zero value means no limit
+type Timeouts struct {
+	// Read is the limit of GET and HEAD requests
+	Read time.Duration
+	// Write is the limit of requests that change data
+	Write time.Duration
+	// Bulk is the limit of streamed lists and the actors graph, which go through the whole catalog
+	Bulk time.Duration
+	// Transfer is the limit of imports and exports. They outlive any fixed limit on a large catalog,
+	// so it is off by default
+	Transfer time.Duration
+}
+
+// transferPrefixes are the paths of imports and exports
+var transferPrefixes = []string{"/api/v1/import/", "/api/v1/export/", "/api/v1/me/letterboxd/"}
+
+// bulkPrefixes are the paths of the other endpoints that read many records at once
+var bulkPrefixes = []string{"/api/v1/actors/graph/"}
+
+// bulkLists are the list endpoints that stream the whole table
+var bulkLists = []string{"/api/v1/films/", "/api/v1/actors/"}
+
+// of picks the limit of the request by its method and path
+func (t Timeouts) of(r *http.Request) time.Duration {
+	for _, prefix := range transferPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return t.Transfer
+		}
+	}
+	for _, prefix := range bulkPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return t.Bulk
+		}
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return t.Write
+	}
+	for _, path := range bulkLists {
+		if r.URL.Path == path {
+			return t.Bulk
+		}
+	}
+	return t.Read
+}
+
+type Timeout struct {
+	timeouts Timeouts
+	handler  http.Handler
+}
+
+// ServeHTTP puts a deadline on the request context, the services and repositories stop
+// their queries once it passes
+func (t *Timeout) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if d := t.timeouts.of(r); d > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), d)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	t.handler.ServeHTTP(w, r)
+}
+
+func NewTimeout(timeouts Timeouts, handlerToWrap http.Handler) *Timeout {
+	return &Timeout{timeouts, handlerToWrap}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/service/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTimeouts_of(t *testing.T) {
+	timeouts := Timeouts{Read: time.Second, Write: 2 * time.Second, Bulk: time.Minute, Transfer: time.Hour}
+
+	tests := []struct {
+		Label  string
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{"GetFilm", http.MethodGet, "/api/v1/films/5/", time.Second},
+		{"HeadFilm", http.MethodHead, "/api/v1/films/5/", time.Second},
+		{"CreateFilm", http.MethodPost, "/api/v1/films/", 2 * time.Second},
+		{"DeleteActor", http.MethodDelete, "/api/v1/actors/3/", 2 * time.Second},
+		{"ListFilms", http.MethodGet, "/api/v1/films/", time.Minute},
+		{"ListActors", http.MethodGet, "/api/v1/actors/", time.Minute},
+		{"Import", http.MethodPost, "/api/v1/import/films/", time.Hour},
+		{"Export", http.MethodGet, "/api/v1/export/actors/", time.Hour},
+		{"Letterboxd", http.MethodPost, "/api/v1/me/letterboxd/", time.Hour},
+		{"Graph", http.MethodGet, "/api/v1/actors/graph/", time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			assert.Equal(t, tt.want, timeouts.of(httptest.NewRequest(tt.method, tt.path, nil)))
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	log := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	t.Run("Deadline", func(t *testing.T) {
+		var deadline time.Time
+		var ok bool
+		handler := NewTimeout(Timeouts{Read: time.Minute}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, ok = r.Context().Deadline()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/films/5/", nil))
+
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	})
+	t.Run("NoLimit", func(t *testing.T) {
+		var ok bool
+		handler := NewTimeout(Timeouts{Read: time.Minute}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, ok = r.Context().Deadline()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/films/", nil))
+
+		assert.False(t, ok)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		films := mocks.NewFilm(t)
+		films.On("GetFilm", mock.Anything, 5).Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).Return(domain.Film{}, service.ErrInternal)
+		h := NewHandler(&service.Service{Film: films}, log)
+		handler := NewTimeout(Timeouts{Read: 10 * time.Millisecond}, http.HandlerFunc(h.GetFilm))
+
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/films/5/", nil)
+		r.SetPathValue("film_id", "5")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "timeout", decodeErrResponse(t, rec).Code)
+	})
+	t.Run("ExpiredStream", func(t *testing.T) {
+		films := mocks.NewFilm(t)
+		films.On("StreamFilms", mock.Anything, sortRating, descSort, -1, domain.FilmFilter{}, mock.Anything).
+			Return(func(ctx context.Context, _, _ string, _ int, _ domain.FilmFilter, _ func(domain.Film) error) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		translations := mocks.NewTranslation(t)
+		translations.On("Localizer", mock.Anything, "").Return(service.Localizer{}, nil)
+		h := NewHandler(&service.Service{Film: films, Translation: translations}, log)
+		handler := NewTimeout(Timeouts{Bulk: 10 * time.Millisecond}, http.HandlerFunc(h.ListFilms))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/films/", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "timeout", decodeErrResponse(t, rec).Code)
+	})
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"golang.org/x/text/language"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// requestLang returns the ISO 639-1 language requested by ?lang= or Accept-Language.
+// Empty result means the original titles and names should be returned
+func requestLang(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return strings.ToLower(lang)
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return ""
+	}
+	base, confidence := tags[0].Base()
+	if confidence == language.No {
+		return ""
+	}
+	return base.String()
+}
+
+func translationErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified translation, film or actor not found",
+	})
+}
+
+// ListFilmTranslations godoc
+//
+//		@Summary		Переводы фильма
+//		@Description	Список переводов названия и описания фильма
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{array}		domain.FilmTranslation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/films/{film_id}/translations/ [get]
+func (h *Handler) ListFilmTranslations(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.ListFilmTranslations"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	translations, err := h.services.ListFilmTranslations(r.Context(), filmId)
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+	if translations == nil {
+		translations = []domain.FilmTranslation{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, translations)
+}
+
+// SetFilmTranslation godoc
+//
+//		@Summary		Задать перевод фильма
+//		@Description	Создать или заменить перевод названия и описания фильма
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			lang path string true "Язык (ISO 639-1)" example(en)
+//	 	@Param			translation body domain.FilmTranslation true "Перевод"
+//		@Success		200	{object}	domain.FilmTranslation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/films/{film_id}/translations/{lang}/ [put]
+func (h *Handler) SetFilmTranslation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.SetFilmTranslation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var translation domain.FilmTranslation
+	err := json.NewDecoder(r.Body).Decode(&translation)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	translation.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+	translation.Lang = r.PathValue("lang")
+
+	err = newValidator().Struct(translation)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.SetFilmTranslation(r.Context(), translation)
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(translation)
+	w.Write(resp)
+}
+
+// DeleteFilmTranslation godoc
+//
+//		@Summary		Удалить перевод фильма
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			lang path string true "Язык (ISO 639-1)"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/films/{film_id}/translations/{lang}/ [delete]
+func (h *Handler) DeleteFilmTranslation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.DeleteFilmTranslation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteFilmTranslation(r.Context(), filmId, r.PathValue("lang"))
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+}
+
+// ListActorTranslations godoc
+//
+//		@Summary		Переводы имени актера
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//		@Produce		text/csv
+//		@Produce		xml
+//	 	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	 	@Param			actor_id path int true "ИД актера"
+//		@Success		200	{array}		domain.ActorTranslation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		500	{object}	errorResponse
+//		@Router			/actors/{actor_id}/translations/ [get]
+func (h *Handler) ListActorTranslations(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.ListActorTranslations"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect actor id. Please, check your input", err.Error())
+		return
+	}
+
+	translations, err := h.services.ListActorTranslations(r.Context(), actorId)
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+	if translations == nil {
+		translations = []domain.ActorTranslation{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, translations)
+}
+
+// SetActorTranslation godoc
+//
+//		@Summary		Задать перевод имени актера
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД актера"
+//	 	@Param			lang path string true "Язык (ISO 639-1)" example(en)
+//	 	@Param			translation body domain.ActorTranslation true "Перевод"
+//		@Success		200	{object}	domain.ActorTranslation
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/actors/{actor_id}/translations/{lang}/ [put]
+func (h *Handler) SetActorTranslation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.SetActorTranslation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var translation domain.ActorTranslation
+	err := json.NewDecoder(r.Body).Decode(&translation)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "json parse error",
+			"Failed to parse json. Please, check your input", err.Error())
+		return
+	}
+	translation.ActorId, err = strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect actor id. Please, check your input", err.Error())
+		return
+	}
+	translation.Lang = r.PathValue("lang")
+
+	err = newValidator().Struct(translation)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	err = h.services.SetActorTranslation(r.Context(), translation)
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(translation)
+	w.Write(resp)
+}
+
+// DeleteActorTranslation godoc
+//
+//		@Summary		Удалить перевод имени актера
+//		@Tags			translations
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД актера"
+//	 	@Param			lang path string true "Язык (ISO 639-1)"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/actors/{actor_id}/translations/{lang}/ [delete]
+func (h *Handler) DeleteActorTranslation(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Translation.DeleteActorTranslation"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect actor id. Please, check your input", err.Error())
+		return
+	}
+
+	err = h.services.DeleteActorTranslation(r.Context(), actorId, r.PathValue("lang"))
+	if err != nil {
+		translationErrResponse(log, w, r, err)
+		return
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func trashErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	errResponse(log, w, r, err, errDetails{
+		apperr.CodeNotFound: "Specified record is not in the trash",
+	})
+}
+
+// ListTrash godoc
+//
+//	@Summary		Корзина
+//	@Description	Удаленные фильмы и актеры, которые еще можно восстановить
+//	@Tags			trash
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{object}	domain.Trash
+//	@Failure		500	{object}	errorResponse
+//	@Router			/trash/ [get]
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Trash.ListTrash"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	trash, err := h.services.ListTrash(r.Context())
+	if err != nil {
+		trashErrResponse(log, w, r, err)
+		return
+	}
+
+	h.respond(log, w, r, http.StatusOK, trash)
+}
+
+// RestoreFilm godoc
+//
+//		@Summary		Восстановить фильм
+//		@Description	Вернуть фильм из корзины вместе со связями с актерами
+//		@Tags			trash
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200	{object}	domain.Film
+//		@Header			200	{string}	ETag	"Версия фильма"
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/films/{film_id}/restore/ [post]
+func (h *Handler) RestoreFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Trash.RestoreFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "input error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	film, err := h.services.RestoreFilm(r.Context(), userId, filmId)
+	if err != nil {
+		trashErrResponse(log, w, r, err)
+		return
+	}
+	film.Actors, err = h.services.ListActors(r.Context(), film.Id)
+	if err != nil {
+		trashErrResponse(log, w, r, err)
+		return
+	}
+	if film.Actors == nil {
+		film.Actors = []domain.Actor{}
+	}
+
+	resp, _ := json.Marshal(film)
+	setETag(w, film.Version)
+	w.Write(resp)
+}
+
+// RestoreActor godoc
+//
+//		@Summary		Восстановить актера
+//		@Description	Вернуть актера из корзины вместе со связями с фильмами
+//		@Tags			trash
+//		@Produce		json
+//	 	@Param			actor_id path int true "ИД актера"
+//		@Success		200	{object}	domain.Actor
+//		@Header			200	{string}	ETag	"Версия актера"
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/actors/{actor_id}/restore/ [post]
+func (h *Handler) RestoreActor(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.Trash.RestoreActor"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+	actorId, err := strconv.Atoi(r.PathValue("actor_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Failed to get actor id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	actor, err := h.services.RestoreActor(r.Context(), userId, actorId)
+	if err != nil {
+		trashErrResponse(log, w, r, err)
+		return
+	}
+	actor.Films, err = h.services.ListFilms(r.Context(), sortRating, descSort, actor.Id, domain.FilmFilter{})
+	if err != nil {
+		trashErrResponse(log, w, r, err)
+		return
+	}
+	if actor.Films == nil {
+		actor.Films = []domain.Film{}
+	}
+
+	resp, _ := json.Marshal(actor)
+	setETag(w, actor.Version)
+	w.Write(resp)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// ListUserFilms godoc
+//
+//	@Summary		Мои оценки
+//	@Description	Оценки и история просмотров текущего пользователя
+//	@Tags			me
+//	@Accept			json
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		xml
+//	@Param			format query string false "Формат ответа, если не задан заголовок Accept" Enums(json, ndjson, csv, xml)
+//	@Success		200	{array}		domain.UserFilm
+//	@Failure		500	{object}	errorResponse
+//	@Router			/me/films/ [get]
+func (h *Handler) ListUserFilms(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.UserFilm.ListUserFilms"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	userId, _ := getUserId(r)
+	marks, err := h.services.ListUserFilms(r.Context(), userId)
+	if err != nil {
+		errResponse(log, w, r, err, nil)
+		return
+	}
+	if marks == nil {
+		marks = []domain.UserFilm{}
+	}
+
+	h.respond(log, w, r, http.StatusOK, marks)
+}
+
+// SetUserFilm godoc
+//
+//		@Summary		Оценить фильм
+//		@Description	Сохранить оценку и/или дату просмотра фильма
+//		@Tags			me
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//	 	@Param			mark body domain.UserFilm true "Оценка и дата просмотра"
+//		@Success		200	{object}	domain.UserFilm
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/me/films/{film_id}/ [put]
+func (h *Handler) SetUserFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.UserFilm.SetUserFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	var mark domain.UserFilm
+	err := json.NewDecoder(r.Body).Decode(&mark)
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "data parse error",
+			"Failed to parse data. Please, check your input", err.Error())
+		return
+	}
+	mark.FilmId, err = strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	err = newValidator().Struct(mark)
+	if err != nil {
+		validationErrResponse(log, w, r, err)
+		return
+	}
+
+	mark.UserId, _ = getUserId(r)
+	err = h.services.SetUserFilm(r.Context(), mark)
+	if err != nil {
+		filmErrResponse(log, w, r, err)
+		return
+	}
+
+	resp, _ := json.Marshal(mark)
+	w.Write(resp)
+}
+
+// DeleteUserFilm godoc
+//
+//		@Summary		Удалить оценку
+//		@Tags			me
+//		@Accept			json
+//		@Produce		json
+//	 	@Param			film_id path int true "ИД фильма"
+//		@Success		200
+//		@Failure		400	{object}	errorResponse
+//		@Failure		404	{object}	errorResponse
+//		@Router			/me/films/{film_id}/ [delete]
+func (h *Handler) DeleteUserFilm(w http.ResponseWriter, r *http.Request) {
+	const method = "Handlers.UserFilm.DeleteUserFilm"
+	log := h.log.With(
+		slog.String("method", method),
+	)
+
+	filmId, err := strconv.Atoi(r.PathValue("film_id"))
+	if err != nil {
+		newErrResponse(log, w, r, http.StatusBadRequest, "param error",
+			"Incorrect film id. Please, check your input", err.Error())
+		return
+	}
+
+	userId, _ := getUserId(r)
+	err = h.services.DeleteUserFilm(r.Context(), userId, filmId)
+	if err != nil {
+		errResponse(log, w, r, err, errDetails{
+			apperr.CodeNotFound: "Film is not marked",
+		})
+		return
+	}
+}
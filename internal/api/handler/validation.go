@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/validation"
+	"github.com/go-playground/validator/v10"
+	"log/slog"
+	"net/http"
+)
+
+// fieldError describes one failed validation rule
+type fieldError = domain.FieldError
+
+// newValidator returns a validator that reports fields by their json or query parameter names
+func newValidator() *validator.Validate {
+	return validation.New()
+}
+
+// fieldErrors converts validator errors into json paths without the root struct name
+func fieldErrors(err error) []fieldError {
+	return validation.FieldErrors(err)
+}
+
+func validationErrResponse(log *slog.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	writeErrResponse(log, w, r, errorResponse{
+		Status:  http.StatusBadRequest,
+		Code:    string(statusCode(http.StatusBadRequest)),
+		Title:   "validation error",
+		Detail:  "Couldn't validate input fields. Please, fix input and try again",
+		Errors:  fieldErrors(err),
+		Message: err.Error(),
+	})
+}
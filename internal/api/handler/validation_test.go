@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewValidator_Dates(t *testing.T) {
+	tests := []struct {
+		Label  string
+		input  any
+		body   string
+		errors []fieldError
+	}{
+		{
+			Label: "PartialReleaseDate",
+			input: &domain.Film{},
+			body:  `{"title":"Metropolis","description":"","released":"1927"}`,
+		},
+		{
+			Label: "MissingReleaseDate",
+			input: &domain.Film{},
+			body:  `{"title":"Metropolis","description":"","released":null}`,
+			errors: []fieldError{
+				{Field: "released", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			Label: "MissingDescription",
+			input: &domain.Film{},
+			body:  `{"title":"Metropolis","released":"1927"}`,
+			errors: []fieldError{
+				{Field: "description", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			Label: "UnknownGender",
+			input: &domain.Actor{},
+			body:  `{"name":"Brigitte Helm","gender":0,"birthday":"1906-03-17"}`,
+		},
+		{
+			Label: "MissingGenderAndBirthday",
+			input: &domain.Actor{},
+			body:  `{"name":"Brigitte Helm"}`,
+			errors: []fieldError{
+				{Field: "gender", Rule: "required", Message: "is required"},
+				{Field: "birthday", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			Label: "MissingDueDate",
+			input: &domain.Loan{},
+			body:  `{"borrower":"Nikita"}`,
+			errors: []fieldError{
+				{Field: "dueDate", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			Label: "PartialDueDate",
+			input: &domain.Loan{},
+			body:  `{"borrower":"Nikita","dueDate":"2024-05"}`,
+			errors: []fieldError{
+				{Field: "dueDate", Rule: "fulldate", Message: "must be a full date in YYYY-MM-DD format"},
+			},
+		},
+		{
+			Label: "PartialWatchDate",
+			input: &domain.UserFilm{},
+			body:  `{"watchedAt":"2024"}`,
+			errors: []fieldError{
+				{Field: "watchedAt", Rule: "fulldate", Message: "must be a full date in YYYY-MM-DD format"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			require.NoError(t, json.Unmarshal([]byte(tt.body), tt.input))
+			err := newValidator().Struct(tt.input)
+			if tt.errors == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.errors, fieldErrors(err))
+		})
+	}
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type Actor struct {
 	mock.Mock
 }
 
-// CreateActor provides a mock function with given fields: actor
-func (_m *Actor) CreateActor(actor domain.Actor) (int, error) {
-	ret := _m.Called(actor)
+// CreateActor provides a mock function with given fields: ctx, userId, actor
+func (_m *Actor) CreateActor(ctx context.Context, userId int, actor domain.Actor) (int, error) {
+	ret := _m.Called(ctx, userId, actor)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateActor")
@@ -22,17 +24,17 @@ func (_m *Actor) CreateActor(actor domain.Actor) (int, error) {
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.Actor) (int, error)); ok {
-		return rf(actor)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Actor) (int, error)); ok {
+		return rf(ctx, userId, actor)
 	}
-	if rf, ok := ret.Get(0).(func(domain.Actor) int); ok {
-		r0 = rf(actor)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Actor) int); ok {
+		r0 = rf(ctx, userId, actor)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.Actor) error); ok {
-		r1 = rf(actor)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Actor) error); ok {
+		r1 = rf(ctx, userId, actor)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,17 +42,17 @@ func (_m *Actor) CreateActor(actor domain.Actor) (int, error) {
 	return r0, r1
 }
 
-// DeleteActor provides a mock function with given fields: id
-func (_m *Actor) DeleteActor(id int) error {
-	ret := _m.Called(id)
+// DeleteActor provides a mock function with given fields: ctx, userId, id, version
+func (_m *Actor) DeleteActor(ctx context.Context, userId int, id int, version int) error {
+	ret := _m.Called(ctx, userId, id, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteActor")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) error); ok {
+		r0 = rf(ctx, userId, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -58,9 +60,67 @@ func (_m *Actor) DeleteActor(id int) error {
 	return r0
 }
 
-// ListActors provides a mock function with given fields: _a0
-func (_m *Actor) ListActors(_a0 int) ([]domain.Actor, error) {
-	ret := _m.Called(_a0)
+// ExistingActorIds provides a mock function with given fields: ctx, ids
+func (_m *Actor) ExistingActorIds(ctx context.Context, ids []int) ([]int, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExistingActorIds")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]int, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []int); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetActor provides a mock function with given fields: ctx, id
+func (_m *Actor) GetActor(ctx context.Context, id int) (domain.Actor, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActor")
+	}
+
+	var r0 domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Actor, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Actor); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Actor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListActors provides a mock function with given fields: ctx, filmId
+func (_m *Actor) ListActors(ctx context.Context, filmId int) ([]domain.Actor, error) {
+	ret := _m.Called(ctx, filmId)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListActors")
@@ -68,19 +128,19 @@ func (_m *Actor) ListActors(_a0 int) ([]domain.Actor, error) {
 
 	var r0 []domain.Actor
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) ([]domain.Actor, error)); ok {
-		return rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Actor, error)); ok {
+		return rf(ctx, filmId)
 	}
-	if rf, ok := ret.Get(0).(func(int) []domain.Actor); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Actor); ok {
+		r0 = rf(ctx, filmId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Actor)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -88,9 +148,9 @@ func (_m *Actor) ListActors(_a0 int) ([]domain.Actor, error) {
 	return r0, r1
 }
 
-// PatchActor provides a mock function with given fields: actor
-func (_m *Actor) PatchActor(actor domain.ActorInput) (domain.Actor, error) {
-	ret := _m.Called(actor)
+// PatchActor provides a mock function with given fields: ctx, userId, actor
+func (_m *Actor) PatchActor(ctx context.Context, userId int, actor domain.ActorInput) (domain.Actor, error) {
+	ret := _m.Called(ctx, userId, actor)
 
 	if len(ret) == 0 {
 		panic("no return value specified for PatchActor")
@@ -98,17 +158,17 @@ func (_m *Actor) PatchActor(actor domain.ActorInput) (domain.Actor, error) {
 
 	var r0 domain.Actor
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.ActorInput) (domain.Actor, error)); ok {
-		return rf(actor)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.ActorInput) (domain.Actor, error)); ok {
+		return rf(ctx, userId, actor)
 	}
-	if rf, ok := ret.Get(0).(func(domain.ActorInput) domain.Actor); ok {
-		r0 = rf(actor)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.ActorInput) domain.Actor); ok {
+		r0 = rf(ctx, userId, actor)
 	} else {
 		r0 = ret.Get(0).(domain.Actor)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.ActorInput) error); ok {
-		r1 = rf(actor)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.ActorInput) error); ok {
+		r1 = rf(ctx, userId, actor)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -116,17 +176,17 @@ func (_m *Actor) PatchActor(actor domain.ActorInput) (domain.Actor, error) {
 	return r0, r1
 }
 
-// UpdateActor provides a mock function with given fields: actor
-func (_m *Actor) UpdateActor(actor domain.Actor) error {
-	ret := _m.Called(actor)
+// StreamActors provides a mock function with given fields: ctx, fn
+func (_m *Actor) StreamActors(ctx context.Context, fn func(domain.Actor) error) error {
+	ret := _m.Called(ctx, fn)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateActor")
+		panic("no return value specified for StreamActors")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(domain.Actor) error); ok {
-		r0 = rf(actor)
+	if rf, ok := ret.Get(0).(func(context.Context, func(domain.Actor) error) error); ok {
+		r0 = rf(ctx, fn)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -134,6 +194,34 @@ func (_m *Actor) UpdateActor(actor domain.Actor) error {
 	return r0
 }
 
+// UpdateActor provides a mock function with given fields: ctx, userId, action, actor
+func (_m *Actor) UpdateActor(ctx context.Context, userId int, action string, actor domain.Actor) (int, error) {
+	ret := _m.Called(ctx, userId, action, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateActor")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, domain.Actor) (int, error)); ok {
+		return rf(ctx, userId, action, actor)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, domain.Actor) int); ok {
+		r0 = rf(ctx, userId, action, actor)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, domain.Actor) error); ok {
+		r1 = rf(ctx, userId, action, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewActor creates a new instance of Actor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewActor(t interface {
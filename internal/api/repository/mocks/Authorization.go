@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type Authorization struct {
 	mock.Mock
 }
 
-// GetUserById provides a mock function with given fields: id
-func (_m *Authorization) GetUserById(id int) (domain.User, error) {
-	ret := _m.Called(id)
+// GetUserById provides a mock function with given fields: ctx, id
+func (_m *Authorization) GetUserById(ctx context.Context, id int) (domain.User, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserById")
@@ -22,17 +24,17 @@ func (_m *Authorization) GetUserById(id int) (domain.User, error) {
 
 	var r0 domain.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (domain.User, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.User, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(int) domain.User); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.User); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Get(0).(domain.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,9 +42,9 @@ func (_m *Authorization) GetUserById(id int) (domain.User, error) {
 	return r0, r1
 }
 
-// GetUserByUsername provides a mock function with given fields: username
-func (_m *Authorization) GetUserByUsername(username string) (domain.User, error) {
-	ret := _m.Called(username)
+// GetUserByUsername provides a mock function with given fields: ctx, username
+func (_m *Authorization) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
+	ret := _m.Called(ctx, username)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserByUsername")
@@ -50,17 +52,17 @@ func (_m *Authorization) GetUserByUsername(username string) (domain.User, error)
 
 	var r0 domain.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (domain.User, error)); ok {
-		return rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.User, error)); ok {
+		return rf(ctx, username)
 	}
-	if rf, ok := ret.Get(0).(func(string) domain.User); ok {
-		r0 = rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.User); ok {
+		r0 = rf(ctx, username)
 	} else {
 		r0 = ret.Get(0).(domain.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(username)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -68,9 +70,27 @@ func (_m *Authorization) GetUserByUsername(username string) (domain.User, error)
 	return r0, r1
 }
 
-// SignUp provides a mock function with given fields: user
-func (_m *Authorization) SignUp(user domain.User) (int, error) {
-	ret := _m.Called(user)
+// SetUserRole provides a mock function with given fields: ctx, id, role
+func (_m *Authorization) SetUserRole(ctx context.Context, id int, role int8) error {
+	ret := _m.Called(ctx, id, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int8) error); ok {
+		r0 = rf(ctx, id, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SignUp provides a mock function with given fields: ctx, user
+func (_m *Authorization) SignUp(ctx context.Context, user domain.User) (int, error) {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SignUp")
@@ -78,17 +98,17 @@ func (_m *Authorization) SignUp(user domain.User) (int, error) {
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.User) (int, error)); ok {
-		return rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User) (int, error)); ok {
+		return rf(ctx, user)
 	}
-	if rf, ok := ret.Get(0).(func(domain.User) int); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User) int); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.User) error); ok {
-		r1 = rf(user)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.User) error); ok {
+		r1 = rf(ctx, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,65 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Export is an autogenerated mock type for the Export type
+type Export struct {
+	mock.Mock
+}
+
+// ExportActors provides a mock function with given fields: ctx, filmId, fn
+func (_m *Export) ExportActors(ctx context.Context, filmId int, fn func(domain.Actor) error) error {
+	ret := _m.Called(ctx, filmId, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportActors")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, func(domain.Actor) error) error); ok {
+		r0 = rf(ctx, filmId, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportFilms provides a mock function with given fields: ctx, filter, actorId, fn
+func (_m *Export) ExportFilms(ctx context.Context, filter domain.FilmFilter, actorId int, fn func(domain.Film) error) error {
+	ret := _m.Called(ctx, filter, actorId, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportFilms")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmFilter, int, func(domain.Film) error) error); ok {
+		r0 = rf(ctx, filter, actorId, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewExport creates a new instance of Export. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExport(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Export {
+	mock := &Export{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,119 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Merge is an autogenerated mock type for the Merge type
+type Merge struct {
+	mock.Mock
+}
+
+// ListMerges provides a mock function with given fields: ctx
+func (_m *Merge) ListMerges(ctx context.Context) ([]domain.Merge, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMerges")
+	}
+
+	var r0 []domain.Merge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Merge, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Merge); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Merge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeActors provides a mock function with given fields: ctx, userId, survivorId, duplicateIds
+func (_m *Merge) MergeActors(ctx context.Context, userId int, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	ret := _m.Called(ctx, userId, survivorId, duplicateIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeActors")
+	}
+
+	var r0 []domain.Merge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []int) ([]domain.Merge, error)); ok {
+		return rf(ctx, userId, survivorId, duplicateIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []int) []domain.Merge); ok {
+		r0 = rf(ctx, userId, survivorId, duplicateIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Merge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, []int) error); ok {
+		r1 = rf(ctx, userId, survivorId, duplicateIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeFilms provides a mock function with given fields: ctx, userId, survivorId, duplicateIds
+func (_m *Merge) MergeFilms(ctx context.Context, userId int, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	ret := _m.Called(ctx, userId, survivorId, duplicateIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeFilms")
+	}
+
+	var r0 []domain.Merge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []int) ([]domain.Merge, error)); ok {
+		return rf(ctx, userId, survivorId, duplicateIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []int) []domain.Merge); ok {
+		r0 = rf(ctx, userId, survivorId, duplicateIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Merge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, []int) error); ok {
+		r1 = rf(ctx, userId, survivorId, duplicateIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewMerge creates a new instance of Merge. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMerge(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Merge {
+	mock := &Merge{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
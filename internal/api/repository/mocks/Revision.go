@@ -0,0 +1,143 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Revision is an autogenerated mock type for the Revision type
+type Revision struct {
+	mock.Mock
+}
+
+// ActorSnapshot provides a mock function with given fields: ctx, id
+func (_m *Revision) ActorSnapshot(ctx context.Context, id int) (domain.Actor, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActorSnapshot")
+	}
+
+	var r0 domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Actor, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Actor); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Actor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FilmSnapshot provides a mock function with given fields: ctx, id
+func (_m *Revision) FilmSnapshot(ctx context.Context, id int) (domain.FilmSnapshot, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FilmSnapshot")
+	}
+
+	var r0 domain.FilmSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.FilmSnapshot, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.FilmSnapshot); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.FilmSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevision provides a mock function with given fields: ctx, id
+func (_m *Revision) GetRevision(ctx context.Context, id int) (domain.Revision, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevision")
+	}
+
+	var r0 domain.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Revision, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Revision); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Revision)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRevisions provides a mock function with given fields: ctx, kind, recordId
+func (_m *Revision) ListRevisions(ctx context.Context, kind string, recordId int) ([]domain.Revision, error) {
+	ret := _m.Called(ctx, kind, recordId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRevisions")
+	}
+
+	var r0 []domain.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]domain.Revision, error)); ok {
+		return rf(ctx, kind, recordId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []domain.Revision); ok {
+		r0 = rf(ctx, kind, recordId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, kind, recordId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRevision creates a new instance of Revision. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevision(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Revision {
+	mock := &Revision{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
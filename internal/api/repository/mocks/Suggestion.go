@@ -0,0 +1,229 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Suggestion is an autogenerated mock type for the Suggestion type
+type Suggestion struct {
+	mock.Mock
+}
+
+// ApproveActorSuggestion provides a mock function with given fields: ctx, id, reviewerId, input
+func (_m *Suggestion) ApproveActorSuggestion(ctx context.Context, id int, reviewerId int, input domain.ActorInput) (domain.Suggestion, error) {
+	ret := _m.Called(ctx, id, reviewerId, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApproveActorSuggestion")
+	}
+
+	var r0 domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, domain.ActorInput) (domain.Suggestion, error)); ok {
+		return rf(ctx, id, reviewerId, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, domain.ActorInput) domain.Suggestion); ok {
+		r0 = rf(ctx, id, reviewerId, input)
+	} else {
+		r0 = ret.Get(0).(domain.Suggestion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, domain.ActorInput) error); ok {
+		r1 = rf(ctx, id, reviewerId, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApproveFilmSuggestion provides a mock function with given fields: ctx, id, reviewerId, input, actorIds
+func (_m *Suggestion) ApproveFilmSuggestion(ctx context.Context, id int, reviewerId int, input domain.NullableFilm, actorIds []int) (domain.Suggestion, error) {
+	ret := _m.Called(ctx, id, reviewerId, input, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApproveFilmSuggestion")
+	}
+
+	var r0 domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, domain.NullableFilm, []int) (domain.Suggestion, error)); ok {
+		return rf(ctx, id, reviewerId, input, actorIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, domain.NullableFilm, []int) domain.Suggestion); ok {
+		r0 = rf(ctx, id, reviewerId, input, actorIds)
+	} else {
+		r0 = ret.Get(0).(domain.Suggestion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, domain.NullableFilm, []int) error); ok {
+		r1 = rf(ctx, id, reviewerId, input, actorIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSuggestion provides a mock function with given fields: ctx, suggestion
+func (_m *Suggestion) CreateSuggestion(ctx context.Context, suggestion domain.Suggestion) (int, error) {
+	ret := _m.Called(ctx, suggestion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSuggestion")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Suggestion) (int, error)); ok {
+		return rf(ctx, suggestion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Suggestion) int); ok {
+		r0 = rf(ctx, suggestion)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Suggestion) error); ok {
+		r1 = rf(ctx, suggestion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSuggestion provides a mock function with given fields: ctx, id
+func (_m *Suggestion) GetSuggestion(ctx context.Context, id int) (domain.Suggestion, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSuggestion")
+	}
+
+	var r0 domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Suggestion, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Suggestion); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Suggestion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSuggestions provides a mock function with given fields: ctx, status
+func (_m *Suggestion) ListSuggestions(ctx context.Context, status string) ([]domain.Suggestion, error) {
+	ret := _m.Called(ctx, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSuggestions")
+	}
+
+	var r0 []domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Suggestion, error)); ok {
+		return rf(ctx, status)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Suggestion); ok {
+		r0 = rf(ctx, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Suggestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUserSuggestions provides a mock function with given fields: ctx, userId
+func (_m *Suggestion) ListUserSuggestions(ctx context.Context, userId int) ([]domain.Suggestion, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserSuggestions")
+	}
+
+	var r0 []domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Suggestion, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Suggestion); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Suggestion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewSuggestion provides a mock function with given fields: ctx, id, reviewerId, status, reason
+func (_m *Suggestion) ReviewSuggestion(ctx context.Context, id int, reviewerId int, status string, reason *string) (domain.Suggestion, error) {
+	ret := _m.Called(ctx, id, reviewerId, status, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewSuggestion")
+	}
+
+	var r0 domain.Suggestion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, *string) (domain.Suggestion, error)); ok {
+		return rf(ctx, id, reviewerId, status, reason)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, string, *string) domain.Suggestion); ok {
+		r0 = rf(ctx, id, reviewerId, status, reason)
+	} else {
+		r0 = ret.Get(0).(domain.Suggestion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, string, *string) error); ok {
+		r1 = rf(ctx, id, reviewerId, status, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewSuggestion creates a new instance of Suggestion. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSuggestion(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Suggestion {
+	mock := &Suggestion{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,221 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Translation is an autogenerated mock type for the Translation type
+type Translation struct {
+	mock.Mock
+}
+
+// DeleteActorTranslation provides a mock function with given fields: ctx, actorId, lang
+func (_m *Translation) DeleteActorTranslation(ctx context.Context, actorId int, lang string) error {
+	ret := _m.Called(ctx, actorId, lang)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteActorTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, actorId, lang)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFilmTranslation provides a mock function with given fields: ctx, filmId, lang
+func (_m *Translation) DeleteFilmTranslation(ctx context.Context, filmId int, lang string) error {
+	ret := _m.Called(ctx, filmId, lang)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFilmTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, filmId, lang)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListActorTranslations provides a mock function with given fields: ctx, actorId
+func (_m *Translation) ListActorTranslations(ctx context.Context, actorId int) ([]domain.ActorTranslation, error) {
+	ret := _m.Called(ctx, actorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActorTranslations")
+	}
+
+	var r0 []domain.ActorTranslation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.ActorTranslation, error)); ok {
+		return rf(ctx, actorId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.ActorTranslation); ok {
+		r0 = rf(ctx, actorId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ActorTranslation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, actorId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListActorTranslationsByLang provides a mock function with given fields: ctx, lang
+func (_m *Translation) ListActorTranslationsByLang(ctx context.Context, lang string) ([]domain.ActorTranslation, error) {
+	ret := _m.Called(ctx, lang)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActorTranslationsByLang")
+	}
+
+	var r0 []domain.ActorTranslation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.ActorTranslation, error)); ok {
+		return rf(ctx, lang)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.ActorTranslation); ok {
+		r0 = rf(ctx, lang)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ActorTranslation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, lang)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFilmTranslations provides a mock function with given fields: ctx, filmId
+func (_m *Translation) ListFilmTranslations(ctx context.Context, filmId int) ([]domain.FilmTranslation, error) {
+	ret := _m.Called(ctx, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFilmTranslations")
+	}
+
+	var r0 []domain.FilmTranslation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.FilmTranslation, error)); ok {
+		return rf(ctx, filmId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.FilmTranslation); ok {
+		r0 = rf(ctx, filmId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FilmTranslation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFilmTranslationsByLang provides a mock function with given fields: ctx, lang
+func (_m *Translation) ListFilmTranslationsByLang(ctx context.Context, lang string) ([]domain.FilmTranslation, error) {
+	ret := _m.Called(ctx, lang)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFilmTranslationsByLang")
+	}
+
+	var r0 []domain.FilmTranslation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.FilmTranslation, error)); ok {
+		return rf(ctx, lang)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.FilmTranslation); ok {
+		r0 = rf(ctx, lang)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FilmTranslation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, lang)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetActorTranslation provides a mock function with given fields: ctx, translation
+func (_m *Translation) SetActorTranslation(ctx context.Context, translation domain.ActorTranslation) error {
+	ret := _m.Called(ctx, translation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetActorTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ActorTranslation) error); ok {
+		r0 = rf(ctx, translation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetFilmTranslation provides a mock function with given fields: ctx, translation
+func (_m *Translation) SetFilmTranslation(ctx context.Context, translation domain.FilmTranslation) error {
+	ret := _m.Called(ctx, translation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFilmTranslation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmTranslation) error); ok {
+		r0 = rf(ctx, translation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTranslation creates a new instance of Translation. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTranslation(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Translation {
+	mock := &Translation{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
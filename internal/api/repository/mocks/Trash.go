@@ -0,0 +1,175 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Trash is an autogenerated mock type for the Trash type
+type Trash struct {
+	mock.Mock
+}
+
+// ListDeletedActors provides a mock function with given fields: ctx
+func (_m *Trash) ListDeletedActors(ctx context.Context) ([]domain.Actor, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeletedActors")
+	}
+
+	var r0 []domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Actor, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Actor); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Actor)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDeletedFilms provides a mock function with given fields: ctx
+func (_m *Trash) ListDeletedFilms(ctx context.Context) ([]domain.Film, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDeletedFilms")
+	}
+
+	var r0 []domain.Film
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Film, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Film); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Film)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *Trash) Purge(ctx context.Context, before time.Time) (domain.PurgeResult, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 domain.PurgeResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (domain.PurgeResult, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) domain.PurgeResult); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(domain.PurgeResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreActor provides a mock function with given fields: ctx, userId, id
+func (_m *Trash) RestoreActor(ctx context.Context, userId int, id int) (domain.Actor, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreActor")
+	}
+
+	var r0 domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Actor, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Actor); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Actor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreFilm provides a mock function with given fields: ctx, userId, id
+func (_m *Trash) RestoreFilm(ctx context.Context, userId int, id int) (domain.Film, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreFilm")
+	}
+
+	var r0 domain.Film
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Film, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Film); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Film)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTrash creates a new instance of Trash. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTrash(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Trash {
+	mock := &Trash{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,113 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserFilm is an autogenerated mock type for the UserFilm type
+type UserFilm struct {
+	mock.Mock
+}
+
+// DeleteUserFilm provides a mock function with given fields: ctx, userId, filmId
+func (_m *UserFilm) DeleteUserFilm(ctx context.Context, userId int, filmId int) error {
+	ret := _m.Called(ctx, userId, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserFilm")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, filmId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ListUserFilms provides a mock function with given fields: ctx, userId
+func (_m *UserFilm) ListUserFilms(ctx context.Context, userId int) ([]domain.UserFilm, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUserFilms")
+	}
+
+	var r0 []domain.UserFilm
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.UserFilm, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.UserFilm); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.UserFilm)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserFilm provides a mock function with given fields: ctx, mark
+func (_m *UserFilm) SetUserFilm(ctx context.Context, mark domain.UserFilm) error {
+	ret := _m.Called(ctx, mark)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserFilm")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.UserFilm) error); ok {
+		r0 = rf(ctx, mark)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserFilms provides a mock function with given fields: ctx, marks
+func (_m *UserFilm) SetUserFilms(ctx context.Context, marks []domain.UserFilm) error {
+	ret := _m.Called(ctx, marks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserFilms")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.UserFilm) error); ok {
+		r0 = rf(ctx, marks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserFilm creates a new instance of UserFilm. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserFilm(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserFilm {
+	mock := &UserFilm{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -1,13 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"github.com/jmoiron/sqlx"
 	"log/slog"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type ActorPostgres struct {
@@ -19,20 +21,61 @@ func NewActorPostgres(db *sqlx.DB, log *slog.Logger) *ActorPostgres {
 	return &ActorPostgres{db: db, log: log}
 }
 
-func (r ActorPostgres) CreateActor(actor domain.Actor) (int, error) {
+// CreateActor adds the actor and records its revision on behalf of userId
+func (r ActorPostgres) CreateActor(ctx context.Context, userId int, actor domain.Actor) (int, error) {
+	const method = "Actors.Repository.CreateActor"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return -1, ErrInternal
+	}
+	defer tx.Rollback()
+
 	var id int
 	query := fmt.Sprintf(`INSERT INTO %s(name, birthday, gender) VALUES($1,$2,$3) RETURNING id`, actorsTable)
-	row := r.db.QueryRowx(query, actor.Name, actor.Birthday.String(), actor.Gender)
-	if err := row.Scan(&id); err != nil {
-		return -1, err
+	row := tx.QueryRowxContext(ctx, query, actor.Name, actor.Birthday, actor.Gender)
+	if err = row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		return -1, mapConstraintError(err)
+	}
+	if err = insertRevision(ctx, tx, domain.KindActor, domain.ActionCreate, userId, id, nil); err != nil {
+		return -1, revisionError(log, err)
 	}
 
-	return id, nil
+	return id, tx.Commit()
 }
 
-func (r ActorPostgres) DeleteActor(id int) error {
-	query := fmt.Sprintf(`DELETE FROM %s where id=$1`, actorsTable)
-	res, err := r.db.Exec(query, id)
+func (r ActorPostgres) GetActor(ctx context.Context, id int) (domain.Actor, error) {
+	var actor domain.Actor
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1 AND deleted_at IS NULL`, actorsTable)
+	err := r.db.GetContext(ctx, &actor, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return actor, ErrNoRows
+	}
+	return actor, err
+}
+
+// DeleteActor moves the actor to the trash. Cast links stay, so a restored actor gets them back
+func (r ActorPostgres) DeleteActor(ctx context.Context, userId, id, version int) error {
+	const method = "Actors.Repository.DeleteActor"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnapshot(ctx, tx, domain.KindActor, id)
+	if err != nil {
+		return revisionError(log, err)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at=now(), deleted_by=$3, version=version+1 WHERE %s`,
+		actorsTable, versionCond(1))
+	res, err := tx.ExecContext(ctx, query, id, version, userId)
 	if err != nil {
 		return ErrInternal
 	}
@@ -41,31 +84,83 @@ func (r ActorPostgres) DeleteActor(id int) error {
 		return ErrInternal
 	}
 	if count == 0 {
-		return ErrNoRows
+		return missingOrStale(ctx, tx, actorsTable, id)
 	}
-	return nil
+	if err = insertRevision(ctx, tx, domain.KindActor, domain.ActionDelete, userId, id, before); err != nil {
+		return revisionError(log, err)
+	}
+
+	return tx.Commit()
 }
 
-func (r ActorPostgres) UpdateActor(actor domain.Actor) error {
-	query := fmt.Sprintf(`UPDATE %s SET name=$1, gender=$2, birthday=$3 WHERE id=$4`, actorsTable)
-	result, err := r.db.Exec(query, actor.Name, actor.Gender, actor.Birthday.String(), actor.Id)
-	count, err := result.RowsAffected()
+// UpdateActor replaces the actor if it still has actor.Version and returns the new version.
+// The revision is recorded with action, an update or a revert
+func (r ActorPostgres) UpdateActor(ctx context.Context, userId int, action string, actor domain.Actor) (int, error) {
+	const method = "Actors.Repository.UpdateActor"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return ErrInternal
+		log.Error(err.Error())
+		return 0, ErrInternal
 	}
-	if count == 0 {
-		return ErrNoRows
+	defer tx.Rollback()
+
+	before, err := lockSnapshot(ctx, tx, domain.KindActor, actor.Id)
+	if err != nil {
+		return 0, revisionError(log, err)
+	}
+	var version int
+	query := fmt.Sprintf(`UPDATE %s SET name=$1, gender=$2, birthday=$3, version=version+1 WHERE %s
+		RETURNING version`, actorsTable, versionCond(4))
+	err = tx.GetContext(ctx, &version, query, actor.Name, actor.Gender, actor.Birthday, actor.Id, actor.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrStale(ctx, tx, actorsTable, actor.Id)
+	}
+	if err != nil {
+		log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+	if err = insertRevision(ctx, tx, domain.KindActor, action, userId, actor.Id, before); err != nil {
+		return 0, revisionError(log, err)
 	}
 
-	return nil
+	return version, tx.Commit()
 }
 
-func (r ActorPostgres) PatchActor(input domain.ActorInput) (domain.Actor, error) {
+// PatchActor sets the fields given in input and records the revision on behalf of userId
+func (r ActorPostgres) PatchActor(ctx context.Context, userId int, input domain.ActorInput) (domain.Actor, error) {
+	const method = "Actors.Repository.PatchActor"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return domain.Actor{}, ErrInternal
+	}
+	defer tx.Rollback()
+
+	actor, err := r.patchActor(ctx, tx, userId, input)
+	if err != nil {
+		return actor, err
+	}
+	return actor, tx.Commit()
+}
+
+// patchActor is PatchActor within the transaction tx
+func (r ActorPostgres) patchActor(ctx context.Context, tx *sqlx.Tx, userId int, input domain.ActorInput) (domain.Actor, error) {
+	const method = "Actors.Repository.patchActor"
+	log := r.log.With(slog.String("method", method))
+
 	var actor domain.Actor
+	before, err := lockSnapshot(ctx, tx, domain.KindActor, input.Id)
+	if err != nil {
+		return actor, revisionError(log, err)
+	}
 
 	queryBegin := fmt.Sprintf(`UPDATE %s SET `, actorsTable)
-	setVals := make([]string, 0, 4)
-	params := make([]interface{}, 0, 4)
+	setVals := []string{"version=version+1"}
+	params := make([]interface{}, 0, 5)
 
 	argId := 1
 	if input.Name != nil {
@@ -80,27 +175,65 @@ func (r ActorPostgres) PatchActor(input domain.ActorInput) (domain.Actor, error)
 	}
 	if input.Birthday != nil {
 		setVals = append(setVals, "birthday=$"+strconv.Itoa(argId))
-		params = append(params, time.Time(*input.Birthday))
+		params = append(params, *input.Birthday)
 		argId++
 	}
 
 	setString := strings.Join(setVals, ",")
-	params = append(params, input.Id)
-	query := queryBegin + setString + " WHERE id=$" + strconv.Itoa(argId) +
-		" RETURNING *"
-	rows := r.db.QueryRowx(query, params...)
-	err := rows.StructScan(&actor)
+	params = append(params, input.Id, input.Version)
+	query := queryBegin + setString + " WHERE " + versionCond(argId) + " RETURNING *"
+	rows := tx.QueryRowxContext(ctx, query, params...)
+	if err = rows.StructScan(&actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return actor, missingOrStale(ctx, tx, actorsTable, input.Id)
+		}
+		log.Error(err.Error())
+		return actor, mapConstraintError(err)
+	}
+	if err = insertRevision(ctx, tx, domain.KindActor, domain.ActionPatch, userId, input.Id, before); err != nil {
+		return actor, revisionError(log, err)
+	}
 
-	return actor, err
+	return actor, nil
 }
 
-func (r ActorPostgres) ListActors(filmId int) (actors []domain.Actor, err error) {
+func (r ActorPostgres) ListActors(ctx context.Context, filmId int) (actors []domain.Actor, err error) {
 	query := fmt.Sprintf(`SELECT a.* FROM %s a`, actorsTable)
 	if filmId == -1 {
-		err = r.db.Select(&actors, query)
+		err = r.db.SelectContext(ctx, &actors, query+` WHERE a.deleted_at IS NULL`)
 	} else {
-		query += fmt.Sprintf(` INNER JOIN %s fa ON a.id = fa.actor_id WHERE fa.film_id = $1`, filmsActorsTable)
-		err = r.db.Select(&actors, query, filmId)
+		query += fmt.Sprintf(` INNER JOIN %s fa ON a.id = fa.actor_id WHERE fa.film_id = $1 AND a.deleted_at IS NULL`,
+			filmsActorsTable)
+		err = r.db.SelectContext(ctx, &actors, query, filmId)
 	}
 	return
 }
+
+// StreamActors passes the live actors to fn one by one, each with its films from the best rated.
+// The query is cancelled with ctx
+func (r ActorPostgres) StreamActors(ctx context.Context, fn func(domain.Actor) error) error {
+	const method = "Actors.Repository.StreamActors"
+	log := r.log.With(slog.String("method", method))
+
+	return queryActorsWithFilms(ctx, r.db, log, -1, "f.rating DESC NULLS LAST, f.id", fn)
+}
+
+// ExistingActorIds returns those of the given ids that belong to existing actors outside the trash
+func (r ActorPostgres) ExistingActorIds(ctx context.Context, ids []int) ([]int, error) {
+	existing := make([]int, 0, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	params := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		params[i] = id
+	}
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id IN (%s) AND deleted_at IS NULL`, actorsTable,
+		strings.Join(placeholders, ","))
+	err := r.db.SelectContext(ctx, &existing, query, params...)
+
+	return existing, err
+}
@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -16,6 +18,10 @@ import (
 	"time"
 )
 
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func prepareActorTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *ActorPostgres) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -39,20 +45,34 @@ func TestActorPostgres_CreateActor(t *testing.T) {
 		actor := domain.Actor{
 			Id:       1,
 			Name:     gofakeit.Name(),
-			Birthday: domain.CustomDate(gofakeit.Date()),
-			Gender:   1,
+			Birthday: domain.NewDate(gofakeit.Date()),
+			Gender:   ptr[int](1),
 		}
 
 		rows := sqlmock.NewRows([]string{"id"}).
 			AddRow(actor.Id)
+		mock.ExpectBegin()
 		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, actorsTable)).
 			WithArgs(actor.Name, actor.Birthday.String(), actor.Gender).WillReturnRows(rows)
+		expectRevision(mock, domain.KindActor, domain.ActionCreate, 2, actor.Id)
+		mock.ExpectCommit()
 
-		got, err := r.CreateActor(actor)
+		got, err := r.CreateActor(context.Background(), 2, actor)
 		assert.NoError(t, err)
 		assert.Equal(t, actor.Id, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("RevisionFailed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, actorsTable)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, revisionsTable)).WillReturnError(fmt.Errorf("connection reset"))
+		mock.ExpectRollback()
+
+		_, err := r.CreateActor(context.Background(), 2, domain.Actor{Name: gofakeit.Name()})
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestActorPostgres_PatchActor(t *testing.T) {
@@ -63,58 +83,126 @@ func TestActorPostgres_PatchActor(t *testing.T) {
 		actor := domain.Actor{
 			Id:       1,
 			Name:     gofakeit.Name(),
-			Gender:   1,
-			Birthday: domain.CustomDate(time.Now()),
+			Gender:   ptr(1),
+			Birthday: domain.NewDate(time.Now()),
 		}
 		actorInput := domain.ActorInput{
 			Id:       1,
 			Name:     &actor.Name,
-			Gender:   &actor.Gender,
+			Gender:   actor.Gender,
 			Birthday: &actor.Birthday,
 		}
 		rows := sqlmock.NewRows([]string{"id", "name", "birthday", "gender"}).
-			AddRow(actor.Id, actor.Name, time.Time(actor.Birthday), actor.Gender)
+			AddRow(actor.Id, actor.Name, actor.Birthday.Time, actor.Gender)
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
 		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, actorsTable)).
-			WithArgs(actor.Name, actor.Gender, time.Time(actor.Birthday), actor.Id).WillReturnRows(rows)
-		got, err := r.PatchActor(actorInput)
+			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id, 0).WillReturnRows(rows)
+		expectRevision(mock, domain.KindActor, domain.ActionPatch, 2, actor.Id)
+		mock.ExpectCommit()
+		got, err := r.PatchActor(context.Background(), 2, actorInput)
 		assert.NoError(t, err)
 		assert.Equal(t, actor, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		name := gofakeit.Name()
+		actorInput := domain.ActorInput{Id: 1, Name: &name}
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 FOR UPDATE`, actorsTable)).
+			WithArgs(actorInput.Id).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+		_, err := r.PatchActor(context.Background(), 2, actorInput)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("StaleVersion", func(t *testing.T) {
+		name := gofakeit.Name()
+		actorInput := domain.ActorInput{Id: 1, Name: &name, Version: 3}
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actorInput.Id)
+		mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(`UPDATE %s SET version=version+1,name=$1 WHERE id=$2 AND deleted_at IS NULL AND ($3 = 0 OR version=$3)`,
+			actorsTable))).WithArgs(name, actorInput.Id, 3).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(actorInput.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		_, err := r.PatchActor(context.Background(), 2, actorInput)
+		assert.ErrorIs(t, err, ErrVersion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestActorPostgres_UpdateActor(t *testing.T) {
 	mock, dbx, r := prepareActorTest(t)
 	defer dbx.Close()
 
+	actor := domain.Actor{
+		Id:       1,
+		Name:     gofakeit.Name(),
+		Gender:   ptr(1),
+		Birthday: domain.NewDate(time.Now()),
+		Version:  2,
+	}
+
 	t.Run("RightCredentials", func(t *testing.T) {
-		actor := domain.Actor{
-			Id:       1,
-			Name:     gofakeit.Name(),
-			Gender:   1,
-			Birthday: domain.CustomDate(time.Now()),
-		}
-		mock.ExpectExec(fmt.Sprintf(`UPDATE %s`, actorsTable)).
-			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id).
-			WillReturnResult(sqlmock.NewResult(1, 1))
-		err := r.UpdateActor(actor)
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, actorsTable)).
+			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id, actor.Version).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+		expectRevision(mock, domain.KindActor, domain.ActionRevert, 2, actor.Id)
+		mock.ExpectCommit()
+		version, err := r.UpdateActor(context.Background(), 2, domain.ActionRevert, actor)
 		assert.NoError(t, err)
+		assert.Equal(t, 3, version)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 	t.Run("NoSuchId", func(t *testing.T) {
-		actor := domain.Actor{
-			Id:       1,
-			Name:     gofakeit.Name(),
-			Gender:   1,
-			Birthday: domain.CustomDate(time.Now()),
-		}
-		mock.ExpectExec(fmt.Sprintf(`UPDATE %s`, actorsTable)).
-			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id).
-			WillReturnResult(sqlmock.NewResult(1, 0))
-		err := r.UpdateActor(actor)
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 FOR UPDATE`, actorsTable)).
+			WithArgs(actor.Id).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+		_, err := r.UpdateActor(context.Background(), 2, domain.ActionUpdate, actor)
 		assert.ErrorIs(t, err, ErrNoRows)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("Trashed", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, actorsTable)).
+			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id, actor.Version).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(actor.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+		_, err := r.UpdateActor(context.Background(), 2, domain.ActionUpdate, actor)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("StaleVersion", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, actorsTable)).
+			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id, actor.Version).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(actor.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		_, err := r.UpdateActor(context.Background(), 2, domain.ActionUpdate, actor)
+		assert.ErrorIs(t, err, ErrVersion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("QueryError", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, actorsTable)).
+			WithArgs(actor.Name, actor.Gender, actor.Birthday.String(), actor.Id, actor.Version).
+			WillReturnError(fmt.Errorf("connection reset"))
+		mock.ExpectRollback()
+		_, err := r.UpdateActor(context.Background(), 2, domain.ActionUpdate, actor)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestActorPostgres_ListActors(t *testing.T) {
@@ -126,15 +214,15 @@ func TestActorPostgres_ListActors(t *testing.T) {
 			{
 				Id:       1,
 				Name:     gofakeit.Name(),
-				Gender:   1,
-				Birthday: domain.CustomDate(time.Now()),
+				Gender:   ptr(1),
+				Birthday: domain.NewDate(time.Now()),
 			},
 		}
 		rows := sqlmock.NewRows([]string{"id", "name", "birthday", "gender"}).
-			AddRows([][]driver.Value{{actors[0].Id, actors[0].Name, time.Time(actors[0].Birthday), actors[0].Gender}}...)
+			AddRows([][]driver.Value{{actors[0].Id, actors[0].Name, actors[0].Birthday.Time, actors[0].Gender}}...)
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT a.* FROM %s a`), actorsTable)).
 			WithoutArgs().WillReturnRows(rows)
-		got, err := r.ListActors(-1)
+		got, err := r.ListActors(context.Background(), -1)
 		assert.NoError(t, err)
 		assert.Equal(t, got, actors)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -146,15 +234,15 @@ func TestActorPostgres_ListActors(t *testing.T) {
 			{
 				Id:       1,
 				Name:     gofakeit.Name(),
-				Gender:   1,
-				Birthday: domain.CustomDate(time.Now()),
+				Gender:   ptr(1),
+				Birthday: domain.NewDate(time.Now()),
 			},
 		}
 		rows := sqlmock.NewRows([]string{"id", "name", "birthday", "gender"}).
-			AddRows([][]driver.Value{{actors[0].Id, actors[0].Name, time.Time(actors[0].Birthday), actors[0].Gender}}...)
+			AddRows([][]driver.Value{{actors[0].Id, actors[0].Name, actors[0].Birthday.Time, actors[0].Gender}}...)
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT a.* FROM %s a`), actorsTable)).
 			WithArgs(filmId).WillReturnRows(rows)
-		got, err := r.ListActors(filmId)
+		got, err := r.ListActors(context.Background(), filmId)
 		assert.NoError(t, err)
 		assert.Equal(t, got, actors)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -169,13 +257,17 @@ func TestActorPostgres_DeleteActor(t *testing.T) {
 		actor := domain.Actor{
 			Id:       1,
 			Name:     gofakeit.Name(),
-			Gender:   1,
-			Birthday: domain.CustomDate(time.Now()),
+			Gender:   ptr(1),
+			Birthday: domain.NewDate(time.Now()),
 		}
-		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s`, actorsTable)).
-			WithArgs(actor.Id).
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(`UPDATE %s SET deleted_at=now(), deleted_by=$3`, actorsTable))).
+			WithArgs(actor.Id, 0, 2).
 			WillReturnResult(sqlmock.NewResult(1, 1))
-		err := r.DeleteActor(actor.Id)
+		expectRevision(mock, domain.KindActor, domain.ActionDelete, 2, actor.Id)
+		mock.ExpectCommit()
+		err := r.DeleteActor(context.Background(), 2, actor.Id, 0)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -183,14 +275,77 @@ func TestActorPostgres_DeleteActor(t *testing.T) {
 		actor := domain.Actor{
 			Id:       1,
 			Name:     gofakeit.Name(),
-			Gender:   1,
-			Birthday: domain.CustomDate(time.Now()),
+			Gender:   ptr(1),
+			Birthday: domain.NewDate(time.Now()),
 		}
-		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s`, actorsTable)).
-			WithArgs(actor.Id).
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, actor.Id)
+		mock.ExpectExec(fmt.Sprintf(`UPDATE %s SET deleted_at`, actorsTable)).
+			WithArgs(actor.Id, 0, 2).
 			WillReturnResult(sqlmock.NewResult(1, 0))
-		err := r.DeleteActor(actor.Id)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(actor.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+		err := r.DeleteActor(context.Background(), 2, actor.Id, 0)
 		assert.ErrorIs(t, err, ErrNoRows)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("StaleVersion", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectSnapshot(mock, actorsTable, 1)
+		mock.ExpectExec(fmt.Sprintf(`UPDATE %s SET deleted_at`, actorsTable)).
+			WithArgs(1, 4, 2).
+			WillReturnResult(sqlmock.NewResult(1, 0))
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		err := r.DeleteActor(context.Background(), 2, 1, 4)
+		assert.ErrorIs(t, err, ErrVersion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestActorPostgres_GetActor(t *testing.T) {
+	mock, dbx, r := prepareActorTest(t)
+	defer dbx.Close()
+
+	t.Run("Found", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "name", "birthday", "gender", "version"}).
+			AddRow(1, "Keanu Reeves", "1964-09-02", 1, 5)
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s WHERE id=$1`), actorsTable)).
+			WithArgs(1).WillReturnRows(rows)
+		got, err := r.GetActor(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, got.Version)
+		assert.Equal(t, "1964-09-02", got.Birthday.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s`, actorsTable)).
+			WithArgs(2).WillReturnError(sql.ErrNoRows)
+		_, err := r.GetActor(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestActorPostgres_ExistingActorIds(t *testing.T) {
+	mock, dbx, r := prepareActorTest(t)
+	defer dbx.Close()
+
+	t.Run("SomeMissing", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id IN \(\$1,\$2,\$3\)`, actorsTable)).
+			WithArgs(1, 2, 3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(3))
+		got, err := r.ExistingActorIds(context.Background(), []int{1, 2, 3})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got, err := r.ExistingActorIds(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewAuditPostgres(db *sqlx.DB, log *slog.Logger) *AuditPostgres {
+	return &AuditPostgres{db: db, log: log}
+}
+
+func (r AuditPostgres) CreateEvent(ctx context.Context, event domain.AuditEvent) error {
+	query := fmt.Sprintf(`INSERT INTO %s(event, user_id, username, ip, user_agent, details)
+		VALUES($1,$2,$3,$4,$5,NULLIF($6::jsonb, 'null'::jsonb))`, auditEventsTable)
+	_, err := r.db.ExecContext(ctx, query, event.Event, event.UserId, event.Username, event.IP, event.UserAgent, event.Details)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	return nil
+}
+
+func auditFilterConditions(filter domain.AuditFilter) ([]string, []interface{}) {
+	conds := make([]string, 0)
+	params := make([]interface{}, 0)
+
+	add := func(cond string, param interface{}) {
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(params)+1)))
+		params = append(params, param)
+	}
+	if filter.Event != nil {
+		add("event = ?", *filter.Event)
+	}
+	if filter.UserId != nil {
+		add("user_id = ?", *filter.UserId)
+	}
+	if filter.Username != nil {
+		add("username = ?", *filter.Username)
+	}
+	if filter.IP != nil {
+		add("ip = ?", *filter.IP)
+	}
+	if filter.From != nil {
+		add("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < ?", *filter.To)
+	}
+
+	return conds, params
+}
+
+// ListEvents returns events matching the filter, newest first
+func (r AuditPostgres) ListEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	events := make([]domain.AuditEvent, 0)
+	conds, params := auditFilterConditions(filter)
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+	query := fmt.Sprintf(`SELECT * FROM %s %s ORDER BY id DESC LIMIT $%d OFFSET $%d`,
+		auditEventsTable, where, len(params)+1, len(params)+2)
+	err := r.db.SelectContext(ctx, &events, query, append(params, filter.Limit, filter.Offset)...)
+
+	return events, err
+}
+
+// PurgeEvents deletes events older than the given time and returns how many were removed
+func (r AuditPostgres) PurgeEvents(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE created_at < $1`, auditEventsTable)
+	res, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		r.log.Error(err.Error())
+		return 0, ErrInternal
+	}
+	return res.RowsAffected()
+}
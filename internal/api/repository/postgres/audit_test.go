@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareAuditTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *AuditPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewAuditPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestAuditPostgres_ListEvents(t *testing.T) {
+	mock, dbx, r := prepareAuditTest(t)
+	defer dbx.Close()
+
+	t.Run("Filtered", func(t *testing.T) {
+		event, userId := domain.AuditAdminDenied, 3
+		from, to := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"id", "event", "user_id", "ip", "user_agent", "created_at"}).
+			AddRow(9, event, userId, "10.0.0.7", "curl/8.0", from.Add(time.Hour))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE event = \$1 AND user_id = \$2 AND created_at >= \$3
+			AND created_at < \$4 ORDER BY id DESC LIMIT \$5 OFFSET \$6`, auditEventsTable)).
+			WithArgs(event, userId, from, to, 50, 100).WillReturnRows(rows)
+
+		got, err := r.ListEvents(context.Background(), domain.AuditFilter{Event: &event, UserId: &userId, From: &from, To: &to,
+			Limit: 50, Offset: 100})
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, "10.0.0.7", got[0].IP)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("All", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s ORDER BY id DESC LIMIT \$1 OFFSET \$2`, auditEventsTable)).
+			WithArgs(100, 0).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		got, err := r.ListEvents(context.Background(), domain.AuditFilter{Limit: 100})
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditPostgres_PurgeEvents(t *testing.T) {
+	mock, dbx, r := prepareAuditTest(t)
+	defer dbx.Close()
+
+	before := time.Now()
+	mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s WHERE created_at < \$1`, auditEventsTable)).
+		WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 12))
+	count, err := r.PurgeEvents(context.Background(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
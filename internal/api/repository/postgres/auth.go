@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
@@ -10,7 +11,8 @@ import (
 )
 
 const (
-	uniqueErrCode = "23505"
+	uniqueErrCode  = "23505"
+	foreignErrCode = "23503"
 )
 
 type AuthPostgres struct {
@@ -22,9 +24,9 @@ func NewAuthPostgres(db *sqlx.DB, log *slog.Logger) *AuthPostgres {
 	return &AuthPostgres{db: db, log: log}
 }
 
-func (r *AuthPostgres) SignUp(user domain.User) (int, error) {
+func (r *AuthPostgres) SignUp(ctx context.Context, user domain.User) (int, error) {
 	query := fmt.Sprintf(`INSERT INTO %s(username,password_hash) VALUES($1,$2) RETURNING id`, usersTable)
-	row := r.db.QueryRowx(query, user.Username, user.PasswordHash)
+	row := r.db.QueryRowxContext(ctx, query, user.Username, user.PasswordHash)
 	var id int
 	if err := row.Scan(&id); err != nil {
 		var pgErr pgx.PgError
@@ -37,10 +39,10 @@ func (r *AuthPostgres) SignUp(user domain.User) (int, error) {
 	return id, nil
 }
 
-func (r *AuthPostgres) GetUserByUsername(username string) (domain.User, error) {
+func (r *AuthPostgres) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
 	var user domain.User
 	query := fmt.Sprintf(`SELECT * FROM %s WHERE username=$1`, usersTable)
-	err := r.db.Get(&user, query, username)
+	err := r.db.GetContext(ctx, &user, query, username)
 	if err != nil {
 		var pgErr pgx.PgError
 		if errors.As(err, &pgErr) {
@@ -51,10 +53,10 @@ func (r *AuthPostgres) GetUserByUsername(username string) (domain.User, error) {
 	return user, nil
 }
 
-func (r *AuthPostgres) GetUserById(id int) (domain.User, error) {
+func (r *AuthPostgres) GetUserById(ctx context.Context, id int) (domain.User, error) {
 	var user domain.User
 	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, usersTable)
-	err := r.db.Get(&user, query, id)
+	err := r.db.GetContext(ctx, &user, query, id)
 	if err != nil {
 		var pgErr pgx.PgError
 		if errors.As(err, &pgErr) {
@@ -65,3 +67,20 @@ func (r *AuthPostgres) GetUserById(id int) (domain.User, error) {
 	}
 	return user, nil
 }
+
+func (r *AuthPostgres) SetUserRole(ctx context.Context, id int, role int8) error {
+	query := fmt.Sprintf(`UPDATE %s SET role=$2 WHERE id=$1`, usersTable)
+	res, err := r.db.ExecContext(ctx, query, id, role)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+	return nil
+}
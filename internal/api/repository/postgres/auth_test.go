@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
@@ -72,7 +73,7 @@ func TestAuthPostgres_SignUp(t *testing.T) {
 
 	t.Run(tests[0].name, func(t *testing.T) {
 		tests[0].mockBehaviour(tests[0].input, tests[0].want)
-		got, err := r.SignUp(tests[0].input)
+		got, err := r.SignUp(context.Background(), tests[0].input)
 		assert.NoError(t, err)
 		assert.Equal(t, tests[0].want, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -80,11 +81,11 @@ func TestAuthPostgres_SignUp(t *testing.T) {
 
 	t.Run(tests[1].name, func(t *testing.T) {
 		tests[1].mockBehaviour(tests[1].input, tests[1].want)
-		got, err := r.SignUp(tests[1].input)
+		got, err := r.SignUp(context.Background(), tests[1].input)
 		assert.NoError(t, err)
 		assert.Equal(t, tests[1].want, got)
 
-		got, err = r.SignUp(tests[1].input)
+		got, err = r.SignUp(context.Background(), tests[1].input)
 
 		assert.ErrorIs(t, err, ErrUnique)
 		assert.Equal(t, -1, got)
@@ -120,7 +121,7 @@ func TestAuthPostgres_GetUserByUsername(t *testing.T) {
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), usersTable)).
 			WithArgs(user.Username).WillReturnRows(rows)
 
-		got, err := r.GetUserByUsername(user.Username)
+		got, err := r.GetUserByUsername(context.Background(), user.Username)
 		assert.NoError(t, err)
 		assert.Equal(t, user, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -130,7 +131,7 @@ func TestAuthPostgres_GetUserByUsername(t *testing.T) {
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), usersTable)).
 			WithArgs(user.Username).WillReturnError(errors.New("sql: no rows in result set"))
 
-		_, err := r.GetUserByUsername(user.Username)
+		_, err := r.GetUserByUsername(context.Background(), user.Username)
 		assert.ErrorIs(t, ErrNoRows, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -166,7 +167,7 @@ func TestAuthPostgres_GetUserById(t *testing.T) {
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), usersTable)).
 			WithArgs(user.Id).WillReturnRows(rows)
 
-		got, err := r.GetUserById(user.Id)
+		got, err := r.GetUserById(context.Background(), user.Id)
 		assert.NoError(t, err)
 		assert.Equal(t, user, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -177,7 +178,7 @@ func TestAuthPostgres_GetUserById(t *testing.T) {
 		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), usersTable)).
 			WithArgs(user.Id).WillReturnError(errors.New("sql: no rows in result set"))
 
-		_, err := r.GetUserById(user.Id)
+		_, err := r.GetUserById(context.Background(), user.Id)
 		assert.ErrorIs(t, ErrNoRows, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+type CollectionPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewCollectionPostgres(db *sqlx.DB, log *slog.Logger) *CollectionPostgres {
+	return &CollectionPostgres{db: db, log: log}
+}
+
+func (r CollectionPostgres) updateItemsList(ctx context.Context, tx *sqlx.Tx, collectionId int, items []domain.CollectionItem) error {
+	const method = "Collections.Repository.updateItemsList"
+	log := r.log.With(slog.String("method", method))
+
+	clearOldItems := fmt.Sprintf(`DELETE FROM %s WHERE collection_id=$1`, collectionsItemsTable)
+	_, err := tx.ExecContext(ctx, clearOldItems, collectionId)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+
+	addItemStmt, err := tx.PreparexContext(ctx, fmt.Sprintf(`INSERT INTO %s(collection_id, film_id, position, note)
+		VALUES($1,$2,$3,$4)`, collectionsItemsTable))
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+
+	for i, item := range items {
+		_, err = addItemStmt.ExecContext(ctx, collectionId, item.FilmId, i+1, item.Note)
+		if err != nil {
+			log.Error(err.Error())
+			var pgErr pgx.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignErrCode {
+				return ErrForeign
+			}
+			return ErrUnique
+		}
+	}
+
+	return nil
+}
+
+func (r CollectionPostgres) listItems(ctx context.Context, collectionId int) ([]domain.CollectionItem, error) {
+	items := make([]domain.CollectionItem, 0)
+	query := fmt.Sprintf(`SELECT ci.film_id, ci.position, ci.note, f.title FROM %s ci
+		INNER JOIN %s f ON f.id = ci.film_id WHERE ci.collection_id = $1 AND f.deleted_at IS NULL
+		ORDER BY ci.position`,
+		collectionsItemsTable, filmsTable)
+	err := r.db.SelectContext(ctx, &items, query, collectionId)
+
+	return items, err
+}
+
+func (r CollectionPostgres) CreateCollection(ctx context.Context, collection domain.Collection) (int, error) {
+	const method = "Collections.Repository.CreateCollection"
+	log := r.log.With(slog.String("method", method))
+
+	var id int
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return 0, ErrInternal
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s(owner_id, title, description, is_public, share_token, cloned_from)
+		VALUES($1,$2,$3,$4,$5,$6) RETURNING id`, collectionsTable)
+	row := tx.QueryRowxContext(ctx, query, collection.OwnerId, collection.Title, collection.Description,
+		collection.IsPublic, collection.ShareToken, collection.ClonedFrom)
+	if err = row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return 0, ErrInternal
+	}
+
+	err = r.updateItemsList(ctx, tx, id, collection.Items)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r CollectionPostgres) getCollection(ctx context.Context, where string, arg interface{}) (domain.Collection, error) {
+	var collection domain.Collection
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE %s=$1`, collectionsTable, where)
+	err := r.db.GetContext(ctx, &collection, query, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return collection, ErrNoRows
+		}
+		return collection, ErrInternal
+	}
+
+	collection.Items, err = r.listItems(ctx, collection.Id)
+	if err != nil {
+		return collection, ErrInternal
+	}
+
+	return collection, nil
+}
+
+func (r CollectionPostgres) GetCollection(ctx context.Context, id int) (domain.Collection, error) {
+	return r.getCollection(ctx, "id", id)
+}
+
+func (r CollectionPostgres) GetCollectionByToken(ctx context.Context, token string) (domain.Collection, error) {
+	return r.getCollection(ctx, "share_token", token)
+}
+
+func (r CollectionPostgres) ListCollections(ctx context.Context, ownerId int) ([]domain.Collection, error) {
+	var collections []domain.Collection
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE owner_id=$1 ORDER BY id`, collectionsTable)
+	err := r.db.SelectContext(ctx, &collections, query, ownerId)
+
+	return collections, err
+}
+
+func (r CollectionPostgres) ListFollowedCollections(ctx context.Context, userId int) ([]domain.Collection, error) {
+	var collections []domain.Collection
+	query := fmt.Sprintf(`SELECT c.* FROM %s c INNER JOIN %s cf ON c.id = cf.collection_id
+		WHERE cf.user_id=$1 AND c.is_public ORDER BY c.id`, collectionsTable, collectionsFollowersTable)
+	err := r.db.SelectContext(ctx, &collections, query, userId)
+
+	return collections, err
+}
+
+func (r CollectionPostgres) UpdateCollection(ctx context.Context, collection domain.Collection) error {
+	const method = "Collections.Repository.UpdateCollection"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET title=$1, description=$2, is_public=$3 WHERE id=$4`, collectionsTable)
+	result, err := tx.ExecContext(ctx, query, collection.Title, collection.Description, collection.IsPublic, collection.Id)
+	if err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil || count == 0 {
+		tx.Rollback()
+		return ErrNoRows
+	}
+
+	err = r.updateItemsList(ctx, tx, collection.Id, collection.Items)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r CollectionPostgres) PatchCollection(ctx context.Context, input domain.CollectionInput) (domain.Collection, error) {
+	const method = "Collections.Repository.PatchCollection"
+	log := r.log.With(slog.String("method", method))
+
+	var collection domain.Collection
+
+	queryBegin := fmt.Sprintf(`UPDATE %s SET `, collectionsTable)
+	setVals := make([]string, 0, 3)
+	params := make([]interface{}, 0, 3)
+
+	argId := 1
+	if input.Title != nil {
+		setVals = append(setVals, "title=$"+strconv.Itoa(argId))
+		params = append(params, *input.Title)
+		argId++
+	}
+	if input.Description != nil {
+		setVals = append(setVals, "description=$"+strconv.Itoa(argId))
+		params = append(params, *input.Description)
+		argId++
+	}
+	if input.IsPublic != nil {
+		setVals = append(setVals, "is_public=$"+strconv.Itoa(argId))
+		params = append(params, *input.IsPublic)
+		argId++
+	}
+
+	if argId == 1 {
+		return r.GetCollection(ctx, input.Id)
+	}
+
+	setString := strings.Join(setVals, ",")
+	params = append(params, input.Id)
+	query := queryBegin + setString + " WHERE id=$" + strconv.Itoa(argId) + " RETURNING *"
+	err := r.db.QueryRowxContext(ctx, query, params...).StructScan(&collection)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return collection, ErrNoRows
+		}
+		log.Error(err.Error())
+		return collection, ErrInternal
+	}
+
+	collection.Items, err = r.listItems(ctx, collection.Id)
+	if err != nil {
+		log.Error(err.Error())
+		return collection, ErrInternal
+	}
+
+	return collection, nil
+}
+
+func (r CollectionPostgres) DeleteCollection(ctx context.Context, id int) error {
+	const method = "Collections.Repository.DeleteCollection"
+	log := r.log.With(slog.String("method", method))
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, collectionsTable)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+func (r CollectionPostgres) FollowCollection(ctx context.Context, collectionId, userId int) error {
+	query := fmt.Sprintf(`INSERT INTO %s(collection_id, user_id) VALUES($1,$2) ON CONFLICT DO NOTHING`,
+		collectionsFollowersTable)
+	_, err := r.db.ExecContext(ctx, query, collectionId, userId)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (r CollectionPostgres) UnfollowCollection(ctx context.Context, collectionId, userId int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE collection_id=$1 AND user_id=$2`, collectionsFollowersTable)
+	result, err := r.db.ExecContext(ctx, query, collectionId, userId)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
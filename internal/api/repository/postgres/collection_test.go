@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/brianvoe/gofakeit"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareCollectionTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *CollectionPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewCollectionPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestCollectionPostgres_CreateCollection(t *testing.T) {
+	mock, dbx, r := prepareCollectionTest(t)
+	defer dbx.Close()
+
+	collection := domain.Collection{
+		Id:          1,
+		OwnerId:     2,
+		Title:       gofakeit.JobTitle(),
+		Description: gofakeit.JobDescriptor(),
+		ShareToken:  "token",
+		Items: []domain.CollectionItem{
+			{FilmId: 3, Note: "first"},
+			{FilmId: 1, Note: "second"},
+		},
+	}
+
+	t.Run("RightCredentials", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(collection.Id)
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, collectionsTable)).
+			WithArgs(collection.OwnerId, collection.Title, collection.Description,
+				collection.IsPublic, collection.ShareToken, collection.ClonedFrom).
+			WillReturnRows(rows)
+		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", collectionsItemsTable)).WithArgs(collection.Id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", collectionsItemsTable))
+		for i, item := range collection.Items {
+			mock.ExpectExec(fmt.Sprintf("INSERT INTO %s", collectionsItemsTable)).
+				WithArgs(collection.Id, item.FilmId, i+1, item.Note).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectCommit()
+
+		got, err := r.CreateCollection(context.Background(), collection)
+		assert.NoError(t, err)
+		assert.Equal(t, collection.Id, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UnknownFilm", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(collection.Id)
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, collectionsTable)).
+			WithArgs(collection.OwnerId, collection.Title, collection.Description,
+				collection.IsPublic, collection.ShareToken, collection.ClonedFrom).
+			WillReturnRows(rows)
+		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", collectionsItemsTable)).WithArgs(collection.Id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", collectionsItemsTable))
+		mock.ExpectExec(fmt.Sprintf("INSERT INTO %s", collectionsItemsTable)).
+			WithArgs(collection.Id, collection.Items[0].FilmId, 1, collection.Items[0].Note).
+			WillReturnError(pgx.PgError{Code: foreignErrCode})
+		mock.ExpectRollback()
+
+		_, err := r.CreateCollection(context.Background(), collection)
+		assert.ErrorIs(t, err, ErrForeign)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCollectionPostgres_GetCollection(t *testing.T) {
+	mock, dbx, r := prepareCollectionTest(t)
+	defer dbx.Close()
+
+	t.Run("WithItems", func(t *testing.T) {
+		collection := domain.Collection{
+			Id:         1,
+			OwnerId:    2,
+			Title:      gofakeit.JobTitle(),
+			IsPublic:   true,
+			ShareToken: "token",
+			Items: []domain.CollectionItem{
+				{FilmId: 3, Position: 1, Note: "note", FilmTitle: gofakeit.JobTitle()},
+			},
+		}
+		rows := sqlmock.NewRows([]string{"id", "owner_id", "title", "description", "is_public",
+			"share_token", "cloned_from"}).
+			AddRow(collection.Id, collection.OwnerId, collection.Title, collection.Description,
+				collection.IsPublic, collection.ShareToken, nil)
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s`, collectionsTable)).
+			WithArgs(collection.Id).WillReturnRows(rows)
+		itemRows := sqlmock.NewRows([]string{"film_id", "position", "note", "title"}).
+			AddRow(3, 1, "note", collection.Items[0].FilmTitle)
+		mock.ExpectQuery(fmt.Sprintf(`SELECT ci.film_id, ci.position, ci.note, f.title FROM %s`,
+			collectionsItemsTable)).WithArgs(collection.Id).WillReturnRows(itemRows)
+
+		got, err := r.GetCollection(context.Background(), collection.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, collection, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NoSuchId", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s`, collectionsTable)).
+			WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := r.GetCollection(context.Background(), 5)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCollectionPostgres_PatchCollection(t *testing.T) {
+	mock, dbx, r := prepareCollectionTest(t)
+	defer dbx.Close()
+
+	title := "Noir"
+	input := domain.CollectionInput{Id: 1, Title: &title}
+	t.Run("NoSuchId", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET title=\$1 WHERE id=\$2`, collectionsTable)).
+			WithArgs(title, 1).WillReturnError(sql.ErrNoRows)
+		_, err := r.PatchCollection(context.Background(), input)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("ConnectionLost", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET title=\$1 WHERE id=\$2`, collectionsTable)).
+			WithArgs(title, 1).WillReturnError(fmt.Errorf("connection reset"))
+		_, err := r.PatchCollection(context.Background(), input)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCollectionPostgres_UnfollowCollection(t *testing.T) {
+	mock, dbx, r := prepareCollectionTest(t)
+	defer dbx.Close()
+
+	t.Run("NotFollowed", func(t *testing.T) {
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s`, collectionsFollowersTable)).
+			WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := r.UnfollowCollection(context.Background(), 1, 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"time"
+)
+
+type CopyPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewCopyPostgres(db *sqlx.DB, log *slog.Logger) *CopyPostgres {
+	return &CopyPostgres{db: db, log: log}
+}
+
+// mapConstraintError converts constraint violations to repository errors
+func mapConstraintError(err error) error {
+	var pgErr pgx.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueErrCode:
+			return ErrUnique
+		case foreignErrCode:
+			return ErrForeign
+		}
+	}
+	return ErrInternal
+}
+
+func copiesSelect() string {
+	return fmt.Sprintf(`SELECT c.*, EXISTS(SELECT 1 FROM %s l WHERE l.copy_id = c.id AND l.returned_at IS NULL)
+		AS on_loan FROM %s c`, loansTable, copiesTable)
+}
+
+func (r CopyPostgres) CreateCopy(ctx context.Context, c domain.Copy) (int, error) {
+	const method = "Copies.Repository.CreateCopy"
+	log := r.log.With(slog.String("method", method))
+
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(film_id, format, barcode, shelf, condition)
+		VALUES($1,$2,$3,$4,$5) RETURNING id`, copiesTable)
+	row := r.db.QueryRowxContext(ctx, query, c.FilmId, c.Format, c.Barcode, c.Shelf, c.Condition)
+	if err := row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+
+	return id, nil
+}
+
+func (r CopyPostgres) GetCopy(ctx context.Context, id int) (domain.Copy, error) {
+	var c domain.Copy
+	err := r.db.GetContext(ctx, &c, copiesSelect()+` WHERE c.id=$1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c, ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return c, ErrInternal
+	}
+
+	return c, nil
+}
+
+// ListCopies leaves out the copies of a trashed film
+func (r CopyPostgres) ListCopies(ctx context.Context, filmId int) ([]domain.Copy, error) {
+	var copies []domain.Copy
+	query := copiesSelect() + fmt.Sprintf(` INNER JOIN %s f ON f.id = c.film_id AND f.deleted_at IS NULL
+		WHERE c.film_id=$1 ORDER BY c.id`, filmsTable)
+	err := r.db.SelectContext(ctx, &copies, query, filmId)
+
+	return copies, err
+}
+
+func (r CopyPostgres) UpdateCopy(ctx context.Context, c domain.Copy) error {
+	query := fmt.Sprintf(`UPDATE %s SET format=$1, barcode=$2, shelf=$3, condition=$4 WHERE id=$5`, copiesTable)
+	result, err := r.db.ExecContext(ctx, query, c.Format, c.Barcode, c.Shelf, c.Condition, c.Id)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteCopy refuses with ErrForeign to delete a copy that has been lent, its loans are kept
+func (r CopyPostgres) DeleteCopy(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, copiesTable)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		r.log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+func (r CopyPostgres) CreateLoan(ctx context.Context, loan domain.Loan) (int, error) {
+	const method = "Copies.Repository.CreateLoan"
+	log := r.log.With(slog.String("method", method))
+
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(copy_id, user_id, borrower, lent_at, due_date)
+		VALUES($1,$2,$3,$4,$5) RETURNING id`, loansTable)
+	row := r.db.QueryRowxContext(ctx, query, loan.CopyId, loan.UserId, loan.Borrower,
+		loan.LentAt.Time, loan.DueDate.Time)
+	if err := row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+
+	return id, nil
+}
+
+func (r CopyPostgres) ReturnCopy(ctx context.Context, copyId int, returnedAt time.Time) (domain.Loan, error) {
+	var loan domain.Loan
+	query := fmt.Sprintf(`UPDATE %s SET returned_at=$1 WHERE copy_id=$2 AND returned_at IS NULL RETURNING *`,
+		loansTable)
+	err := r.db.QueryRowxContext(ctx, query, returnedAt, copyId).StructScan(&loan)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return loan, ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return loan, ErrInternal
+	}
+
+	return loan, nil
+}
+
+func (r CopyPostgres) ListLoans(ctx context.Context, copyId int) ([]domain.Loan, error) {
+	var loans []domain.Loan
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE copy_id=$1 ORDER BY lent_at DESC, id DESC`, loansTable)
+	err := r.db.SelectContext(ctx, &loans, query, copyId)
+
+	return loans, err
+}
+
+func (r CopyPostgres) ListOverdueLoans(ctx context.Context, date time.Time) ([]domain.Loan, error) {
+	var loans []domain.Loan
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE returned_at IS NULL AND due_date < $1 ORDER BY due_date`,
+		loansTable)
+	err := r.db.SelectContext(ctx, &loans, query, date)
+
+	return loans, err
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func prepareCopyTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *CopyPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewCopyPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestCopyPostgres_CreateCopy(t *testing.T) {
+	mock, dbx, r := prepareCopyTest(t)
+	defer dbx.Close()
+
+	c := domain.Copy{
+		Id:        1,
+		FilmId:    2,
+		Format:    "bluray",
+		Barcode:   "4607173012345",
+		Shelf:     "A3",
+		Condition: "good",
+	}
+
+	t.Run("RightCredentials", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(c.Id)
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, copiesTable)).
+			WithArgs(c.FilmId, c.Format, c.Barcode, c.Shelf, c.Condition).WillReturnRows(rows)
+
+		got, err := r.CreateCopy(context.Background(), c)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Id, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UnknownFilm", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, copiesTable)).
+			WithArgs(c.FilmId, c.Format, c.Barcode, c.Shelf, c.Condition).
+			WillReturnError(pgx.PgError{Code: foreignErrCode})
+
+		_, err := r.CreateCopy(context.Background(), c)
+		assert.ErrorIs(t, err, ErrForeign)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyPostgres_ListCopies(t *testing.T) {
+	mock, dbx, r := prepareCopyTest(t)
+	defer dbx.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(`INNER JOIN %s f ON f.id = c.film_id AND f.deleted_at IS NULL`,
+		filmsTable))).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "film_id", "format", "barcode", "shelf", "condition", "on_loan"}).
+			AddRow(1, 2, "dvd", "", "A3", "good", false))
+
+	got, err := r.ListCopies(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCopyPostgres_DeleteCopy(t *testing.T) {
+	mock, dbx, r := prepareCopyTest(t)
+	defer dbx.Close()
+
+	t.Run("LentCopy", func(t *testing.T) {
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s`, copiesTable)).WithArgs(1).
+			WillReturnError(pgx.PgError{Code: foreignErrCode})
+
+		err := r.DeleteCopy(context.Background(), 1)
+		assert.ErrorIs(t, err, ErrForeign)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyPostgres_CreateLoan(t *testing.T) {
+	mock, dbx, r := prepareCopyTest(t)
+	defer dbx.Close()
+
+	loan := domain.Loan{
+		CopyId:   1,
+		Borrower: "Иван",
+		LentAt:   domain.NewDate(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		DueDate:  domain.NewDate(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("AlreadyLent", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, loansTable)).
+			WithArgs(loan.CopyId, loan.UserId, loan.Borrower, loan.LentAt.Time, loan.DueDate.Time).
+			WillReturnError(pgx.PgError{Code: uniqueErrCode})
+
+		_, err := r.CreateLoan(context.Background(), loan)
+		assert.ErrorIs(t, err, ErrUnique)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCopyPostgres_ReturnCopy(t *testing.T) {
+	mock, dbx, r := prepareCopyTest(t)
+	defer dbx.Close()
+
+	returned := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("OnLoan", func(t *testing.T) {
+		lent := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		due := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"id", "copy_id", "user_id", "borrower", "lent_at", "due_date",
+			"returned_at"}).AddRow(3, 1, 2, "", lent, due, returned)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, loansTable)).WithArgs(returned, 1).WillReturnRows(rows)
+
+		got, err := r.ReturnCopy(context.Background(), 1, returned)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, got.Id)
+		assert.Equal(t, 2, *got.UserId)
+		assert.Equal(t, domain.NewDate(returned), *got.ReturnedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NotLent", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, loansTable)).WithArgs(returned, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := r.ReturnCopy(context.Background(), 1, returned)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// filmObject and actorObject build the json of a film or an actor with the field names of the api
+const (
+	filmObject = `json_build_object('id', f.id, 'title', f.title, 'description', f.description,
+		'released', f.released, 'rating', f.rating, 'runtime', f.runtime, 'countries', f.countries,
+		'originalLanguage', f.original_language, 'languages', f.languages, 'ageRating', f.age_rating,
+		'budget', f.budget, 'boxOffice', f.box_office, 'genres', f.genres)`
+	actorObject = `json_build_object('id', a.id, 'name', a.name, 'gender', a.gender, 'birthday', a.birthday)`
+)
+
+type ExportPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewExportPostgres(db *sqlx.DB, log *slog.Logger) *ExportPostgres {
+	return &ExportPostgres{db: db, log: log}
+}
+
+type exportFilm struct {
+	domain.Film
+	ActorsJSON types.JSONText `db:"actors_json"`
+}
+
+type exportActor struct {
+	domain.Actor
+	FilmsJSON types.JSONText `db:"films_json"`
+}
+
+// ExportFilms streams the live films matching the filter, with their cast, to fn in id order.
+// An actorId other than -1 keeps the films of that actor only
+func (r ExportPostgres) ExportFilms(ctx context.Context, filter domain.FilmFilter, actorId int, fn func(domain.Film) error) error {
+	const method = "Export.Repository.ExportFilms"
+	log := r.log.With(slog.String("method", method))
+
+	return queryFilmsWithCast(ctx, r.db, log, filter, actorId, "ft.id", fn)
+}
+
+// ExportActors streams the live actors, with their films, to fn in id order.
+// A filmId other than -1 keeps the cast of that film only
+func (r ExportPostgres) ExportActors(ctx context.Context, filmId int, fn func(domain.Actor) error) error {
+	const method = "Export.Repository.ExportActors"
+	log := r.log.With(slog.String("method", method))
+
+	return queryActorsWithFilms(ctx, r.db, log, filmId, "f.released, f.id", fn)
+}
+
+// queryFilmsWithCast reads the live films matching the filter in the order, each with its cast in one row,
+// and passes them to fn as they come. An actorId other than -1 keeps the films of that actor only
+func queryFilmsWithCast(ctx context.Context, db *sqlx.DB, log *slog.Logger, filter domain.FilmFilter, actorId int,
+	order string, fn func(domain.Film) error) error {
+	conds, params := filmFilterConditions(filter, 1)
+	conds = append([]string{"ft.deleted_at IS NULL"}, conds...)
+	if actorId != -1 {
+		params = append(params, actorId)
+		conds = append(conds, fmt.Sprintf(`EXISTS(SELECT 1 FROM %s WHERE film_id = ft.id AND actor_id = $%s)`,
+			filmsActorsTable, strconv.Itoa(len(params))))
+	}
+	query := fmt.Sprintf(`SELECT ft.*, COALESCE((SELECT json_agg(%s ORDER BY a.id) FROM %s fa
+		JOIN %s a ON a.id = fa.actor_id AND a.deleted_at IS NULL WHERE fa.film_id = ft.id), '[]') AS actors_json
+		FROM %s ft WHERE %s ORDER BY %s`,
+		actorObject, filmsActorsTable, actorsTable, filmsTable, strings.Join(conds, " AND "), order)
+
+	rows, err := db.QueryxContext(ctx, query, params...)
+	if err != nil {
+		return streamError(ctx, log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var film exportFilm
+		if err = rows.StructScan(&film); err != nil {
+			return streamError(ctx, log, err)
+		}
+		if err = json.Unmarshal(film.ActorsJSON, &film.Actors); err != nil {
+			log.Error(err.Error())
+			return ErrInternal
+		}
+		if err = fn(film.Film); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return streamError(ctx, log, err)
+	}
+	return nil
+}
+
+// queryActorsWithFilms reads the live actors in id order, each with its films in the filmOrder,
+// and passes them to fn as they come. A filmId other than -1 keeps the cast of that film only
+func queryActorsWithFilms(ctx context.Context, db *sqlx.DB, log *slog.Logger, filmId int, filmOrder string,
+	fn func(domain.Actor) error) error {
+	params := make([]interface{}, 0, 1)
+	where := "a.deleted_at IS NULL"
+	if filmId != -1 {
+		params = append(params, filmId)
+		where += fmt.Sprintf(` AND EXISTS(SELECT 1 FROM %s WHERE actor_id = a.id AND film_id = $1)`, filmsActorsTable)
+	}
+	query := fmt.Sprintf(`SELECT a.*, COALESCE((SELECT json_agg(%s ORDER BY %s) FROM %s fa
+		JOIN %s f ON f.id = fa.film_id AND f.deleted_at IS NULL WHERE fa.actor_id = a.id), '[]') AS films_json
+		FROM %s a WHERE %s ORDER BY a.id`,
+		filmObject, filmOrder, filmsActorsTable, filmsTable, actorsTable, where)
+
+	rows, err := db.QueryxContext(ctx, query, params...)
+	if err != nil {
+		return streamError(ctx, log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var actor exportActor
+		if err = rows.StructScan(&actor); err != nil {
+			return streamError(ctx, log, err)
+		}
+		if err = json.Unmarshal(actor.FilmsJSON, &actor.Films); err != nil {
+			log.Error(err.Error())
+			return ErrInternal
+		}
+		if err = fn(actor.Actor); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return streamError(ctx, log, err)
+	}
+	return nil
+}
+
+// streamError tells a query cancelled along with its context, the client has gone, from a failed one
+func streamError(ctx context.Context, log *slog.Logger, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	log.Error(err.Error())
+	return ErrInternal
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareExportTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *ExportPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewExportPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestExportPostgres_ExportFilms(t *testing.T) {
+	mock, dbx, r := prepareExportTest(t)
+	defer dbx.Close()
+
+	country := "RU"
+	rows := sqlmock.NewRows([]string{"id", "title", "released", "actors_json"}).
+		AddRow(1, "Brother", "1997-05", `[{"id": 3, "name": "Sergei Bodrov", "gender": 1, "birthday": "1971-12-27"}]`).
+		AddRow(2, "Brother 2", "2000", `[]`)
+	mock.ExpectQuery(fmt.Sprintf(`SELECT ft.\*, COALESCE\(.+\) AS actors_json FROM %s ft
+		WHERE ft.deleted_at IS NULL AND \$1 = ANY\(ft.countries\) AND EXISTS\(SELECT 1 FROM %s
+		WHERE film_id = ft.id AND actor_id = \$2\) ORDER BY ft.id`, filmsTable, filmsActorsTable)).
+		WithArgs(country, 3).WillReturnRows(rows)
+
+	var got []domain.Film
+	err := r.ExportFilms(context.Background(), domain.FilmFilter{Country: &country}, 3, func(film domain.Film) error {
+		got = append(got, film)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "Sergei Bodrov", got[0].Actors[0].Name)
+	assert.Equal(t, "1971-12-27", got[0].Actors[0].Birthday.String())
+	assert.Empty(t, got[1].Actors)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportPostgres_ExportActors(t *testing.T) {
+	mock, dbx, r := prepareExportTest(t)
+	defer dbx.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "films_json"}).
+		AddRow(3, "Sergei Bodrov", `[{"id": 1, "title": "Brother", "released": "1997-05", "countries": ["RU"]}]`)
+	mock.ExpectQuery(fmt.Sprintf(`SELECT a.\*, COALESCE\(.+\) AS films_json FROM %s a WHERE a.deleted_at IS NULL
+		ORDER BY a.id`, actorsTable)).WithArgs().WillReturnRows(rows)
+
+	var got []domain.Actor
+	err := r.ExportActors(context.Background(), -1, func(actor domain.Actor) error {
+		got = append(got, actor)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StringList{"RU"}, got[0].Films[0].Countries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
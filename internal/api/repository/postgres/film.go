@@ -1,13 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"github.com/jmoiron/sqlx"
 	"log/slog"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type FilmPostgres struct {
@@ -15,30 +17,84 @@ type FilmPostgres struct {
 	log *slog.Logger
 }
 
-func (r FilmPostgres) ListFilms(sortBy, sortDir string) ([]domain.Film, error) {
+// filmFilterConditions turns the metadata filter into WHERE conditions on the films alias ft
+func filmFilterConditions(filter domain.FilmFilter, argId int) ([]string, []interface{}) {
+	conds := make([]string, 0)
+	params := make([]interface{}, 0)
+
+	add := func(cond string, param interface{}) {
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(argId)))
+		params = append(params, param)
+		argId++
+	}
+	if filter.Country != nil {
+		add("? = ANY(ft.countries)", *filter.Country)
+	}
+	if filter.Language != nil {
+		add("(ft.original_language = ? OR ? = ANY(ft.languages))", *filter.Language)
+	}
+	if filter.AgeRating != nil {
+		add("ft.age_rating = ?", *filter.AgeRating)
+	}
+	if filter.RuntimeMin != nil {
+		add("ft.runtime >= ?", *filter.RuntimeMin)
+	}
+	if filter.RuntimeMax != nil {
+		add("ft.runtime <= ?", *filter.RuntimeMax)
+	}
+	if filter.BudgetMin != nil {
+		add("ft.budget >= ?", *filter.BudgetMin)
+	}
+	if filter.BudgetMax != nil {
+		add("ft.budget <= ?", *filter.BudgetMax)
+	}
+	if filter.BoxOfficeMin != nil {
+		add("ft.box_office >= ?", *filter.BoxOfficeMin)
+	}
+	if filter.BoxOfficeMax != nil {
+		add("ft.box_office <= ?", *filter.BoxOfficeMax)
+	}
+
+	return conds, params
+}
+
+func (r FilmPostgres) ListFilms(ctx context.Context, sortBy, sortDir string, filter domain.FilmFilter) ([]domain.Film, error) {
 	var films []domain.Film
-	query := fmt.Sprintf(`SELECT * from %s ft ORDER BY %s %s`, filmsTable, sortBy, sortDir)
-	err := r.db.Select(&films, query)
+	conds, params := filmFilterConditions(filter, 1)
+	where := "WHERE " + strings.Join(append([]string{"ft.deleted_at IS NULL"}, conds...), " AND ")
+	query := fmt.Sprintf(`SELECT * from %s ft %s ORDER BY %s %s NULLS LAST`, filmsTable, where, sortBy, sortDir)
+	err := r.db.SelectContext(ctx, &films, query, params...)
 
 	return films, err
 }
 
+// StreamFilms passes the films ListFilms or, with an actorId other than -1, ListFilmsByActor would return
+// to fn one by one, each with its cast. The query is cancelled with ctx
+func (r FilmPostgres) StreamFilms(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter,
+	fn func(domain.Film) error) error {
+	const method = "Films.Repository.StreamFilms"
+	log := r.log.With(slog.String("method", method))
+
+	order := fmt.Sprintf(`ft.%s %s NULLS LAST, ft.id`, sortBy, sortDir)
+	return queryFilmsWithCast(ctx, r.db, log, filter, actorId, order, fn)
+}
+
 func NewFilmPostgres(db *sqlx.DB, log *slog.Logger) *FilmPostgres {
 	return &FilmPostgres{db: db, log: log}
 }
 
-func (r FilmPostgres) updateActorsList(tx *sqlx.Tx, filmId int, actorIds []int) error {
+func (r FilmPostgres) updateActorsList(ctx context.Context, tx *sqlx.Tx, filmId int, actorIds []int) error {
 	const method = "Films.Repository.updateActorsList"
 	log := r.log.With(slog.String("method", method))
 
 	clearOldActors := fmt.Sprintf(`DELETE FROM %s where film_id=$1`, filmsActorsTable)
-	_, err := tx.Exec(clearOldActors, filmId)
+	_, err := tx.ExecContext(ctx, clearOldActors, filmId)
 	if err != nil {
 		log.Error(err.Error())
 		return err
 	}
 
-	addActorsStmt, err := tx.Preparex(
+	addActorsStmt, err := tx.PreparexContext(ctx,
 		fmt.Sprintf(`INSERT INTO %s(film_id, actor_id) VALUES($1,$2)`, filmsActorsTable))
 	if err != nil {
 		log.Error(err.Error())
@@ -46,24 +102,52 @@ func (r FilmPostgres) updateActorsList(tx *sqlx.Tx, filmId int, actorIds []int)
 	}
 
 	for _, actorId := range actorIds {
-		_, err = addActorsStmt.Exec(filmId, actorId)
+		_, err = addActorsStmt.ExecContext(ctx, filmId, actorId)
 		if err != nil {
 			log.Error(err.Error())
-			return ErrUnique
+			return mapConstraintError(err)
 		}
 	}
 	return nil
 }
 
-func (r FilmPostgres) PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Film, error) {
+// PatchFilm sets the fields given in input and records the revision on behalf of userId.
+// The cast is replaced with actorIds unless they are nil
+func (r FilmPostgres) PatchFilm(ctx context.Context, userId int, input domain.NullableFilm,
+	actorIds []int) (domain.Film, error) {
 	const method = "Films.Repository.PatchFilm"
 	log := r.log.With(slog.String("method", method))
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return domain.Film{}, ErrInternal
+	}
+	defer tx.Rollback()
+
+	film, err := r.patchFilm(ctx, tx, userId, input, actorIds)
+	if err != nil {
+		return film, err
+	}
+	return film, tx.Commit()
+}
+
+// patchFilm is PatchFilm within the transaction tx
+func (r FilmPostgres) patchFilm(ctx context.Context, tx *sqlx.Tx, userId int, input domain.NullableFilm,
+	actorIds []int) (domain.Film, error) {
+	const method = "Films.Repository.patchFilm"
+	log := r.log.With(slog.String("method", method))
+
 	var film domain.Film
+	before, err := lockSnapshot(ctx, tx, domain.KindFilm, input.Id)
+	if err != nil {
+		return film, revisionError(log, err)
+	}
 
 	queryBegin := fmt.Sprintf(`UPDATE %s SET `, filmsTable)
-	setVals := make([]string, 0, 4)
-	params := make([]interface{}, 0, 4)
+	// the version goes up on every patch, even one that only changes the actors
+	setVals := []string{"version=version+1"}
+	params := make([]interface{}, 0, 14)
 
 	argId := 1
 	if input.Title != nil {
@@ -78,7 +162,7 @@ func (r FilmPostgres) PatchFilm(input domain.NullableFilm, actorIds []int) (doma
 	}
 	if input.Released != nil {
 		setVals = append(setVals, "released=$"+strconv.Itoa(argId))
-		params = append(params, time.Time(*input.Released))
+		params = append(params, *input.Released)
 		argId++
 	}
 	if input.Rating != nil {
@@ -86,71 +170,135 @@ func (r FilmPostgres) PatchFilm(input domain.NullableFilm, actorIds []int) (doma
 		params = append(params, *input.Rating)
 		argId++
 	}
+	if input.Runtime != nil {
+		setVals = append(setVals, "runtime=$"+strconv.Itoa(argId))
+		params = append(params, *input.Runtime)
+		argId++
+	}
+	if input.Countries != nil {
+		setVals = append(setVals, "countries=$"+strconv.Itoa(argId))
+		params = append(params, *input.Countries)
+		argId++
+	}
+	if input.OriginalLanguage != nil {
+		setVals = append(setVals, "original_language=$"+strconv.Itoa(argId))
+		params = append(params, *input.OriginalLanguage)
+		argId++
+	}
+	if input.Languages != nil {
+		setVals = append(setVals, "languages=$"+strconv.Itoa(argId))
+		params = append(params, *input.Languages)
+		argId++
+	}
+	if input.AgeRating != nil {
+		setVals = append(setVals, "age_rating=$"+strconv.Itoa(argId))
+		params = append(params, *input.AgeRating)
+		argId++
+	}
+	if input.Budget != nil {
+		setVals = append(setVals, "budget=$"+strconv.Itoa(argId))
+		params = append(params, *input.Budget)
+		argId++
+	}
+	if input.BoxOffice != nil {
+		setVals = append(setVals, "box_office=$"+strconv.Itoa(argId))
+		params = append(params, *input.BoxOffice)
+		argId++
+	}
+	if input.Genres != nil {
+		setVals = append(setVals, "genres=$"+strconv.Itoa(argId))
+		params = append(params, *input.Genres)
+		argId++
+	}
 
-	tx, err := r.db.Beginx()
+	setString := strings.Join(setVals, ",")
+	params = append(params, input.Id, input.Version)
+	query := queryBegin + setString + " WHERE " + versionCond(argId) + " RETURNING *"
+	err = tx.QueryRowxContext(ctx, query, params...).StructScan(&film)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return film, missingOrStale(ctx, tx, filmsTable, input.Id)
+		}
 		log.Error(err.Error())
-		return film, err
+		return film, mapConstraintError(err)
 	}
 
-	if argId != 1 {
-		setString := strings.Join(setVals, ",")
-		params = append(params, input.Id)
-		query := queryBegin + setString + " WHERE id=$" + strconv.Itoa(argId) +
-			" RETURNING *"
-		rows := tx.QueryRowx(query, params...)
-		err = rows.StructScan(&film)
+	if actorIds != nil {
+		err = r.updateActorsList(ctx, tx, input.Id, actorIds)
 		if err != nil {
 			log.Error(err.Error())
-			tx.Rollback()
-			return film, ErrNoRows
+			return film, err
 		}
 	}
-
-	err = r.updateActorsList(tx, input.Id, actorIds)
-	if err != nil {
-		log.Error(err.Error())
-		tx.Rollback()
-		return film, err
+	if err = insertRevision(ctx, tx, domain.KindFilm, domain.ActionPatch, userId, input.Id, before); err != nil {
+		return film, revisionError(log, err)
 	}
-
-	return film, tx.Commit()
+	return film, nil
 }
 
-func (r FilmPostgres) CreateFilm(film domain.Film, actorIds []int) (int, error) {
+// CreateFilm adds the film with its cast and records its revision on behalf of userId
+func (r FilmPostgres) CreateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
 	var filmId int
 	const method = "Films.Repository.CreateFilm"
 	log := r.log.With(slog.String("method", method))
 
-	tx, err := r.db.Beginx()
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return 0, ErrInternal
 	}
-
-	createFilmQuery := fmt.Sprintf(`INSERT INTO %s(title, description, released, rating) 
-		VALUES($1,$2,$3,$4) RETURNING id`, filmsTable)
-	row := tx.QueryRowx(createFilmQuery, film.Title, film.Description, film.Released.String(), film.Rating)
+	defer tx.Rollback()
+
+	createFilmQuery := fmt.Sprintf(`INSERT INTO %s(title, description, released, rating, runtime, countries,
+		original_language, languages, age_rating, budget, box_office, genres) 
+		VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id`, filmsTable)
+	row := tx.QueryRowxContext(ctx, createFilmQuery, film.Title, film.Description, film.Released, film.Rating,
+		film.Runtime, film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget,
+		film.BoxOffice, film.Genres)
 	if err = row.Scan(&filmId); err != nil {
-		tx.Rollback()
 		log.Error(err.Error())
 		return 0, ErrInternal
 	}
-	err = r.updateActorsList(tx, filmId, actorIds)
+	err = r.updateActorsList(ctx, tx, filmId, actorIds)
 	if err != nil {
-		tx.Rollback()
 		log.Error(err.Error())
 		return 0, err
 	}
+	if err = insertRevision(ctx, tx, domain.KindFilm, domain.ActionCreate, userId, filmId, nil); err != nil {
+		return 0, revisionError(log, err)
+	}
 
 	return filmId, tx.Commit()
 }
 
-func (r FilmPostgres) DeleteFilm(id int) error {
+func (r FilmPostgres) GetFilm(ctx context.Context, id int) (domain.Film, error) {
+	var film domain.Film
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1 AND deleted_at IS NULL`, filmsTable)
+	err := r.db.GetContext(ctx, &film, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return film, ErrNoRows
+	}
+	return film, err
+}
+
+// DeleteFilm moves the film to the trash. Cast links stay, so a restored film gets them back
+func (r FilmPostgres) DeleteFilm(ctx context.Context, userId, id, version int) error {
 	const method = "Films.Repository.DeleteFilm"
 	log := r.log.With(slog.String("method", method))
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE id=$1", filmsTable)
-	result, err := r.db.Exec(query, id)
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnapshot(ctx, tx, domain.KindFilm, id)
+	if err != nil {
+		return revisionError(log, err)
+	}
+	query := fmt.Sprintf("UPDATE %s SET deleted_at=now(), deleted_by=$3, version=version+1 WHERE %s",
+		filmsTable, versionCond(1))
+	result, err := tx.ExecContext(ctx, query, id, version, userId)
 	if err != nil {
 		log.Error(err.Error())
 		return ErrInternal
@@ -162,59 +310,97 @@ func (r FilmPostgres) DeleteFilm(id int) error {
 	}
 	if count == 0 {
 		log.Info("No rows affected")
-		return ErrNoRows
+		return missingOrStale(ctx, tx, filmsTable, id)
+	}
+	if err = insertRevision(ctx, tx, domain.KindFilm, domain.ActionDelete, userId, id, before); err != nil {
+		return revisionError(log, err)
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (r FilmPostgres) UpdateFilm(film domain.Film, actorIds []int) error {
+// UpdateFilm replaces the film if it still has film.Version and returns the new version.
+// The revision is recorded with action, an update or a revert
+func (r FilmPostgres) UpdateFilm(ctx context.Context, userId int, action string, film domain.Film,
+	actorIds []int) (int, error) {
 	const method = "Films.Repository.UpdateFilm"
 	log := r.log.With(slog.String("method", method))
 
-	tx, err := r.db.Beginx()
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		log.Error(err.Error())
-		tx.Rollback()
-		return ErrInternal
+		return 0, ErrInternal
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnapshot(ctx, tx, domain.KindFilm, film.Id)
+	if err != nil {
+		return 0, revisionError(log, err)
+	}
+	var version int
+	modifyFilmInfo := fmt.Sprintf(`UPDATE %s SET title=$1, description=$2, released=$3, rating=$4, runtime=$5,
+          countries=$6, original_language=$7, languages=$8, age_rating=$9, budget=$10, box_office=$11,
+          genres=$12, version=version+1 WHERE %s RETURNING version`, filmsTable, versionCond(13))
+	err = tx.GetContext(ctx, &version, modifyFilmInfo, film.Title, film.Description, film.Released, film.Rating,
+		film.Runtime, film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget,
+		film.BoxOffice, film.Genres, film.Id, film.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, missingOrStale(ctx, tx, filmsTable, film.Id)
 	}
-	modifyFilmInfo := fmt.Sprintf(`UPDATE %s SET title=$1, description=$2, released=$3, rating=$4 
-          WHERE id=$5`, filmsTable)
-	_, err = tx.Exec(modifyFilmInfo, film.Title, film.Description, film.Released.String(), film.Rating, film.Id)
 	if err != nil {
 		log.Error(err.Error())
-		tx.Rollback()
-		return err
+		return 0, mapConstraintError(err)
 	}
 
-	err = r.updateActorsList(tx, film.Id, actorIds)
+	err = r.updateActorsList(ctx, tx, film.Id, actorIds)
 	if err != nil {
 		log.Error(err.Error())
-		tx.Rollback()
-		return err
+		return 0, err
+	}
+	if err = insertRevision(ctx, tx, domain.KindFilm, action, userId, film.Id, before); err != nil {
+		return 0, revisionError(log, err)
 	}
 
-	return tx.Commit()
+	return version, tx.Commit()
 }
 
-func (r FilmPostgres) ListFilmsByActor(sortBy, sortDir string, actorId int) ([]domain.Film, error) {
+func (r FilmPostgres) ListFilmsByActor(ctx context.Context, sortBy, sortDir string, actorId int,
+	filter domain.FilmFilter) ([]domain.Film, error) {
 	var films []domain.Film
+	conds, params := filmFilterConditions(filter, 2)
+	where := "WHERE " + strings.Join(append([]string{"fa.actor_id = $1", "ft.deleted_at IS NULL"}, conds...), " AND ")
 	query := fmt.Sprintf(`SELECT ft.* from %s ft INNER JOIN %s fa ON ft.id = fa.film_id 
-                                     WHERE fa.actor_id = $1 ORDER BY %s %s`,
-		filmsTable, filmsActorsTable, sortBy, sortDir)
+                                     %s ORDER BY %s %s NULLS LAST`,
+		filmsTable, filmsActorsTable, where, sortBy, sortDir)
 
-	err := r.db.Select(&films, query, actorId)
+	err := r.db.SelectContext(ctx, &films, query, append([]interface{}{actorId}, params...)...)
 
 	return films, err
 }
 
-func (r FilmPostgres) SearchFilm(searchQuery string) ([]domain.Film, error) {
+func (r FilmPostgres) SearchFilm(ctx context.Context, searchQuery string) ([]domain.Film, error) {
 	var films []domain.Film
 	query := fmt.Sprintf(`SELECT f.* FROM %s f 
-           INNER JOIN %s fa ON f.id = fa.film_id 
-           INNER JOIN %s a ON a.id = fa.actor_id 
-           WHERE f.title LIKE $1 OR a.name LIKE $1 GROUP BY f.id`, filmsTable, filmsActorsTable, actorsTable)
+           LEFT JOIN %s fa ON f.id = fa.film_id 
+           LEFT JOIN %s a ON a.id = fa.actor_id AND a.deleted_at IS NULL 
+           LEFT JOIN %s ft ON ft.film_id = f.id 
+           LEFT JOIN %s at ON at.actor_id = a.id 
+           LEFT JOIN %s al ON al.film_id = f.id 
+           WHERE f.deleted_at IS NULL 
+             AND (f.title LIKE $1 OR a.name LIKE $1 OR ft.title LIKE $1 OR at.name LIKE $1 OR al.title LIKE $1) 
+           GROUP BY f.id`,
+		filmsTable, filmsActorsTable, actorsTable, filmsTranslationsTable, actorsTranslationsTable, filmsAliasesTable)
 	like := fmt.Sprintf("%%%s%%", searchQuery)
-	err := r.db.Select(&films, query, like)
+	err := r.db.SelectContext(ctx, &films, query, like)
 	return films, err
 }
+
+func (r FilmPostgres) ListFilmsActors(ctx context.Context) ([]domain.FilmActor, error) {
+	var links []domain.FilmActor
+	query := fmt.Sprintf(`SELECT fa.film_id, fa.actor_id FROM %s fa
+		INNER JOIN %s f ON f.id = fa.film_id AND f.deleted_at IS NULL
+		INNER JOIN %s a ON a.id = fa.actor_id AND a.deleted_at IS NULL`, filmsActorsTable, filmsTable, actorsTable)
+	err := r.db.SelectContext(ctx, &links, query)
+
+	return links, err
+}
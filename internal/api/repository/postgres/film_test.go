@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"log/slog"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -37,8 +40,8 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 		film := domain.Film{
 			Id:       1,
 			Title:    gofakeit.JobTitle(),
-			Released: domain.CustomDate(gofakeit.Date()),
-			Rating:   5,
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   ptr[int8](5),
 		}
 
 		actorIds := []int{1, 2, 3}
@@ -47,7 +50,8 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 			AddRow(film.Id)
 		mock.ExpectBegin()
 		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, filmsTable)).
-			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating).WillReturnRows(rows)
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres).WillReturnRows(rows)
 		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", filmsActorsTable)).WithArgs(film.Id).
 			WillReturnResult(sqlmock.NewResult(1, 3))
 		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", filmsActorsTable))
@@ -56,8 +60,9 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 				WithArgs(film.Id, actorId).
 				WillReturnResult(sqlmock.NewResult(1, 1))
 		}
+		expectRevision(mock, domain.KindFilm, domain.ActionCreate, 2, film.Id)
 		mock.ExpectCommit()
-		got, err := r.CreateFilm(film, actorIds)
+		got, err := r.CreateFilm(context.Background(), 2, film, actorIds)
 		assert.NoError(t, err)
 		assert.Equal(t, film.Id, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -67,8 +72,8 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 		film := domain.Film{
 			Id:       1,
 			Title:    gofakeit.JobTitle(),
-			Released: domain.CustomDate(gofakeit.Date()),
-			Rating:   5,
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   ptr[int8](5),
 		}
 
 		actorIds := []int{2, 2}
@@ -77,7 +82,8 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 			AddRow(film.Id)
 		mock.ExpectBegin()
 		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, filmsTable)).
-			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating).WillReturnRows(rows)
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres).WillReturnRows(rows)
 		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", filmsActorsTable)).WithArgs(film.Id).
 			WillReturnResult(sqlmock.NewResult(1, 3))
 		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", filmsActorsTable))
@@ -89,7 +95,7 @@ func TestFilmPostgres_CreateFilm(t *testing.T) {
 			WithArgs(film.Id, actorIds[1]).
 			WillReturnError(pgx.PgError{Code: uniqueErrCode})
 		mock.ExpectRollback()
-		_, err := r.CreateFilm(film, actorIds)
+		_, err := r.CreateFilm(context.Background(), 2, film, actorIds)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, ErrUnique)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -104,15 +110,18 @@ func TestFilmPostgres_UpdateFilm(t *testing.T) {
 		film := domain.Film{
 			Id:       1,
 			Title:    gofakeit.JobTitle(),
-			Released: domain.CustomDate(gofakeit.Date()),
-			Rating:   5,
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   ptr[int8](5),
 		}
 		actorIds := []int{1, 2}
 
 		mock.ExpectBegin()
-		mock.ExpectExec(fmt.Sprintf(`UPDATE %s`, filmsTable)).
-			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Id).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres,
+				film.Id, film.Version).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
 		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", filmsActorsTable)).WithArgs(film.Id).
 			WillReturnResult(sqlmock.NewResult(1, 3))
 		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", filmsActorsTable))
@@ -121,25 +130,30 @@ func TestFilmPostgres_UpdateFilm(t *testing.T) {
 				WithArgs(film.Id, actorId).
 				WillReturnResult(sqlmock.NewResult(1, 1))
 		}
+		expectRevision(mock, domain.KindFilm, domain.ActionUpdate, 2, film.Id)
 		mock.ExpectCommit()
-		err := r.UpdateFilm(film, actorIds)
+		version, err := r.UpdateFilm(context.Background(), 2, domain.ActionUpdate, film, actorIds)
 		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 	t.Run("UniqueActorIds", func(t *testing.T) {
 		film := domain.Film{
 			Id:       1,
 			Title:    gofakeit.JobTitle(),
-			Released: domain.CustomDate(gofakeit.Date()),
-			Rating:   5,
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   ptr[int8](5),
 		}
 
 		actorIds := []int{2, 2}
 
 		mock.ExpectBegin()
-		mock.ExpectExec(fmt.Sprintf(`UPDATE %s`, filmsTable)).
-			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Id).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres,
+				film.Id, film.Version).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
 		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", filmsActorsTable)).WithArgs(film.Id).
 			WillReturnResult(sqlmock.NewResult(1, 3))
 		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", filmsActorsTable))
@@ -150,11 +164,55 @@ func TestFilmPostgres_UpdateFilm(t *testing.T) {
 			WithArgs(film.Id, actorIds[1]).
 			WillReturnError(pgx.PgError{Code: uniqueErrCode})
 		mock.ExpectRollback()
-		err := r.UpdateFilm(film, actorIds)
+		_, err := r.UpdateFilm(context.Background(), 2, domain.ActionUpdate, film, actorIds)
 		assert.Error(t, err)
 		assert.ErrorIs(t, err, ErrUnique)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		film := domain.Film{
+			Id:       1,
+			Title:    gofakeit.JobTitle(),
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   ptr[int8](5),
+		}
+
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres,
+				film.Id, film.Version).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(film.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+		_, err := r.UpdateFilm(context.Background(), 2, domain.ActionUpdate, film, []int{1})
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("StaleVersion", func(t *testing.T) {
+		film := domain.Film{
+			Id:       1,
+			Title:    gofakeit.JobTitle(),
+			Released: domain.NewDate(gofakeit.Date()),
+			Version:  4,
+		}
+
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE id=$13 AND deleted_at IS NULL AND ($14 = 0 OR version=$14) RETURNING version`)).
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Runtime,
+				film.Countries, film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres,
+				film.Id, film.Version).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(film.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+		_, err := r.UpdateFilm(context.Background(), 2, domain.ActionUpdate, film, []int{1})
+		assert.ErrorIs(t, err, ErrVersion)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestFilmPostgres_PatchFilm(t *testing.T) {
@@ -165,23 +223,24 @@ func TestFilmPostgres_PatchFilm(t *testing.T) {
 		film := domain.Film{
 			Id:          1,
 			Title:       gofakeit.JobTitle(),
-			Description: gofakeit.JobDescriptor(),
-			Rating:      1,
-			Released:    domain.CustomDate(time.Now()),
+			Description: ptr(gofakeit.JobDescriptor()),
+			Rating:      ptr[int8](1),
+			Released:    domain.NewDate(time.Now()),
 		}
 		filmInput := domain.NullableFilm{
 			Id:          1,
 			Title:       &film.Title,
-			Description: &film.Description,
-			Rating:      &film.Rating,
+			Description: film.Description,
+			Rating:      film.Rating,
 			Released:    &film.Released,
 			ActorIds:    []int{1, 2},
 		}
 		rows := sqlmock.NewRows([]string{"id", "title", "description", "released", "rating"}).
-			AddRow(film.Id, film.Title, film.Description, time.Time(film.Released), film.Rating)
+			AddRow(film.Id, film.Title, film.Description, film.Released.Time, film.Rating)
 		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
 		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
-			WithArgs(film.Title, film.Description, time.Time(film.Released), film.Rating, film.Id).WillReturnRows(rows)
+			WithArgs(film.Title, film.Description, film.Released.String(), film.Rating, film.Id, 0).WillReturnRows(rows)
 		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", filmsActorsTable)).WithArgs(film.Id).
 			WillReturnResult(sqlmock.NewResult(1, 3))
 		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", filmsActorsTable))
@@ -190,10 +249,171 @@ func TestFilmPostgres_PatchFilm(t *testing.T) {
 				WithArgs(film.Id, actorId).
 				WillReturnResult(sqlmock.NewResult(1, 1))
 		}
+		expectRevision(mock, domain.KindFilm, domain.ActionPatch, 2, 1)
 		mock.ExpectCommit()
-		got, err := r.PatchFilm(filmInput, filmInput.ActorIds)
+		got, err := r.PatchFilm(context.Background(), 2, filmInput, filmInput.ActorIds)
 		assert.NoError(t, err)
 		assert.Equal(t, film, got)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		title := gofakeit.JobTitle()
+		filmInput := domain.NullableFilm{Id: 1, Title: &title}
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
+			WithArgs(title, filmInput.Id, 0).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(`SELECT EXISTS`).WithArgs(filmInput.Id).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectRollback()
+		_, err := r.PatchFilm(context.Background(), 2, filmInput, nil)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("QueryError", func(t *testing.T) {
+		title := gofakeit.JobTitle()
+		filmInput := domain.NullableFilm{Id: 1, Title: &title}
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s`, filmsTable)).
+			WithArgs(title, filmInput.Id, 0).WillReturnError(fmt.Errorf("connection reset"))
+		mock.ExpectRollback()
+		_, err := r.PatchFilm(context.Background(), 2, filmInput, nil)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFilmPostgres_PatchFilmMetadata(t *testing.T) {
+	mock, dbx, r := prepareFilmTest(t)
+	defer dbx.Close()
+
+	t.Run("UpdateMetadataColumns", func(t *testing.T) {
+		runtime := 162
+		countries := domain.StringList{"US", "GB"}
+		ageRating := "PG-13"
+		budget := int64(237000000)
+		filmInput := domain.NullableFilm{
+			Id:        1,
+			Runtime:   &runtime,
+			Countries: &countries,
+			AgeRating: &ageRating,
+			Budget:    &budget,
+		}
+		rows := sqlmock.NewRows([]string{"id", "runtime", "countries", "age_rating", "budget"}).
+			AddRow(filmInput.Id, runtime, "{US,GB}", ageRating, budget)
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET version=version\+1,runtime=\$1,countries=\$2,age_rating=\$3,budget=\$4 WHERE id=\$5`,
+			filmsTable)).
+			WithArgs(runtime, countries, ageRating, budget, filmInput.Id, 0).WillReturnRows(rows)
+		expectRevision(mock, domain.KindFilm, domain.ActionPatch, 2, 1)
+		mock.ExpectCommit()
+		got, err := r.PatchFilm(context.Background(), 2, filmInput, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, countries, got.Countries)
+		assert.Equal(t, runtime, got.Runtime)
+		assert.Equal(t, budget, got.Budget)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFilmPostgres_ListFilms(t *testing.T) {
+	mock, dbx, r := prepareFilmTest(t)
+	defer dbx.Close()
+
+	t.Run("NoFilter", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "title", "countries", "languages"}).
+			AddRow(1, gofakeit.JobTitle(), "{}", "{}")
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* from %s ft WHERE ft.deleted_at IS NULL ORDER BY rating desc`, filmsTable)).
+			WillReturnRows(rows)
+		got, err := r.ListFilms(context.Background(), "rating", "desc", domain.FilmFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, domain.StringList{}, got[0].Countries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NullColumns", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "title", "description", "released", "rating"}).
+			AddRow(1, gofakeit.JobTitle(), nil, nil, nil).
+			AddRow(2, gofakeit.JobTitle(), "Silent film", "1927", 8)
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* from %s ft WHERE ft.deleted_at IS NULL ORDER BY released asc NULLS LAST`, filmsTable)).
+			WillReturnRows(rows)
+		got, err := r.ListFilms(context.Background(), "released", "asc", domain.FilmFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Nil(t, got[0].Description)
+		assert.Nil(t, got[0].Rating)
+		assert.False(t, got[0].Released.Valid())
+		assert.Equal(t, "Silent film", *got[1].Description)
+		assert.Equal(t, domain.PrecisionYear, got[1].Released.Precision)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MetadataFilter", func(t *testing.T) {
+		country, language := "FR", "fr"
+		runtimeMax := 120
+		filter := domain.FilmFilter{Country: &country, Language: &language, RuntimeMax: &runtimeMax}
+		rows := sqlmock.NewRows([]string{"id", "title", "countries", "languages"}).
+			AddRow(2, gofakeit.JobTitle(), "{FR,BE}", "{fr}")
+		mock.ExpectQuery(`WHERE ft.deleted_at IS NULL AND \$1 = ANY\(ft.countries\) AND \(ft.original_language = \$2 OR \$2 = ANY\(ft.languages\)\) `+
+			`AND ft.runtime <= \$3 ORDER BY title asc`).
+			WithArgs(country, language, runtimeMax).WillReturnRows(rows)
+		got, err := r.ListFilms(context.Background(), "title", "asc", filter)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StringList{"FR", "BE"}, got[0].Countries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFilmPostgres_StreamFilms(t *testing.T) {
+	mock, dbx, r := prepareFilmTest(t)
+	defer dbx.Close()
+
+	t.Run("SortedWithCast", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "title", "rating", "actors_json"}).
+			AddRow(1, "Brother", 8, `[{"id": 3, "name": "Sergei Bodrov", "gender": 1, "birthday": "1971-12-27"}]`).
+			AddRow(2, "Brother 2", 7, `[]`)
+		mock.ExpectQuery(fmt.Sprintf(`SELECT ft.\*, COALESCE\(.+\) AS actors_json FROM %s ft
+			WHERE ft.deleted_at IS NULL AND EXISTS\(SELECT 1 FROM %s WHERE film_id = ft.id AND actor_id = \$1\)
+			ORDER BY ft.rating desc NULLS LAST, ft.id`, filmsTable, filmsActorsTable)).
+			WithArgs(3).WillReturnRows(rows)
+
+		var got []domain.Film
+		err := r.StreamFilms(context.Background(), "rating", "desc", 3, domain.FilmFilter{}, func(film domain.Film) error {
+			got = append(got, film)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, "Sergei Bodrov", got[0].Actors[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("StoppedByCallback", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "title", "actors_json"}).
+			AddRow(1, "Brother", `[]`).
+			AddRow(2, "Brother 2", `[]`)
+		mock.ExpectQuery(`ORDER BY ft.title asc NULLS LAST, ft.id`).WillReturnRows(rows)
+
+		calls := 0
+		err := r.StreamFilms(context.Background(), "title", "asc", -1, domain.FilmFilter{}, func(film domain.Film) error {
+			calls++
+			return context.Canceled
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := r.StreamFilms(ctx, "title", "asc", -1, domain.FilmFilter{}, func(film domain.Film) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
 }
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/stdlib"
+	"io"
+	"log/slog"
+)
+
+// copySource feeds COPY from a stream that ends with io.EOF
+type copySource struct {
+	next   func() ([]interface{}, error)
+	values []interface{}
+	err    error
+}
+
+func (s *copySource) Next() bool {
+	values, err := s.next()
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			s.err = err
+		}
+		return false
+	}
+	s.values = values
+	return true
+}
+
+func (s *copySource) Values() ([]interface{}, error) {
+	return s.values, nil
+}
+
+func (s *copySource) Err() error {
+	return s.err
+}
+
+// dateValue stores a missing date as NULL
+func dateValue(d domain.CustomDate) interface{} {
+	if !d.Valid() {
+		return nil
+	}
+	return d.String()
+}
+
+// imdbRevisions records an import revision of every film or actor of the dataset table,
+// the matched ones have their snapshot from before the import in imdb_before
+func imdbRevisions(kind, dataset, column string) string {
+	snap := snapshots[kind]
+	return fmt.Sprintf(`INSERT INTO %[1]s(kind, record_id, action, before, after, user_id)
+		SELECT '%[2]s', %[4]s.id, '%[3]s', b.snapshot, %[5]s, NULLIF($1::int, 0) FROM %[6]s %[4]s
+		LEFT JOIN imdb_before b ON b.kind='%[2]s' AND b.id=%[4]s.id
+		WHERE %[4]s.id IN (SELECT %[7]s FROM %[8]s)`,
+		revisionsTable, kind, domain.ActionImport, snap.alias, snap.json, snap.table, column, dataset)
+}
+
+// imdbSnapshots keeps the snapshots of the matched films or actors before they are updated
+func imdbSnapshots(kind, dataset, column string) string {
+	snap := snapshots[kind]
+	return fmt.Sprintf(`INSERT INTO imdb_before SELECT '%[1]s', %[2]s.id, %[3]s FROM %[4]s %[2]s
+		WHERE %[2]s.id IN (SELECT %[5]s FROM %[6]s WHERE %[5]s IS NOT NULL)`,
+		kind, snap.alias, snap.json, snap.table, column, dataset)
+}
+
+// imdbStatements upsert the copied datasets. Records are found by their IMDb ids first, then films
+// by title and release date. New records get ids from the sequences up front, so that
+// their keys can be written in one statement. Every film and actor of the datasets gets an import
+// revision on behalf of the user passed as $1 to the statements that take it
+var imdbStatements = []struct {
+	query  string
+	result func(*domain.IMDbResult) *int64
+	user   bool
+}{
+	{query: `CREATE TEMP TABLE imdb_before (kind text, id int, snapshot jsonb) ON COMMIT DROP`},
+	{query: fmt.Sprintf(`UPDATE imdb_titles t SET film_id=f.id FROM %s k JOIN %s f ON f.id=k.record_id
+		AND f.deleted_at IS NULL WHERE k.kind='%s' AND k.source='%s' AND k.key=t.tconst`,
+		externalKeysTable, filmsTable, domain.KindFilm, domain.SourceIMDb)},
+	{query: fmt.Sprintf(`UPDATE imdb_titles t SET film_id=f.id FROM %s f WHERE t.film_id IS NULL
+		AND f.title=t.title AND f.released IS NOT DISTINCT FROM t.released AND f.deleted_at IS NULL`, filmsTable)},
+	{query: imdbSnapshots(domain.KindFilm, "imdb_titles", "film_id")},
+	{query: fmt.Sprintf(`UPDATE %s f SET title=t.title, released=t.released, runtime=t.runtime, genres=t.genres,
+		version=version+1 FROM imdb_titles t WHERE f.id=t.film_id`, filmsTable),
+		result: func(r *domain.IMDbResult) *int64 { return &r.FilmsUpdated }},
+	{query: fmt.Sprintf(`UPDATE imdb_titles SET film_id=nextval(pg_get_serial_sequence('%s', 'id'))
+		WHERE film_id IS NULL`, filmsTable),
+		result: func(r *domain.IMDbResult) *int64 { return &r.FilmsCreated }},
+	{query: fmt.Sprintf(`INSERT INTO %s(id, title, released, runtime, genres)
+		SELECT film_id, title, released, runtime, genres FROM imdb_titles t
+		WHERE NOT EXISTS(SELECT 1 FROM %[1]s f WHERE f.id=t.film_id)`, filmsTable)},
+	{query: fmt.Sprintf(`INSERT INTO %s(kind, source, key, record_id) SELECT '%s', '%s', tconst, film_id FROM imdb_titles
+		ON CONFLICT (kind, source, key) DO UPDATE SET record_id=EXCLUDED.record_id`,
+		externalKeysTable, domain.KindFilm, domain.SourceIMDb)},
+
+	{query: fmt.Sprintf(`UPDATE imdb_names n SET actor_id=a.id FROM %s k JOIN %s a ON a.id=k.record_id
+		AND a.deleted_at IS NULL WHERE k.kind='%s' AND k.source='%s' AND k.key=n.nconst`,
+		externalKeysTable, actorsTable, domain.KindActor, domain.SourceIMDb)},
+	{query: imdbSnapshots(domain.KindActor, "imdb_names", "actor_id")},
+	{query: fmt.Sprintf(`UPDATE %s a SET name=n.name, birthday=n.birthday, version=version+1
+		FROM imdb_names n WHERE a.id=n.actor_id`, actorsTable),
+		result: func(r *domain.IMDbResult) *int64 { return &r.ActorsUpdated }},
+	{query: fmt.Sprintf(`UPDATE imdb_names SET actor_id=nextval(pg_get_serial_sequence('%s', 'id'))
+		WHERE actor_id IS NULL`, actorsTable),
+		result: func(r *domain.IMDbResult) *int64 { return &r.ActorsCreated }},
+	{query: fmt.Sprintf(`INSERT INTO %s(id, name, birthday)
+		SELECT actor_id, name, birthday FROM imdb_names n
+		WHERE NOT EXISTS(SELECT 1 FROM %[1]s a WHERE a.id=n.actor_id)`, actorsTable)},
+	{query: fmt.Sprintf(`INSERT INTO %s(kind, source, key, record_id) SELECT '%s', '%s', nconst, actor_id FROM imdb_names
+		ON CONFLICT (kind, source, key) DO UPDATE SET record_id=EXCLUDED.record_id`,
+		externalKeysTable, domain.KindActor, domain.SourceIMDb)},
+
+	{query: fmt.Sprintf(`INSERT INTO %s(film_id, actor_id) SELECT DISTINCT t.film_id, n.actor_id
+		FROM imdb_principals p JOIN imdb_titles t ON t.tconst=p.tconst JOIN imdb_names n ON n.nconst=p.nconst
+		ON CONFLICT DO NOTHING`, filmsActorsTable),
+		result: func(r *domain.IMDbResult) *int64 { return &r.CastAdded }},
+
+	{query: imdbRevisions(domain.KindFilm, "imdb_titles", "film_id"), user: true},
+	{query: imdbRevisions(domain.KindActor, "imdb_names", "actor_id"), user: true},
+}
+
+// ImportIMDb copies the datasets into temporary tables and upserts films, actors and their links
+// from there in one transaction. The streams are read one after another: titles, principals, names
+func (r ImportPostgres) ImportIMDb(ctx context.Context, userId int, titles func() (domain.IMDbTitle, error),
+	principals func() (domain.IMDbPrincipal, error), names func() (domain.IMDbName, error)) (domain.IMDbResult, error) {
+	const method = "Import.Repository.ImportIMDb"
+	log := r.log.With(slog.String("method", method))
+
+	var result domain.IMDbResult
+	// COPY needs the pgx connection under database/sql
+	conn, err := stdlib.AcquireConn(r.db.DB)
+	if err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+	defer stdlib.ReleaseConn(r.db.DB, conn)
+
+	tx, err := conn.BeginEx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+	defer tx.Rollback()
+
+	tables := []struct {
+		name    string
+		schema  string
+		columns []string
+		source  *copySource
+	}{
+		{
+			name:    "imdb_titles",
+			schema:  "tconst text PRIMARY KEY, title text, released text, runtime int, genres text[], film_id int",
+			columns: []string{"tconst", "title", "released", "runtime", "genres"},
+			source: &copySource{next: func() ([]interface{}, error) {
+				t, err := titles()
+				return []interface{}{t.Tconst, t.Title, dateValue(t.Released), t.Runtime, t.Genres}, err
+			}},
+		},
+		{
+			name:    "imdb_principals",
+			schema:  "tconst text, nconst text",
+			columns: []string{"tconst", "nconst"},
+			source: &copySource{next: func() ([]interface{}, error) {
+				p, err := principals()
+				return []interface{}{p.Tconst, p.Nconst}, err
+			}},
+		},
+		{
+			name:    "imdb_names",
+			schema:  "nconst text PRIMARY KEY, name text, birthday text, actor_id int",
+			columns: []string{"nconst", "name", "birthday"},
+			source: &copySource{next: func() ([]interface{}, error) {
+				n, err := names()
+				return []interface{}{n.Nconst, n.Name, dateValue(n.Birthday)}, err
+			}},
+		},
+	}
+	copied := []*int{&result.Titles, &result.Principals, &result.Names}
+	for i, table := range tables {
+		_, err = tx.ExecEx(ctx, fmt.Sprintf(`CREATE TEMP TABLE %s (%s) ON COMMIT DROP`, table.name, table.schema), nil)
+		if err == nil {
+			*copied[i], err = tx.CopyFrom(pgx.Identifier{table.name}, table.columns, table.source)
+		}
+		if err == nil {
+			_, err = tx.ExecEx(ctx, `ANALYZE `+table.name, nil)
+		}
+		if table.source.err != nil {
+			// the dataset is broken, not the database
+			return result, table.source.err
+		}
+		if err != nil {
+			log.With(slog.String("table", table.name)).Error(err.Error())
+			return result, ErrInternal
+		}
+		log.With(slog.String("table", table.name), slog.Int("rows", *copied[i])).Info("dataset copied")
+	}
+
+	for _, stmt := range imdbStatements {
+		var args []interface{}
+		if stmt.user {
+			args = append(args, userId)
+		}
+		tag, err := tx.ExecEx(ctx, stmt.query, nil, args...)
+		if err != nil {
+			log.Error(err.Error())
+			return result, mapConstraintError(err)
+		}
+		if stmt.result != nil {
+			*stmt.result(&result) = tag.RowsAffected()
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+	return result, nil
+}
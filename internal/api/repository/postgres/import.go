@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"log/slog"
+	"strings"
+)
+
+type ImportPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewImportPostgres(db *sqlx.DB, log *slog.Logger) *ImportPostgres {
+	return &ImportPostgres{db: db, log: log}
+}
+
+// castError is a cast reference that matches no live actor
+type castError struct {
+	ref domain.CastRef
+}
+
+func (e castError) Error() string {
+	if e.ref.Key != "" {
+		return fmt.Sprintf("cast member with key %q not found", e.ref.Key)
+	}
+	if e.ref.Birthday.Valid() {
+		return fmt.Sprintf("cast member %q born %s not found", e.ref.Name, e.ref.Birthday)
+	}
+	return fmt.Sprintf("cast member %q not found", e.ref.Name)
+}
+
+// importRow is one record to write, upsert tells whether it created a new record
+type importRow struct {
+	row    int
+	key    string
+	upsert func(tx *sqlx.Tx) (bool, error)
+}
+
+// importErrMessage explains a failed row, unexpected errors are reported as not ok
+func importErrMessage(err error) (string, bool) {
+	var cErr castError
+	if errors.As(err, &cErr) {
+		return cErr.Error(), true
+	}
+	switch mapConstraintError(err) {
+	case ErrUnique:
+		return "conflicts with an existing record", true
+	case ErrForeign:
+		return "references a missing record", true
+	default:
+		return "couldn't save the row", false
+	}
+}
+
+// run writes the rows in one transaction, each behind its own savepoint, so that a failed row
+// doesn't abort the rest and every error gets reported. The transaction is committed unless
+// it's a dry run or an atomic import with failed rows
+func (r ImportPostgres) run(ctx context.Context, opts domain.ImportOptions, rows []importRow) (domain.ImportResult, error) {
+	const method = "Import.Repository.run"
+	log := r.log.With(slog.String("method", method))
+
+	result := domain.ImportResult{Rows: len(rows), Errors: make([]domain.ImportRowError, 0)}
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		if _, err = tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+			log.Error(err.Error())
+			return result, ErrInternal
+		}
+		created, err := row.upsert(tx)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT import_row`); rbErr != nil {
+				log.Error(rbErr.Error())
+				return result, ErrInternal
+			}
+			message, ok := importErrMessage(err)
+			if !ok {
+				log.With(slog.Int("row", row.row)).Error(err.Error())
+			}
+			result.Failed++
+			result.Errors = append(result.Errors, domain.ImportRowError{Row: row.row, Key: row.key, Message: message})
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	if opts.DryRun || (opts.Mode == domain.ImportAtomic && result.Failed > 0) {
+		return result, nil
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+	result.Committed = true
+	return result, nil
+}
+
+// keyedRecord finds a live film or actor by its import key
+func (r ImportPostgres) keyedRecord(ctx context.Context, tx *sqlx.Tx, kind, table, key string) (int, error) {
+	var id int
+	query := fmt.Sprintf(`SELECT t.id FROM %s k JOIN %s t ON t.id = k.record_id AND t.deleted_at IS NULL
+		WHERE k.kind=$1 AND k.source=$2 AND k.key=$3`, externalKeysTable, table)
+	err := tx.GetContext(ctx, &id, query, kind, domain.SourceImport, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// setKey points the import key at the record, taking it over from a purged or merged one
+func (r ImportPostgres) setKey(ctx context.Context, tx *sqlx.Tx, kind, key string, id int) error {
+	if key == "" {
+		return nil
+	}
+	query := fmt.Sprintf(`INSERT INTO %s(kind, source, key, record_id) VALUES($1,$2,$3,$4)
+		ON CONFLICT (kind, source, key) DO UPDATE SET record_id=EXCLUDED.record_id`, externalKeysTable)
+	_, err := tx.ExecContext(ctx, query, kind, domain.SourceImport, key, id)
+	return err
+}
+
+// matchActor finds the live actor for a record: by the key first, then by name and birthday
+func (r ImportPostgres) matchActor(ctx context.Context, tx *sqlx.Tx, key, name string, birthday domain.CustomDate) (int, error) {
+	if key != "" {
+		id, err := r.keyedRecord(ctx, tx, domain.KindActor, actorsTable, key)
+		if err != nil || id != 0 || name == "" {
+			return id, err
+		}
+	}
+	var id int
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE name=$1 AND birthday IS NOT DISTINCT FROM $2
+		AND deleted_at IS NULL ORDER BY id LIMIT 1`, actorsTable)
+	err := tx.GetContext(ctx, &id, query, name, birthday)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// upsertActor creates or updates the actor of the record and records a revision of it
+func (r ImportPostgres) upsertActor(ctx context.Context, tx *sqlx.Tx, userId int, rec domain.ActorRecord) (bool, error) {
+	id, err := r.matchActor(ctx, tx, rec.Key, rec.Name, rec.Birthday)
+	if err != nil {
+		return false, err
+	}
+
+	var before types.JSONText
+	created := id == 0
+	if !created {
+		if before, err = takeSnapshot(ctx, tx, domain.KindActor, id); err != nil {
+			return false, err
+		}
+	}
+	if created {
+		query := fmt.Sprintf(`INSERT INTO %s(name, gender, birthday) VALUES($1,$2,$3) RETURNING id`, actorsTable)
+		err = tx.GetContext(ctx, &id, query, rec.Name, rec.Gender, rec.Birthday)
+	} else {
+		query := fmt.Sprintf(`UPDATE %s SET name=$1, gender=$2, birthday=$3, version=version+1 WHERE id=$4`,
+			actorsTable)
+		_, err = tx.ExecContext(ctx, query, rec.Name, rec.Gender, rec.Birthday, id)
+	}
+	if err == nil {
+		err = r.setKey(ctx, tx, domain.KindActor, rec.Key, id)
+	}
+	if err == nil {
+		err = insertRevision(ctx, tx, domain.KindActor, domain.ActionImport, userId, id, before)
+	}
+	return created, err
+}
+
+// ImportActors upserts the actors, each one gets an import revision on behalf of userId
+func (r ImportPostgres) ImportActors(ctx context.Context, userId int, records []domain.ActorRecord,
+	opts domain.ImportOptions) (domain.ImportResult, error) {
+	rows := make([]importRow, len(records))
+	for i, rec := range records {
+		rows[i] = importRow{row: rec.Row, key: rec.Key, upsert: func(tx *sqlx.Tx) (bool, error) {
+			return r.upsertActor(ctx, tx, userId, rec)
+		}}
+	}
+	return r.run(ctx, opts, rows)
+}
+
+// matchFilm finds the live film for a record: by the key first, then by title and release date
+func (r ImportPostgres) matchFilm(ctx context.Context, tx *sqlx.Tx, rec domain.FilmRecord) (int, error) {
+	if rec.Key != "" {
+		id, err := r.keyedRecord(ctx, tx, domain.KindFilm, filmsTable, rec.Key)
+		if err != nil || id != 0 {
+			return id, err
+		}
+	}
+	var id int
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE title=$1 AND released IS NOT DISTINCT FROM $2
+		AND deleted_at IS NULL ORDER BY id LIMIT 1`, filmsTable)
+	err := tx.GetContext(ctx, &id, query, rec.Title, rec.Released)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// castIds resolves the cast references into actor ids, without repeats
+func (r ImportPostgres) castIds(ctx context.Context, tx *sqlx.Tx, cast []domain.CastRef) ([]int, error) {
+	ids := make([]int, 0, len(cast))
+	seen := make(map[int]struct{}, len(cast))
+	for _, ref := range cast {
+		id, err := r.matchActor(ctx, tx, ref.Key, ref.Name, ref.Birthday)
+		if err != nil {
+			return nil, err
+		}
+		if id == 0 {
+			return nil, castError{ref: ref}
+		}
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// upsertFilm creates or updates the film of the record and records a revision of it
+func (r ImportPostgres) upsertFilm(ctx context.Context, tx *sqlx.Tx, userId int, rec domain.FilmRecord) (bool, error) {
+	id, err := r.matchFilm(ctx, tx, rec)
+	if err != nil {
+		return false, err
+	}
+	var actorIds []int
+	if rec.Cast != nil {
+		if actorIds, err = r.castIds(ctx, tx, rec.Cast); err != nil {
+			return false, err
+		}
+	}
+
+	film := rec.Film
+	args := []interface{}{film.Title, film.Description, film.Released, film.Rating, film.Runtime, film.Countries,
+		film.OriginalLanguage, film.Languages, film.AgeRating, film.Budget, film.BoxOffice, film.Genres}
+	var before types.JSONText
+	created := id == 0
+	if !created {
+		if before, err = takeSnapshot(ctx, tx, domain.KindFilm, id); err != nil {
+			return false, err
+		}
+	}
+	if created {
+		query := fmt.Sprintf(`INSERT INTO %s(title, description, released, rating, runtime, countries,
+			original_language, languages, age_rating, budget, box_office, genres)
+			VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id`, filmsTable)
+		err = tx.GetContext(ctx, &id, query, args...)
+	} else {
+		query := fmt.Sprintf(`UPDATE %s SET title=$1, description=$2, released=$3, rating=$4, runtime=$5,
+			countries=$6, original_language=$7, languages=$8, age_rating=$9, budget=$10, box_office=$11,
+			genres=$12, version=version+1 WHERE id=$13`, filmsTable)
+		_, err = tx.ExecContext(ctx, query, append(args, id)...)
+	}
+	if err == nil {
+		err = r.setKey(ctx, tx, domain.KindFilm, rec.Key, id)
+	}
+	if err == nil && rec.Cast != nil {
+		err = r.replaceCast(ctx, tx, id, actorIds)
+	}
+	if err == nil {
+		err = insertRevision(ctx, tx, domain.KindFilm, domain.ActionImport, userId, id, before)
+	}
+	return created, err
+}
+
+func (r ImportPostgres) replaceCast(ctx context.Context, tx *sqlx.Tx, filmId int, actorIds []int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE film_id=$1`, filmsActorsTable)
+	if _, err := tx.ExecContext(ctx, query, filmId); err != nil {
+		return err
+	}
+	if len(actorIds) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(actorIds))
+	args := make([]interface{}, 0, len(actorIds)+1)
+	args = append(args, filmId)
+	for i, actorId := range actorIds {
+		values[i] = fmt.Sprintf("($1,$%d)", i+2)
+		args = append(args, actorId)
+	}
+	query = fmt.Sprintf(`INSERT INTO %s(film_id, actor_id) VALUES %s`, filmsActorsTable, strings.Join(values, ","))
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// ImportFilms upserts the films with their cast, each one gets an import revision on behalf of userId
+func (r ImportPostgres) ImportFilms(ctx context.Context, userId int, records []domain.FilmRecord,
+	opts domain.ImportOptions) (domain.ImportResult, error) {
+	rows := make([]importRow, len(records))
+	for i, rec := range records {
+		rows[i] = importRow{row: rec.Row, key: rec.Key, upsert: func(tx *sqlx.Tx) (bool, error) {
+			return r.upsertFilm(ctx, tx, userId, rec)
+		}}
+	}
+	return r.run(ctx, opts, rows)
+}
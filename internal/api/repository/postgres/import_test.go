@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func prepareImportTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *ImportPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewImportPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestImportPostgres_ImportActors(t *testing.T) {
+	mock, dbx, r := prepareImportTest(t)
+	defer dbx.Close()
+
+	records := []domain.ActorRecord{{Row: 2, Key: "nm0091788", Actor: domain.Actor{Name: "Sergei Bodrov"}}}
+	t.Run("Create", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT t.id FROM %s k JOIN %s t`, externalKeysTable, actorsTable)).
+			WithArgs(domain.KindActor, domain.SourceImport, "nm0091788").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE name=\$1`, actorsTable)).
+			WithArgs("Sergei Bodrov", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s\(name, gender, birthday\)`, actorsTable)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s\(kind, source, key, record_id\)`, externalKeysTable)).
+			WithArgs(domain.KindActor, domain.SourceImport, "nm0091788", 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s\(kind, record_id, action, before, after, user_id\)
+		SELECT \$1::text, a.id, \$2::text, NULLIF\(\$3::jsonb, 'null'::jsonb\), jsonb_build_object`, revisionsTable)).
+			WithArgs(domain.KindActor, domain.ActionImport, []byte("null"), 3, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		got, err := r.ImportActors(context.Background(), 3, records, domain.ImportOptions{Mode: domain.ImportAtomic})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got.Created)
+		assert.True(t, got.Committed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("DryRun", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT t.id FROM`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		before := `{"id": 7, "name": "Bodrov Sergei"}`
+		mock.ExpectQuery(fmt.Sprintf(`SELECT jsonb_build_object\(.+\) FROM %s a WHERE a.id=\$1`, actorsTable)).
+			WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow(before))
+		mock.ExpectExec(fmt.Sprintf(`UPDATE %s SET name=\$1, gender=\$2, birthday=\$3, version=version\+1`, actorsTable)).
+			WithArgs("Sergei Bodrov", nil, nil, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO external_keys`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, revisionsTable)).
+			WithArgs(domain.KindActor, domain.ActionImport, []byte(before), 3, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		got, err := r.ImportActors(context.Background(), 3, records, domain.ImportOptions{DryRun: true, Mode: domain.ImportAtomic})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, got.Updated)
+		assert.False(t, got.Committed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestImportPostgres_ImportFilms(t *testing.T) {
+	mock, dbx, r := prepareImportTest(t)
+	defer dbx.Close()
+
+	records := []domain.FilmRecord{{Row: 2, Film: domain.Film{Title: "Brother"},
+		Cast: []domain.CastRef{{Key: "nm0091788"}}}}
+	for _, mode := range []string{domain.ImportAtomic, domain.ImportBestEffort} {
+		t.Run("UnknownCast_"+mode, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(`SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE title=\$1`, filmsTable)).
+				WithArgs("Brother", nil).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectQuery(`SELECT t.id FROM`).
+				WithArgs(domain.KindActor, domain.SourceImport, "nm0091788").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectExec(`ROLLBACK TO SAVEPOINT import_row`).WillReturnResult(sqlmock.NewResult(0, 0))
+			if mode == domain.ImportAtomic {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectCommit()
+			}
+
+			got, err := r.ImportFilms(context.Background(), 3, records, domain.ImportOptions{Mode: mode})
+			assert.NoError(t, err)
+			assert.Equal(t, 1, got.Failed)
+			assert.Equal(t, mode == domain.ImportBestEffort, got.Committed)
+			assert.Equal(t, []domain.ImportRowError{{Row: 2, Message: `cast member with key "nm0091788" not found`}},
+				got.Errors)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestImdbStatements(t *testing.T) {
+	// the matched records are snapshotted before the updates, and the revisions written last
+	// carry the importing user
+	position := func(substr string) int {
+		for i, stmt := range imdbStatements {
+			if strings.Contains(stmt.query, substr) {
+				return i
+			}
+		}
+		t.Fatalf("no statement contains %q", substr)
+		return -1
+	}
+	assert.Less(t, position(fmt.Sprintf("INSERT INTO imdb_before SELECT '%s'", domain.KindFilm)),
+		position(fmt.Sprintf("UPDATE %s f SET", filmsTable)))
+	assert.Less(t, position(fmt.Sprintf("INSERT INTO imdb_before SELECT '%s'", domain.KindActor)),
+		position(fmt.Sprintf("UPDATE %s a SET", actorsTable)))
+
+	for _, stmt := range imdbStatements {
+		assert.Equal(t, strings.Contains(stmt.query, "$1"), stmt.user, stmt.query)
+	}
+	for _, stmt := range imdbStatements[len(imdbStatements)-2:] {
+		assert.Contains(t, stmt.query, fmt.Sprintf("INSERT INTO %s", revisionsTable))
+	}
+}
@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"strings"
+)
+
+// reference is a table column pointing to a merged record. keys are the other columns
+// of the table's unique key: rows that would collide with the survivor are left to cascade
+type reference struct {
+	table  string
+	column string
+	keys   []string
+	// distinct is a column that must not become equal to the survivor (self relations)
+	distinct string
+	// kind limits the tables shared by films and actors to the rows of the merged kind
+	kind string
+}
+
+var actorReferences = []reference{
+	{table: filmsActorsTable, column: "actor_id", keys: []string{"film_id"}},
+	{table: seriesActorsTable, column: "actor_id", keys: []string{"series_id"}},
+	{table: episodesActorsTable, column: "actor_id", keys: []string{"episode_id"}},
+	{table: actorsTranslationsTable, column: "actor_id", keys: []string{"lang"}},
+	{table: externalKeysTable, column: "record_id", kind: domain.KindActor},
+}
+
+var filmReferences = []reference{
+	{table: filmsActorsTable, column: "film_id", keys: []string{"actor_id"}},
+	{table: collectionsItemsTable, column: "film_id", keys: []string{"collection_id"}},
+	{table: copiesTable, column: "film_id"},
+	{table: usersFilmsTable, column: "film_id", keys: []string{"user_id"}},
+	{table: filmsTranslationsTable, column: "film_id", keys: []string{"lang"}},
+	{table: filmsAliasesTable, column: "film_id", keys: []string{"title"}},
+	{table: filmsRelationsTable, column: "film_id", keys: []string{"related_id", "kind"}, distinct: "related_id"},
+	{table: filmsRelationsTable, column: "related_id", keys: []string{"film_id", "kind"}, distinct: "film_id"},
+	{table: franchisesFilmsTable, column: "film_id", keys: []string{"franchise_id"}},
+	{table: externalKeysTable, column: "record_id", kind: domain.KindFilm},
+}
+
+func (ref reference) repoint(ctx context.Context, tx *sqlx.Tx, survivorId, mergedId int) error {
+	query := fmt.Sprintf(`UPDATE %[1]s t SET %[2]s=$1 WHERE t.%[2]s=$2`, ref.table, ref.column)
+	if len(ref.keys) > 0 {
+		conds := make([]string, 0, len(ref.keys))
+		for _, key := range ref.keys {
+			conds = append(conds, fmt.Sprintf("o.%[1]s = t.%[1]s", key))
+		}
+		query += fmt.Sprintf(` AND NOT EXISTS (SELECT 1 FROM %s o WHERE o.%s=$1 AND %s)`,
+			ref.table, ref.column, strings.Join(conds, " AND "))
+	}
+	if ref.distinct != "" {
+		query += fmt.Sprintf(` AND t.%s <> $1`, ref.distinct)
+	}
+	if ref.kind != "" {
+		query += fmt.Sprintf(` AND t.kind='%s'`, ref.kind)
+	}
+
+	_, err := tx.ExecContext(ctx, query, survivorId, mergedId)
+	return err
+}
+
+// lockRecord locks a record taking part in a merge. Trashed records can't be merged
+func lockRecord(ctx context.Context, tx *sqlx.Tx, table string, id int) error {
+	var locked int
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id=$1 AND deleted_at IS NULL FOR UPDATE`, table)
+	err := tx.GetContext(ctx, &locked, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoRows
+	}
+	return err
+}
+
+// rejectMerged rejects the pending suggestions for a merged record, they were written against its data
+func rejectMerged(ctx context.Context, tx *sqlx.Tx, kind string, userId, survivorId, mergedId int) error {
+	query := fmt.Sprintf(`UPDATE %s SET status=$1, reviewer_id=NULLIF($2, 0), reason=$3, reviewed_at=now()
+		WHERE kind=$4 AND record_id=$5 AND status=$6`, suggestionsTable)
+	_, err := tx.ExecContext(ctx, query, domain.SuggestionRejected, userId,
+		fmt.Sprintf("merged into %s %d", kind, survivorId), kind, mergedId, domain.SuggestionPending)
+	return err
+}
+
+type MergePostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewMergePostgres(db *sqlx.DB, log *slog.Logger) *MergePostgres {
+	return &MergePostgres{db: db, log: log}
+}
+
+func (r MergePostgres) MergeActors(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	return r.merge(ctx, domain.KindActor, actorsTable, actorReferences, userId, survivorId, duplicateIds)
+}
+
+func (r MergePostgres) MergeFilms(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	return r.merge(ctx, domain.KindFilm, filmsTable, filmReferences, userId, survivorId, duplicateIds)
+}
+
+// merge moves references of every duplicate to the survivor, deletes the duplicate and records its snapshot.
+// The survivor and every duplicate get a merge revision on behalf of userId. Revisions and suggestions
+// stay with the duplicate's id, its pending suggestions are rejected.
+// All duplicates are merged in one transaction, none of them or the survivor may be in the trash
+func (r MergePostgres) merge(ctx context.Context, kind, table string, refs []reference, userId, survivorId int,
+	duplicateIds []int) ([]domain.Merge, error) {
+	const method = "Merges.Repository.merge"
+	log := r.log.With(slog.String("method", method), slog.String("kind", kind))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	if err = lockRecord(ctx, tx, table, survivorId); err != nil {
+		tx.Rollback()
+		if errors.Is(err, ErrNoRows) {
+			return nil, ErrNoRows
+		}
+		log.Error(err.Error())
+		return nil, ErrInternal
+	}
+	survivorBefore, err := takeSnapshot(ctx, tx, kind, survivorId)
+	if err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return nil, ErrInternal
+	}
+
+	merges := make([]domain.Merge, 0, len(duplicateIds))
+	for _, mergedId := range duplicateIds {
+		if err = lockRecord(ctx, tx, table, mergedId); err != nil {
+			tx.Rollback()
+			if errors.Is(err, ErrNoRows) {
+				return nil, ErrNoRows
+			}
+			log.Error(err.Error())
+			return nil, ErrInternal
+		}
+		before, err := takeSnapshot(ctx, tx, kind, mergedId)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNoRows
+			}
+			log.Error(err.Error())
+			return nil, ErrInternal
+		}
+		for _, ref := range refs {
+			if err = ref.repoint(ctx, tx, survivorId, mergedId); err != nil {
+				log.Error(err.Error())
+				tx.Rollback()
+				return nil, ErrInternal
+			}
+		}
+		if err = rejectMerged(ctx, tx, kind, userId, survivorId, mergedId); err != nil {
+			log.Error(err.Error())
+			tx.Rollback()
+			return nil, ErrInternal
+		}
+
+		var snapshot string
+		err = tx.GetContext(ctx, &snapshot, fmt.Sprintf(`DELETE FROM %[1]s WHERE id=$1 RETURNING row_to_json(%[1]s.*)`, table),
+			mergedId)
+		if err != nil {
+			tx.Rollback()
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNoRows
+			}
+			log.Error(err.Error())
+			return nil, ErrInternal
+		}
+
+		var merge domain.Merge
+		query := fmt.Sprintf(`INSERT INTO %s(kind, survivor_id, merged_id, snapshot, user_id)
+			VALUES($1,$2,$3,$4,$5) RETURNING *`, mergesTable)
+		if err = tx.QueryRowxContext(ctx, query, kind, survivorId, mergedId, snapshot, userId).StructScan(&merge); err != nil {
+			log.Error(err.Error())
+			tx.Rollback()
+			return nil, ErrInternal
+		}
+		merges = append(merges, merge)
+
+		// the duplicate is gone, its last state stays in the revision with a pointer to the survivor
+		query = fmt.Sprintf(`INSERT INTO %s(kind, record_id, action, before, after, user_id)
+			VALUES($1,$2,$3,$4::jsonb,$4::jsonb || jsonb_build_object('mergedInto', $5::int),NULLIF($6, 0))`,
+			revisionsTable)
+		if _, err = tx.ExecContext(ctx, query, kind, mergedId, domain.ActionMerge, before, survivorId, userId); err != nil {
+			log.Error(err.Error())
+			tx.Rollback()
+			return nil, ErrInternal
+		}
+	}
+
+	if err = insertRevision(ctx, tx, kind, domain.ActionMerge, userId, survivorId, survivorBefore); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return nil, ErrInternal
+	}
+	return merges, tx.Commit()
+}
+
+func (r MergePostgres) ListMerges(ctx context.Context) ([]domain.Merge, error) {
+	var merges []domain.Merge
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY merged_at DESC, id DESC`, mergesTable)
+	err := r.db.SelectContext(ctx, &merges, query)
+
+	return merges, err
+}
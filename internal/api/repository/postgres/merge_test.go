@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareMergeTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *MergePostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewMergePostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestMergePostgres_MergeActors(t *testing.T) {
+	mock, dbx, r := prepareMergeTest(t)
+	defer dbx.Close()
+
+	t.Run("RepointAndRecord", func(t *testing.T) {
+		snapshot := `{"id": 2, "name": "Bodrov Sergei"}`
+		survivor := `{"id": 1, "name": "Sergei Bodrov"}`
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 AND deleted_at IS NULL FOR UPDATE`, actorsTable)).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT jsonb_build_object`).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow(survivor))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 AND deleted_at IS NULL FOR UPDATE`, actorsTable)).
+			WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectQuery(`SELECT jsonb_build_object`).WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow(snapshot))
+		mock.ExpectExec(fmt.Sprintf(`UPDATE %[1]s t SET actor_id=\$1 WHERE t.actor_id=\$2 AND NOT EXISTS `+
+			`\(SELECT 1 FROM %[1]s o WHERE o.actor_id=\$1 AND o.film_id = t.film_id\)`, filmsActorsTable)).
+			WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 3))
+		for _, ref := range actorReferences[1:] {
+			mock.ExpectExec(fmt.Sprintf(`UPDATE %s t`, ref.table)).WithArgs(1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(fmt.Sprintf(`UPDATE %s SET status=\$1`, suggestionsTable)).
+			WithArgs(domain.SuggestionRejected, 7, "merged into actor 1", domain.KindActor, 2, domain.SuggestionPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(fmt.Sprintf(`DELETE FROM %s WHERE id=\$1 RETURNING`, actorsTable)).WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"row_to_json"}).AddRow(snapshot))
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, mergesTable)).
+			WithArgs(domain.KindActor, 1, 2, snapshot, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "survivor_id", "merged_id", "snapshot", "user_id",
+				"merged_at"}).AddRow(1, domain.KindActor, 1, 2, []byte(snapshot), 7, time.Now()))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s.+jsonb_build_object\('mergedInto', \$5::int\)`, revisionsTable)).
+			WithArgs(domain.KindActor, 2, domain.ActionMerge, []byte(snapshot), 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s.+SELECT \$1::text, a.id`, revisionsTable)).
+			WithArgs(domain.KindActor, domain.ActionMerge, []byte(survivor), 7, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		got, err := r.MergeActors(context.Background(), 7, 1, []int{2})
+		assert.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, 2, got[0].MergedId)
+			assert.JSONEq(t, snapshot, string(got[0].Snapshot))
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("TrashedSurvivor", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 AND deleted_at IS NULL FOR UPDATE`, actorsTable)).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, err := r.MergeActors(context.Background(), 7, 1, []int{2})
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MissingOrTrashedDuplicate", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 AND deleted_at IS NULL FOR UPDATE`, actorsTable)).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT jsonb_build_object`).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"snapshot"}).AddRow(`{"id": 1}`))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 AND deleted_at IS NULL FOR UPDATE`, actorsTable)).
+			WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+
+		_, err := r.MergeActors(context.Background(), 7, 1, []int{3})
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReference_repoint(t *testing.T) {
+	mock, dbx, _ := prepareMergeTest(t)
+	defer dbx.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(fmt.Sprintf(`UPDATE %[1]s t SET record_id=\$1 WHERE t.record_id=\$2 AND t.kind='%[2]s'`,
+		externalKeysTable, domain.KindActor)).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := dbx.Beginx()
+	assert.NoError(t, err)
+	ref := reference{table: externalKeysTable, column: "record_id", kind: domain.KindActor}
+	assert.NoError(t, ref.repoint(context.Background(), tx, 1, 2))
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// the import and IMDb keys follow the survivor, history and suggestions stay with the merged id
+	for _, refs := range [][]reference{actorReferences, filmReferences} {
+		tables := make([]string, 0, len(refs))
+		for _, ref := range refs {
+			tables = append(tables, ref.table)
+		}
+		assert.Contains(t, tables, externalKeysTable)
+		assert.NotContains(t, tables, revisionsTable)
+		assert.NotContains(t, tables, suggestionsTable)
+	}
+}
@@ -1,8 +1,9 @@
 package postgres
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	_ "github.com/jackc/pgx/stdlib"
 	"github.com/jmoiron/sqlx"
 )
@@ -12,14 +13,66 @@ const (
 	actorsTable      = "actors"
 	filmsTable       = "films"
 	filmsActorsTable = "films_actors"
+
+	collectionsTable          = "collections"
+	collectionsItemsTable     = "collections_items"
+	collectionsFollowersTable = "collections_followers"
+
+	copiesTable = "copies"
+	loansTable  = "loans"
+
+	usersFilmsTable = "users_films"
+
+	seriesTable         = "series"
+	seasonsTable        = "seasons"
+	episodesTable       = "episodes"
+	seriesActorsTable   = "series_actors"
+	episodesActorsTable = "episodes_actors"
+
+	filmsTranslationsTable  = "films_translations"
+	actorsTranslationsTable = "actors_translations"
+
+	filmsAliasesTable    = "films_aliases"
+	filmsRelationsTable  = "films_relations"
+	franchisesTable      = "franchises"
+	franchisesFilmsTable = "franchises_films"
+
+	mergesTable    = "merges"
+	revisionsTable = "revisions"
+
+	auditEventsTable  = "audit_events"
+	suggestionsTable  = "suggestions"
+	externalKeysTable = "external_keys"
 )
 
 var (
-	ErrUnique   = errors.New("unique costraint violation")
-	ErrNoRows   = errors.New("no rows in relation")
-	ErrInternal = errors.New("internal error")
+	ErrUnique   = apperr.New(apperr.CodeConflict, "unique costraint violation")
+	ErrNoRows   = apperr.New(apperr.CodeNotFound, "no rows in relation")
+	ErrInternal = apperr.New(apperr.CodeInternal, "internal error")
+	ErrForeign  = apperr.New(apperr.CodeUnprocessable, "foreign key constraint violation")
+	ErrVersion  = apperr.New(apperr.CodePrecondition, "row version mismatch")
+	ErrReviewed = apperr.New(apperr.CodeConflict, "suggestion is not pending")
 )
 
+// versionCond matches a live (not soft deleted) row by the id in argument argId and,
+// unless the expected version in the next argument is 0, by version
+func versionCond(argId int) string {
+	return fmt.Sprintf("id=$%[1]d AND deleted_at IS NULL AND ($%[2]d = 0 OR version=$%[2]d)", argId, argId+1)
+}
+
+// missingOrStale tells why a versioned write touched no rows: the row is gone or it has been changed since
+func missingOrStale(ctx context.Context, q sqlx.QueryerContext, table string, id int) error {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE id=$1 AND deleted_at IS NULL)`, table)
+	if err := sqlx.GetContext(ctx, q, &exists, query, id); err != nil {
+		return ErrInternal
+	}
+	if exists {
+		return ErrVersion
+	}
+	return ErrNoRows
+}
+
 type Config struct {
 	Host     string
 	Port     string
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+)
+
+type RelationPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewRelationPostgres(db *sqlx.DB, log *slog.Logger) *RelationPostgres {
+	return &RelationPostgres{db: db, log: log}
+}
+
+func (r RelationPostgres) CreateAlias(ctx context.Context, alias domain.FilmAlias) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(film_id, title, kind, region) VALUES($1,$2,$3,$4) RETURNING id`,
+		filmsAliasesTable)
+	row := r.db.QueryRowxContext(ctx, query, alias.FilmId, alias.Title, alias.Kind, alias.Region)
+	if err := row.Scan(&id); err != nil {
+		r.log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+
+	return id, nil
+}
+
+func (r RelationPostgres) ListAliases(ctx context.Context, filmId int) ([]domain.FilmAlias, error) {
+	var aliases []domain.FilmAlias
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE film_id=$1 ORDER BY kind, title`, filmsAliasesTable)
+	err := r.db.SelectContext(ctx, &aliases, query, filmId)
+
+	return aliases, err
+}
+
+func (r RelationPostgres) DeleteAlias(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, filmsAliasesTable)
+	return r.exec(ctx, query, id)
+}
+
+func (r RelationPostgres) CreateRelation(ctx context.Context, relation domain.FilmRelation) error {
+	query := fmt.Sprintf(`INSERT INTO %s(film_id, related_id, kind) VALUES($1,$2,$3)`, filmsRelationsTable)
+	_, err := r.db.ExecContext(ctx, query, relation.FilmId, relation.RelatedId, relation.Kind)
+	if err != nil {
+		r.log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+// ListRelations returns relations in both directions: where the film is the subject or the related film
+func (r RelationPostgres) ListRelations(ctx context.Context, filmId int) ([]domain.FilmRelation, error) {
+	var relations []domain.FilmRelation
+	query := fmt.Sprintf(`SELECT fr.film_id, f.title AS film_title, fr.related_id, rf.title AS related_title, fr.kind
+		FROM %[1]s fr INNER JOIN %[2]s f ON f.id = fr.film_id INNER JOIN %[2]s rf ON rf.id = fr.related_id
+		WHERE (fr.film_id=$1 OR fr.related_id=$1) AND f.deleted_at IS NULL AND rf.deleted_at IS NULL
+		ORDER BY fr.kind, f.released, rf.released`,
+		filmsRelationsTable, filmsTable)
+	err := r.db.SelectContext(ctx, &relations, query, filmId)
+
+	return relations, err
+}
+
+func (r RelationPostgres) DeleteRelation(ctx context.Context, relation domain.FilmRelation) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE film_id=$1 AND related_id=$2 AND kind=$3`, filmsRelationsTable)
+	return r.exec(ctx, query, relation.FilmId, relation.RelatedId, relation.Kind)
+}
+
+func (r RelationPostgres) CreateFranchise(ctx context.Context, franchise domain.Franchise) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(title, description) VALUES($1,$2) RETURNING id`, franchisesTable)
+	row := r.db.QueryRowxContext(ctx, query, franchise.Title, franchise.Description)
+	if err := row.Scan(&id); err != nil {
+		r.log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+
+	return id, nil
+}
+
+func (r RelationPostgres) GetFranchise(ctx context.Context, id int) (domain.Franchise, error) {
+	const method = "Relations.Repository.GetFranchise"
+	log := r.log.With(slog.String("method", method))
+
+	var franchise domain.Franchise
+	err := r.db.GetContext(ctx, &franchise, fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, franchisesTable), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return franchise, ErrNoRows
+		}
+		log.Error(err.Error())
+		return franchise, ErrInternal
+	}
+
+	query := fmt.Sprintf(`SELECT ff.position, f.* FROM %s ff INNER JOIN %s f ON f.id = ff.film_id
+		WHERE ff.franchise_id=$1 AND f.deleted_at IS NULL ORDER BY ff.position`, franchisesFilmsTable, filmsTable)
+	if err = r.db.SelectContext(ctx, &franchise.Films, query, id); err != nil {
+		log.Error(err.Error())
+		return franchise, ErrInternal
+	}
+
+	return franchise, nil
+}
+
+func (r RelationPostgres) ListFranchises(ctx context.Context) ([]domain.Franchise, error) {
+	var franchises []domain.Franchise
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY title`, franchisesTable)
+	err := r.db.SelectContext(ctx, &franchises, query)
+
+	return franchises, err
+}
+
+func (r RelationPostgres) DeleteFranchise(ctx context.Context, id int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, franchisesTable)
+	return r.exec(ctx, query, id)
+}
+
+func (r RelationPostgres) SetFranchiseFilm(ctx context.Context, franchiseId, filmId, position int) error {
+	query := fmt.Sprintf(`INSERT INTO %s(franchise_id, film_id, position) VALUES($1,$2,$3)
+		ON CONFLICT (franchise_id, film_id) DO UPDATE SET position=EXCLUDED.position`, franchisesFilmsTable)
+	_, err := r.db.ExecContext(ctx, query, franchiseId, filmId, position)
+	if err != nil {
+		r.log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+func (r RelationPostgres) RemoveFranchiseFilm(ctx context.Context, franchiseId, filmId int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE franchise_id=$1 AND film_id=$2`, franchisesFilmsTable)
+	return r.exec(ctx, query, franchiseId, filmId)
+}
+
+func (r RelationPostgres) exec(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
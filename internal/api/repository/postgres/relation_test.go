@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareRelationTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *RelationPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewRelationPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestRelationPostgres_GetFranchise(t *testing.T) {
+	mock, dbx, r := prepareRelationTest(t)
+	defer dbx.Close()
+
+	t.Run("FilmsInOrder", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE id=\$1`, franchisesTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description"}).AddRow(1, "Alien", ""))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT ff.position, f.\* FROM %s ff INNER JOIN %s f`,
+			franchisesFilmsTable, filmsTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"position", "id", "title"}).
+				AddRow(1, 10, "Alien").
+				AddRow(2, 11, "Aliens"))
+
+		got, err := r.GetFranchise(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Alien", got.Title)
+		assert.Equal(t, []domain.FranchiseFilm{
+			{Position: 1, Film: domain.Film{Id: 10, Title: "Alien"}},
+			{Position: 2, Film: domain.Film{Id: 11, Title: "Aliens"}},
+		}, got.Films)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE id=\$1`, franchisesTable)).WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description"}))
+
+		_, err := r.GetFranchise(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
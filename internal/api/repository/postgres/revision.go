@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+	"log/slog"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// jsonTime renders a timestamp column the way time.Time is encoded to JSON
+func jsonTime(column string) string {
+	return fmt.Sprintf(`to_char(%s, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')`, column)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// snapshotObject builds in SQL the JSON that record, a domain struct, is encoded to from its row alias.
+// The keys are the json tags of the fields that have a column, extra pairs are appended as they are
+func snapshotObject(alias string, record any, extra ...string) string {
+	recordType := reflect.TypeOf(record)
+	pairs := make([]string, 0, recordType.NumField()+len(extra))
+	for i := 0; i < recordType.NumField(); i++ {
+		field := recordType.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		column := field.Tag.Get("db")
+		if key == "" || key == "-" || column == "" || column == "-" {
+			continue
+		}
+		value := alias + "." + column
+		if field.Type == timeType || field.Type == reflect.PointerTo(timeType) {
+			value = jsonTime(value)
+		}
+		pairs = append(pairs, fmt.Sprintf("'%s', %s", key, value))
+	}
+	return "jsonb_build_object(" + strings.Join(append(pairs, extra...), ", ") + ")"
+}
+
+// snapshots build the revision snapshot of a film (row f), see domain.FilmSnapshot, or an actor (row a) in SQL
+var snapshots = map[string]struct{ table, alias, json string }{
+	domain.KindFilm: {table: filmsTable, alias: "f", json: snapshotObject("f", domain.Film{},
+		fmt.Sprintf(`'actorIds', COALESCE((SELECT jsonb_agg(actor_id ORDER BY actor_id) FROM %s
+		WHERE film_id=f.id), '[]'::jsonb)`, filmsActorsTable))},
+	domain.KindActor: {table: actorsTable, alias: "a", json: snapshotObject("a", domain.Actor{})},
+}
+
+// takeSnapshot reads the revision snapshot of a film or an actor inside the transaction
+func takeSnapshot(ctx context.Context, tx *sqlx.Tx, kind string, id int) (types.JSONText, error) {
+	var snapshot types.JSONText
+	snap := snapshots[kind]
+	query := fmt.Sprintf(`SELECT %s FROM %s %s WHERE %[3]s.id=$1`, snap.json, snap.table, snap.alias)
+	err := tx.GetContext(ctx, &snapshot, query, id)
+	return snapshot, err
+}
+
+// lockSnapshot locks a film or an actor, trashed or not, for the rest of the transaction and then
+// takes its snapshot, so that no other change comes between the snapshot and the write
+func lockSnapshot(ctx context.Context, tx *sqlx.Tx, kind string, id int) (types.JSONText, error) {
+	var locked int
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE id=$1 FOR UPDATE`, snapshots[kind].table)
+	if err := tx.GetContext(ctx, &locked, query, id); err != nil {
+		return nil, err
+	}
+	return takeSnapshot(ctx, tx, kind, id)
+}
+
+// insertRevision records the change of a film or an actor inside the transaction that made it,
+// After is the record as it is now. before is nil for a new record, userId is 0 for changes
+// made by no user, e.g. from the command line
+func insertRevision(ctx context.Context, tx *sqlx.Tx, kind, action string, userId, id int, before types.JSONText) error {
+	if before == nil {
+		before = types.JSONText("null")
+	}
+	snap := snapshots[kind]
+	query := fmt.Sprintf(`INSERT INTO %s(kind, record_id, action, before, after, user_id)
+		SELECT $1::text, %[3]s.id, $2::text, NULLIF($3::jsonb, 'null'::jsonb), %[2]s, NULLIF($4::int, 0) FROM %[4]s %[3]s
+		WHERE %[3]s.id=$5`, revisionsTable, snap.json, snap.alias, snap.table)
+	_, err := tx.ExecContext(ctx, query, kind, action, before, userId, id)
+	return err
+}
+
+// revisionError logs why a record could not be snapshotted or its revision recorded.
+// ErrNoRows means there is no such record
+func revisionError(log *slog.Logger, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoRows
+	}
+	log.Error(err.Error())
+	return ErrInternal
+}
+
+type RevisionPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewRevisionPostgres(db *sqlx.DB, log *slog.Logger) *RevisionPostgres {
+	return &RevisionPostgres{db: db, log: log}
+}
+
+// FilmSnapshot reads the film with all of its cast links, trashed or not
+func (r RevisionPostgres) FilmSnapshot(ctx context.Context, id int) (domain.FilmSnapshot, error) {
+	var snapshot domain.FilmSnapshot
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, filmsTable)
+	if err := r.db.GetContext(ctx, &snapshot.Film, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return snapshot, ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return snapshot, ErrInternal
+	}
+
+	snapshot.ActorIds = make([]int, 0)
+	query = fmt.Sprintf(`SELECT actor_id FROM %s WHERE film_id=$1 ORDER BY actor_id`, filmsActorsTable)
+	if err := r.db.SelectContext(ctx, &snapshot.ActorIds, query, id); err != nil {
+		r.log.Error(err.Error())
+		return snapshot, ErrInternal
+	}
+
+	return snapshot, nil
+}
+
+// ActorSnapshot reads the actor, trashed or not
+func (r RevisionPostgres) ActorSnapshot(ctx context.Context, id int) (domain.Actor, error) {
+	var actor domain.Actor
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, actorsTable)
+	if err := r.db.GetContext(ctx, &actor, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return actor, ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return actor, ErrInternal
+	}
+	return actor, nil
+}
+
+func (r RevisionPostgres) ListRevisions(ctx context.Context, kind string, recordId int) ([]domain.Revision, error) {
+	revisions := make([]domain.Revision, 0)
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE kind=$1 AND record_id=$2 ORDER BY id DESC`, revisionsTable)
+	err := r.db.SelectContext(ctx, &revisions, query, kind, recordId)
+
+	return revisions, err
+}
+
+func (r RevisionPostgres) GetRevision(ctx context.Context, id int) (domain.Revision, error) {
+	var revision domain.Revision
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, revisionsTable)
+	err := r.db.GetContext(ctx, &revision, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return revision, ErrNoRows
+	}
+	return revision, err
+}
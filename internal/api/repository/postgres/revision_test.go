@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func prepareRevisionTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *RevisionPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewRevisionPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+// expectSnapshot expects a film or an actor to be locked and snapshotted before a change
+func expectSnapshot(mock sqlmock.Sqlmock, table string, id int) {
+	mock.ExpectQuery(fmt.Sprintf(`SELECT id FROM %s WHERE id=\$1 FOR UPDATE`, table)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	mock.ExpectQuery(fmt.Sprintf(`(?s)SELECT jsonb_build_object\(.+\) FROM %s`, table)).WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"jsonb_build_object"}).AddRow(fmt.Sprintf(`{"id": %d}`, id)))
+}
+
+// expectRevision expects the revision of a change to be recorded
+func expectRevision(mock sqlmock.Sqlmock, kind, action string, userId, id int) {
+	mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, revisionsTable)).
+		WithArgs(kind, action, sqlmock.AnyArg(), userId, id).WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestRevisionPostgres_FilmSnapshot(t *testing.T) {
+	mock, dbx, r := prepareRevisionTest(t)
+	defer dbx.Close()
+
+	t.Run("Trashed", func(t *testing.T) {
+		deletedAt := time.Now()
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE id=\$1`, filmsTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "version", "deleted_at", "deleted_by"}).
+				AddRow(1, "Metropolis", 3, deletedAt, 2))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT actor_id FROM %s WHERE film_id=\$1 ORDER BY actor_id`, filmsActorsTable)).
+			WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"actor_id"}).AddRow(4).AddRow(9))
+		got, err := r.FilmSnapshot(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Metropolis", got.Title)
+		assert.Equal(t, &deletedAt, got.DeletedAt)
+		assert.Equal(t, []int{4, 9}, got.ActorIds)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE id=\$1`, filmsTable)).WithArgs(2).
+			WillReturnError(sql.ErrNoRows)
+		_, err := r.FilmSnapshot(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSnapshots(t *testing.T) {
+	// the snapshots taken in SQL must have the keys the domain structs are encoded with
+	deletedAt, deletedBy := time.Now(), 2
+	records := map[string]any{
+		domain.KindFilm: domain.FilmSnapshot{Film: domain.Film{Description: ptr(""), Rating: ptr[int8](0),
+			Actors: []domain.Actor{{}}, DeletedAt: &deletedAt, DeletedBy: &deletedBy}},
+		domain.KindActor: domain.Actor{Gender: ptr(0), Films: []domain.Film{{}}, DeletedAt: &deletedAt,
+			DeletedBy: &deletedBy},
+	}
+	key := regexp.MustCompile(`'(\w+)', `)
+	for kind, record := range records {
+		data, err := json.Marshal(record)
+		assert.NoError(t, err)
+		var fields map[string]json.RawMessage
+		assert.NoError(t, json.Unmarshal(data, &fields))
+		delete(fields, "actors")
+		delete(fields, "films")
+
+		want := make([]string, 0, len(fields))
+		for field := range fields {
+			want = append(want, field)
+		}
+		var got []string
+		for _, match := range key.FindAllStringSubmatch(snapshots[kind].json, -1) {
+			got = append(got, match[1])
+		}
+		assert.ElementsMatch(t, want, got, kind)
+	}
+}
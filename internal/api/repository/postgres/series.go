@@ -0,0 +1,286 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+)
+
+type SeriesPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewSeriesPostgres(db *sqlx.DB, log *slog.Logger) *SeriesPostgres {
+	return &SeriesPostgres{db: db, log: log}
+}
+
+// replaceCast replaces actor links of a series or an episode
+func replaceCast(ctx context.Context, tx *sqlx.Tx, table, column string, id int, actorIds []int) error {
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s=$1`, table, column), id)
+	if err != nil {
+		return ErrInternal
+	}
+
+	stmt, err := tx.PreparexContext(ctx, fmt.Sprintf(`INSERT INTO %s(%s, actor_id) VALUES($1,$2)`, table, column))
+	if err != nil {
+		return ErrInternal
+	}
+	for _, actorId := range actorIds {
+		if _, err = stmt.ExecContext(ctx, id, actorId); err != nil {
+			return mapConstraintError(err)
+		}
+	}
+
+	return nil
+}
+
+func (r SeriesPostgres) CreateSeries(ctx context.Context, series domain.Series, actorIds []int) (int, error) {
+	const method = "Series.Repository.CreateSeries"
+	log := r.log.With(slog.String("method", method))
+
+	var id int
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return 0, ErrInternal
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s(title, description, released, rating)
+		VALUES($1,$2,$3,$4) RETURNING id`, seriesTable)
+	row := tx.QueryRowxContext(ctx, query, series.Title, series.Description, series.Released, series.Rating)
+	if err = row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return 0, ErrInternal
+	}
+
+	if err = replaceCast(ctx, tx, seriesActorsTable, "series_id", id, actorIds); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r SeriesPostgres) UpdateSeries(ctx context.Context, series domain.Series, actorIds []int) error {
+	const method = "Series.Repository.UpdateSeries"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET title=$1, description=$2, released=$3, rating=$4 WHERE id=$5`,
+		seriesTable)
+	result, err := tx.ExecContext(ctx, query, series.Title, series.Description, series.Released, series.Rating, series.Id)
+	if err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return ErrInternal
+	}
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		tx.Rollback()
+		return ErrNoRows
+	}
+
+	if err = replaceCast(ctx, tx, seriesActorsTable, "series_id", series.Id, actorIds); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r SeriesPostgres) GetSeries(ctx context.Context, id int) (domain.Series, error) {
+	const method = "Series.Repository.GetSeries"
+	log := r.log.With(slog.String("method", method))
+
+	var series domain.Series
+	err := r.db.GetContext(ctx, &series, fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, seriesTable), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return series, ErrNoRows
+		}
+		log.Error(err.Error())
+		return series, ErrInternal
+	}
+
+	query := fmt.Sprintf(`SELECT a.* FROM %s a INNER JOIN %s sa ON a.id = sa.actor_id
+		WHERE sa.series_id=$1 AND a.deleted_at IS NULL`,
+		actorsTable, seriesActorsTable)
+	if err = r.db.SelectContext(ctx, &series.Actors, query, id); err != nil {
+		log.Error(err.Error())
+		return series, ErrInternal
+	}
+
+	query = fmt.Sprintf(`SELECT * FROM %s WHERE series_id=$1 ORDER BY number`, seasonsTable)
+	if err = r.db.SelectContext(ctx, &series.Seasons, query, id); err != nil {
+		log.Error(err.Error())
+		return series, ErrInternal
+	}
+
+	var episodes []domain.Episode
+	query = fmt.Sprintf(`SELECT e.* FROM %s e INNER JOIN %s s ON s.id = e.season_id
+		WHERE s.series_id=$1 ORDER BY e.number`, episodesTable, seasonsTable)
+	if err = r.db.SelectContext(ctx, &episodes, query, id); err != nil {
+		log.Error(err.Error())
+		return series, ErrInternal
+	}
+
+	var cast []struct {
+		EpisodeId int `db:"episode_id"`
+		domain.Actor
+	}
+	query = fmt.Sprintf(`SELECT ea.episode_id, a.* FROM %s a INNER JOIN %s ea ON a.id = ea.actor_id
+		INNER JOIN %s e ON e.id = ea.episode_id INNER JOIN %s s ON s.id = e.season_id
+		WHERE s.series_id=$1 AND a.deleted_at IS NULL`,
+		actorsTable, episodesActorsTable, episodesTable, seasonsTable)
+	if err = r.db.SelectContext(ctx, &cast, query, id); err != nil {
+		log.Error(err.Error())
+		return series, ErrInternal
+	}
+
+	episodeActors := make(map[int][]domain.Actor)
+	for _, c := range cast {
+		episodeActors[c.EpisodeId] = append(episodeActors[c.EpisodeId], c.Actor)
+	}
+	seasonIndex := make(map[int]int, len(series.Seasons))
+	for i, season := range series.Seasons {
+		seasonIndex[season.Id] = i
+	}
+	for _, episode := range episodes {
+		episode.Actors = episodeActors[episode.Id]
+		i := seasonIndex[episode.SeasonId]
+		series.Seasons[i].Episodes = append(series.Seasons[i].Episodes, episode)
+	}
+
+	return series, nil
+}
+
+func (r SeriesPostgres) ListSeries(ctx context.Context, sortBy, sortDir string) ([]domain.Series, error) {
+	var series []domain.Series
+	query := fmt.Sprintf(`SELECT * FROM %s ORDER BY %s %s`, seriesTable, sortBy, sortDir)
+	err := r.db.SelectContext(ctx, &series, query)
+
+	return series, err
+}
+
+func (r SeriesPostgres) deleteById(ctx context.Context, table string, id int) error {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id=$1`, table), id)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
+
+func (r SeriesPostgres) DeleteSeries(ctx context.Context, id int) error {
+	return r.deleteById(ctx, seriesTable, id)
+}
+
+func (r SeriesPostgres) CreateSeason(ctx context.Context, season domain.Season) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(series_id, number, title) VALUES($1,$2,$3) RETURNING id`, seasonsTable)
+	row := r.db.QueryRowxContext(ctx, query, season.SeriesId, season.Number, season.Title)
+	if err := row.Scan(&id); err != nil {
+		r.log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+
+	return id, nil
+}
+
+func (r SeriesPostgres) DeleteSeason(ctx context.Context, id int) error {
+	return r.deleteById(ctx, seasonsTable, id)
+}
+
+func (r SeriesPostgres) CreateEpisode(ctx context.Context, episode domain.Episode, actorIds []int) (int, error) {
+	const method = "Series.Repository.CreateEpisode"
+	log := r.log.With(slog.String("method", method))
+
+	var id int
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return 0, ErrInternal
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s(season_id, number, title, air_date, runtime)
+		VALUES($1,$2,$3,$4,$5) RETURNING id`, episodesTable)
+	row := tx.QueryRowxContext(ctx, query, episode.SeasonId, episode.Number, episode.Title, episode.AirDate,
+		episode.Runtime)
+	if err = row.Scan(&id); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return 0, mapConstraintError(err)
+	}
+
+	if err = replaceCast(ctx, tx, episodesActorsTable, "episode_id", id, actorIds); err != nil {
+		log.Error(err.Error())
+		tx.Rollback()
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r SeriesPostgres) DeleteEpisode(ctx context.Context, id int) error {
+	return r.deleteById(ctx, episodesTable, id)
+}
+
+func (r SeriesPostgres) ListCatalog(ctx context.Context, sortBy, sortDir string) ([]domain.CatalogItem, error) {
+	var items []domain.CatalogItem
+	query := fmt.Sprintf(`SELECT '%s' AS kind, id, title, description, released, rating FROM %s
+		WHERE deleted_at IS NULL
+		UNION ALL SELECT '%s' AS kind, id, title, description, released, rating FROM %s
+		ORDER BY %s %s NULLS LAST, kind, id`,
+		domain.KindFilm, filmsTable, domain.KindSeries, seriesTable, sortBy, sortDir)
+	err := r.db.SelectContext(ctx, &items, query)
+
+	return items, err
+}
+
+func (r SeriesPostgres) SearchCatalog(ctx context.Context, searchQuery string) ([]domain.CatalogItem, error) {
+	var items []domain.CatalogItem
+	query := fmt.Sprintf(`SELECT '%[1]s' AS kind, f.id, f.title, f.description, f.released, f.rating FROM %[2]s f
+		WHERE f.deleted_at IS NULL AND (f.title LIKE $1
+		OR EXISTS (SELECT 1 FROM %[3]s fa INNER JOIN %[4]s a ON a.id = fa.actor_id
+			WHERE fa.film_id = f.id AND a.deleted_at IS NULL AND (a.name LIKE $1
+				OR EXISTS (SELECT 1 FROM %[13]s at WHERE at.actor_id = a.id AND at.name LIKE $1)))
+		OR EXISTS (SELECT 1 FROM %[11]s ft WHERE ft.film_id = f.id AND ft.title LIKE $1)
+		OR EXISTS (SELECT 1 FROM %[12]s al WHERE al.film_id = f.id AND al.title LIKE $1))
+		UNION ALL
+		SELECT '%[5]s' AS kind, s.id, s.title, s.description, s.released, s.rating FROM %[6]s s
+		WHERE s.title LIKE $1 OR EXISTS (SELECT 1 FROM %[7]s sa INNER JOIN %[4]s a ON a.id = sa.actor_id
+			WHERE sa.series_id = s.id AND a.deleted_at IS NULL AND (a.name LIKE $1
+				OR EXISTS (SELECT 1 FROM %[13]s at WHERE at.actor_id = a.id AND at.name LIKE $1)))
+		OR EXISTS (SELECT 1 FROM %[8]s se INNER JOIN %[9]s e ON e.season_id = se.id
+			INNER JOIN %[10]s ea ON ea.episode_id = e.id INNER JOIN %[4]s a ON a.id = ea.actor_id
+			WHERE se.series_id = s.id AND a.deleted_at IS NULL AND (a.name LIKE $1
+				OR EXISTS (SELECT 1 FROM %[13]s at WHERE at.actor_id = a.id AND at.name LIKE $1)))
+		ORDER BY rating DESC NULLS LAST, kind, id`,
+		domain.KindFilm, filmsTable, filmsActorsTable, actorsTable,
+		domain.KindSeries, seriesTable, seriesActorsTable, seasonsTable, episodesTable, episodesActorsTable,
+		filmsTranslationsTable, filmsAliasesTable, actorsTranslationsTable)
+	like := fmt.Sprintf("%%%s%%", searchQuery)
+	err := r.db.SelectContext(ctx, &items, query, like)
+
+	return items, err
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/brianvoe/gofakeit"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func prepareSeriesTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *SeriesPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewSeriesPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestSeriesPostgres_CreateSeries(t *testing.T) {
+	mock, dbx, r := prepareSeriesTest(t)
+	defer dbx.Close()
+
+	t.Run("RightCredentials", func(t *testing.T) {
+		series := domain.Series{
+			Id:       1,
+			Title:    gofakeit.JobTitle(),
+			Released: domain.NewDate(gofakeit.Date()),
+			Rating:   8,
+		}
+		actorIds := []int{1, 2}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`INSERT INTO %s`, seriesTable)).
+			WithArgs(series.Title, series.Description, series.Released.String(), series.Rating).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(series.Id))
+		mock.ExpectExec(fmt.Sprintf("DELETE FROM %s", seriesActorsTable)).WithArgs(series.Id).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectPrepare(fmt.Sprintf("INSERT INTO %s", seriesActorsTable))
+		for _, actorId := range actorIds {
+			mock.ExpectExec(fmt.Sprintf("INSERT INTO %s", seriesActorsTable)).
+				WithArgs(series.Id, actorId).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+		mock.ExpectCommit()
+
+		got, err := r.CreateSeries(context.Background(), series, actorIds)
+		assert.NoError(t, err)
+		assert.Equal(t, series.Id, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSeriesPostgres_GetSeries(t *testing.T) {
+	mock, dbx, r := prepareSeriesTest(t)
+	defer dbx.Close()
+
+	t.Run("WithSeasonsAndEpisodes", func(t *testing.T) {
+		date := time.Date(2008, 1, 20, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), seriesTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "released", "rating"}).
+				AddRow(1, "Breaking Bad", "", date, 9))
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT a.* FROM %s a`), actorsTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "birthday", "gender"}).
+				AddRow(10, "Bryan Cranston", date, 1))
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), seasonsTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "series_id", "number", "title"}).
+				AddRow(5, 1, 1, "").AddRow(6, 1, 2, ""))
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT e.* FROM %s`), episodesTable)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "season_id", "number", "title", "air_date", "runtime"}).
+				AddRow(20, 5, 1, "Pilot", date, 58).AddRow(21, 6, 1, "Seven Thirty-Seven", date, 47))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT ea.episode_id, a.*`)).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"episode_id", "id", "name", "birthday", "gender"}).
+				AddRow(21, 11, "Aaron Paul", date, 1))
+
+		got, err := r.GetSeries(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, got.Actors, 1)
+		assert.Len(t, got.Seasons, 2)
+		assert.Equal(t, "Pilot", got.Seasons[0].Episodes[0].Title)
+		assert.Empty(t, got.Seasons[0].Episodes[0].Actors)
+		assert.Equal(t, "Aaron Paul", got.Seasons[1].Episodes[0].Actors[0].Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NoSuchId", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(regexp.QuoteMeta(`SELECT * FROM %s`), seriesTable)).WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := r.GetSeries(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSeriesPostgres_ListCatalog(t *testing.T) {
+	mock, dbx, r := prepareSeriesTest(t)
+	defer dbx.Close()
+
+	t.Run("FilmsAndSeries", func(t *testing.T) {
+		date := time.Date(2008, 1, 20, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery(regexp.QuoteMeta(`UNION ALL`)).WithoutArgs().
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "title", "description", "released", "rating"}).
+				AddRow(domain.KindSeries, 1, "Breaking Bad", "", date, 9).
+				AddRow(domain.KindFilm, 1, "Solaris", "", date, 8))
+
+		got, err := r.ListCatalog(context.Background(), "rating", "desc")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.KindSeries, got[0].Kind)
+		assert.Equal(t, domain.KindFilm, got[1].Kind)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSeriesPostgres_SearchCatalog(t *testing.T) {
+	mock, dbx, r := prepareSeriesTest(t)
+	defer dbx.Close()
+
+	t.Run("ActorTranslations", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(`OR EXISTS (SELECT 1 FROM %s at WHERE at.actor_id = a.id`,
+			actorsTranslationsTable))).WithArgs("%Банионис%").
+			WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "title", "description", "released", "rating"}).
+				AddRow(domain.KindFilm, 1, "Solaris", "", nil, 8))
+
+		got, err := r.SearchCatalog(context.Background(), "Банионис")
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
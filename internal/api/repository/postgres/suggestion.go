@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+)
+
+type SuggestionPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewSuggestionPostgres(db *sqlx.DB, log *slog.Logger) *SuggestionPostgres {
+	return &SuggestionPostgres{db: db, log: log}
+}
+
+func (r SuggestionPostgres) CreateSuggestion(ctx context.Context, suggestion domain.Suggestion) (int, error) {
+	var id int
+	query := fmt.Sprintf(`INSERT INTO %s(kind, record_id, user_id, changes, comment)
+		VALUES($1,$2,$3,$4,$5) RETURNING id`, suggestionsTable)
+	err := r.db.QueryRowxContext(ctx, query, suggestion.Kind, suggestion.RecordId, suggestion.UserId,
+		suggestion.Changes, suggestion.Comment).Scan(&id)
+	if err != nil {
+		r.log.Error(err.Error())
+		return 0, mapConstraintError(err)
+	}
+	return id, nil
+}
+
+func (r SuggestionPostgres) GetSuggestion(ctx context.Context, id int) (domain.Suggestion, error) {
+	var suggestion domain.Suggestion
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE id=$1`, suggestionsTable)
+	err := r.db.GetContext(ctx, &suggestion, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return suggestion, ErrNoRows
+	}
+	return suggestion, err
+}
+
+// ListSuggestions returns suggestions with the given status, all of them if status is empty. Oldest go first
+func (r SuggestionPostgres) ListSuggestions(ctx context.Context, status string) ([]domain.Suggestion, error) {
+	suggestions := make([]domain.Suggestion, 0)
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE $1 = '' OR status=$1 ORDER BY created_at, id`, suggestionsTable)
+	err := r.db.SelectContext(ctx, &suggestions, query, status)
+
+	return suggestions, err
+}
+
+func (r SuggestionPostgres) ListUserSuggestions(ctx context.Context, userId int) ([]domain.Suggestion, error) {
+	suggestions := make([]domain.Suggestion, 0)
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=$1 ORDER BY created_at DESC, id DESC`, suggestionsTable)
+	err := r.db.SelectContext(ctx, &suggestions, query, userId)
+
+	return suggestions, err
+}
+
+// ReviewSuggestion closes a pending suggestion with the given status. ErrNoRows means it is missing or already reviewed
+func (r SuggestionPostgres) ReviewSuggestion(ctx context.Context, id, reviewerId int, status string,
+	reason *string) (domain.Suggestion, error) {
+	return r.review(ctx, r.db, id, reviewerId, status, reason)
+}
+
+func (r SuggestionPostgres) review(ctx context.Context, q sqlx.QueryerContext, id, reviewerId int, status string,
+	reason *string) (domain.Suggestion, error) {
+	var suggestion domain.Suggestion
+	query := fmt.Sprintf(`UPDATE %s SET status=$2, reviewer_id=$3, reason=$4, reviewed_at=now()
+		WHERE id=$1 AND status='pending' RETURNING *`, suggestionsTable)
+	err := q.QueryRowxContext(ctx, query, id, status, reviewerId, reason).StructScan(&suggestion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return suggestion, ErrNoRows
+		}
+		r.log.Error(err.Error())
+		return suggestion, ErrInternal
+	}
+	return suggestion, nil
+}
+
+// approve marks a pending suggestion approved and applies it with apply in the same transaction:
+// the suggestion is claimed first, so that concurrent approvals apply it once, and a failed change
+// leaves it pending. ErrReviewed means it is missing or no longer pending
+func (r SuggestionPostgres) approve(ctx context.Context, id, reviewerId int,
+	apply func(tx *sqlx.Tx) error) (domain.Suggestion, error) {
+	const method = "Suggestions.Repository.approve"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return domain.Suggestion{}, ErrInternal
+	}
+	suggestion, err := r.review(ctx, tx, id, reviewerId, domain.SuggestionApproved, nil)
+	if errors.Is(err, ErrNoRows) {
+		err = ErrReviewed
+	}
+	if err == nil {
+		err = apply(tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		return suggestion, err
+	}
+	if err = tx.Commit(); err != nil {
+		log.Error(err.Error())
+		return suggestion, ErrInternal
+	}
+	return suggestion, nil
+}
+
+// ApproveFilmSuggestion approves a pending suggestion and patches the film with it on behalf of the reviewer,
+// see PatchFilm
+func (r SuggestionPostgres) ApproveFilmSuggestion(ctx context.Context, id, reviewerId int, input domain.NullableFilm,
+	actorIds []int) (domain.Suggestion, error) {
+	films := FilmPostgres{db: r.db, log: r.log}
+	return r.approve(ctx, id, reviewerId, func(tx *sqlx.Tx) error {
+		_, err := films.patchFilm(ctx, tx, reviewerId, input, actorIds)
+		return err
+	})
+}
+
+// ApproveActorSuggestion approves a pending suggestion and patches the actor with it on behalf of the reviewer
+func (r SuggestionPostgres) ApproveActorSuggestion(ctx context.Context, id, reviewerId int,
+	input domain.ActorInput) (domain.Suggestion, error) {
+	actors := ActorPostgres{db: r.db, log: r.log}
+	return r.approve(ctx, id, reviewerId, func(tx *sqlx.Tx) error {
+		_, err := actors.patchActor(ctx, tx, reviewerId, input)
+		return err
+	})
+}
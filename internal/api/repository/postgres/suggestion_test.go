@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareSuggestionTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *SuggestionPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewSuggestionPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestSuggestionPostgres_ReviewSuggestion(t *testing.T) {
+	mock, dbx, r := prepareSuggestionTest(t)
+	defer dbx.Close()
+
+	reason := "duplicate"
+	t.Run("Pending", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "status", "reviewer_id", "reason"}).
+			AddRow(3, domain.SuggestionRejected, 1, reason)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET status=\$2, reviewer_id=\$3, reason=\$4, reviewed_at=now\(\)
+		WHERE id=\$1 AND status='pending' RETURNING \*`, suggestionsTable)).
+			WithArgs(3, domain.SuggestionRejected, 1, &reason).WillReturnRows(rows)
+		got, err := r.ReviewSuggestion(context.Background(), 3, 1, domain.SuggestionRejected, &reason)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SuggestionRejected, got.Status)
+		assert.Equal(t, &reason, got.Reason)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("Reviewed", func(t *testing.T) {
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET status=\$2`, suggestionsTable)).
+			WithArgs(4, domain.SuggestionApproved, 1, nil).WillReturnError(sql.ErrNoRows)
+		_, err := r.ReviewSuggestion(context.Background(), 4, 1, domain.SuggestionApproved, nil)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSuggestionPostgres_ApproveFilmSuggestion(t *testing.T) {
+	mock, dbx, r := prepareSuggestionTest(t)
+	defer dbx.Close()
+
+	title := "Brother"
+	input := domain.NullableFilm{Id: 5, Title: &title}
+	t.Run("TitleOnlyKeepsCast", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET status=\$2`, suggestionsTable)).
+			WithArgs(8, domain.SuggestionApproved, 1, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(8, domain.SuggestionApproved))
+		expectSnapshot(mock, filmsTable, 5)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET version=version\+1,title=\$1 WHERE`, filmsTable)).
+			WithArgs(title, 5, 0).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(5, title))
+		expectRevision(mock, domain.KindFilm, domain.ActionPatch, 1, 5)
+		mock.ExpectCommit()
+		got, err := r.ApproveFilmSuggestion(context.Background(), 8, 1, input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SuggestionApproved, got.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("AlreadyClaimed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET status=\$2`, suggestionsTable)).
+			WithArgs(8, domain.SuggestionApproved, 1, nil).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+		_, err := r.ApproveFilmSuggestion(context.Background(), 8, 1, input, nil)
+		assert.ErrorIs(t, err, ErrReviewed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("FailedPatchStaysPending", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET status=\$2`, suggestionsTable)).
+			WithArgs(8, domain.SuggestionApproved, 1, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(8, domain.SuggestionApproved))
+		expectSnapshot(mock, filmsTable, 5)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET version=version\+1,title=\$1 WHERE`, filmsTable)).
+			WithArgs(title, 5, 0).WillReturnError(fmt.Errorf("connection reset"))
+		mock.ExpectRollback()
+		_, err := r.ApproveFilmSuggestion(context.Background(), 8, 1, input, nil)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSuggestionPostgres_ListSuggestions(t *testing.T) {
+	mock, dbx, r := prepareSuggestionTest(t)
+	defer dbx.Close()
+
+	mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE \$1 = '' OR status=\$1 ORDER BY created_at, id`,
+		suggestionsTable)).WithArgs(domain.SuggestionPending).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "record_id", "status"}).
+			AddRow(1, domain.KindFilm, 5, domain.SuggestionPending))
+	got, err := r.ListSuggestions(context.Background(), domain.SuggestionPending)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+)
+
+type TranslationPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewTranslationPostgres(db *sqlx.DB, log *slog.Logger) *TranslationPostgres {
+	return &TranslationPostgres{db: db, log: log}
+}
+
+func (r TranslationPostgres) SetFilmTranslation(ctx context.Context, translation domain.FilmTranslation) error {
+	const method = "Translations.Repository.SetFilmTranslation"
+	log := r.log.With(slog.String("method", method))
+
+	query := fmt.Sprintf(`INSERT INTO %s(film_id, lang, title, description) VALUES($1,$2,$3,$4)
+		ON CONFLICT (film_id, lang) DO UPDATE SET title=EXCLUDED.title, description=EXCLUDED.description`,
+		filmsTranslationsTable)
+	_, err := r.db.ExecContext(ctx, query, translation.FilmId, translation.Lang, translation.Title, translation.Description)
+	if err != nil {
+		log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+func (r TranslationPostgres) ListFilmTranslations(ctx context.Context, filmId int) ([]domain.FilmTranslation, error) {
+	var translations []domain.FilmTranslation
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE film_id=$1 ORDER BY lang`, filmsTranslationsTable)
+	err := r.db.SelectContext(ctx, &translations, query, filmId)
+
+	return translations, err
+}
+
+func (r TranslationPostgres) ListFilmTranslationsByLang(ctx context.Context, lang string) ([]domain.FilmTranslation, error) {
+	var translations []domain.FilmTranslation
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE lang=$1`, filmsTranslationsTable)
+	err := r.db.SelectContext(ctx, &translations, query, lang)
+
+	return translations, err
+}
+
+func (r TranslationPostgres) DeleteFilmTranslation(ctx context.Context, filmId int, lang string) error {
+	return r.deleteTranslation(ctx, filmsTranslationsTable, "film_id", filmId, lang)
+}
+
+func (r TranslationPostgres) SetActorTranslation(ctx context.Context, translation domain.ActorTranslation) error {
+	const method = "Translations.Repository.SetActorTranslation"
+	log := r.log.With(slog.String("method", method))
+
+	query := fmt.Sprintf(`INSERT INTO %s(actor_id, lang, name) VALUES($1,$2,$3)
+		ON CONFLICT (actor_id, lang) DO UPDATE SET name=EXCLUDED.name`, actorsTranslationsTable)
+	_, err := r.db.ExecContext(ctx, query, translation.ActorId, translation.Lang, translation.Name)
+	if err != nil {
+		log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+func (r TranslationPostgres) ListActorTranslations(ctx context.Context, actorId int) ([]domain.ActorTranslation, error) {
+	var translations []domain.ActorTranslation
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE actor_id=$1 ORDER BY lang`, actorsTranslationsTable)
+	err := r.db.SelectContext(ctx, &translations, query, actorId)
+
+	return translations, err
+}
+
+func (r TranslationPostgres) ListActorTranslationsByLang(ctx context.Context, lang string) ([]domain.ActorTranslation, error) {
+	var translations []domain.ActorTranslation
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE lang=$1`, actorsTranslationsTable)
+	err := r.db.SelectContext(ctx, &translations, query, lang)
+
+	return translations, err
+}
+
+func (r TranslationPostgres) DeleteActorTranslation(ctx context.Context, actorId int, lang string) error {
+	return r.deleteTranslation(ctx, actorsTranslationsTable, "actor_id", actorId, lang)
+}
+
+func (r TranslationPostgres) deleteTranslation(ctx context.Context, table, column string, id int, lang string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE %s=$1 AND lang=$2`, table, column)
+	result, err := r.db.ExecContext(ctx, query, id, lang)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
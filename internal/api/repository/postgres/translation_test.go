@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareTranslationTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *TranslationPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewTranslationPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestTranslationPostgres_SetFilmTranslation(t *testing.T) {
+	mock, dbx, r := prepareTranslationTest(t)
+	defer dbx.Close()
+
+	translation := domain.FilmTranslation{FilmId: 1, Lang: "en", Title: "Brother", Description: "Description"}
+
+	t.Run("Upsert", func(t *testing.T) {
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, filmsTranslationsTable)).
+			WithArgs(translation.FilmId, translation.Lang, translation.Title, translation.Description).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		err := r.SetFilmTranslation(context.Background(), translation)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("MissingFilm", func(t *testing.T) {
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, filmsTranslationsTable)).
+			WithArgs(translation.FilmId, translation.Lang, translation.Title, translation.Description).
+			WillReturnError(pgx.PgError{Code: foreignErrCode})
+		err := r.SetFilmTranslation(context.Background(), translation)
+		assert.ErrorIs(t, err, ErrForeign)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTranslationPostgres_DeleteActorTranslation(t *testing.T) {
+	mock, dbx, r := prepareTranslationTest(t)
+	defer dbx.Close()
+
+	t.Run("Missing", func(t *testing.T) {
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s WHERE actor_id=\$1 AND lang=\$2`, actorsTranslationsTable)).
+			WithArgs(1, "en").WillReturnResult(sqlmock.NewResult(0, 0))
+		err := r.DeleteActorTranslation(context.Background(), 1, "en")
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"time"
+)
+
+type TrashPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewTrashPostgres(db *sqlx.DB, log *slog.Logger) *TrashPostgres {
+	return &TrashPostgres{db: db, log: log}
+}
+
+func (r TrashPostgres) ListDeletedFilms(ctx context.Context) ([]domain.Film, error) {
+	films := make([]domain.Film, 0)
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`, filmsTable)
+	err := r.db.SelectContext(ctx, &films, query)
+
+	return films, err
+}
+
+func (r TrashPostgres) ListDeletedActors(ctx context.Context) ([]domain.Actor, error) {
+	actors := make([]domain.Actor, 0)
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`, actorsTable)
+	err := r.db.SelectContext(ctx, &actors, query)
+
+	return actors, err
+}
+
+// restore takes a film or an actor out of the trash, scans it into dest and records the revision
+// on behalf of userId
+func (r TrashPostgres) restore(ctx context.Context, dest interface{}, kind string, userId, id int) error {
+	table := snapshots[kind].table
+	log := r.log.With(slog.String("table", table))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnapshot(ctx, tx, kind, id)
+	if err != nil {
+		return revisionError(log, err)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET deleted_at=NULL, deleted_by=NULL, version=version+1
+		WHERE id=$1 AND deleted_at IS NOT NULL RETURNING *`, table)
+	err = tx.QueryRowxContext(ctx, query, id).StructScan(dest)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoRows
+		}
+		log.Error(err.Error())
+		return ErrInternal
+	}
+	if err = insertRevision(ctx, tx, kind, domain.ActionRestore, userId, id, before); err != nil {
+		return revisionError(log, err)
+	}
+
+	return tx.Commit()
+}
+
+func (r TrashPostgres) RestoreFilm(ctx context.Context, userId, id int) (domain.Film, error) {
+	var film domain.Film
+	err := r.restore(ctx, &film, domain.KindFilm, userId, id)
+	return film, err
+}
+
+func (r TrashPostgres) RestoreActor(ctx context.Context, userId, id int) (domain.Actor, error) {
+	var actor domain.Actor
+	err := r.restore(ctx, &actor, domain.KindActor, userId, id)
+	return actor, err
+}
+
+// Purge hard deletes films and actors trashed before the given time, their links cascade.
+// Films with copies or in collections stay in the trash so that neither is lost
+func (r TrashPostgres) Purge(ctx context.Context, before time.Time) (domain.PurgeResult, error) {
+	const method = "Trash.Repository.Purge"
+	log := r.log.With(slog.String("method", method))
+
+	var result domain.PurgeResult
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return result, ErrInternal
+	}
+
+	queries := map[string]string{
+		filmsTable: fmt.Sprintf(`DELETE FROM %s f WHERE f.deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM %s c WHERE c.film_id = f.id)
+			AND NOT EXISTS (SELECT 1 FROM %s ci WHERE ci.film_id = f.id)`, filmsTable, copiesTable, collectionsItemsTable),
+		actorsTable: fmt.Sprintf(`DELETE FROM %s WHERE deleted_at < $1`, actorsTable),
+	}
+	counts := map[string]*int64{filmsTable: &result.Films, actorsTable: &result.Actors}
+	for _, table := range []string{filmsTable, actorsTable} {
+		res, err := tx.ExecContext(ctx, queries[table], before)
+		if err != nil {
+			tx.Rollback()
+			log.Error(err.Error())
+			return domain.PurgeResult{}, ErrInternal
+		}
+		if *counts[table], err = res.RowsAffected(); err != nil {
+			tx.Rollback()
+			return domain.PurgeResult{}, ErrInternal
+		}
+	}
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE deleted_at < $1`, filmsTable)
+	if err = tx.GetContext(ctx, &result.Kept, query, before); err != nil {
+		tx.Rollback()
+		log.Error(err.Error())
+		return domain.PurgeResult{}, ErrInternal
+	}
+
+	return result, tx.Commit()
+}
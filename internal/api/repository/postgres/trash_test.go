@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareTrashTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *TrashPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewTrashPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestTrashPostgres_RestoreFilm(t *testing.T) {
+	mock, dbx, r := prepareTrashTest(t)
+	defer dbx.Close()
+
+	t.Run("Trashed", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "title", "version", "deleted_at", "deleted_by"}).
+			AddRow(1, "Metropolis", 4, nil, nil)
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 1)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET deleted_at=NULL, deleted_by=NULL, version=version\+1
+		WHERE id=\$1 AND deleted_at IS NOT NULL RETURNING \*`, filmsTable)).
+			WithArgs(1).WillReturnRows(rows)
+		expectRevision(mock, domain.KindFilm, domain.ActionRestore, 3, 1)
+		mock.ExpectCommit()
+		got, err := r.RestoreFilm(context.Background(), 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, got.Version)
+		assert.Nil(t, got.DeletedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("NotInTrash", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectSnapshot(mock, filmsTable, 2)
+		mock.ExpectQuery(fmt.Sprintf(`UPDATE %s SET deleted_at=NULL`, filmsTable)).
+			WithArgs(2).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+		_, err := r.RestoreFilm(context.Background(), 3, 2)
+		assert.ErrorIs(t, err, ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTrashPostgres_ListDeletedActors(t *testing.T) {
+	mock, dbx, r := prepareTrashTest(t)
+	defer dbx.Close()
+
+	deletedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "name", "version", "deleted_at", "deleted_by"}).
+		AddRow(3, "Brigitte Helm", 2, deletedAt, 7)
+	mock.ExpectQuery(fmt.Sprintf(`SELECT \* FROM %s WHERE deleted_at IS NOT NULL`, actorsTable)).
+		WillReturnRows(rows)
+	got, err := r.ListDeletedActors(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, deletedAt, *got[0].DeletedAt)
+		assert.Equal(t, 7, *got[0].DeletedBy)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTrashPostgres_Purge(t *testing.T) {
+	mock, dbx, r := prepareTrashTest(t)
+	defer dbx.Close()
+
+	before := time.Now().Add(-30 * 24 * time.Hour)
+
+	t.Run("Expired", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s f WHERE f.deleted_at < \$1
+			AND NOT EXISTS \(SELECT 1 FROM %s c WHERE c.film_id = f.id\)
+			AND NOT EXISTS \(SELECT 1 FROM %s ci WHERE ci.film_id = f.id\)`, filmsTable, copiesTable, collectionsItemsTable)).
+			WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s WHERE deleted_at < \$1`, actorsTable)).
+			WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(fmt.Sprintf(`SELECT count\(\*\) FROM %s WHERE deleted_at < \$1`, filmsTable)).
+			WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectCommit()
+		got, err := r.Purge(context.Background(), before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), got.Films)
+		assert.Equal(t, int64(1), got.Actors)
+		assert.Equal(t, int64(1), got.Kept)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("QueryError", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(`DELETE FROM %s`, filmsTable)).
+			WithArgs(before).WillReturnError(fmt.Errorf("connection reset"))
+		mock.ExpectRollback()
+		_, err := r.Purge(context.Background(), before)
+		assert.ErrorIs(t, err, ErrInternal)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"time"
+)
+
+type UserFilmPostgres struct {
+	db  *sqlx.DB
+	log *slog.Logger
+}
+
+func NewUserFilmPostgres(db *sqlx.DB, log *slog.Logger) *UserFilmPostgres {
+	return &UserFilmPostgres{db: db, log: log}
+}
+
+func (r UserFilmPostgres) SetUserFilm(ctx context.Context, mark domain.UserFilm) error {
+	const method = "UserFilms.Repository.SetUserFilm"
+	log := r.log.With(slog.String("method", method))
+
+	var watchedAt *time.Time
+	if mark.WatchedAt != nil {
+		watchedAt = &mark.WatchedAt.Time
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s(user_id, film_id, rating, watched_at) VALUES($1,$2,$3,$4)
+		ON CONFLICT (user_id, film_id) DO UPDATE SET rating=EXCLUDED.rating, watched_at=EXCLUDED.watched_at`,
+		usersFilmsTable)
+	_, err := r.db.ExecContext(ctx, query, mark.UserId, mark.FilmId, mark.Rating, watchedAt)
+	if err != nil {
+		log.Error(err.Error())
+		return mapConstraintError(err)
+	}
+
+	return nil
+}
+
+// SetUserFilms saves several marks of a user at once, none of them if any fails
+func (r UserFilmPostgres) SetUserFilms(ctx context.Context, marks []domain.UserFilm) error {
+	const method = "UserFilms.Repository.SetUserFilms"
+	log := r.log.With(slog.String("method", method))
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Error(err.Error())
+		return ErrInternal
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s(user_id, film_id, rating, watched_at) VALUES($1,$2,$3,$4)
+		ON CONFLICT (user_id, film_id) DO UPDATE SET rating=EXCLUDED.rating, watched_at=EXCLUDED.watched_at`,
+		usersFilmsTable)
+	for _, mark := range marks {
+		var watchedAt *time.Time
+		if mark.WatchedAt != nil {
+			watchedAt = &mark.WatchedAt.Time
+		}
+		if _, err = tx.ExecContext(ctx, query, mark.UserId, mark.FilmId, mark.Rating, watchedAt); err != nil {
+			log.Error(err.Error())
+			tx.Rollback()
+			return mapConstraintError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r UserFilmPostgres) ListUserFilms(ctx context.Context, userId int) ([]domain.UserFilm, error) {
+	var marks []domain.UserFilm
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE user_id=$1 ORDER BY watched_at DESC NULLS LAST, film_id`,
+		usersFilmsTable)
+	err := r.db.SelectContext(ctx, &marks, query, userId)
+
+	return marks, err
+}
+
+func (r UserFilmPostgres) DeleteUserFilm(ctx context.Context, userId, filmId int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id=$1 AND film_id=$2`, usersFilmsTable)
+	result, err := r.db.ExecContext(ctx, query, userId, filmId)
+	if err != nil {
+		r.log.Error(err.Error())
+		return ErrInternal
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ErrInternal
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+
+	return nil
+}
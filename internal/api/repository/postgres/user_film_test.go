@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jackc/pgx"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareUserFilmTest(t *testing.T) (sqlmock.Sqlmock, *sqlx.DB, *UserFilmPostgres) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	dbx := sqlx.NewDb(db, "sqlmock")
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	r := NewUserFilmPostgres(dbx, log)
+
+	return mock, dbx, r
+}
+
+func TestUserFilmPostgres_SetUserFilms(t *testing.T) {
+	mock, dbx, r := prepareUserFilmTest(t)
+	defer dbx.Close()
+
+	rating := int8(8)
+	watched := domain.NewDate(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	marks := []domain.UserFilm{
+		{UserId: 1, FilmId: 2, Rating: &rating, WatchedAt: &watched},
+		{UserId: 1, FilmId: 3},
+	}
+
+	t.Run("Saved", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, usersFilmsTable)).
+			WithArgs(1, 2, &rating, &watched.Time).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, usersFilmsTable)).
+			WithArgs(1, 3, nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, r.SetUserFilms(context.Background(), marks))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("UnknownFilm", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, usersFilmsTable)).
+			WithArgs(1, 2, &rating, &watched.Time).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(fmt.Sprintf(`INSERT INTO %s`, usersFilmsTable)).
+			WithArgs(1, 3, nil, nil).WillReturnError(pgx.PgError{Code: foreignErrCode})
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, r.SetUserFilms(context.Background(), marks), ErrForeign)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
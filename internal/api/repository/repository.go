@@ -1,42 +1,189 @@
 package repository
 
 import (
+	"context"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"github.com/jmoiron/sqlx"
 	"log/slog"
+	"time"
 )
 
 //go:generate mockery --all --dry-run=false
 
 type Authorization interface {
-	SignUp(user domain.User) (int, error)
-	GetUserByUsername(username string) (domain.User, error)
-	GetUserById(id int) (domain.User, error)
+	SignUp(ctx context.Context, user domain.User) (int, error)
+	GetUserByUsername(ctx context.Context, username string) (domain.User, error)
+	GetUserById(ctx context.Context, id int) (domain.User, error)
+	SetUserRole(ctx context.Context, id int, role int8) error
 }
 
 type Actor interface {
-	CreateActor(actor domain.Actor) (int, error)
-	DeleteActor(id int) error
-	UpdateActor(actor domain.Actor) error
-	PatchActor(actor domain.ActorInput) (domain.Actor, error)
-	ListActors(int) ([]domain.Actor, error)
+	CreateActor(ctx context.Context, userId int, actor domain.Actor) (int, error)
+	GetActor(ctx context.Context, id int) (domain.Actor, error)
+	DeleteActor(ctx context.Context, userId, id, version int) error
+	UpdateActor(ctx context.Context, userId int, action string, actor domain.Actor) (int, error)
+	PatchActor(ctx context.Context, userId int, actor domain.ActorInput) (domain.Actor, error)
+	ListActors(ctx context.Context, filmId int) ([]domain.Actor, error)
+	ExistingActorIds(ctx context.Context, ids []int) ([]int, error)
+	StreamActors(ctx context.Context, fn func(domain.Actor) error) error
 }
 
 type Film interface {
-	CreateFilm(film domain.Film, actorIds []int) (int, error)
-	DeleteFilm(id int) error
-	UpdateFilm(film domain.Film, actorIds []int) error
-	PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Film, error)
-	ListFilms(sortBy, sortDir string) ([]domain.Film, error)
-	SearchFilm(query string) ([]domain.Film, error)
-	ListFilmsByActor(sortBy, sortDir string, actorId int) ([]domain.Film, error)
+	CreateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error)
+	GetFilm(ctx context.Context, id int) (domain.Film, error)
+	DeleteFilm(ctx context.Context, userId, id, version int) error
+	UpdateFilm(ctx context.Context, userId int, action string, film domain.Film, actorIds []int) (int, error)
+	PatchFilm(ctx context.Context, userId int, input domain.NullableFilm, actorIds []int) (domain.Film, error)
+	ListFilms(ctx context.Context, sortBy, sortDir string, filter domain.FilmFilter) ([]domain.Film, error)
+	SearchFilm(ctx context.Context, query string) ([]domain.Film, error)
+	ListFilmsByActor(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter) ([]domain.Film, error)
+	ListFilmsActors(ctx context.Context) ([]domain.FilmActor, error)
+	StreamFilms(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter,
+		fn func(domain.Film) error) error
+}
+
+type Collection interface {
+	CreateCollection(ctx context.Context, collection domain.Collection) (int, error)
+	GetCollection(ctx context.Context, id int) (domain.Collection, error)
+	GetCollectionByToken(ctx context.Context, token string) (domain.Collection, error)
+	ListCollections(ctx context.Context, ownerId int) ([]domain.Collection, error)
+	ListFollowedCollections(ctx context.Context, userId int) ([]domain.Collection, error)
+	UpdateCollection(ctx context.Context, collection domain.Collection) error
+	PatchCollection(ctx context.Context, input domain.CollectionInput) (domain.Collection, error)
+	DeleteCollection(ctx context.Context, id int) error
+	FollowCollection(ctx context.Context, collectionId, userId int) error
+	UnfollowCollection(ctx context.Context, collectionId, userId int) error
+}
+
+type Copy interface {
+	CreateCopy(ctx context.Context, c domain.Copy) (int, error)
+	GetCopy(ctx context.Context, id int) (domain.Copy, error)
+	ListCopies(ctx context.Context, filmId int) ([]domain.Copy, error)
+	UpdateCopy(ctx context.Context, c domain.Copy) error
+	DeleteCopy(ctx context.Context, id int) error
+	CreateLoan(ctx context.Context, loan domain.Loan) (int, error)
+	ReturnCopy(ctx context.Context, copyId int, returnedAt time.Time) (domain.Loan, error)
+	ListLoans(ctx context.Context, copyId int) ([]domain.Loan, error)
+	ListOverdueLoans(ctx context.Context, date time.Time) ([]domain.Loan, error)
+}
+
+type UserFilm interface {
+	SetUserFilm(ctx context.Context, mark domain.UserFilm) error
+	SetUserFilms(ctx context.Context, marks []domain.UserFilm) error
+	ListUserFilms(ctx context.Context, userId int) ([]domain.UserFilm, error)
+	DeleteUserFilm(ctx context.Context, userId, filmId int) error
+}
+
+type Series interface {
+	CreateSeries(ctx context.Context, series domain.Series, actorIds []int) (int, error)
+	UpdateSeries(ctx context.Context, series domain.Series, actorIds []int) error
+	GetSeries(ctx context.Context, id int) (domain.Series, error)
+	ListSeries(ctx context.Context, sortBy, sortDir string) ([]domain.Series, error)
+	DeleteSeries(ctx context.Context, id int) error
+	CreateSeason(ctx context.Context, season domain.Season) (int, error)
+	DeleteSeason(ctx context.Context, id int) error
+	CreateEpisode(ctx context.Context, episode domain.Episode, actorIds []int) (int, error)
+	DeleteEpisode(ctx context.Context, id int) error
+	ListCatalog(ctx context.Context, sortBy, sortDir string) ([]domain.CatalogItem, error)
+	SearchCatalog(ctx context.Context, query string) ([]domain.CatalogItem, error)
+}
+
+type Translation interface {
+	SetFilmTranslation(ctx context.Context, translation domain.FilmTranslation) error
+	ListFilmTranslations(ctx context.Context, filmId int) ([]domain.FilmTranslation, error)
+	ListFilmTranslationsByLang(ctx context.Context, lang string) ([]domain.FilmTranslation, error)
+	DeleteFilmTranslation(ctx context.Context, filmId int, lang string) error
+	SetActorTranslation(ctx context.Context, translation domain.ActorTranslation) error
+	ListActorTranslations(ctx context.Context, actorId int) ([]domain.ActorTranslation, error)
+	ListActorTranslationsByLang(ctx context.Context, lang string) ([]domain.ActorTranslation, error)
+	DeleteActorTranslation(ctx context.Context, actorId int, lang string) error
+}
+
+type Relation interface {
+	CreateAlias(ctx context.Context, alias domain.FilmAlias) (int, error)
+	ListAliases(ctx context.Context, filmId int) ([]domain.FilmAlias, error)
+	DeleteAlias(ctx context.Context, id int) error
+	CreateRelation(ctx context.Context, relation domain.FilmRelation) error
+	ListRelations(ctx context.Context, filmId int) ([]domain.FilmRelation, error)
+	DeleteRelation(ctx context.Context, relation domain.FilmRelation) error
+	CreateFranchise(ctx context.Context, franchise domain.Franchise) (int, error)
+	GetFranchise(ctx context.Context, id int) (domain.Franchise, error)
+	ListFranchises(ctx context.Context) ([]domain.Franchise, error)
+	DeleteFranchise(ctx context.Context, id int) error
+	SetFranchiseFilm(ctx context.Context, franchiseId, filmId, position int) error
+	RemoveFranchiseFilm(ctx context.Context, franchiseId, filmId int) error
+}
+
+type Merge interface {
+	MergeActors(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error)
+	MergeFilms(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error)
+	ListMerges(ctx context.Context) ([]domain.Merge, error)
+}
+
+type Trash interface {
+	ListDeletedFilms(ctx context.Context) ([]domain.Film, error)
+	ListDeletedActors(ctx context.Context) ([]domain.Actor, error)
+	RestoreFilm(ctx context.Context, userId, id int) (domain.Film, error)
+	RestoreActor(ctx context.Context, userId, id int) (domain.Actor, error)
+	Purge(ctx context.Context, before time.Time) (domain.PurgeResult, error)
+}
+
+type Revision interface {
+	FilmSnapshot(ctx context.Context, id int) (domain.FilmSnapshot, error)
+	ActorSnapshot(ctx context.Context, id int) (domain.Actor, error)
+	ListRevisions(ctx context.Context, kind string, recordId int) ([]domain.Revision, error)
+	GetRevision(ctx context.Context, id int) (domain.Revision, error)
+}
+
+type Audit interface {
+	CreateEvent(ctx context.Context, event domain.AuditEvent) error
+	ListEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+	PurgeEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+type Suggestion interface {
+	CreateSuggestion(ctx context.Context, suggestion domain.Suggestion) (int, error)
+	GetSuggestion(ctx context.Context, id int) (domain.Suggestion, error)
+	ListSuggestions(ctx context.Context, status string) ([]domain.Suggestion, error)
+	ListUserSuggestions(ctx context.Context, userId int) ([]domain.Suggestion, error)
+	ReviewSuggestion(ctx context.Context, id, reviewerId int, status string, reason *string) (domain.Suggestion, error)
+	ApproveFilmSuggestion(ctx context.Context, id, reviewerId int, input domain.NullableFilm,
+		actorIds []int) (domain.Suggestion, error)
+	ApproveActorSuggestion(ctx context.Context, id, reviewerId int, input domain.ActorInput) (domain.Suggestion, error)
+}
+
+type Import interface {
+	ImportActors(ctx context.Context, userId int, records []domain.ActorRecord,
+		opts domain.ImportOptions) (domain.ImportResult, error)
+	ImportFilms(ctx context.Context, userId int, records []domain.FilmRecord,
+		opts domain.ImportOptions) (domain.ImportResult, error)
+	ImportIMDb(ctx context.Context, userId int, titles func() (domain.IMDbTitle, error),
+		principals func() (domain.IMDbPrincipal, error), names func() (domain.IMDbName, error)) (domain.IMDbResult, error)
+}
+
+type Export interface {
+	ExportFilms(ctx context.Context, filter domain.FilmFilter, actorId int, fn func(domain.Film) error) error
+	ExportActors(ctx context.Context, filmId int, fn func(domain.Actor) error) error
 }
 
 type Repository struct {
 	Authorization
 	Actor
 	Film
+	Collection
+	Copy
+	UserFilm
+	Series
+	Translation
+	Relation
+	Merge
+	Trash
+	Revision
+	Audit
+	Suggestion
+	Import
+	Export
 }
 
 func NewRepository(db *sqlx.DB, log *slog.Logger) *Repository {
@@ -44,5 +191,18 @@ func NewRepository(db *sqlx.DB, log *slog.Logger) *Repository {
 		Authorization: postgres.NewAuthPostgres(db, log),
 		Film:          postgres.NewFilmPostgres(db, log),
 		Actor:         postgres.NewActorPostgres(db, log),
+		Collection:    postgres.NewCollectionPostgres(db, log),
+		Copy:          postgres.NewCopyPostgres(db, log),
+		UserFilm:      postgres.NewUserFilmPostgres(db, log),
+		Series:        postgres.NewSeriesPostgres(db, log),
+		Translation:   postgres.NewTranslationPostgres(db, log),
+		Relation:      postgres.NewRelationPostgres(db, log),
+		Merge:         postgres.NewMergePostgres(db, log),
+		Trash:         postgres.NewTrashPostgres(db, log),
+		Revision:      postgres.NewRevisionPostgres(db, log),
+		Audit:         postgres.NewAuditPostgres(db, log),
+		Suggestion:    postgres.NewSuggestionPostgres(db, log),
+		Import:        postgres.NewImportPostgres(db, log),
+		Export:        postgres.NewExportPostgres(db, log),
 	}
 }
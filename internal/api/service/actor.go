@@ -1,36 +1,91 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"log/slog"
 )
 
 type ActorService struct {
 	repos repository.Actor
+	graph *graphCache
 	log   *slog.Logger
 }
 
-func (s *ActorService) PatchActor(actor domain.ActorInput) (domain.Actor, error) {
-	return s.repos.PatchActor(actor)
+func mapActorError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrUnique):
+		return ErrConflict
+	case errors.Is(err, postgres.ErrVersion):
+		return ErrPrecondition
+	default:
+		return err
+	}
 }
 
-func NewActorService(repos repository.Actor, log *slog.Logger) *ActorService {
-	return &ActorService{repos: repos, log: log}
+func (s *ActorService) PatchActor(ctx context.Context, userId int, actor domain.ActorInput) (domain.Actor, error) {
+	result, err := s.repos.PatchActor(ctx, userId, actor)
+	if err != nil {
+		return result, mapActorError(err)
+	}
+	s.graph.invalidate()
+	return result, nil
 }
 
-func (s *ActorService) CreateActor(actor domain.Actor) (int, error) {
-	return s.repos.CreateActor(actor)
+func NewActorService(repos repository.Actor, graph *graphCache, log *slog.Logger) *ActorService {
+	return &ActorService{repos: repos, graph: graph, log: log}
 }
 
-func (s *ActorService) DeleteActor(id int) error {
-	return s.repos.DeleteActor(id)
+func (s *ActorService) CreateActor(ctx context.Context, userId int, actor domain.Actor) (int, error) {
+	id, err := s.repos.CreateActor(ctx, userId, actor)
+	if err != nil {
+		return id, mapActorError(err)
+	}
+	s.graph.invalidate()
+	return id, nil
 }
 
-func (s *ActorService) UpdateActor(actor domain.Actor) error {
-	return s.repos.UpdateActor(actor)
+func (s *ActorService) GetActor(ctx context.Context, id int) (domain.Actor, error) {
+	actor, err := s.repos.GetActor(ctx, id)
+	return actor, mapActorError(err)
 }
 
-func (s *ActorService) ListActors(filmId int) (actors []domain.Actor, err error) {
-	return s.repos.ListActors(filmId)
+func (s *ActorService) DeleteActor(ctx context.Context, userId, id, version int) error {
+	if err := s.repos.DeleteActor(ctx, userId, id, version); err != nil {
+		return mapActorError(err)
+	}
+	s.graph.invalidate()
+	return nil
+}
+
+func (s *ActorService) UpdateActor(ctx context.Context, userId int, actor domain.Actor) (int, error) {
+	return s.updateActor(ctx, userId, domain.ActionUpdate, actor)
+}
+
+// RevertActor replaces the actor with the state kept in one of its revisions
+func (s *ActorService) RevertActor(ctx context.Context, userId int, actor domain.Actor) (int, error) {
+	return s.updateActor(ctx, userId, domain.ActionRevert, actor)
+}
+
+func (s *ActorService) updateActor(ctx context.Context, userId int, action string, actor domain.Actor) (int, error) {
+	version, err := s.repos.UpdateActor(ctx, userId, action, actor)
+	if err != nil {
+		return version, mapActorError(err)
+	}
+	s.graph.invalidate()
+	return version, nil
+}
+
+func (s *ActorService) ListActors(ctx context.Context, filmId int) (actors []domain.Actor, err error) {
+	return s.repos.ListActors(ctx, filmId)
+}
+
+// StreamActors passes the actors with their films to fn as they are read, until fn fails or ctx is done
+func (s *ActorService) StreamActors(ctx context.Context, fn func(domain.Actor) error) error {
+	return s.repos.StreamActors(ctx, fn)
 }
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"time"
+)
+
+const (
+	maxAuditIP        = 64
+	maxAuditUserAgent = 512
+)
+
+type AuditService struct {
+	repos repository.Audit
+	log   *slog.Logger
+}
+
+func NewAuditService(repos repository.Audit, log *slog.Logger) *AuditService {
+	return &AuditService{repos: repos, log: log}
+}
+
+// truncate cuts s to n characters, varchar limits count characters rather than bytes
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+func (s *AuditService) RecordEvent(ctx context.Context, event domain.AuditEvent) error {
+	event.IP = truncate(event.IP, maxAuditIP)
+	event.UserAgent = truncate(event.UserAgent, maxAuditUserAgent)
+	return s.repos.CreateEvent(ctx, event)
+}
+
+func (s *AuditService) ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	return s.repos.ListEvents(ctx, filter)
+}
+
+// PurgeAuditLog removes events older than retention
+func (s *AuditService) PurgeAuditLog(ctx context.Context, retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		return 0, ErrBadRequest
+	}
+	return s.repos.PurgeEvents(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func prepareAuditTest() (*mocks.Audit, *AuditService) {
+	repos := new(mocks.Audit)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewAuditService(repos, log)
+}
+
+func TestAuditService_RecordEvent(t *testing.T) {
+	repos, s := prepareAuditTest()
+	repos.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e domain.AuditEvent) bool {
+		return len(e.UserAgent) == maxAuditUserAgent
+	})).Return(nil)
+
+	err := s.RecordEvent(context.Background(), domain.AuditEvent{Event: domain.AuditSignIn, UserAgent: strings.Repeat("a", 2000)})
+	assert.NoError(t, err)
+	repos.AssertExpectations(t)
+}
+
+func TestAuditService_PurgeAuditLog(t *testing.T) {
+	t.Run("Retention", func(t *testing.T) {
+		repos, s := prepareAuditTest()
+		repos.On("PurgeEvents", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return time.Since(before) > 89*24*time.Hour && time.Since(before) < 91*24*time.Hour
+		})).Return(int64(4), nil)
+
+		count, err := s.PurgeAuditLog(context.Background(), 90*24*time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+	})
+	t.Run("NoRetention", func(t *testing.T) {
+		repos, s := prepareAuditTest()
+
+		_, err := s.PurgeAuditLog(context.Background(), 0)
+		assert.ErrorIs(t, err, ErrBadRequest)
+		repos.AssertNotCalled(t, "PurgeEvents", mock.Anything, mock.Anything)
+	})
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
@@ -16,12 +19,30 @@ type AuthService struct {
 	log   *slog.Logger
 }
 
-func (s *AuthService) GetUserById(id int) (domain.User, error) {
-	return s.repos.GetUserById(id)
+func (s *AuthService) GetUserById(ctx context.Context, id int) (domain.User, error) {
+	return s.repos.GetUserById(ctx, id)
+}
+
+// SetUserRole changes the role of the user and returns the previous one
+func (s *AuthService) SetUserRole(ctx context.Context, id int, role int8) (int8, error) {
+	user, err := s.repos.GetUserById(ctx, id)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	if err = s.repos.SetUserRole(ctx, id, role); err != nil {
+		if errors.Is(err, postgres.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return user.Role, nil
 }
 
 var (
-	ErrUserNotFound = fmt.Errorf("specified user not found")
+	ErrUserNotFound = apperr.New(apperr.CodeUnauthorized, "specified user not found")
 )
 
 func NewAuthService(repos repository.Authorization, log *slog.Logger) *AuthService {
@@ -75,14 +96,14 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
-func (s *AuthService) SignUp(user domain.User) error {
+func (s *AuthService) SignUp(ctx context.Context, user domain.User) error {
 	hash, err := HashPassword(user.Password)
 	if err != nil {
 		return fmt.Errorf("error hashing password: %w", err)
 	}
 	user.PasswordHash = hash
 
-	_, err = s.repos.SignUp(user)
+	_, err = s.repos.SignUp(ctx, user)
 	if err != nil {
 		return err
 	}
@@ -90,19 +111,20 @@ func (s *AuthService) SignUp(user domain.User) error {
 	return nil
 }
 
-func (s *AuthService) SignIn(username, password string) (string, error) {
-	user, err := s.repos.GetUserByUsername(username)
+// SignIn checks the credentials and returns a token along with the id of the signed in user
+func (s *AuthService) SignIn(ctx context.Context, username, password string) (string, int, error) {
+	user, err := s.repos.GetUserByUsername(ctx, username)
 	if err != nil {
-		return "", ErrUserNotFound
+		return "", 0, ErrUserNotFound
 	}
 	hash := user.PasswordHash
 	if CheckPassword(password, hash) {
 		token, err := GenerateJWT(user)
 		if err != nil {
-			return "", ErrInternal
+			return "", 0, ErrInternal
 		}
-		return token, nil
+		return token, user.Id, nil
 	}
 
-	return "", ErrUnauthorized
+	return "", 0, ErrUnauthorized
 }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+const (
+	GraphFormatGraphML = "graphml"
+	GraphFormatDOT     = "dot"
+)
+
+// coStars returns actors who played with the given one, mapped to their shared films in ascending order
+func (g *filmGraph) coStars(actorId int) map[int][]int {
+	result := make(map[int][]int)
+	for _, filmId := range g.actorFilms[actorId] {
+		for id := range g.filmActors[filmId] {
+			if id != actorId {
+				result[id] = append(result[id], filmId)
+			}
+		}
+	}
+	for id := range result {
+		slices.Sort(result[id])
+	}
+	return result
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+type CollaborationService struct {
+	graph *graphCache
+	log   *slog.Logger
+}
+
+func NewCollaborationService(graph *graphCache, log *slog.Logger) *CollaborationService {
+	return &CollaborationService{graph: graph, log: log}
+}
+
+func (s *CollaborationService) CoStars(ctx context.Context, actorId, limit int) ([]domain.CoStar, error) {
+	g, err := s.graph.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := g.actors[actorId]; !ok {
+		return nil, ErrNotFound
+	}
+
+	coStars := g.coStars(actorId)
+	result := make([]domain.CoStar, 0, len(coStars))
+	for id, filmIds := range coStars {
+		coStar := domain.CoStar{Actor: g.actors[id], SharedCount: len(filmIds), Films: make([]domain.Film, 0, len(filmIds))}
+		for _, filmId := range filmIds {
+			coStar.Films = append(coStar.Films, g.films[filmId])
+		}
+		result = append(result, coStar)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].SharedCount != result[j].SharedCount {
+			return result[i].SharedCount > result[j].SharedCount
+		}
+		return result[i].Actor.Id < result[j].Actor.Id
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// ActorPath finds the shortest chain of co-stars between two actors using breadth-first search
+func (s *CollaborationService) ActorPath(ctx context.Context, fromId, toId int) (domain.ActorPath, error) {
+	g, err := s.graph.get(ctx)
+	if err != nil {
+		return domain.ActorPath{}, err
+	}
+	if _, ok := g.actors[fromId]; !ok {
+		return domain.ActorPath{}, ErrNotFound
+	}
+	if _, ok := g.actors[toId]; !ok {
+		return domain.ActorPath{}, ErrNotFound
+	}
+
+	type link struct{ actorId, filmId int }
+	parents := map[int]link{fromId: {-1, -1}}
+	queue := []int{fromId}
+	for len(queue) > 0 && !containsKey(parents, toId) {
+		current := queue[0]
+		queue = queue[1:]
+
+		coStars := g.coStars(current)
+		for _, id := range sortedKeys(coStars) {
+			if _, ok := parents[id]; ok {
+				continue
+			}
+			parents[id] = link{current, coStars[id][0]}
+			queue = append(queue, id)
+		}
+	}
+	if !containsKey(parents, toId) {
+		return domain.ActorPath{}, ErrNoPath
+	}
+
+	steps := []domain.PathStep{{Actor: g.actors[toId]}}
+	for id := toId; parents[id].actorId != -1; id = parents[id].actorId {
+		film := g.films[parents[id].filmId]
+		steps = append(steps, domain.PathStep{Actor: g.actors[parents[id].actorId], Film: &film})
+	}
+	slices.Reverse(steps)
+
+	return domain.ActorPath{Degrees: len(steps) - 1, Steps: steps}, nil
+}
+
+func containsKey[V any](m map[int]V, key int) bool {
+	_, ok := m[key]
+	return ok
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	Id   string      `xml:"id,attr"`
+	Data graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Data   graphmlData `xml:"data"`
+}
+
+type graphmlKey struct {
+	Id       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   struct {
+		Id          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphmlNode `xml:"node"`
+		Edges       []graphmlEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func nodeId(actorId int) string {
+	return "a" + strconv.Itoa(actorId)
+}
+
+// ExportGraph writes the actor collaboration graph, weighted by the number of shared films
+func (s *CollaborationService) ExportGraph(ctx context.Context, w io.Writer, format string) error {
+	if format != GraphFormatGraphML && format != GraphFormatDOT {
+		return ErrBadRequest
+	}
+	g, err := s.graph.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	actorIds := sortedKeys(g.actors)
+	if format == GraphFormatDOT {
+		if _, err = io.WriteString(w, "graph collaborations {\n"); err != nil {
+			return err
+		}
+		for _, id := range actorIds {
+			if _, err = fmt.Fprintf(w, "  %s [label=%s];\n", nodeId(id), strconv.Quote(g.actors[id].Name)); err != nil {
+				return err
+			}
+		}
+		for _, id := range actorIds {
+			coStars := g.coStars(id)
+			for _, coStarId := range sortedKeys(coStars) {
+				if coStarId < id {
+					continue
+				}
+				_, err = fmt.Fprintf(w, "  %s -- %s [weight=%d];\n", nodeId(id), nodeId(coStarId), len(coStars[coStarId]))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = io.WriteString(w, "}\n")
+		return err
+	}
+
+	var doc graphmlDocument
+	doc.Xmlns = "http://graphml.graphdrawing.org/xmlns"
+	doc.Keys = []graphmlKey{
+		{Id: "name", For: "node", AttrName: "name", AttrType: "string"},
+		{Id: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+	}
+	doc.Graph.Id = "collaborations"
+	doc.Graph.EdgeDefault = "undirected"
+	for _, id := range actorIds {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{
+			Id: nodeId(id), Data: graphmlData{Key: "name", Value: g.actors[id].Name},
+		})
+		coStars := g.coStars(id)
+		for _, coStarId := range sortedKeys(coStars) {
+			if coStarId < id {
+				continue
+			}
+			doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+				Source: nodeId(id), Target: nodeId(coStarId),
+				Data: graphmlData{Key: "weight", Value: strconv.Itoa(len(coStars[coStarId]))},
+			})
+		}
+	}
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
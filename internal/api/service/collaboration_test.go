@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareCollaborationTest() *CollaborationService {
+	films := new(mocks.Film)
+	actors := new(mocks.Actor)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+
+	films.On("ListFilms", mock.Anything, "id", "asc", domain.FilmFilter{}).Return([]domain.Film{
+		{Id: 1, Title: "Solaris"}, {Id: 2, Title: "Stalker"}, {Id: 3, Title: "Mirror"}, {Id: 4, Title: "Nostalghia"},
+	}, nil)
+	actors.On("ListActors", mock.Anything, -1).Return([]domain.Actor{
+		{Id: 10, Name: "Banionis"}, {Id: 11, Name: "Solonitsyn"}, {Id: 12, Name: "Kaidanovsky"},
+		{Id: 13, Name: "Terekhova"}, {Id: 14, Name: "Jankowski \"Oleg\""},
+	}, nil)
+	films.On("ListFilmsActors", mock.Anything, mock.Anything).Return([]domain.FilmActor{
+		{FilmId: 1, ActorId: 10}, {FilmId: 1, ActorId: 11},
+		{FilmId: 2, ActorId: 11}, {FilmId: 2, ActorId: 12},
+		{FilmId: 3, ActorId: 11}, {FilmId: 3, ActorId: 13},
+		{FilmId: 4, ActorId: 14},
+	}, nil)
+
+	return NewCollaborationService(newGraphCache(films, actors), log)
+}
+
+func TestCollaborationService_ActorPath(t *testing.T) {
+	s := prepareCollaborationTest()
+
+	t.Run("TwoDegrees", func(t *testing.T) {
+		got, err := s.ActorPath(context.Background(), 10, 12)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, got.Degrees)
+		assert.Len(t, got.Steps, 3)
+		assert.Equal(t, 10, got.Steps[0].Actor.Id)
+		assert.Equal(t, 1, got.Steps[0].Film.Id)
+		assert.Equal(t, 11, got.Steps[1].Actor.Id)
+		assert.Equal(t, 2, got.Steps[1].Film.Id)
+		assert.Equal(t, 12, got.Steps[2].Actor.Id)
+		assert.Nil(t, got.Steps[2].Film)
+	})
+
+	t.Run("SameActor", func(t *testing.T) {
+		got, err := s.ActorPath(context.Background(), 10, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, got.Degrees)
+	})
+
+	t.Run("NotConnected", func(t *testing.T) {
+		_, err := s.ActorPath(context.Background(), 10, 14)
+		assert.ErrorIs(t, err, ErrNoPath)
+	})
+
+	t.Run("UnknownActor", func(t *testing.T) {
+		_, err := s.ActorPath(context.Background(), 10, 99)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestCollaborationService_CoStars(t *testing.T) {
+	s := prepareCollaborationTest()
+
+	got, err := s.CoStars(context.Background(), 11, 2)
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, 10, got[0].Actor.Id)
+	assert.Equal(t, 1, got[0].SharedCount)
+	assert.Equal(t, "Solaris", got[0].Films[0].Title)
+}
+
+func TestCollaborationService_ExportGraph(t *testing.T) {
+	s := prepareCollaborationTest()
+
+	t.Run("DOT", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, s.ExportGraph(context.Background(), &buf, GraphFormatDOT))
+		assert.Contains(t, buf.String(), `a14 [label="Jankowski \"Oleg\""];`)
+		assert.Contains(t, buf.String(), "a10 -- a11 [weight=1];")
+		assert.NotContains(t, buf.String(), "a11 -- a10")
+	})
+
+	t.Run("GraphML", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, s.ExportGraph(context.Background(), &buf, GraphFormatGraphML))
+		assert.Contains(t, buf.String(), `<edge source="a11" target="a12">`)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.ErrorIs(t, s.ExportGraph(context.Background(), &buf, "gexf"), ErrBadRequest)
+	})
+}
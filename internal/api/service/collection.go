@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+)
+
+const shareTokenLength = 16
+
+type CollectionService struct {
+	repos repository.Collection
+	log   *slog.Logger
+}
+
+func NewCollectionService(repos repository.Collection, log *slog.Logger) *CollectionService {
+	return &CollectionService{repos: repos, log: log}
+}
+
+func generateShareToken() (string, error) {
+	b := make([]byte, shareTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func mapCollectionError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrForeign), errors.Is(err, postgres.ErrUnique):
+		return ErrBadRequest
+	default:
+		return err
+	}
+}
+
+// getOwned returns the collection only if it belongs to the user
+func (s *CollectionService) getOwned(ctx context.Context, userId, id int) (domain.Collection, error) {
+	collection, err := s.repos.GetCollection(ctx, id)
+	if err != nil {
+		return collection, mapCollectionError(err)
+	}
+	if collection.OwnerId != userId {
+		if collection.IsPublic {
+			return collection, ErrForbidden
+		}
+		return collection, ErrNotFound
+	}
+	return collection, nil
+}
+
+func (s *CollectionService) CreateCollection(ctx context.Context, userId int, collection domain.Collection) (int, error) {
+	var err error
+	collection.OwnerId = userId
+	collection.ShareToken, err = generateShareToken()
+	if err != nil {
+		return 0, ErrInternal
+	}
+
+	id, err := s.repos.CreateCollection(ctx, collection)
+	return id, mapCollectionError(err)
+}
+
+func (s *CollectionService) GetCollection(ctx context.Context, userId, id int) (domain.Collection, error) {
+	collection, err := s.repos.GetCollection(ctx, id)
+	if err != nil {
+		return collection, mapCollectionError(err)
+	}
+	if collection.OwnerId != userId {
+		if !collection.IsPublic {
+			return domain.Collection{}, ErrNotFound
+		}
+		collection.ShareToken = ""
+	}
+	return collection, nil
+}
+
+func (s *CollectionService) GetSharedCollection(ctx context.Context, token string) (domain.Collection, error) {
+	collection, err := s.repos.GetCollectionByToken(ctx, token)
+	if err != nil {
+		return collection, mapCollectionError(err)
+	}
+	if !collection.IsPublic {
+		return domain.Collection{}, ErrNotFound
+	}
+	collection.ShareToken = ""
+	return collection, nil
+}
+
+func (s *CollectionService) ListCollections(ctx context.Context, userId int) ([]domain.Collection, error) {
+	return s.repos.ListCollections(ctx, userId)
+}
+
+func (s *CollectionService) ListFollowedCollections(ctx context.Context, userId int) ([]domain.Collection, error) {
+	collections, err := s.repos.ListFollowedCollections(ctx, userId)
+	for i := range collections {
+		collections[i].ShareToken = ""
+	}
+	return collections, err
+}
+
+func (s *CollectionService) UpdateCollection(ctx context.Context, userId int, collection domain.Collection) error {
+	if _, err := s.getOwned(ctx, userId, collection.Id); err != nil {
+		return err
+	}
+	return mapCollectionError(s.repos.UpdateCollection(ctx, collection))
+}
+
+func (s *CollectionService) PatchCollection(ctx context.Context, userId int, input domain.CollectionInput) (domain.Collection, error) {
+	if _, err := s.getOwned(ctx, userId, input.Id); err != nil {
+		return domain.Collection{}, err
+	}
+	collection, err := s.repos.PatchCollection(ctx, input)
+	return collection, mapCollectionError(err)
+}
+
+func (s *CollectionService) DeleteCollection(ctx context.Context, userId, id int) error {
+	if _, err := s.getOwned(ctx, userId, id); err != nil {
+		return err
+	}
+	return mapCollectionError(s.repos.DeleteCollection(ctx, id))
+}
+
+func (s *CollectionService) FollowCollection(ctx context.Context, userId, id int) error {
+	collection, err := s.GetCollection(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+	if collection.OwnerId == userId {
+		return ErrBadRequest
+	}
+	return mapCollectionError(s.repos.FollowCollection(ctx, id, userId))
+}
+
+func (s *CollectionService) UnfollowCollection(ctx context.Context, userId, id int) error {
+	return mapCollectionError(s.repos.UnfollowCollection(ctx, id, userId))
+}
+
+func (s *CollectionService) CloneCollection(ctx context.Context, userId, id int) (int, error) {
+	collection, err := s.GetCollection(ctx, userId, id)
+	if err != nil {
+		return 0, err
+	}
+
+	collection.ClonedFrom = &collection.Id
+	collection.IsPublic = false
+	return s.CreateCollection(ctx, userId, collection)
+}
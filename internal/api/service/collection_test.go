@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareCollectionTest() (*mocks.Collection, *CollectionService) {
+	repos := new(mocks.Collection)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewCollectionService(repos, log)
+}
+
+func TestCollectionService_GetCollection(t *testing.T) {
+	t.Run("PrivateForeign", func(t *testing.T) {
+		repos, s := prepareCollectionTest()
+		repos.On("GetCollection", mock.Anything, 1).Return(domain.Collection{Id: 1, OwnerId: 2}, nil)
+
+		_, err := s.GetCollection(context.Background(), 3, 1)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("PublicForeignHidesToken", func(t *testing.T) {
+		repos, s := prepareCollectionTest()
+		repos.On("GetCollection", mock.Anything, 1).
+			Return(domain.Collection{Id: 1, OwnerId: 2, IsPublic: true, ShareToken: "token"}, nil)
+
+		got, err := s.GetCollection(context.Background(), 3, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, got.ShareToken)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		repos, s := prepareCollectionTest()
+		repos.On("GetCollection", mock.Anything, 1).Return(domain.Collection{}, postgres.ErrNoRows)
+
+		_, err := s.GetCollection(context.Background(), 3, 1)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestCollectionService_PatchCollection(t *testing.T) {
+	t.Run("PublicForeign", func(t *testing.T) {
+		repos, s := prepareCollectionTest()
+		repos.On("GetCollection", mock.Anything, 1).Return(domain.Collection{Id: 1, OwnerId: 2, IsPublic: true}, nil)
+
+		_, err := s.PatchCollection(context.Background(), 3, domain.CollectionInput{Id: 1})
+		assert.ErrorIs(t, err, ErrForbidden)
+		repos.AssertNotCalled(t, "PatchCollection", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCollectionService_CloneCollection(t *testing.T) {
+	t.Run("PublicForeign", func(t *testing.T) {
+		repos, s := prepareCollectionTest()
+		source := domain.Collection{
+			Id:       1,
+			OwnerId:  2,
+			Title:    "Best of Soviet sci-fi",
+			IsPublic: true,
+			Items:    []domain.CollectionItem{{FilmId: 5, Note: "note"}},
+		}
+		repos.On("GetCollection", mock.Anything, 1).Return(source, nil)
+		repos.On("CreateCollection", mock.Anything, mock.MatchedBy(func(c domain.Collection) bool {
+			return c.OwnerId == 3 && !c.IsPublic && c.ClonedFrom != nil && *c.ClonedFrom == 1 &&
+				c.ShareToken != "" && len(c.Items) == 1
+		})).Return(7, nil)
+
+		got, err := s.CloneCollection(context.Background(), 3, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, got)
+		repos.AssertExpectations(t)
+	})
+}
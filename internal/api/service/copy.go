@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"time"
+)
+
+type CopyService struct {
+	repos repository.Copy
+	log   *slog.Logger
+}
+
+func NewCopyService(repos repository.Copy, log *slog.Logger) *CopyService {
+	return &CopyService{repos: repos, log: log}
+}
+
+func mapCopyError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrForeign):
+		return ErrBadRequest
+	case errors.Is(err, postgres.ErrUnique):
+		return ErrConflict
+	default:
+		return err
+	}
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func (s *CopyService) CreateCopy(ctx context.Context, c domain.Copy) (int, error) {
+	id, err := s.repos.CreateCopy(ctx, c)
+	return id, mapCopyError(err)
+}
+
+func (s *CopyService) ListCopies(ctx context.Context, filmId int) ([]domain.Copy, error) {
+	return s.repos.ListCopies(ctx, filmId)
+}
+
+func (s *CopyService) UpdateCopy(ctx context.Context, c domain.Copy) error {
+	return mapCopyError(s.repos.UpdateCopy(ctx, c))
+}
+
+// DeleteCopy removes a copy that has never been lent. The loan history of a lent copy is kept
+func (s *CopyService) DeleteCopy(ctx context.Context, id int) error {
+	err := s.repos.DeleteCopy(ctx, id)
+	if errors.Is(err, postgres.ErrForeign) {
+		return ErrConflict
+	}
+	return mapCopyError(err)
+}
+
+func (s *CopyService) LendCopy(ctx context.Context, loan domain.Loan) (int, error) {
+	if (loan.UserId == nil) == (loan.Borrower == "") {
+		return 0, ErrBadRequest
+	}
+	lentAt := today()
+	if loan.DueDate.Before(lentAt) {
+		return 0, ErrBadRequest
+	}
+
+	c, err := s.repos.GetCopy(ctx, loan.CopyId)
+	if err != nil {
+		return 0, mapCopyError(err)
+	}
+	if c.OnLoan {
+		return 0, ErrConflict
+	}
+
+	loan.LentAt = domain.NewDate(lentAt)
+	id, err := s.repos.CreateLoan(ctx, loan)
+	return id, mapCopyError(err)
+}
+
+func (s *CopyService) ReturnCopy(ctx context.Context, copyId int) (domain.Loan, error) {
+	loan, err := s.repos.ReturnCopy(ctx, copyId, today())
+	return loan, mapCopyError(err)
+}
+
+func (s *CopyService) ListLoans(ctx context.Context, copyId int) ([]domain.Loan, error) {
+	if _, err := s.repos.GetCopy(ctx, copyId); err != nil {
+		return nil, mapCopyError(err)
+	}
+	return s.repos.ListLoans(ctx, copyId)
+}
+
+func (s *CopyService) ListOverdueLoans(ctx context.Context) ([]domain.Loan, error) {
+	return s.repos.ListOverdueLoans(ctx, today())
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareCopyTest() (*mocks.Copy, *CopyService) {
+	repos := new(mocks.Copy)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewCopyService(repos, log)
+}
+
+func TestCopyService_DeleteCopy(t *testing.T) {
+	repos, s := prepareCopyTest()
+	repos.On("DeleteCopy", mock.Anything, 1).Return(postgres.ErrForeign)
+
+	err := s.DeleteCopy(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestCopyService_LendCopy(t *testing.T) {
+	userId := 5
+	due := domain.NewDate(today().AddDate(0, 0, 14))
+
+	t.Run("BorrowerAndUser", func(t *testing.T) {
+		repos, s := prepareCopyTest()
+		_, err := s.LendCopy(context.Background(), domain.Loan{CopyId: 1, UserId: &userId, Borrower: "Иван", DueDate: due})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		repos.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("DueInPast", func(t *testing.T) {
+		repos, s := prepareCopyTest()
+		past := domain.NewDate(today().Add(-24 * time.Hour))
+		_, err := s.LendCopy(context.Background(), domain.Loan{CopyId: 1, Borrower: "Иван", DueDate: past})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		repos.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AlreadyLent", func(t *testing.T) {
+		repos, s := prepareCopyTest()
+		repos.On("GetCopy", mock.Anything, 1).Return(domain.Copy{Id: 1, OnLoan: true}, nil)
+		_, err := s.LendCopy(context.Background(), domain.Loan{CopyId: 1, UserId: &userId, DueDate: due})
+		assert.ErrorIs(t, err, ErrConflict)
+		repos.AssertNotCalled(t, "CreateLoan", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Available", func(t *testing.T) {
+		repos, s := prepareCopyTest()
+		repos.On("GetCopy", mock.Anything, 1).Return(domain.Copy{Id: 1}, nil)
+		repos.On("CreateLoan", mock.Anything, mock.MatchedBy(func(l domain.Loan) bool {
+			return l.LentAt == domain.NewDate(today()) && *l.UserId == userId
+		})).Return(9, nil)
+		got, err := s.LendCopy(context.Background(), domain.Loan{CopyId: 1, UserId: &userId, DueDate: due})
+		assert.NoError(t, err)
+		assert.Equal(t, 9, got)
+		repos.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+	ExportFormatJSON   = "json"
+)
+
+var (
+	filmExportColumns = []string{"id", "title", "description", "released", "rating", "runtime", "countries",
+		"original_language", "languages", "age_rating", "budget", "box_office", "genres", "cast"}
+	actorExportColumns = []string{"id", "name", "gender", "birthday", "films"}
+)
+
+// exportWriter writes records one by one: a json document per line, a CSV row or an element of a json array
+type exportWriter struct {
+	format string
+	w      io.Writer
+	enc    *json.Encoder
+	csv    *csv.Writer
+	rows   int
+}
+
+func newExportWriter(w io.Writer, format string, columns []string) (*exportWriter, error) {
+	out := &exportWriter{format: format, w: w}
+	switch format {
+	case ExportFormatNDJSON, ExportFormatJSON:
+		out.enc = json.NewEncoder(w)
+	case ExportFormatCSV:
+		out.csv = csv.NewWriter(w)
+		if err := out.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrBadRequest
+	}
+	return out, nil
+}
+
+// write adds a record, record builds its CSV row
+func (out *exportWriter) write(v any, record func() []string) error {
+	out.rows++
+	switch out.format {
+	case ExportFormatCSV:
+		return out.csv.Write(record())
+	case ExportFormatJSON:
+		sep := ","
+		if out.rows == 1 {
+			sep = "["
+		}
+		if _, err := io.WriteString(out.w, sep); err != nil {
+			return err
+		}
+	}
+	return out.enc.Encode(v)
+}
+
+func (out *exportWriter) close() error {
+	switch out.format {
+	case ExportFormatCSV:
+		out.csv.Flush()
+		return out.csv.Error()
+	case ExportFormatJSON:
+		end := "]\n"
+		if out.rows == 0 {
+			end = "[]\n"
+		}
+		_, err := io.WriteString(out.w, end)
+		return err
+	}
+	return nil
+}
+
+func dateString(d domain.CustomDate) string {
+	if !d.Valid() {
+		return ""
+	}
+	return d.String()
+}
+
+// filmExportRecord flattens a film into a CSV row. The cast is written as in imports: "name|birthday" separated by semicolons
+func filmExportRecord(film domain.Film) []string {
+	var description, rating string
+	if film.Description != nil {
+		description = *film.Description
+	}
+	if film.Rating != nil {
+		rating = strconv.Itoa(int(*film.Rating))
+	}
+	cast := make([]string, len(film.Actors))
+	for i, actor := range film.Actors {
+		cast[i] = actor.Name + "|" + dateString(actor.Birthday)
+	}
+	return []string{strconv.Itoa(film.Id), film.Title, description, dateString(film.Released), rating,
+		strconv.Itoa(film.Runtime), strings.Join(film.Countries, ";"), film.OriginalLanguage,
+		strings.Join(film.Languages, ";"), film.AgeRating, strconv.FormatInt(film.Budget, 10),
+		strconv.FormatInt(film.BoxOffice, 10), strings.Join(film.Genres, ";"), strings.Join(cast, ";")}
+}
+
+// actorExportRecord flattens an actor into a CSV row, films are "title|released" separated by semicolons
+func actorExportRecord(actor domain.Actor) []string {
+	var gender string
+	if actor.Gender != nil {
+		gender = strconv.Itoa(*actor.Gender)
+	}
+	films := make([]string, len(actor.Films))
+	for i, film := range actor.Films {
+		films[i] = film.Title + "|" + dateString(film.Released)
+	}
+	return []string{strconv.Itoa(actor.Id), actor.Name, gender, dateString(actor.Birthday), strings.Join(films, ";")}
+}
+
+type ExportService struct {
+	repos repository.Export
+	log   *slog.Logger
+}
+
+func NewExportService(repos repository.Export, log *slog.Logger) *ExportService {
+	return &ExportService{repos: repos, log: log}
+}
+
+// ExportFilms writes the films matching the filter with their cast as they are read from the database.
+// An actorId other than -1 keeps the films of that actor only
+func (s *ExportService) ExportFilms(ctx context.Context, w io.Writer, format string, filter domain.FilmFilter, actorId int) error {
+	out, err := newExportWriter(w, format, filmExportColumns)
+	if err != nil {
+		return err
+	}
+	err = s.repos.ExportFilms(ctx, filter, actorId, func(film domain.Film) error {
+		return out.write(film, func() []string { return filmExportRecord(film) })
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// ExportActors writes the actors with their filmography as they are read from the database.
+// A filmId other than -1 keeps the cast of that film only
+func (s *ExportService) ExportActors(ctx context.Context, w io.Writer, format string, filmId int) error {
+	out, err := newExportWriter(w, format, actorExportColumns)
+	if err != nil {
+		return err
+	}
+	err = s.repos.ExportActors(ctx, filmId, func(actor domain.Actor) error {
+		return out.write(actor, func() []string { return actorExportRecord(actor) })
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
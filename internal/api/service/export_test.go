@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareExportTest() (*mocks.Export, *ExportService) {
+	repos := new(mocks.Export)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewExportService(repos, log)
+}
+
+func TestExportService_ExportFilms(t *testing.T) {
+	rating := int8(8)
+	films := []domain.Film{
+		{Id: 1, Title: "Brother", Released: mustDate("1997-05"), Rating: &rating, Runtime: 100,
+			Countries: domain.StringList{"RU"}, Languages: domain.StringList{}, Genres: domain.StringList{"Crime", "Drama"},
+			Actors: []domain.Actor{{Id: 3, Name: "Sergei Bodrov", Birthday: mustDate("1971-12-27")}, {Id: 4, Name: "Kirill Pirogov"}}},
+		{Id: 2, Title: "Brother 2", Countries: domain.StringList{}, Languages: domain.StringList{}, Genres: domain.StringList{}},
+	}
+	tests := []struct {
+		format string
+		films  []domain.Film
+		want   string
+	}{
+		{format: ExportFormatCSV, films: films, want: "id,title,description,released,rating,runtime,countries," +
+			"original_language,languages,age_rating,budget,box_office,genres,cast\n" +
+			"1,Brother,,1997-05,8,100,RU,,,,0,0,Crime;Drama,Sergei Bodrov|1971-12-27;Kirill Pirogov|\n" +
+			"2,Brother 2,,,,0,,,,,0,0,,\n"},
+		{format: ExportFormatJSON, films: nil, want: "[]\n"},
+		{format: ExportFormatJSON, films: films[1:], want: `[{"id":2,"title":"Brother 2","description":null,` +
+			`"released":null,"rating":null,"runtime":0,"countries":[],"originalLanguage":"","languages":[],` +
+			`"ageRating":"","budget":0,"boxOffice":0,"genres":[]}` + "\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			repos, s := prepareExportTest()
+			repos.On("ExportFilms", mock.Anything, domain.FilmFilter{}, -1, mock.Anything).Run(func(args mock.Arguments) {
+				fn := args.Get(3).(func(domain.Film) error)
+				for _, film := range tt.films {
+					assert.NoError(t, fn(film))
+				}
+			}).Return(nil)
+
+			var buf bytes.Buffer
+			assert.NoError(t, s.ExportFilms(context.Background(), &buf, tt.format, domain.FilmFilter{}, -1))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+	t.Run("UnknownFormat", func(t *testing.T) {
+		_, s := prepareExportTest()
+		assert.ErrorIs(t, s.ExportFilms(context.Background(), new(bytes.Buffer), "xml", domain.FilmFilter{}, -1), ErrBadRequest)
+	})
+}
+
+func TestExportService_ExportActors(t *testing.T) {
+	repos, s := prepareExportTest()
+	repos.On("ExportActors", mock.Anything, 5, mock.Anything).Run(func(args mock.Arguments) {
+		fn := args.Get(2).(func(domain.Actor) error)
+		assert.NoError(t, fn(domain.Actor{Id: 3, Name: "Sergei Bodrov", Films: []domain.Film{{Id: 1, Title: "Brother"}}}))
+		assert.NoError(t, fn(domain.Actor{Id: 4, Name: "Kirill Pirogov"}))
+	}).Return(nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.ExportActors(context.Background(), &buf, ExportFormatNDJSON, 5))
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+	assert.Contains(t, buf.String(), `"films":[{"id":1,"title":"Brother"`)
+}
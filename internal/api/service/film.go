@@ -1,44 +1,146 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	"log/slog"
+	"sort"
 )
 
 type FilmService struct {
-	repos repository.Film
-	log   *slog.Logger
+	repos  repository.Film
+	actors repository.Actor
+	graph  *graphCache
+	log    *slog.Logger
 }
 
-func (s FilmService) PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Film, error) {
-	return s.repos.PatchFilm(input, actorIds)
+// PatchFilm sets the fields given in input. nil actorIds leave the cast as is, an empty list clears it
+func (s FilmService) PatchFilm(ctx context.Context, userId int, input domain.NullableFilm, actorIds []int) (domain.Film, error) {
+	actorIds, err := checkActors(ctx, s.actors, actorIds)
+	if err != nil {
+		return domain.Film{}, err
+	}
+	film, err := s.repos.PatchFilm(ctx, userId, input, actorIds)
+	if err != nil {
+		return film, mapFilmError(err)
+	}
+	s.graph.invalidate()
+	return film, nil
 }
 
-func NewFilmService(repos repository.Film, log *slog.Logger) *FilmService {
-	return &FilmService{repos: repos, log: log}
+func NewFilmService(repos repository.Film, actors repository.Actor, graph *graphCache, log *slog.Logger) *FilmService {
+	return &FilmService{repos: repos, actors: actors, graph: graph, log: log}
 }
 
-func (s FilmService) CreateFilm(film domain.Film, actorIds []int) (int, error) {
-	return s.repos.CreateFilm(film, actorIds)
+func mapFilmError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrForeign):
+		return ErrUnprocessable
+	case errors.Is(err, postgres.ErrVersion):
+		return ErrPrecondition
+	default:
+		return err
+	}
+}
+
+// checkActors removes repeated ids and makes sure every actor exists. nil stays nil
+func checkActors(ctx context.Context, actors repository.Actor, actorIds []int) ([]int, error) {
+	if actorIds == nil {
+		return nil, nil
+	}
+	ids := uniqueIds(actorIds)
+	existing, err := actors.ExistingActorIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]struct{}, len(existing))
+	for _, id := range existing {
+		found[id] = struct{}{}
+	}
+	var unknown []int
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Ints(unknown)
+		return nil, &UnknownActorsError{Ids: unknown}
+	}
+
+	return ids, nil
 }
 
-func (s FilmService) DeleteFilm(id int) error {
-	return s.repos.DeleteFilm(id)
+func (s FilmService) CreateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	actorIds, err := checkActors(ctx, s.actors, actorIds)
+	if err != nil {
+		return 0, err
+	}
+	id, err := s.repos.CreateFilm(ctx, userId, film, actorIds)
+	if err != nil {
+		return id, mapFilmError(err)
+	}
+	s.graph.invalidate()
+	return id, nil
 }
 
-func (s FilmService) UpdateFilm(film domain.Film, actorIds []int) error {
-	return s.repos.UpdateFilm(film, actorIds)
+func (s FilmService) GetFilm(ctx context.Context, id int) (domain.Film, error) {
+	film, err := s.repos.GetFilm(ctx, id)
+	return film, mapFilmError(err)
 }
 
-func (s FilmService) ListFilms(sortBy, sortDir string, actorId int) ([]domain.Film, error) {
+func (s FilmService) DeleteFilm(ctx context.Context, userId, id, version int) error {
+	if err := s.repos.DeleteFilm(ctx, userId, id, version); err != nil {
+		return mapFilmError(err)
+	}
+	s.graph.invalidate()
+	return nil
+}
+
+func (s FilmService) UpdateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	return s.updateFilm(ctx, userId, domain.ActionUpdate, film, actorIds)
+}
+
+// RevertFilm replaces the film with the state kept in one of its revisions
+func (s FilmService) RevertFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	return s.updateFilm(ctx, userId, domain.ActionRevert, film, actorIds)
+}
+
+func (s FilmService) updateFilm(ctx context.Context, userId int, action string, film domain.Film, actorIds []int) (int, error) {
+	actorIds, err := checkActors(ctx, s.actors, actorIds)
+	if err != nil {
+		return 0, err
+	}
+	version, err := s.repos.UpdateFilm(ctx, userId, action, film, actorIds)
+	if err != nil {
+		return version, mapFilmError(err)
+	}
+	s.graph.invalidate()
+	return version, nil
+}
+
+func (s FilmService) ListFilms(ctx context.Context, sortBy, sortDir string, actorId int,
+	filter domain.FilmFilter) ([]domain.Film, error) {
 	if actorId != -1 {
-		return s.repos.ListFilmsByActor(sortBy, sortDir, actorId)
+		return s.repos.ListFilmsByActor(ctx, sortBy, sortDir, actorId, filter)
 	} else {
-		return s.repos.ListFilms(sortBy, sortDir)
+		return s.repos.ListFilms(ctx, sortBy, sortDir, filter)
 	}
 }
 
-func (s FilmService) SearchFilm(query string) ([]domain.Film, error) {
-	return s.repos.SearchFilm(query)
+// StreamFilms passes the films ListFilms would return, with their cast, to fn as they are read,
+// until fn fails or ctx is done
+func (s FilmService) StreamFilms(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter,
+	fn func(domain.Film) error) error {
+	return s.repos.StreamFilms(ctx, sortBy, sortDir, actorId, filter, fn)
+}
+
+func (s FilmService) SearchFilm(ctx context.Context, query string) ([]domain.Film, error) {
+	return s.repos.SearchFilm(ctx, query)
 }
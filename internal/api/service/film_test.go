@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareFilmTest() (*mocks.Film, *mocks.Actor, *FilmService) {
+	films, actors := new(mocks.Film), new(mocks.Actor)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return films, actors, NewFilmService(films, actors, newGraphCache(films, actors), log)
+}
+
+func TestFilmService_CreateFilm(t *testing.T) {
+	t.Run("UnknownActors", func(t *testing.T) {
+		films, actors, s := prepareFilmTest()
+		actors.On("ExistingActorIds", mock.Anything, []int{7, 1, 3}).Return([]int{1}, nil)
+
+		_, err := s.CreateFilm(context.Background(), 1, domain.Film{Title: "Brother"}, []int{7, 1, 3})
+		var unknownErr *UnknownActorsError
+		if assert.ErrorAs(t, err, &unknownErr) {
+			assert.Equal(t, []int{3, 7}, unknownErr.Ids)
+		}
+		assert.ErrorIs(t, err, ErrUnprocessable)
+		films.AssertNotCalled(t, "CreateFilm", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RepeatedActors", func(t *testing.T) {
+		films, actors, s := prepareFilmTest()
+		film := domain.Film{Title: "Brother"}
+		actors.On("ExistingActorIds", mock.Anything, []int{2, 1}).Return([]int{1, 2}, nil)
+		films.On("CreateFilm", mock.Anything, 1, film, []int{2, 1}).Return(5, nil)
+
+		s.graph.graph, s.graph.builtAt = &filmGraph{}, time.Now()
+
+		id, err := s.CreateFilm(context.Background(), 1, film, []int{2, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, id)
+		assert.Nil(t, s.graph.graph)
+		films.AssertExpectations(t)
+	})
+	t.Run("RevisionFailed", func(t *testing.T) {
+		films, _, s := prepareFilmTest()
+		film := domain.Film{Title: "Brother"}
+		films.On("CreateFilm", mock.Anything, 1, film, []int(nil)).Return(0, postgres.ErrInternal)
+
+		s.graph.graph, s.graph.builtAt = &filmGraph{}, time.Now()
+
+		_, err := s.CreateFilm(context.Background(), 1, film, nil)
+		assert.ErrorIs(t, err, postgres.ErrInternal)
+		assert.NotNil(t, s.graph.graph)
+	})
+}
+
+func TestFilmService_UpdateFilm(t *testing.T) {
+	t.Run("UnknownActors", func(t *testing.T) {
+		films, actors, s := prepareFilmTest()
+		actors.On("ExistingActorIds", mock.Anything, []int{4}).Return([]int{}, nil)
+
+		_, err := s.UpdateFilm(context.Background(), 1, domain.Film{Id: 1}, []int{4, 4})
+		assert.EqualError(t, err, "unknown actor ids: 4")
+		films.AssertNotCalled(t, "UpdateFilm", mock.Anything, mock.Anything, mock.Anything, mock.Anything,
+			mock.Anything)
+	})
+	t.Run("StaleVersion", func(t *testing.T) {
+		films, actors, s := prepareFilmTest()
+		actors.On("ExistingActorIds", mock.Anything, []int{4}).Return([]int{4}, nil)
+		film := domain.Film{Id: 1, Version: 2}
+		films.On("UpdateFilm", mock.Anything, 1, domain.ActionUpdate, film, []int{4}).Return(0, postgres.ErrVersion)
+
+		_, err := s.UpdateFilm(context.Background(), 1, film, []int{4})
+		assert.ErrorIs(t, err, ErrPrecondition)
+	})
+	t.Run("Revert", func(t *testing.T) {
+		films, actors, s := prepareFilmTest()
+		actors.On("ExistingActorIds", mock.Anything, []int{4}).Return([]int{4}, nil)
+		film := domain.Film{Id: 1, Title: "Brother"}
+		films.On("UpdateFilm", mock.Anything, 1, domain.ActionRevert, film, []int{4}).Return(3, nil)
+
+		version, err := s.RevertFilm(context.Background(), 1, film, []int{4})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, version)
+		films.AssertExpectations(t)
+	})
+}
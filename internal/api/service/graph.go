@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"strings"
+	"sync"
+	"time"
+)
+
+const graphTTL = 5 * time.Minute
+
+// filmGraph is an in-memory copy of the film-actor bipartite graph along with the genres of films.
+// Genres are compared in lower case
+type filmGraph struct {
+	films      map[int]domain.Film
+	actors     map[int]domain.Actor
+	filmActors map[int]map[int]struct{}
+	actorFilms map[int][]int
+	filmGenres map[int]map[string]struct{}
+	genreFilms map[string][]int
+}
+
+func buildFilmGraph(films []domain.Film, actors []domain.Actor, links []domain.FilmActor) *filmGraph {
+	g := &filmGraph{
+		films:      make(map[int]domain.Film, len(films)),
+		actors:     make(map[int]domain.Actor, len(actors)),
+		filmActors: make(map[int]map[int]struct{}, len(films)),
+		actorFilms: make(map[int][]int, len(actors)),
+		filmGenres: make(map[int]map[string]struct{}, len(films)),
+		genreFilms: make(map[string][]int),
+	}
+	for _, film := range films {
+		g.films[film.Id] = film
+		g.filmActors[film.Id] = make(map[int]struct{})
+		genres := make(map[string]struct{}, len(film.Genres))
+		for _, genre := range film.Genres {
+			genre = strings.ToLower(genre)
+			if _, ok := genres[genre]; !ok {
+				genres[genre] = struct{}{}
+				g.genreFilms[genre] = append(g.genreFilms[genre], film.Id)
+			}
+		}
+		g.filmGenres[film.Id] = genres
+	}
+	for _, actor := range actors {
+		g.actors[actor.Id] = actor
+	}
+	for _, link := range links {
+		cast, ok := g.filmActors[link.FilmId]
+		if !ok {
+			continue
+		}
+		cast[link.ActorId] = struct{}{}
+		g.actorFilms[link.ActorId] = append(g.actorFilms[link.ActorId], link.FilmId)
+	}
+	return g
+}
+
+// graphBuild is a graph load shared by everyone who asked for the graph while it was running
+type graphBuild struct {
+	done  chan struct{}
+	graph *filmGraph
+	err   error
+}
+
+// graphCache shares one graph between services and rebuilds it from the repository once graphTTL has passed
+// or a film or an actor has been written. The graph is loaded outside the mutex by a single build
+// and swapped in when it's done
+type graphCache struct {
+	films   repository.Film
+	actors  repository.Actor
+	mu      sync.Mutex
+	graph   *filmGraph
+	builtAt time.Time
+	build   *graphBuild
+	gen     uint64
+}
+
+func newGraphCache(films repository.Film, actors repository.Actor) *graphCache {
+	return &graphCache{films: films, actors: actors}
+}
+
+func (c *graphCache) get(ctx context.Context) (*filmGraph, error) {
+	c.mu.Lock()
+	if c.graph != nil && time.Since(c.builtAt) < graphTTL {
+		g := c.graph
+		c.mu.Unlock()
+		return g, nil
+	}
+	b := c.build
+	if b == nil {
+		b = &graphBuild{done: make(chan struct{})}
+		c.build = b
+		go c.run(context.WithoutCancel(ctx), b, c.gen)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return b.graph, b.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run loads the graph for b and keeps it unless the cache was invalidated in the meantime
+func (c *graphCache) run(ctx context.Context, b *graphBuild, gen uint64) {
+	b.graph, b.err = c.load(ctx)
+
+	c.mu.Lock()
+	if c.build == b {
+		c.build = nil
+	}
+	if b.err == nil && c.gen == gen {
+		c.graph = b.graph
+		c.builtAt = time.Now()
+	}
+	c.mu.Unlock()
+	close(b.done)
+}
+
+func (c *graphCache) load(ctx context.Context) (*filmGraph, error) {
+	films, err := c.films.ListFilms(ctx, "id", "asc", domain.FilmFilter{})
+	if err != nil {
+		return nil, err
+	}
+	actors, err := c.actors.ListActors(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+	links, err := c.films.ListFilmsActors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildFilmGraph(films, actors, links), nil
+}
+
+// invalidate drops the graph after a write. A build that is already running may have read
+// the old data, so the next get starts a new one. A nil cache, as the import tool has, does nothing
+func (c *graphCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.gen++
+	c.graph = nil
+	c.build = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"sync"
+	"testing"
+)
+
+func prepareGraphTest(release <-chan struct{}) (*mocks.Film, *graphCache) {
+	films, actors := new(mocks.Film), new(mocks.Actor)
+	call := films.On("ListFilms", mock.Anything, "id", "asc", domain.FilmFilter{}).
+		Return([]domain.Film{{Id: 1, Title: "Solaris"}}, nil)
+	if release != nil {
+		call.Run(func(mock.Arguments) { <-release })
+	}
+	actors.On("ListActors", mock.Anything, -1).Return([]domain.Actor{{Id: 10, Name: "Banionis"}}, nil)
+	films.On("ListFilmsActors", mock.Anything).Return([]domain.FilmActor{{FilmId: 1, ActorId: 10}}, nil)
+	return films, newGraphCache(films, actors)
+}
+
+func TestGraphCache_get(t *testing.T) {
+	t.Run("SingleBuild", func(t *testing.T) {
+		release := make(chan struct{})
+		films, c := prepareGraphTest(release)
+
+		var wg sync.WaitGroup
+		graphs := make([]*filmGraph, 8)
+		for i := range graphs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				graphs[i], _ = c.get(context.Background())
+			}()
+		}
+		close(release)
+		wg.Wait()
+
+		films.AssertNumberOfCalls(t, "ListFilms", 1)
+		for _, g := range graphs {
+			assert.Same(t, graphs[0], g)
+		}
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		films, c := prepareGraphTest(nil)
+
+		first, err := c.get(context.Background())
+		assert.NoError(t, err)
+		c.invalidate()
+		second, err := c.get(context.Background())
+		assert.NoError(t, err)
+
+		assert.NotSame(t, first, second)
+		films.AssertNumberOfCalls(t, "ListFilms", 2)
+	})
+
+	t.Run("InvalidateWhileBuilding", func(t *testing.T) {
+		release := make(chan struct{})
+		films, c := prepareGraphTest(release)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.get(context.Background())
+		}()
+		for {
+			c.mu.Lock()
+			building := c.build != nil
+			c.mu.Unlock()
+			if building {
+				break
+			}
+		}
+		c.invalidate()
+		close(release)
+		<-done
+
+		c.mu.Lock()
+		assert.Nil(t, c.graph)
+		c.mu.Unlock()
+		_, err := c.get(context.Background())
+		assert.NoError(t, err)
+		films.AssertNumberOfCalls(t, "ListFilms", 2)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		_, c := prepareGraphTest(release)
+		defer close(release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.get(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	imdbNull       = `\N`
+	maxTitleLength = 150
+	maxNameLength  = 255
+	maxRuntime     = 1000
+	maxGenreLength = 32
+)
+
+var (
+	defaultIMDbTitleTypes = []string{"movie", "tvMovie"}
+	defaultIMDbCategories = []string{"actor", "actress"}
+)
+
+// tsvReader reads an IMDb dataset: a header line, tab separated values without quoting, \N for missing values.
+// Gzipped files are unpacked on the fly
+type tsvReader struct {
+	dataset string
+	scanner *bufio.Scanner
+	columns map[string]int
+	line    int
+}
+
+func newTSVReader(r io.Reader, dataset string, required ...string) (*tsvReader, error) {
+	if r == nil {
+		return nil, importFileError("%s dataset is missing", dataset)
+	}
+	buffered := bufio.NewReader(r)
+	var src io.Reader = buffered
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, importFileError("%s: %s", dataset, err)
+		}
+		src = gz
+	}
+
+	t := &tsvReader{dataset: dataset, scanner: bufio.NewScanner(src)}
+	t.scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+	header, err := t.read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, importFileError("%s: file is empty", dataset)
+		}
+		return nil, err
+	}
+	t.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		t.columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := t.columns[name]; !ok {
+			return nil, importFileError("%s: column %q is missing", dataset, name)
+		}
+	}
+	return t, nil
+}
+
+func (t *tsvReader) read() ([]string, error) {
+	if !t.scanner.Scan() {
+		if err := t.scanner.Err(); err != nil {
+			return nil, importFileError("%s: line %d: %s", t.dataset, t.line+1, err)
+		}
+		return nil, io.EOF
+	}
+	t.line++
+	return strings.Split(t.scanner.Text(), "\t"), nil
+}
+
+// get returns the value of the column, empty for a missing one
+func (t *tsvReader) get(row []string, column string) string {
+	i := t.columns[column]
+	if i >= len(row) || row[i] == imdbNull {
+		return ""
+	}
+	return row[i]
+}
+
+// imdbGenres splits the comma separated genres of a title
+func imdbGenres(s string) []string {
+	genres := make([]string, 0, 3)
+	for _, genre := range strings.Split(s, ",") {
+		if genre != "" {
+			genres = append(genres, truncate(genre, maxGenreLength))
+		}
+	}
+	return genres
+}
+
+// imdbId takes the number out of an IMDb id like tt0118767, sets of numbers are much smaller than of strings
+func imdbId(id, prefix string) (uint32, bool) {
+	if !strings.HasPrefix(id, prefix) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(id[len(prefix):], 10, 32)
+	return uint32(n), err == nil
+}
+
+func stringSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+// ImportIMDb loads titles of the chosen types from title.basics, their cast of the chosen categories
+// from title.principals and those people from name.basics. Only ids are kept in memory,
+// the rows are streamed to the database. Every film and actor of the datasets gets a revision
+// on behalf of userId
+func (s *ImportService) ImportIMDb(ctx context.Context, userId int, files domain.IMDbFiles,
+	opts domain.IMDbOptions) (domain.IMDbResult, error) {
+	if len(opts.TitleTypes) == 0 {
+		opts.TitleTypes = defaultIMDbTitleTypes
+	}
+	if len(opts.Categories) == 0 {
+		opts.Categories = defaultIMDbCategories
+	}
+
+	titles, err := newTSVReader(files.Titles, "title.basics",
+		"tconst", "titleType", "primaryTitle", "isAdult", "startYear", "runtimeMinutes", "genres")
+	if err != nil {
+		return domain.IMDbResult{}, err
+	}
+	principals, err := newTSVReader(files.Principals, "title.principals", "tconst", "nconst", "category")
+	if err != nil {
+		return domain.IMDbResult{}, err
+	}
+	names, err := newTSVReader(files.Names, "name.basics", "nconst", "primaryName", "birthYear")
+	if err != nil {
+		return domain.IMDbResult{}, err
+	}
+
+	types, categories := stringSet(opts.TitleTypes), stringSet(opts.Categories)
+	films, people := make(map[uint32]struct{}), make(map[uint32]struct{})
+	skipped := 0
+
+	nextTitle := func() (domain.IMDbTitle, error) {
+		for {
+			row, err := titles.read()
+			if err != nil {
+				return domain.IMDbTitle{}, err
+			}
+			tconst, title := titles.get(row, "tconst"), titles.get(row, "primaryTitle")
+			id, ok := imdbId(tconst, "tt")
+			if _, typeOk := types[titles.get(row, "titleType")]; !ok || !typeOk || title == "" ||
+				titles.get(row, "isAdult") == "1" {
+				skipped++
+				continue
+			}
+			films[id] = struct{}{}
+
+			rec := domain.IMDbTitle{Tconst: tconst, Title: truncate(title, maxTitleLength)}
+			rec.Released, _ = domain.ParseDate(titles.get(row, "startYear"))
+			if runtime, err := strconv.Atoi(titles.get(row, "runtimeMinutes")); err == nil &&
+				runtime >= 0 && runtime <= maxRuntime {
+				rec.Runtime = runtime
+			}
+			rec.Genres = imdbGenres(titles.get(row, "genres"))
+			return rec, nil
+		}
+	}
+	nextPrincipal := func() (domain.IMDbPrincipal, error) {
+		for {
+			row, err := principals.read()
+			if err != nil {
+				return domain.IMDbPrincipal{}, err
+			}
+			tconst, nconst := principals.get(row, "tconst"), principals.get(row, "nconst")
+			filmId, _ := imdbId(tconst, "tt")
+			personId, ok := imdbId(nconst, "nm")
+			_, film := films[filmId]
+			if _, category := categories[principals.get(row, "category")]; !ok || !film || !category {
+				skipped++
+				continue
+			}
+			people[personId] = struct{}{}
+			return domain.IMDbPrincipal{Tconst: tconst, Nconst: nconst}, nil
+		}
+	}
+	nextName := func() (domain.IMDbName, error) {
+		for {
+			row, err := names.read()
+			if err != nil {
+				return domain.IMDbName{}, err
+			}
+			nconst, name := names.get(row, "nconst"), names.get(row, "primaryName")
+			id, _ := imdbId(nconst, "nm")
+			if _, ok := people[id]; !ok || name == "" {
+				skipped++
+				continue
+			}
+			rec := domain.IMDbName{Nconst: nconst, Name: truncate(name, maxNameLength)}
+			rec.Birthday, _ = domain.ParseDate(names.get(row, "birthYear"))
+			return rec, nil
+		}
+	}
+
+	result, err := s.repos.ImportIMDb(ctx, userId, nextTitle, nextPrincipal, nextName)
+	result.Skipped = skipped
+	if err == nil {
+		s.graph.invalidate()
+	}
+	return result, err
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) io.Reader {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	return &buf
+}
+
+// drain reads a stream the way the repository does, until io.EOF
+func drain[T any](t *testing.T, next func() (T, error)) []T {
+	var rows []T
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if !assert.NoError(t, err) {
+			return rows
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestImportService_ImportIMDb(t *testing.T) {
+	t.Run("Filter", func(t *testing.T) {
+		repos, s := prepareImportTest()
+		var titles []domain.IMDbTitle
+		var principals []domain.IMDbPrincipal
+		var names []domain.IMDbName
+		repos.On("ImportIMDb", mock.Anything, 1, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			titles = drain(t, args.Get(2).(func() (domain.IMDbTitle, error)))
+			principals = drain(t, args.Get(3).(func() (domain.IMDbPrincipal, error)))
+			names = drain(t, args.Get(4).(func() (domain.IMDbName, error)))
+		}).Return(domain.IMDbResult{Titles: 1, FilmsCreated: 1}, nil)
+
+		files := domain.IMDbFiles{
+			Titles: gzipped(t, "tconst\ttitleType\tprimaryTitle\toriginalTitle\tisAdult\tstartYear\tendYear\truntimeMinutes\tgenres\n"+
+				"tt0118767\tmovie\tBrother\tBrat\t0\t1997\t\\N\t100\tCrime,Drama\n"+
+				"tt0185906\ttvSeries\tBand of Brothers\tBand of Brothers\t0\t2001\t2001\t594\tDrama\n"),
+			Principals: strings.NewReader("tconst\tordering\tnconst\tcategory\tjob\tcharacters\n" +
+				"tt0118767\t1\tnm0091788\tactor\t\\N\t[\"Danila\"]\n" +
+				"tt0118767\t2\tnm0000001\tdirector\t\\N\t\\N\n" +
+				"tt0185906\t1\tnm0000002\tactor\t\\N\t\\N\n"),
+			Names: strings.NewReader("nconst\tprimaryName\tbirthYear\tdeathYear\tprimaryProfession\tknownForTitles\n" +
+				"nm0000001\tAleksei Balabanov\t1959\t2013\tdirector\ttt0118767\n" +
+				"nm0091788\tSergei Bodrov\t1971\t2002\tactor\ttt0118767\n"),
+		}
+		got, err := s.ImportIMDb(context.Background(), 1, files, domain.IMDbOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.IMDbTitle{
+			{Tconst: "tt0118767", Title: "Brother", Released: mustDate("1997"), Runtime: 100,
+				Genres: []string{"Crime", "Drama"}},
+		}, titles)
+		assert.Equal(t, []domain.IMDbPrincipal{{Tconst: "tt0118767", Nconst: "nm0091788"}}, principals)
+		assert.Equal(t, []domain.IMDbName{{Nconst: "nm0091788", Name: "Sergei Bodrov", Birthday: mustDate("1971")}}, names)
+		assert.Equal(t, 4, got.Skipped)
+		assert.Equal(t, int64(1), got.FilmsCreated)
+	})
+	t.Run("MissingColumn", func(t *testing.T) {
+		_, s := prepareImportTest()
+		_, err := s.ImportIMDb(context.Background(), 1, domain.IMDbFiles{
+			Titles:     strings.NewReader("tconst\tprimaryTitle\n"),
+			Principals: strings.NewReader(""),
+			Names:      strings.NewReader(""),
+		}, domain.IMDbOptions{})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, apperr.CodeInvalid, apperr.CodeOf(err))
+	})
+}
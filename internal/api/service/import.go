@@ -0,0 +1,417 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/validation"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	// maxImportLine is the longest NDJSON line accepted
+	maxImportLine = 1 << 20
+)
+
+var (
+	actorColumns = []string{"key", "name", "gender", "birthday"}
+	filmColumns  = []string{"key", "title", "description", "released", "rating", "runtime", "countries",
+		"original_language", "languages", "age_rating", "budget", "box_office", "genres", "cast"}
+)
+
+// importFileError rejects the whole file, message tells what is wrong with it
+func importFileError(format string, args ...any) error {
+	return &apperr.Error{Code: apperr.CodeInvalid, Message: fmt.Sprintf(format, args...), Err: ErrBadRequest}
+}
+
+type ImportService struct {
+	repos    repository.Import
+	validate *validator.Validate
+	graph    *graphCache
+	log      *slog.Logger
+}
+
+func NewImportService(repos repository.Import, graph *graphCache, log *slog.Logger) *ImportService {
+	return &ImportService{repos: repos, validate: validation.New(), graph: graph, log: log}
+}
+
+// ImportActors upserts actors from a CSV or NDJSON file. Rows that can't be parsed or validated
+// are reported along with the rows the database refused. Every saved actor gets a revision
+// on behalf of userId
+func (s *ImportService) ImportActors(ctx context.Context, userId int, r io.Reader, format string,
+	opts domain.ImportOptions) (domain.ImportResult, error) {
+	if err := s.checkOptions(format, opts); err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	var records []domain.ActorRecord
+	var rowErrs []domain.ImportRowError
+	add := func(rec domain.ActorRecord, fields []domain.FieldError) {
+		fields = append(fields, s.check("key", s.validate.Var(rec.Key, "lte=64"))...)
+		fields = append(fields, s.check("", s.validate.Struct(rec.Actor))...)
+		if len(fields) > 0 {
+			rowErrs = append(rowErrs, invalidRow(rec.Row, rec.Key, fields))
+			return
+		}
+		records = append(records, rec)
+	}
+
+	var err error
+	if format == ImportFormatCSV {
+		err = readCSV(r, actorColumns, "name", func(row csvRow) {
+			rec := domain.ActorRecord{Row: row.line, Key: row.str("key")}
+			rec.Name = row.str("name")
+			rec.Gender = row.optInt("gender")
+			rec.Birthday = row.date("birthday")
+			add(rec, row.fields)
+		})
+	} else {
+		err = readNDJSON(r, func(line int, data []byte) error {
+			var rec domain.ActorRecord
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			rec.Row = line
+			add(rec, nil)
+			return nil
+		}, &rowErrs)
+	}
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	return s.write(ctx, len(records)+len(rowErrs), rowErrs, opts, func(opts domain.ImportOptions) (domain.ImportResult, error) {
+		return s.repos.ImportActors(ctx, userId, records, opts)
+	})
+}
+
+// ImportFilms upserts films from a CSV or NDJSON file. The cast refers to actors that exist
+// or come earlier in the same import. Every saved film gets a revision on behalf of userId
+func (s *ImportService) ImportFilms(ctx context.Context, userId int, r io.Reader, format string,
+	opts domain.ImportOptions) (domain.ImportResult, error) {
+	if err := s.checkOptions(format, opts); err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	var records []domain.FilmRecord
+	var rowErrs []domain.ImportRowError
+	add := func(rec domain.FilmRecord, fields []domain.FieldError) {
+		fields = append(fields, s.check("key", s.validate.Var(rec.Key, "lte=64"))...)
+		fields = append(fields, s.check("", s.validate.Struct(rec.Film))...)
+		for i, ref := range rec.Cast {
+			fields = append(fields, s.check(fmt.Sprintf("cast[%d]", i), s.validate.Struct(ref))...)
+		}
+		if len(fields) > 0 {
+			rowErrs = append(rowErrs, invalidRow(rec.Row, rec.Key, fields))
+			return
+		}
+		records = append(records, rec)
+	}
+
+	var err error
+	if format == ImportFormatCSV {
+		err = readCSV(r, filmColumns, "title", func(row csvRow) {
+			rec := domain.FilmRecord{Row: row.line, Key: row.str("key")}
+			rec.Title = row.str("title")
+			rec.Description = row.optStr("description")
+			rec.Released = row.date("released")
+			if rating := row.optInt("rating"); rating != nil {
+				if *rating < 0 || *rating > 10 {
+					row.fail("rating", "must be between 0 and 10")
+				} else {
+					value := int8(*rating)
+					rec.Rating = &value
+				}
+			}
+			rec.Runtime = int(row.int64("runtime"))
+			rec.Countries = row.list("countries")
+			rec.OriginalLanguage = row.str("original_language")
+			rec.Languages = row.list("languages")
+			rec.AgeRating = row.str("age_rating")
+			rec.Budget = row.int64("budget")
+			rec.BoxOffice = row.int64("box_office")
+			rec.Genres = row.list("genres")
+			rec.Cast = row.cast("cast")
+			add(rec, row.fields)
+		})
+	} else {
+		err = readNDJSON(r, func(line int, data []byte) error {
+			var rec domain.FilmRecord
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			rec.Row = line
+			add(rec, nil)
+			return nil
+		}, &rowErrs)
+	}
+	if err != nil {
+		return domain.ImportResult{}, err
+	}
+
+	return s.write(ctx, len(records)+len(rowErrs), rowErrs, opts, func(opts domain.ImportOptions) (domain.ImportResult, error) {
+		return s.repos.ImportFilms(ctx, userId, records, opts)
+	})
+}
+
+func (s *ImportService) checkOptions(format string, opts domain.ImportOptions) error {
+	if format != ImportFormatCSV && format != ImportFormatNDJSON {
+		return importFileError("unsupported format %q, expected csv or ndjson", format)
+	}
+	if err := s.validate.Struct(opts); err != nil {
+		return importFileError("unknown import mode %q, expected atomic or best_effort", opts.Mode)
+	}
+	return nil
+}
+
+// check puts validation errors of a record part under the prefix
+func (s *ImportService) check(prefix string, err error) []domain.FieldError {
+	fields := validation.FieldErrors(err)
+	for i := range fields {
+		switch {
+		case fields[i].Field == "":
+			fields[i].Field = prefix
+		case prefix != "":
+			fields[i].Field = prefix + "." + fields[i].Field
+		}
+	}
+	return fields
+}
+
+func invalidRow(row int, key string, fields []domain.FieldError) domain.ImportRowError {
+	return domain.ImportRowError{Row: row, Key: key, Message: "validation failed", Fields: fields}
+}
+
+// write saves the valid records and adds the rows rejected before reaching the database to the result.
+// An atomic import with rejected rows can't be committed, but the rest still gets a dry run
+// so that every problem is reported at once
+func (s *ImportService) write(ctx context.Context, rows int, rowErrs []domain.ImportRowError, opts domain.ImportOptions,
+	save func(domain.ImportOptions) (domain.ImportResult, error)) (domain.ImportResult, error) {
+	if opts.Mode == domain.ImportAtomic && len(rowErrs) > 0 {
+		opts.DryRun = true
+	}
+	result, err := save(opts)
+	if err != nil {
+		return result, err
+	}
+	if !opts.DryRun {
+		s.graph.invalidate()
+	}
+
+	result.Rows = rows
+	result.Failed += len(rowErrs)
+	result.Errors = append(result.Errors, rowErrs...)
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+	return result, nil
+}
+
+// readNDJSON calls fn for every non empty line. Lines fn can't decode are added to rowErrs
+func readNDJSON(r io.Reader, fn func(line int, data []byte) error, rowErrs *[]domain.ImportRowError) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := fn(line, data); err != nil {
+			*rowErrs = append(*rowErrs, domain.ImportRowError{Row: line, Message: "couldn't parse row: " + err.Error()})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return importFileError("line is longer than %d bytes", maxImportLine)
+		}
+		return importFileError("couldn't read file: %s", err)
+	}
+	return nil
+}
+
+// csvRow is a CSV record by column name. Cells that fail to parse are collected in fields
+type csvRow struct {
+	line   int
+	cells  map[string]string
+	fields []domain.FieldError
+}
+
+func (row *csvRow) fail(column, message string) {
+	row.fields = append(row.fields, domain.FieldError{Field: column, Rule: "format", Message: message})
+}
+
+func (row *csvRow) str(column string) string {
+	return strings.TrimSpace(row.cells[column])
+}
+
+func (row *csvRow) optStr(column string) *string {
+	if v := row.str(column); v != "" {
+		return &v
+	}
+	return nil
+}
+
+func (row *csvRow) optInt(column string) *int {
+	v := row.str(column)
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		row.fail(column, "must be a whole number")
+		return nil
+	}
+	return &n
+}
+
+func (row *csvRow) int64(column string) int64 {
+	v := row.str(column)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		row.fail(column, "must be a whole number")
+	}
+	return n
+}
+
+func (row *csvRow) date(column string) domain.CustomDate {
+	v := row.str(column)
+	if v == "" {
+		return domain.CustomDate{}
+	}
+	date, err := domain.ParseDate(v)
+	if err != nil {
+		row.fail(column, "must be a date in YYYY, YYYY-MM or YYYY-MM-DD format")
+	}
+	return date
+}
+
+// list splits a cell of values separated by semicolons
+func (row *csvRow) list(column string) domain.StringList {
+	list := make(domain.StringList, 0)
+	for _, v := range strings.Split(row.str(column), ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// cast reads references separated by semicolons: an import key or "name|birthday", the birthday may be empty.
+// Without the column the cast is nil and stays as it is
+func (row *csvRow) cast(column string) []domain.CastRef {
+	if _, ok := row.cells[column]; !ok {
+		return nil
+	}
+	refs := make([]domain.CastRef, 0)
+	for _, v := range row.list(column) {
+		name, birthday, ok := strings.Cut(v, "|")
+		if !ok {
+			refs = append(refs, domain.CastRef{Key: v})
+			continue
+		}
+		ref := domain.CastRef{Name: strings.TrimSpace(name)}
+		if birthday = strings.TrimSpace(birthday); birthday != "" {
+			date, err := domain.ParseDate(birthday)
+			if err != nil {
+				row.fail(column, "birthdays must be dates in YYYY, YYYY-MM or YYYY-MM-DD format")
+			}
+			ref.Birthday = date
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// readCSV reads a file with a header of known columns, the required one among them, and calls fn for every record
+func readCSV(r io.Reader, columns []string, required string, fn func(row csvRow)) error {
+	return scanCSV(r, required, func(name string) (string, error) {
+		if !contains(columns, name) {
+			return "", importFileError("unknown column %q, expected some of: %s", name, strings.Join(columns, ", "))
+		}
+		return name, nil
+	}, fn)
+}
+
+// scanCSV reads a file with a header and calls fn for every record. column gives the name a header cell
+// is known by, the cell is skipped when the name is empty
+func scanCSV(r io.Reader, required string, column func(name string) (string, error), fn func(row csvRow)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return importFileError("file is empty")
+		}
+		return importFileError("couldn't read header: %s", err)
+	}
+	names := make([]string, len(header))
+	seen := make(map[string]struct{}, len(header))
+	for i, name := range header {
+		name, err = column(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))))
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			return importFileError("column %q is repeated", name)
+		}
+		seen[name] = struct{}{}
+		names[i] = name
+	}
+	if _, ok := seen[required]; !ok {
+		return importFileError("column %q is required", required)
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return importFileError("couldn't read file: %s", err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := csvRow{line: line, cells: make(map[string]string, len(seen))}
+		for i, name := range names {
+			if name == "" {
+				continue
+			}
+			row.cells[name] = ""
+			if i < len(record) {
+				row.cells[name] = record[i]
+			}
+		}
+		fn(row)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func prepareImportTest() (*mocks.Import, *ImportService) {
+	repos := new(mocks.Import)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewImportService(repos, nil, log)
+}
+
+func TestImportService_ImportFilms(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		repos, s := prepareImportTest()
+		opts := domain.ImportOptions{Mode: domain.ImportBestEffort}
+		repos.On("ImportFilms", mock.Anything, 1, mock.MatchedBy(func(records []domain.FilmRecord) bool {
+			rec := records[0]
+			return len(records) == 1 && rec.Row == 2 && rec.Key == "tt0118767" && rec.Title == "Brother" &&
+				rec.Released.String() == "1997-05" && *rec.Rating == 8 && rec.Runtime == 100 &&
+				assert.ObjectsAreEqual(domain.StringList{"RU"}, rec.Countries) &&
+				assert.ObjectsAreEqual([]domain.CastRef{{Key: "nm0091788"},
+					{Name: "Viktor Sukhorukov", Birthday: mustDate("1951-11-10")}}, rec.Cast)
+		}), opts).Return(domain.ImportResult{Created: 1, Committed: true,
+			Errors: make([]domain.ImportRowError, 0)}, nil)
+
+		file := "key,title,description,released,rating,runtime,countries,cast\n" +
+			"tt0118767,Brother,Danila comes back from the army,1997-05,8,100,RU,nm0091788;Viktor Sukhorukov|1951-11-10\n" +
+			",Brother 2,,2000,11,abc,,\n"
+		got, err := s.ImportFilms(context.Background(), 1, strings.NewReader(file), ImportFormatCSV, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, got.Rows)
+		assert.Equal(t, 1, got.Created)
+		assert.Equal(t, 1, got.Failed)
+		assert.Equal(t, 3, got.Errors[0].Row)
+		assert.ElementsMatch(t, []domain.FieldError{
+			{Field: "runtime", Rule: "format", Message: "must be a whole number"},
+			{Field: "rating", Rule: "format", Message: "must be between 0 and 10"},
+			{Field: "description", Rule: "required", Message: "is required"},
+		}, got.Errors[0].Fields)
+	})
+	t.Run("AtomicWithInvalidRows", func(t *testing.T) {
+		repos, s := prepareImportTest()
+		repos.On("ImportFilms", mock.Anything, 1, mock.Anything, domain.ImportOptions{DryRun: true, Mode: domain.ImportAtomic}).
+			Return(domain.ImportResult{Created: 1, Errors: make([]domain.ImportRowError, 0)}, nil)
+
+		file := `{"title": "Brother", "description": "", "released": "1997", "cast": [{"key": "nm0091788"}]}` + "\n\n" + `{"title": ""}` + "\n" + `{"title": 5}`
+		got, err := s.ImportFilms(context.Background(), 1, strings.NewReader(file), ImportFormatNDJSON,
+			domain.ImportOptions{Mode: domain.ImportAtomic})
+		assert.NoError(t, err)
+		assert.False(t, got.Committed)
+		assert.Equal(t, 3, got.Rows)
+		assert.Equal(t, 2, got.Failed)
+		assert.Equal(t, 3, got.Errors[0].Row)
+		assert.Equal(t, "title", got.Errors[0].Fields[0].Field)
+		assert.Equal(t, 4, got.Errors[1].Row)
+		assert.Contains(t, got.Errors[1].Message, "couldn't parse row")
+	})
+	t.Run("UnknownColumn", func(t *testing.T) {
+		_, s := prepareImportTest()
+		_, err := s.ImportFilms(context.Background(), 1, strings.NewReader("title,director\nBrother,Balabanov\n"), ImportFormatCSV,
+			domain.ImportOptions{Mode: domain.ImportAtomic})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		assert.Equal(t, apperr.CodeInvalid, apperr.CodeOf(err))
+	})
+}
+
+func TestImportService_ImportActors(t *testing.T) {
+	repos, s := prepareImportTest()
+	opts := domain.ImportOptions{Mode: domain.ImportAtomic}
+	gender := 1
+	repos.On("ImportActors", mock.Anything, 1, []domain.ActorRecord{
+		{Row: 2, Key: "nm0091788", Actor: domain.Actor{Name: "Sergei Bodrov", Gender: &gender, Birthday: mustDate("1971-12-27")}},
+	}, opts).Return(domain.ImportResult{Updated: 1, Committed: true, Errors: make([]domain.ImportRowError, 0)}, nil)
+
+	got, err := s.ImportActors(context.Background(), 1, strings.NewReader("key,name,gender,birthday\nnm0091788,Sergei Bodrov,1,1971-12-27\n"),
+		ImportFormatCSV, opts)
+	assert.NoError(t, err)
+	assert.True(t, got.Committed)
+	assert.Equal(t, 1, got.Updated)
+}
+
+func mustDate(s string) domain.CustomDate {
+	date, err := domain.ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
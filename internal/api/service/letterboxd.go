@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// letterboxdSimilarity is the least similarity of titles for a film to be taken for the one in a row
+const letterboxdSimilarity = 0.85
+
+// letterboxdColumns maps the header cells of Letterboxd files to the columns read, the rest are skipped
+var letterboxdColumns = map[string]string{
+	"title":        "title",
+	"name":         "title",
+	"year":         "year",
+	"rating":       "rating",
+	"rating10":     "rating10",
+	"watcheddate":  "watched",
+	"watched date": "watched",
+}
+
+// letterboxdHeader is the header of the exported file, the one the Letterboxd importer reads
+var letterboxdHeader = []string{"Title", "Year", "Rating10", "WatchedDate"}
+
+// stars reads a rating of 0.5 to 5 stars in halves as a rating out of 10
+func (row *csvRow) stars(column string) *int8 {
+	v := row.str(column)
+	if v == "" {
+		return nil
+	}
+	stars, err := strconv.ParseFloat(v, 64)
+	if err != nil || stars < 0.5 || stars > 5 || stars*2 != math.Trunc(stars*2) {
+		row.fail(column, "must be from 0.5 to 5 stars in halves")
+		return nil
+	}
+	rating := int8(stars * 2)
+	return &rating
+}
+
+func (row *csvRow) fullDate(column string) *domain.CustomDate {
+	v := row.str(column)
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		row.fail(column, "must be a full date in YYYY-MM-DD format")
+		return nil
+	}
+	date := domain.NewDate(t)
+	return &date
+}
+
+// readLetterboxd reads the title, year, rating and watch date of the rows of a Letterboxd diary, ratings
+// or watchlist file. Rows that can't be read are added to rowErrs
+func readLetterboxd(r io.Reader, rowErrs *[]domain.LetterboxdRowError) ([]domain.LetterboxdEntry, error) {
+	var entries []domain.LetterboxdEntry
+	err := scanCSV(r, "title", func(name string) (string, error) {
+		return letterboxdColumns[name], nil
+	}, func(row csvRow) {
+		entry := domain.LetterboxdEntry{
+			Row:       row.line,
+			Title:     row.str("title"),
+			Year:      row.optInt("year"),
+			Rating:    row.stars("rating"),
+			WatchedAt: row.fullDate("watched"),
+		}
+		if rating := row.optInt("rating10"); rating != nil {
+			if *rating < 0 || *rating > 10 {
+				row.fail("rating10", "must be from 0 to 10")
+			}
+			rating10 := int8(*rating)
+			entry.Rating = &rating10
+		}
+		if entry.Title == "" {
+			row.fields = append(row.fields, domain.FieldError{Field: "title", Rule: "required", Message: "is required"})
+		}
+		if entry.Year != nil && *entry.Year < 1 {
+			row.fail("year", "must be a year")
+		}
+
+		if len(row.fields) > 0 {
+			*rowErrs = append(*rowErrs, domain.LetterboxdRowError{Row: row.line, Title: entry.Title, Year: entry.Year,
+				Message: "validation failed", Fields: row.fields})
+			return
+		}
+		entries = append(entries, entry)
+	})
+	return entries, err
+}
+
+// filmMatcher finds catalog films by title and release year
+type filmMatcher struct {
+	all    []domain.Film
+	byYear map[int][]domain.Film
+}
+
+func newFilmMatcher(films []domain.Film) filmMatcher {
+	m := filmMatcher{all: films, byYear: make(map[int][]domain.Film)}
+	for _, film := range films {
+		if film.Released.Valid() {
+			year := film.Released.Time.Year()
+			m.byYear[year] = append(m.byYear[year], film)
+		}
+	}
+	return m
+}
+
+// match returns the films whose titles are the most similar to the title, looking among the films of the year
+// and then of the years around it, as Letterboxd may date a film by its premiere. More than one film means
+// the title is ambiguous
+func (m filmMatcher) match(title string, year *int) ([]domain.Film, float64) {
+	groups := [][]domain.Film{m.all}
+	if year != nil {
+		groups = [][]domain.Film{m.byYear[*year], slices.Concat(m.byYear[*year-1], m.byYear[*year+1])}
+	}
+
+	for _, films := range groups {
+		var best []domain.Film
+		bestSimilarity := 0.0
+		for _, film := range films {
+			similarity := nameSimilarity(title, film.Title)
+			switch {
+			case similarity > bestSimilarity:
+				best, bestSimilarity = []domain.Film{film}, similarity
+			case similarity == bestSimilarity:
+				best = append(best, film)
+			}
+		}
+		if bestSimilarity >= letterboxdSimilarity {
+			return best, bestSimilarity
+		}
+	}
+	return nil, 0
+}
+
+// ImportLetterboxd marks the films of a Letterboxd file as rated and watched by the user, rows are matched
+// to films by title and year. A film met in several rows gets the rating of the last one and the latest
+// watch date, what the file lacks is kept from the user's marks. Rows matched to no film or to several are reported
+func (s *UserFilmService) ImportLetterboxd(ctx context.Context, userId int, r io.Reader,
+	dryRun bool) (domain.LetterboxdResult, error) {
+	result := domain.LetterboxdResult{Fuzzy: []domain.LetterboxdMatch{}, Unmatched: []domain.LetterboxdRowError{}}
+	entries, err := readLetterboxd(r, &result.Unmatched)
+	if err != nil {
+		return result, err
+	}
+	result.Rows = len(entries) + len(result.Unmatched)
+
+	films, err := s.films.ListFilms(ctx, "id", "asc", domain.FilmFilter{})
+	if err != nil {
+		return result, err
+	}
+	current, err := s.repos.ListUserFilms(ctx, userId)
+	if err != nil {
+		return result, err
+	}
+	marks := make(map[int]domain.UserFilm, len(current))
+	for _, mark := range current {
+		marks[mark.FilmId] = mark
+	}
+
+	matcher := newFilmMatcher(films)
+	var changed []int
+	for _, entry := range entries {
+		found, similarity := matcher.match(entry.Title, entry.Year)
+		if len(found) != 1 {
+			rowErr := domain.LetterboxdRowError{Row: entry.Row, Title: entry.Title, Year: entry.Year,
+				Message: "no film with a similar title"}
+			if len(found) > 1 {
+				rowErr.Message = fmt.Sprintf("%d films match the title", len(found))
+				for _, film := range found {
+					rowErr.FilmIds = append(rowErr.FilmIds, film.Id)
+				}
+			}
+			result.Unmatched = append(result.Unmatched, rowErr)
+			continue
+		}
+
+		film := found[0]
+		result.Matched++
+		if similarity < 1 {
+			result.Fuzzy = append(result.Fuzzy, domain.LetterboxdMatch{Row: entry.Row, Title: entry.Title,
+				FilmId: film.Id, FilmTitle: film.Title, Similarity: similarity})
+		}
+
+		mark, ok := marks[film.Id]
+		if !ok {
+			mark = domain.UserFilm{UserId: userId, FilmId: film.Id}
+		}
+		updated := !ok
+		if entry.Rating != nil && (mark.Rating == nil || *mark.Rating != *entry.Rating) {
+			mark.Rating = entry.Rating
+			updated = true
+		}
+		if entry.WatchedAt != nil && (mark.WatchedAt == nil || entry.WatchedAt.Compare(*mark.WatchedAt) > 0) {
+			mark.WatchedAt = entry.WatchedAt
+			updated = true
+		}
+		marks[film.Id] = mark
+		if updated && !slices.Contains(changed, film.Id) {
+			changed = append(changed, film.Id)
+		}
+	}
+	sort.SliceStable(result.Unmatched, func(i, j int) bool {
+		return result.Unmatched[i].Row < result.Unmatched[j].Row
+	})
+
+	result.Marked = len(changed)
+	if dryRun || len(changed) == 0 {
+		result.Committed = !dryRun
+		return result, nil
+	}
+	save := make([]domain.UserFilm, len(changed))
+	for i, id := range changed {
+		save[i] = marks[id]
+	}
+	err = s.repos.SetUserFilms(ctx, save)
+	if errors.Is(err, postgres.ErrForeign) {
+		return result, ErrNotFound
+	}
+	if err != nil {
+		return result, err
+	}
+	result.Committed = true
+	return result, nil
+}
+
+// ExportLetterboxd writes the user's marks in the CSV format Letterboxd imports. Films marked
+// without a rating and a watch date are what Letterboxd keeps in a watchlist
+func (s *UserFilmService) ExportLetterboxd(ctx context.Context, userId int, w io.Writer) error {
+	marks, err := s.repos.ListUserFilms(ctx, userId)
+	if err != nil {
+		return err
+	}
+	films, err := s.films.ListFilms(ctx, "id", "asc", domain.FilmFilter{})
+	if err != nil {
+		return err
+	}
+	byId := make(map[int]domain.Film, len(films))
+	for _, film := range films {
+		byId[film.Id] = film
+	}
+
+	out := csv.NewWriter(w)
+	out.Write(letterboxdHeader)
+	for _, mark := range marks {
+		film, ok := byId[mark.FilmId]
+		if !ok {
+			continue
+		}
+		record := make([]string, len(letterboxdHeader))
+		record[0] = film.Title
+		if film.Released.Valid() {
+			record[1] = strconv.Itoa(film.Released.Time.Year())
+		}
+		if mark.Rating != nil {
+			record[2] = strconv.Itoa(int(*mark.Rating))
+		}
+		if mark.WatchedAt != nil {
+			record[3] = mark.WatchedAt.Time.Format(time.DateOnly)
+		}
+		out.Write(record)
+	}
+	out.Flush()
+	return out.Error()
+}
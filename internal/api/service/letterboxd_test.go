@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+const letterboxdDiary = `Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date
+2024-01-02,"Matrix, The",1999,https://boxd.it/1,4.5,,,2024-01-01
+2024-01-03,Brothr,1997,https://boxd.it/2,3,,,2024-01-02
+2024-01-04,Solaris,2002,https://boxd.it/3,,,,
+2024-01-05,Heat,,https://boxd.it/4,4,,,
+2024-01-06,Stalker,1979,https://boxd.it/5,5,,,
+2024-01-07,Solaris,1973,https://boxd.it/6,2,Yes,,
+2024-01-08,Brother,1997,https://boxd.it/7,6,,,
+`
+
+func prepareLetterboxdTest() (*mocks.UserFilm, *mocks.Film, *UserFilmService) {
+	marks := new(mocks.UserFilm)
+	films := new(mocks.Film)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+
+	films.On("ListFilms", mock.Anything, "id", "asc", domain.FilmFilter{}).Return([]domain.Film{
+		{Id: 1, Title: "The Matrix", Released: released(1999)},
+		{Id: 2, Title: "Brother", Released: released(1997)},
+		{Id: 3, Title: "Solaris", Released: released(1972)},
+		{Id: 4, Title: "Solaris", Released: released(2002)},
+		{Id: 5, Title: "Heat", Released: released(1995)},
+		{Id: 6, Title: "Heat", Released: released(1986)},
+	}, nil)
+
+	return marks, films, NewUserFilmService(marks, films, log)
+}
+
+func TestUserFilmService_ImportLetterboxd(t *testing.T) {
+	nine, six, four := int8(9), int8(6), int8(4)
+	watched := mustDate("2023-05-01")
+
+	t.Run("Matched", func(t *testing.T) {
+		marks, _, s := prepareLetterboxdTest()
+		marks.On("ListUserFilms", mock.Anything, 7).Return([]domain.UserFilm{
+			{UserId: 7, FilmId: 4, Rating: &nine, WatchedAt: &watched},
+		}, nil)
+		first, second := mustDate("2024-01-01"), mustDate("2024-01-02")
+		marks.On("SetUserFilms", mock.Anything, []domain.UserFilm{
+			{UserId: 7, FilmId: 1, Rating: &nine, WatchedAt: &first},
+			{UserId: 7, FilmId: 2, Rating: &six, WatchedAt: &second},
+			{UserId: 7, FilmId: 3, Rating: &four},
+		}).Return(nil).Once()
+
+		got, err := s.ImportLetterboxd(context.Background(), 7, strings.NewReader(letterboxdDiary), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, got.Rows)
+		assert.Equal(t, 4, got.Matched)
+		assert.Equal(t, 3, got.Marked)
+		assert.True(t, got.Committed)
+		assert.Len(t, got.Fuzzy, 1)
+		assert.Equal(t, 3, got.Fuzzy[0].Row)
+		assert.Equal(t, 2, got.Fuzzy[0].FilmId)
+
+		assert.Len(t, got.Unmatched, 3)
+		assert.Equal(t, 5, got.Unmatched[0].Row)
+		assert.Equal(t, []int{5, 6}, got.Unmatched[0].FilmIds)
+		assert.Equal(t, 6, got.Unmatched[1].Row)
+		assert.Equal(t, "no film with a similar title", got.Unmatched[1].Message)
+		assert.Equal(t, 8, got.Unmatched[2].Row)
+		assert.Equal(t, "rating", got.Unmatched[2].Fields[0].Field)
+		marks.AssertExpectations(t)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		marks, _, s := prepareLetterboxdTest()
+		marks.On("ListUserFilms", mock.Anything, 7).Return(nil, nil)
+
+		got, err := s.ImportLetterboxd(context.Background(), 7, strings.NewReader(letterboxdDiary), true)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, got.Marked)
+		assert.False(t, got.Committed)
+		marks.AssertNotCalled(t, "SetUserFilms", mock.Anything)
+	})
+
+	t.Run("NoTitle", func(t *testing.T) {
+		_, _, s := prepareLetterboxdTest()
+
+		_, err := s.ImportLetterboxd(context.Background(), 7, strings.NewReader("Year,Rating\n1999,4\n"), false)
+		assert.ErrorIs(t, err, ErrBadRequest)
+	})
+}
+
+func TestUserFilmService_ExportLetterboxd(t *testing.T) {
+	marks, _, s := prepareLetterboxdTest()
+	nine := int8(9)
+	watched := mustDate("2024-01-01")
+	marks.On("ListUserFilms", mock.Anything, 7).Return([]domain.UserFilm{
+		{UserId: 7, FilmId: 1, Rating: &nine, WatchedAt: &watched},
+		{UserId: 7, FilmId: 4},
+		{UserId: 7, FilmId: 99},
+	}, nil)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.ExportLetterboxd(context.Background(), 7, &buf))
+	assert.Equal(t, "Title,Year,Rating10,WatchedDate\nThe Matrix,1999,9,2024-01-01\nSolaris,2002,,\n", buf.String())
+}
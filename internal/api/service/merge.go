@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const duplicateSimilarity = 0.85
+
+// normalizeName lowercases the name and keeps only letters and digits separated by single spaces
+func normalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == 'ё':
+			r = 'е'
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteRune(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// nameSimilarity compares normalized names as written and with words sorted, so that
+// "Bodrov, Sergei" matches "Sergei Bodrov"
+func nameSimilarity(a, b string) float64 {
+	a, b = normalizeName(a), normalizeName(b)
+	sorted := func(s string) string {
+		words := strings.Fields(s)
+		sort.Strings(words)
+		return strings.Join(words, " ")
+	}
+	return max(levenshteinRatio(a, b), levenshteinRatio(sorted(a), sorted(b)))
+}
+
+// levenshteinRatio is 1 minus the edit distance divided by the length of the longer string
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+type MergeService struct {
+	actors repository.Actor
+	films  repository.Film
+	merges repository.Merge
+	graph  *graphCache
+	log    *slog.Logger
+}
+
+func NewMergeService(actors repository.Actor, films repository.Film, merges repository.Merge,
+	graph *graphCache, log *slog.Logger) *MergeService {
+	return &MergeService{actors: actors, films: films, merges: merges, graph: graph, log: log}
+}
+
+func mapMergeError(err error) error {
+	if errors.Is(err, postgres.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// fullDate keys records by a date known to the day. Missing and partial dates say too little to pair records
+func fullDate(d domain.CustomDate) (string, bool) {
+	if !d.Valid() || d.Precision != domain.PrecisionDay {
+		return "", false
+	}
+	return d.String(), true
+}
+
+// FindDuplicates reports pairs of actors born on the same day and films released on the same day
+// whose normalized names are similar. Records without a full date are skipped
+func (s *MergeService) FindDuplicates(ctx context.Context) (domain.Duplicates, error) {
+	var result domain.Duplicates
+
+	actors, err := s.actors.ListActors(ctx, -1)
+	if err != nil {
+		return result, err
+	}
+	byBirthday := make(map[string][]domain.Actor)
+	for _, actor := range actors {
+		if day, ok := fullDate(actor.Birthday); ok {
+			byBirthday[day] = append(byBirthday[day], actor)
+		}
+	}
+	result.Actors = []domain.ActorDuplicate{}
+	for _, group := range byBirthday {
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				if sim := nameSimilarity(group[i].Name, group[j].Name); sim >= duplicateSimilarity {
+					first, second := group[i], group[j]
+					if first.Id > second.Id {
+						first, second = second, first
+					}
+					result.Actors = append(result.Actors,
+						domain.ActorDuplicate{First: first, Second: second, Similarity: sim})
+				}
+			}
+		}
+	}
+
+	films, err := s.films.ListFilms(ctx, "id", "asc", domain.FilmFilter{})
+	if err != nil {
+		return result, err
+	}
+	byRelease := make(map[string][]domain.Film)
+	for _, film := range films {
+		if day, ok := fullDate(film.Released); ok {
+			byRelease[day] = append(byRelease[day], film)
+		}
+	}
+	result.Films = []domain.FilmDuplicate{}
+	for _, group := range byRelease {
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				if sim := nameSimilarity(group[i].Title, group[j].Title); sim >= duplicateSimilarity {
+					result.Films = append(result.Films,
+						domain.FilmDuplicate{First: group[i], Second: group[j], Similarity: sim})
+				}
+			}
+		}
+	}
+
+	sort.Slice(result.Actors, func(i, j int) bool {
+		if result.Actors[i].Similarity != result.Actors[j].Similarity {
+			return result.Actors[i].Similarity > result.Actors[j].Similarity
+		}
+		return result.Actors[i].First.Id < result.Actors[j].First.Id
+	})
+	sort.Slice(result.Films, func(i, j int) bool {
+		if result.Films[i].Similarity != result.Films[j].Similarity {
+			return result.Films[i].Similarity > result.Films[j].Similarity
+		}
+		return result.Films[i].First.Id < result.Films[j].First.Id
+	})
+
+	return result, nil
+}
+
+func (s *MergeService) MergeActors(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	ids := uniqueIds(duplicateIds)
+	for _, id := range ids {
+		if id == survivorId {
+			return nil, ErrBadRequest
+		}
+	}
+	merges, err := s.merges.MergeActors(ctx, userId, survivorId, ids)
+	if err != nil {
+		return merges, mapMergeError(err)
+	}
+	s.graph.invalidate()
+	return merges, nil
+}
+
+func (s *MergeService) MergeFilms(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error) {
+	ids := uniqueIds(duplicateIds)
+	for _, id := range ids {
+		if id == survivorId {
+			return nil, ErrBadRequest
+		}
+	}
+	merges, err := s.merges.MergeFilms(ctx, userId, survivorId, ids)
+	if err != nil {
+		return merges, mapMergeError(err)
+	}
+	s.graph.invalidate()
+	return merges, nil
+}
+
+func (s *MergeService) ListMerges(ctx context.Context) ([]domain.Merge, error) {
+	return s.merges.ListMerges(ctx)
+}
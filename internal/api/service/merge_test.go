@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareMergeTest() (*mocks.Actor, *mocks.Film, *mocks.Merge, *MergeService) {
+	actors, films, merges := new(mocks.Actor), new(mocks.Film), new(mocks.Merge)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return actors, films, merges, NewMergeService(actors, films, merges, nil, log)
+}
+
+func TestNameSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, nameSimilarity("Сергей Бодров", "сергей  бодров!"))
+	assert.Equal(t, 1.0, nameSimilarity("Bodrov, Sergei", "Sergei Bodrov"))
+	assert.Equal(t, 1.0, nameSimilarity("Ёжик в тумане", "Ежик в тумане"))
+	assert.Less(t, nameSimilarity("Brother", "Solaris"), duplicateSimilarity)
+}
+
+func TestMergeService_FindDuplicates(t *testing.T) {
+	actorsRepo, filmsRepo, _, s := prepareMergeTest()
+	birthday := domain.NewDate(time.Date(1971, 12, 27, 0, 0, 0, 0, time.UTC))
+	actorsRepo.On("ListActors", mock.Anything, -1).Return([]domain.Actor{
+		{Id: 1, Name: "Sergei Bodrov", Birthday: birthday},
+		{Id: 2, Name: "Bodrov Sergei", Birthday: birthday},
+		{Id: 3, Name: "Sergei Bodrov", Birthday: domain.NewDate(time.Date(1948, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{Id: 4, Name: "Nikita Mikhalkov"},
+		{Id: 5, Name: "Nikita Mikhalkov"},
+		{Id: 6, Name: "Sergei Bodrov", Birthday: domain.CustomDate{Time: birthday.Time, Precision: domain.PrecisionYear}},
+		{Id: 7, Name: "Sergei Bodrov", Birthday: domain.CustomDate{Time: birthday.Time, Precision: domain.PrecisionYear}},
+	}, nil)
+	filmsRepo.On("ListFilms", mock.Anything, "id", "asc", domain.FilmFilter{}).Return([]domain.Film{
+		{Id: 1, Title: "Solaris", Released: domain.NewDate(time.Date(1972, 3, 20, 0, 0, 0, 0, time.UTC))},
+		{Id: 2, Title: "Solaris", Released: domain.NewDate(time.Date(2002, 11, 27, 0, 0, 0, 0, time.UTC))},
+		{Id: 3, Title: "Solyaris", Released: domain.NewDate(time.Date(1972, 3, 20, 0, 0, 0, 0, time.UTC))},
+		{Id: 4, Title: "Solaris", Released: domain.NewDate(time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{Id: 5, Title: "Stalker"},
+		{Id: 6, Title: "Stalker"},
+	}, nil)
+
+	got, err := s.FindDuplicates(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, got.Actors, 1) {
+		assert.Equal(t, 1, got.Actors[0].First.Id)
+		assert.Equal(t, 2, got.Actors[0].Second.Id)
+	}
+	if assert.Len(t, got.Films, 1) {
+		assert.Equal(t, 1, got.Films[0].First.Id)
+		assert.Equal(t, 3, got.Films[0].Second.Id)
+	}
+}
+
+func TestMergeService_MergeFilms(t *testing.T) {
+	t.Run("IntoItself", func(t *testing.T) {
+		_, _, merges, s := prepareMergeTest()
+
+		_, err := s.MergeFilms(context.Background(), 1, 5, []int{4, 5})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		merges.AssertNotCalled(t, "MergeFilms", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("DuplicateIdsCollapsed", func(t *testing.T) {
+		_, _, merges, s := prepareMergeTest()
+		merges.On("MergeFilms", mock.Anything, 1, 5, []int{4, 3}).Return([]domain.Merge{{Id: 1}, {Id: 2}}, nil)
+
+		got, err := s.MergeFilms(context.Background(), 1, 5, []int{4, 3, 4})
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+}
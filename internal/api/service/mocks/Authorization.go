@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type Authorization struct {
 	mock.Mock
 }
 
-// GetUserById provides a mock function with given fields: id
-func (_m *Authorization) GetUserById(id int) (domain.User, error) {
-	ret := _m.Called(id)
+// GetUserById provides a mock function with given fields: ctx, id
+func (_m *Authorization) GetUserById(ctx context.Context, id int) (domain.User, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetUserById")
@@ -22,17 +24,17 @@ func (_m *Authorization) GetUserById(id int) (domain.User, error) {
 
 	var r0 domain.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (domain.User, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.User, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(int) domain.User); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.User); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Get(0).(domain.User)
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,27 +42,27 @@ func (_m *Authorization) GetUserById(id int) (domain.User, error) {
 	return r0, r1
 }
 
-// SignIn provides a mock function with given fields: username, password
-func (_m *Authorization) SignIn(username string, password string) (string, error) {
-	ret := _m.Called(username, password)
+// SetUserRole provides a mock function with given fields: ctx, id, role
+func (_m *Authorization) SetUserRole(ctx context.Context, id int, role int8) (int8, error) {
+	ret := _m.Called(ctx, id, role)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SignIn")
+		panic("no return value specified for SetUserRole")
 	}
 
-	var r0 string
+	var r0 int8
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
-		return rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int8) (int8, error)); ok {
+		return rf(ctx, id, role)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) string); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int8) int8); ok {
+		r0 = rf(ctx, id, role)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(int8)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(username, password)
+	if rf, ok := ret.Get(1).(func(context.Context, int, int8) error); ok {
+		r1 = rf(ctx, id, role)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -68,17 +70,52 @@ func (_m *Authorization) SignIn(username string, password string) (string, error
 	return r0, r1
 }
 
-// SignUp provides a mock function with given fields: user
-func (_m *Authorization) SignUp(user domain.User) error {
-	ret := _m.Called(user)
+// SignIn provides a mock function with given fields: ctx, username, password
+func (_m *Authorization) SignIn(ctx context.Context, username string, password string) (string, int, error) {
+	ret := _m.Called(ctx, username, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SignIn")
+	}
+
+	var r0 string
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, int, error)); ok {
+		return rf(ctx, username, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, username, password)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) int); ok {
+		r1 = rf(ctx, username, password)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, username, password)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SignUp provides a mock function with given fields: ctx, user
+func (_m *Authorization) SignUp(ctx context.Context, user domain.User) error {
+	ret := _m.Called(ctx, user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SignUp")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(domain.User) error); ok {
-		r0 = rf(user)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.User) error); ok {
+		r0 = rf(ctx, user)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,107 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Collaboration is an autogenerated mock type for the Collaboration type
+type Collaboration struct {
+	mock.Mock
+}
+
+// ActorPath provides a mock function with given fields: ctx, fromId, toId
+func (_m *Collaboration) ActorPath(ctx context.Context, fromId int, toId int) (domain.ActorPath, error) {
+	ret := _m.Called(ctx, fromId, toId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActorPath")
+	}
+
+	var r0 domain.ActorPath
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.ActorPath, error)); ok {
+		return rf(ctx, fromId, toId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.ActorPath); ok {
+		r0 = rf(ctx, fromId, toId)
+	} else {
+		r0 = ret.Get(0).(domain.ActorPath)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, fromId, toId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CoStars provides a mock function with given fields: ctx, actorId, limit
+func (_m *Collaboration) CoStars(ctx context.Context, actorId int, limit int) ([]domain.CoStar, error) {
+	ret := _m.Called(ctx, actorId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CoStars")
+	}
+
+	var r0 []domain.CoStar
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.CoStar, error)); ok {
+		return rf(ctx, actorId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.CoStar); ok {
+		r0 = rf(ctx, actorId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CoStar)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, actorId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExportGraph provides a mock function with given fields: ctx, w, format
+func (_m *Collaboration) ExportGraph(ctx context.Context, w io.Writer, format string) error {
+	ret := _m.Called(ctx, w, format)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportGraph")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer, string) error); ok {
+		r0 = rf(ctx, w, format)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCollaboration creates a new instance of Collaboration. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollaboration(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Collaboration {
+	mock := &Collaboration{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,301 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Collection is an autogenerated mock type for the Collection type
+type Collection struct {
+	mock.Mock
+}
+
+// CloneCollection provides a mock function with given fields: ctx, userId, id
+func (_m *Collection) CloneCollection(ctx context.Context, userId int, id int) (int, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloneCollection")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (int, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) int); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCollection provides a mock function with given fields: ctx, userId, collection
+func (_m *Collection) CreateCollection(ctx context.Context, userId int, collection domain.Collection) (int, error) {
+	ret := _m.Called(ctx, userId, collection)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCollection")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Collection) (int, error)); ok {
+		return rf(ctx, userId, collection)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Collection) int); ok {
+		r0 = rf(ctx, userId, collection)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Collection) error); ok {
+		r1 = rf(ctx, userId, collection)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCollection provides a mock function with given fields: ctx, userId, id
+func (_m *Collection) DeleteCollection(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCollection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FollowCollection provides a mock function with given fields: ctx, userId, id
+func (_m *Collection) FollowCollection(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FollowCollection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCollection provides a mock function with given fields: ctx, userId, id
+func (_m *Collection) GetCollection(ctx context.Context, userId int, id int) (domain.Collection, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollection")
+	}
+
+	var r0 domain.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Collection, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Collection); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Collection)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSharedCollection provides a mock function with given fields: ctx, token
+func (_m *Collection) GetSharedCollection(ctx context.Context, token string) (domain.Collection, error) {
+	ret := _m.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSharedCollection")
+	}
+
+	var r0 domain.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (domain.Collection, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Collection); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(domain.Collection)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCollections provides a mock function with given fields: ctx, userId
+func (_m *Collection) ListCollections(ctx context.Context, userId int) ([]domain.Collection, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCollections")
+	}
+
+	var r0 []domain.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Collection, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Collection); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFollowedCollections provides a mock function with given fields: ctx, userId
+func (_m *Collection) ListFollowedCollections(ctx context.Context, userId int) ([]domain.Collection, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFollowedCollections")
+	}
+
+	var r0 []domain.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Collection, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Collection); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchCollection provides a mock function with given fields: ctx, userId, input
+func (_m *Collection) PatchCollection(ctx context.Context, userId int, input domain.CollectionInput) (domain.Collection, error) {
+	ret := _m.Called(ctx, userId, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchCollection")
+	}
+
+	var r0 domain.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.CollectionInput) (domain.Collection, error)); ok {
+		return rf(ctx, userId, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.CollectionInput) domain.Collection); ok {
+		r0 = rf(ctx, userId, input)
+	} else {
+		r0 = ret.Get(0).(domain.Collection)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.CollectionInput) error); ok {
+		r1 = rf(ctx, userId, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnfollowCollection provides a mock function with given fields: ctx, userId, id
+func (_m *Collection) UnfollowCollection(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnfollowCollection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCollection provides a mock function with given fields: ctx, userId, collection
+func (_m *Collection) UpdateCollection(ctx context.Context, userId int, collection domain.Collection) error {
+	ret := _m.Called(ctx, userId, collection)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCollection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Collection) error); ok {
+		r0 = rf(ctx, userId, collection)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCollection creates a new instance of Collection. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollection(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Collection {
+	mock := &Collection{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
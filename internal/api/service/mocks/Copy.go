@@ -0,0 +1,239 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Copy is an autogenerated mock type for the Copy type
+type Copy struct {
+	mock.Mock
+}
+
+// CreateCopy provides a mock function with given fields: ctx, c
+func (_m *Copy) CreateCopy(ctx context.Context, c domain.Copy) (int, error) {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCopy")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Copy) (int, error)); ok {
+		return rf(ctx, c)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Copy) int); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Copy) error); ok {
+		r1 = rf(ctx, c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCopy provides a mock function with given fields: ctx, id
+func (_m *Copy) DeleteCopy(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCopy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LendCopy provides a mock function with given fields: ctx, loan
+func (_m *Copy) LendCopy(ctx context.Context, loan domain.Loan) (int, error) {
+	ret := _m.Called(ctx, loan)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LendCopy")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Loan) (int, error)); ok {
+		return rf(ctx, loan)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Loan) int); ok {
+		r0 = rf(ctx, loan)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Loan) error); ok {
+		r1 = rf(ctx, loan)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCopies provides a mock function with given fields: ctx, filmId
+func (_m *Copy) ListCopies(ctx context.Context, filmId int) ([]domain.Copy, error) {
+	ret := _m.Called(ctx, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCopies")
+	}
+
+	var r0 []domain.Copy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Copy, error)); ok {
+		return rf(ctx, filmId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Copy); ok {
+		r0 = rf(ctx, filmId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Copy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLoans provides a mock function with given fields: ctx, copyId
+func (_m *Copy) ListLoans(ctx context.Context, copyId int) ([]domain.Loan, error) {
+	ret := _m.Called(ctx, copyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListLoans")
+	}
+
+	var r0 []domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Loan, error)); ok {
+		return rf(ctx, copyId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Loan); ok {
+		r0 = rf(ctx, copyId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, copyId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOverdueLoans provides a mock function with given fields: ctx
+func (_m *Copy) ListOverdueLoans(ctx context.Context) ([]domain.Loan, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOverdueLoans")
+	}
+
+	var r0 []domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Loan, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Loan); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Loan)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReturnCopy provides a mock function with given fields: ctx, copyId
+func (_m *Copy) ReturnCopy(ctx context.Context, copyId int) (domain.Loan, error) {
+	ret := _m.Called(ctx, copyId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReturnCopy")
+	}
+
+	var r0 domain.Loan
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Loan, error)); ok {
+		return rf(ctx, copyId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Loan); ok {
+		r0 = rf(ctx, copyId)
+	} else {
+		r0 = ret.Get(0).(domain.Loan)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, copyId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCopy provides a mock function with given fields: ctx, c
+func (_m *Copy) UpdateCopy(ctx context.Context, c domain.Copy) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCopy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Copy) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCopy creates a new instance of Copy. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCopy(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Copy {
+	mock := &Copy{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,67 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Export is an autogenerated mock type for the Export type
+type Export struct {
+	mock.Mock
+}
+
+// ExportActors provides a mock function with given fields: ctx, w, format, filmId
+func (_m *Export) ExportActors(ctx context.Context, w io.Writer, format string, filmId int) error {
+	ret := _m.Called(ctx, w, format, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportActors")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer, string, int) error); ok {
+		r0 = rf(ctx, w, format, filmId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportFilms provides a mock function with given fields: ctx, w, format, filter, actorId
+func (_m *Export) ExportFilms(ctx context.Context, w io.Writer, format string, filter domain.FilmFilter, actorId int) error {
+	ret := _m.Called(ctx, w, format, filter, actorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportFilms")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer, string, domain.FilmFilter, int) error); ok {
+		r0 = rf(ctx, w, format, filter, actorId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewExport creates a new instance of Export. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExport(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Export {
+	mock := &Export{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
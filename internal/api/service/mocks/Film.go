@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type Film struct {
 	mock.Mock
 }
 
-// CreateFilm provides a mock function with given fields: film, actorIds
-func (_m *Film) CreateFilm(film domain.Film, actorIds []int) (int, error) {
-	ret := _m.Called(film, actorIds)
+// CreateFilm provides a mock function with given fields: ctx, userId, film, actorIds
+func (_m *Film) CreateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	ret := _m.Called(ctx, userId, film, actorIds)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateFilm")
@@ -22,17 +24,17 @@ func (_m *Film) CreateFilm(film domain.Film, actorIds []int) (int, error) {
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.Film, []int) (int, error)); ok {
-		return rf(film, actorIds)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) (int, error)); ok {
+		return rf(ctx, userId, film, actorIds)
 	}
-	if rf, ok := ret.Get(0).(func(domain.Film, []int) int); ok {
-		r0 = rf(film, actorIds)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) int); ok {
+		r0 = rf(ctx, userId, film, actorIds)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.Film, []int) error); ok {
-		r1 = rf(film, actorIds)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Film, []int) error); ok {
+		r1 = rf(ctx, userId, film, actorIds)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -40,17 +42,17 @@ func (_m *Film) CreateFilm(film domain.Film, actorIds []int) (int, error) {
 	return r0, r1
 }
 
-// DeleteFilm provides a mock function with given fields: id
-func (_m *Film) DeleteFilm(id int) error {
-	ret := _m.Called(id)
+// DeleteFilm provides a mock function with given fields: ctx, userId, id, version
+func (_m *Film) DeleteFilm(ctx context.Context, userId int, id int, version int) error {
+	ret := _m.Called(ctx, userId, id, version)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteFilm")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) error); ok {
+		r0 = rf(ctx, userId, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -58,9 +60,37 @@ func (_m *Film) DeleteFilm(id int) error {
 	return r0
 }
 
-// ListFilms provides a mock function with given fields: sortBy, sortDir, actorId
-func (_m *Film) ListFilms(sortBy string, sortDir string, actorId int) ([]domain.Film, error) {
-	ret := _m.Called(sortBy, sortDir, actorId)
+// GetFilm provides a mock function with given fields: ctx, id
+func (_m *Film) GetFilm(ctx context.Context, id int) (domain.Film, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFilm")
+	}
+
+	var r0 domain.Film
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Film, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Film); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Film)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFilms provides a mock function with given fields: ctx, sortBy, sortDir, actorId, filter
+func (_m *Film) ListFilms(ctx context.Context, sortBy string, sortDir string, actorId int, filter domain.FilmFilter) ([]domain.Film, error) {
+	ret := _m.Called(ctx, sortBy, sortDir, actorId, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ListFilms")
@@ -68,19 +98,19 @@ func (_m *Film) ListFilms(sortBy string, sortDir string, actorId int) ([]domain.
 
 	var r0 []domain.Film
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string, int) ([]domain.Film, error)); ok {
-		return rf(sortBy, sortDir, actorId)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, domain.FilmFilter) ([]domain.Film, error)); ok {
+		return rf(ctx, sortBy, sortDir, actorId, filter)
 	}
-	if rf, ok := ret.Get(0).(func(string, string, int) []domain.Film); ok {
-		r0 = rf(sortBy, sortDir, actorId)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, domain.FilmFilter) []domain.Film); ok {
+		r0 = rf(ctx, sortBy, sortDir, actorId, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Film)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
-		r1 = rf(sortBy, sortDir, actorId)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int, domain.FilmFilter) error); ok {
+		r1 = rf(ctx, sortBy, sortDir, actorId, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -88,9 +118,9 @@ func (_m *Film) ListFilms(sortBy string, sortDir string, actorId int) ([]domain.
 	return r0, r1
 }
 
-// PatchFilm provides a mock function with given fields: input, actorIds
-func (_m *Film) PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Film, error) {
-	ret := _m.Called(input, actorIds)
+// PatchFilm provides a mock function with given fields: ctx, userId, input, actorIds
+func (_m *Film) PatchFilm(ctx context.Context, userId int, input domain.NullableFilm, actorIds []int) (domain.Film, error) {
+	ret := _m.Called(ctx, userId, input, actorIds)
 
 	if len(ret) == 0 {
 		panic("no return value specified for PatchFilm")
@@ -98,17 +128,17 @@ func (_m *Film) PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Fil
 
 	var r0 domain.Film
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.NullableFilm, []int) (domain.Film, error)); ok {
-		return rf(input, actorIds)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.NullableFilm, []int) (domain.Film, error)); ok {
+		return rf(ctx, userId, input, actorIds)
 	}
-	if rf, ok := ret.Get(0).(func(domain.NullableFilm, []int) domain.Film); ok {
-		r0 = rf(input, actorIds)
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.NullableFilm, []int) domain.Film); ok {
+		r0 = rf(ctx, userId, input, actorIds)
 	} else {
 		r0 = ret.Get(0).(domain.Film)
 	}
 
-	if rf, ok := ret.Get(1).(func(domain.NullableFilm, []int) error); ok {
-		r1 = rf(input, actorIds)
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.NullableFilm, []int) error); ok {
+		r1 = rf(ctx, userId, input, actorIds)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -116,9 +146,37 @@ func (_m *Film) PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Fil
 	return r0, r1
 }
 
-// SearchFilm provides a mock function with given fields: query
-func (_m *Film) SearchFilm(query string) ([]domain.Film, error) {
-	ret := _m.Called(query)
+// RevertFilm provides a mock function with given fields: ctx, userId, film, actorIds
+func (_m *Film) RevertFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	ret := _m.Called(ctx, userId, film, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertFilm")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) (int, error)); ok {
+		return rf(ctx, userId, film, actorIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) int); ok {
+		r0 = rf(ctx, userId, film, actorIds)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Film, []int) error); ok {
+		r1 = rf(ctx, userId, film, actorIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchFilm provides a mock function with given fields: ctx, query
+func (_m *Film) SearchFilm(ctx context.Context, query string) ([]domain.Film, error) {
+	ret := _m.Called(ctx, query)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SearchFilm")
@@ -126,19 +184,19 @@ func (_m *Film) SearchFilm(query string) ([]domain.Film, error) {
 
 	var r0 []domain.Film
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]domain.Film, error)); ok {
-		return rf(query)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.Film, error)); ok {
+		return rf(ctx, query)
 	}
-	if rf, ok := ret.Get(0).(func(string) []domain.Film); ok {
-		r0 = rf(query)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.Film); ok {
+		r0 = rf(ctx, query)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]domain.Film)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(query)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, query)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -146,17 +204,17 @@ func (_m *Film) SearchFilm(query string) ([]domain.Film, error) {
 	return r0, r1
 }
 
-// UpdateFilm provides a mock function with given fields: film, actorIds
-func (_m *Film) UpdateFilm(film domain.Film, actorIds []int) error {
-	ret := _m.Called(film, actorIds)
+// StreamFilms provides a mock function with given fields: ctx, sortBy, sortDir, actorId, filter, fn
+func (_m *Film) StreamFilms(ctx context.Context, sortBy string, sortDir string, actorId int, filter domain.FilmFilter, fn func(domain.Film) error) error {
+	ret := _m.Called(ctx, sortBy, sortDir, actorId, filter, fn)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateFilm")
+		panic("no return value specified for StreamFilms")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(domain.Film, []int) error); ok {
-		r0 = rf(film, actorIds)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int, domain.FilmFilter, func(domain.Film) error) error); ok {
+		r0 = rf(ctx, sortBy, sortDir, actorId, filter, fn)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -164,6 +222,34 @@ func (_m *Film) UpdateFilm(film domain.Film, actorIds []int) error {
 	return r0
 }
 
+// UpdateFilm provides a mock function with given fields: ctx, userId, film, actorIds
+func (_m *Film) UpdateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error) {
+	ret := _m.Called(ctx, userId, film, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFilm")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) (int, error)); ok {
+		return rf(ctx, userId, film, actorIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Film, []int) int); ok {
+		r0 = rf(ctx, userId, film, actorIds)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.Film, []int) error); ok {
+		r1 = rf(ctx, userId, film, actorIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewFilm creates a new instance of Film. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewFilm(t interface {
@@ -0,0 +1,115 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	io "io"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Import is an autogenerated mock type for the Import type
+type Import struct {
+	mock.Mock
+}
+
+// ImportActors provides a mock function with given fields: ctx, userId, r, format, opts
+func (_m *Import) ImportActors(ctx context.Context, userId int, r io.Reader, format string, opts domain.ImportOptions) (domain.ImportResult, error) {
+	ret := _m.Called(ctx, userId, r, format, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportActors")
+	}
+
+	var r0 domain.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, io.Reader, string, domain.ImportOptions) (domain.ImportResult, error)); ok {
+		return rf(ctx, userId, r, format, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, io.Reader, string, domain.ImportOptions) domain.ImportResult); ok {
+		r0 = rf(ctx, userId, r, format, opts)
+	} else {
+		r0 = ret.Get(0).(domain.ImportResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, io.Reader, string, domain.ImportOptions) error); ok {
+		r1 = rf(ctx, userId, r, format, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportFilms provides a mock function with given fields: ctx, userId, r, format, opts
+func (_m *Import) ImportFilms(ctx context.Context, userId int, r io.Reader, format string, opts domain.ImportOptions) (domain.ImportResult, error) {
+	ret := _m.Called(ctx, userId, r, format, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportFilms")
+	}
+
+	var r0 domain.ImportResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, io.Reader, string, domain.ImportOptions) (domain.ImportResult, error)); ok {
+		return rf(ctx, userId, r, format, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, io.Reader, string, domain.ImportOptions) domain.ImportResult); ok {
+		r0 = rf(ctx, userId, r, format, opts)
+	} else {
+		r0 = ret.Get(0).(domain.ImportResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, io.Reader, string, domain.ImportOptions) error); ok {
+		r1 = rf(ctx, userId, r, format, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportIMDb provides a mock function with given fields: ctx, userId, files, opts
+func (_m *Import) ImportIMDb(ctx context.Context, userId int, files domain.IMDbFiles, opts domain.IMDbOptions) (domain.IMDbResult, error) {
+	ret := _m.Called(ctx, userId, files, opts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportIMDb")
+	}
+
+	var r0 domain.IMDbResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.IMDbFiles, domain.IMDbOptions) (domain.IMDbResult, error)); ok {
+		return rf(ctx, userId, files, opts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.IMDbFiles, domain.IMDbOptions) domain.IMDbResult); ok {
+		r0 = rf(ctx, userId, files, opts)
+	} else {
+		r0 = ret.Get(0).(domain.IMDbResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, domain.IMDbFiles, domain.IMDbOptions) error); ok {
+		r1 = rf(ctx, userId, files, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewImport creates a new instance of Import. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImport(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Import {
+	mock := &Import{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Recommendation is an autogenerated mock type for the Recommendation type
+type Recommendation struct {
+	mock.Mock
+}
+
+// Recommendations provides a mock function with given fields: ctx, userId, limit
+func (_m *Recommendation) Recommendations(ctx context.Context, userId int, limit int) ([]domain.ScoredFilm, error) {
+	ret := _m.Called(ctx, userId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Recommendations")
+	}
+
+	var r0 []domain.ScoredFilm
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.ScoredFilm, error)); ok {
+		return rf(ctx, userId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.ScoredFilm); ok {
+		r0 = rf(ctx, userId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScoredFilm)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SimilarFilms provides a mock function with given fields: ctx, filmId, limit
+func (_m *Recommendation) SimilarFilms(ctx context.Context, filmId int, limit int) ([]domain.ScoredFilm, error) {
+	ret := _m.Called(ctx, filmId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SimilarFilms")
+	}
+
+	var r0 []domain.ScoredFilm
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]domain.ScoredFilm, error)); ok {
+		return rf(ctx, filmId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []domain.ScoredFilm); ok {
+		r0 = rf(ctx, filmId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScoredFilm)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, filmId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRecommendation creates a new instance of Recommendation. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRecommendation(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Recommendation {
+	mock := &Recommendation{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,311 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Relation is an autogenerated mock type for the Relation type
+type Relation struct {
+	mock.Mock
+}
+
+// CreateAlias provides a mock function with given fields: ctx, alias
+func (_m *Relation) CreateAlias(ctx context.Context, alias domain.FilmAlias) (int, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAlias")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmAlias) (int, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmAlias) int); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.FilmAlias) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFranchise provides a mock function with given fields: ctx, franchise
+func (_m *Relation) CreateFranchise(ctx context.Context, franchise domain.Franchise) (int, error) {
+	ret := _m.Called(ctx, franchise)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFranchise")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Franchise) (int, error)); ok {
+		return rf(ctx, franchise)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Franchise) int); ok {
+		r0 = rf(ctx, franchise)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Franchise) error); ok {
+		r1 = rf(ctx, franchise)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateRelation provides a mock function with given fields: ctx, relation
+func (_m *Relation) CreateRelation(ctx context.Context, relation domain.FilmRelation) error {
+	ret := _m.Called(ctx, relation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRelation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmRelation) error); ok {
+		r0 = rf(ctx, relation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAlias provides a mock function with given fields: ctx, id
+func (_m *Relation) DeleteAlias(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAlias")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFranchise provides a mock function with given fields: ctx, id
+func (_m *Relation) DeleteFranchise(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFranchise")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRelation provides a mock function with given fields: ctx, relation
+func (_m *Relation) DeleteRelation(ctx context.Context, relation domain.FilmRelation) error {
+	ret := _m.Called(ctx, relation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRelation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FilmRelation) error); ok {
+		r0 = rf(ctx, relation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetFranchise provides a mock function with given fields: ctx, id
+func (_m *Relation) GetFranchise(ctx context.Context, id int) (domain.Franchise, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFranchise")
+	}
+
+	var r0 domain.Franchise
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Franchise, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Franchise); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Franchise)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAliases provides a mock function with given fields: ctx, filmId
+func (_m *Relation) ListAliases(ctx context.Context, filmId int) ([]domain.FilmAlias, error) {
+	ret := _m.Called(ctx, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAliases")
+	}
+
+	var r0 []domain.FilmAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.FilmAlias, error)); ok {
+		return rf(ctx, filmId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.FilmAlias); ok {
+		r0 = rf(ctx, filmId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FilmAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFranchises provides a mock function with given fields: ctx
+func (_m *Relation) ListFranchises(ctx context.Context) ([]domain.Franchise, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFranchises")
+	}
+
+	var r0 []domain.Franchise
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]domain.Franchise, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []domain.Franchise); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Franchise)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRelations provides a mock function with given fields: ctx, filmId
+func (_m *Relation) ListRelations(ctx context.Context, filmId int) ([]domain.FilmRelation, error) {
+	ret := _m.Called(ctx, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRelations")
+	}
+
+	var r0 []domain.FilmRelation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.FilmRelation, error)); ok {
+		return rf(ctx, filmId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.FilmRelation); ok {
+		r0 = rf(ctx, filmId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.FilmRelation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFranchiseFilm provides a mock function with given fields: ctx, franchiseId, filmId
+func (_m *Relation) RemoveFranchiseFilm(ctx context.Context, franchiseId int, filmId int) error {
+	ret := _m.Called(ctx, franchiseId, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveFranchiseFilm")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, franchiseId, filmId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetFranchiseFilm provides a mock function with given fields: ctx, franchiseId, filmId, position
+func (_m *Relation) SetFranchiseFilm(ctx context.Context, franchiseId int, filmId int, position int) error {
+	ret := _m.Called(ctx, franchiseId, filmId, position)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFranchiseFilm")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) error); ok {
+		r0 = rf(ctx, franchiseId, filmId, position)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRelation creates a new instance of Relation. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRelation(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Relation {
+	mock := &Relation{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Revision is an autogenerated mock type for the Revision type
+type Revision struct {
+	mock.Mock
+}
+
+// ActorRevision provides a mock function with given fields: ctx, actorId, id
+func (_m *Revision) ActorRevision(ctx context.Context, actorId int, id int) (domain.Actor, error) {
+	ret := _m.Called(ctx, actorId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActorRevision")
+	}
+
+	var r0 domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Actor, error)); ok {
+		return rf(ctx, actorId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Actor); ok {
+		r0 = rf(ctx, actorId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Actor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, actorId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FilmRevision provides a mock function with given fields: ctx, filmId, id
+func (_m *Revision) FilmRevision(ctx context.Context, filmId int, id int) (domain.FilmSnapshot, error) {
+	ret := _m.Called(ctx, filmId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FilmRevision")
+	}
+
+	var r0 domain.FilmSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.FilmSnapshot, error)); ok {
+		return rf(ctx, filmId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.FilmSnapshot); ok {
+		r0 = rf(ctx, filmId, id)
+	} else {
+		r0 = ret.Get(0).(domain.FilmSnapshot)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, filmId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListActorRevisions provides a mock function with given fields: ctx, actorId
+func (_m *Revision) ListActorRevisions(ctx context.Context, actorId int) ([]domain.Revision, error) {
+	ret := _m.Called(ctx, actorId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActorRevisions")
+	}
+
+	var r0 []domain.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Revision, error)); ok {
+		return rf(ctx, actorId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Revision); ok {
+		r0 = rf(ctx, actorId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, actorId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFilmRevisions provides a mock function with given fields: ctx, filmId
+func (_m *Revision) ListFilmRevisions(ctx context.Context, filmId int) ([]domain.Revision, error) {
+	ret := _m.Called(ctx, filmId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFilmRevisions")
+	}
+
+	var r0 []domain.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Revision, error)); ok {
+		return rf(ctx, filmId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Revision); ok {
+		r0 = rf(ctx, filmId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, filmId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRevision creates a new instance of Revision. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevision(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Revision {
+	mock := &Revision{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,303 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Series is an autogenerated mock type for the Series type
+type Series struct {
+	mock.Mock
+}
+
+// CreateEpisode provides a mock function with given fields: ctx, episode, actorIds
+func (_m *Series) CreateEpisode(ctx context.Context, episode domain.Episode, actorIds []int) (int, error) {
+	ret := _m.Called(ctx, episode, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEpisode")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Episode, []int) (int, error)); ok {
+		return rf(ctx, episode, actorIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Episode, []int) int); ok {
+		r0 = rf(ctx, episode, actorIds)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Episode, []int) error); ok {
+		r1 = rf(ctx, episode, actorIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSeason provides a mock function with given fields: ctx, season
+func (_m *Series) CreateSeason(ctx context.Context, season domain.Season) (int, error) {
+	ret := _m.Called(ctx, season)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSeason")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Season) (int, error)); ok {
+		return rf(ctx, season)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Season) int); ok {
+		r0 = rf(ctx, season)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Season) error); ok {
+		r1 = rf(ctx, season)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSeries provides a mock function with given fields: ctx, series, actorIds
+func (_m *Series) CreateSeries(ctx context.Context, series domain.Series, actorIds []int) (int, error) {
+	ret := _m.Called(ctx, series, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSeries")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Series, []int) (int, error)); ok {
+		return rf(ctx, series, actorIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Series, []int) int); ok {
+		r0 = rf(ctx, series, actorIds)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Series, []int) error); ok {
+		r1 = rf(ctx, series, actorIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteEpisode provides a mock function with given fields: ctx, id
+func (_m *Series) DeleteEpisode(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEpisode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSeason provides a mock function with given fields: ctx, id
+func (_m *Series) DeleteSeason(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSeason")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSeries provides a mock function with given fields: ctx, id
+func (_m *Series) DeleteSeries(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSeries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSeries provides a mock function with given fields: ctx, id
+func (_m *Series) GetSeries(ctx context.Context, id int) (domain.Series, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSeries")
+	}
+
+	var r0 domain.Series
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (domain.Series, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) domain.Series); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Series)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCatalog provides a mock function with given fields: ctx, sortBy, sortDir
+func (_m *Series) ListCatalog(ctx context.Context, sortBy string, sortDir string) ([]domain.CatalogItem, error) {
+	ret := _m.Called(ctx, sortBy, sortDir)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListCatalog")
+	}
+
+	var r0 []domain.CatalogItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.CatalogItem, error)); ok {
+		return rf(ctx, sortBy, sortDir)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.CatalogItem); ok {
+		r0 = rf(ctx, sortBy, sortDir)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CatalogItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sortBy, sortDir)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSeries provides a mock function with given fields: ctx, sortBy, sortDir
+func (_m *Series) ListSeries(ctx context.Context, sortBy string, sortDir string) ([]domain.Series, error) {
+	ret := _m.Called(ctx, sortBy, sortDir)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSeries")
+	}
+
+	var r0 []domain.Series
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]domain.Series, error)); ok {
+		return rf(ctx, sortBy, sortDir)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []domain.Series); ok {
+		r0 = rf(ctx, sortBy, sortDir)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Series)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, sortBy, sortDir)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchCatalog provides a mock function with given fields: ctx, query
+func (_m *Series) SearchCatalog(ctx context.Context, query string) ([]domain.CatalogItem, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchCatalog")
+	}
+
+	var r0 []domain.CatalogItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]domain.CatalogItem, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []domain.CatalogItem); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CatalogItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateSeries provides a mock function with given fields: ctx, series, actorIds
+func (_m *Series) UpdateSeries(ctx context.Context, series domain.Series, actorIds []int) error {
+	ret := _m.Called(ctx, series, actorIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSeries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Series, []int) error); ok {
+		r0 = rf(ctx, series, actorIds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSeries creates a new instance of Series. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSeries(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Series {
+	mock := &Series{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
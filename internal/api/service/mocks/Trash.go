@@ -0,0 +1,143 @@
+// Code generated by mockery v2.42.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	domain "github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Trash is an autogenerated mock type for the Trash type
+type Trash struct {
+	mock.Mock
+}
+
+// ListTrash provides a mock function with given fields: ctx
+func (_m *Trash) ListTrash(ctx context.Context) (domain.Trash, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTrash")
+	}
+
+	var r0 domain.Trash
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (domain.Trash, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) domain.Trash); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.Trash)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeTrash provides a mock function with given fields: ctx, retention
+func (_m *Trash) PurgeTrash(ctx context.Context, retention time.Duration) (domain.PurgeResult, error) {
+	ret := _m.Called(ctx, retention)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTrash")
+	}
+
+	var r0 domain.PurgeResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) (domain.PurgeResult, error)); ok {
+		return rf(ctx, retention)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration) domain.PurgeResult); ok {
+		r0 = rf(ctx, retention)
+	} else {
+		r0 = ret.Get(0).(domain.PurgeResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration) error); ok {
+		r1 = rf(ctx, retention)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreActor provides a mock function with given fields: ctx, userId, id
+func (_m *Trash) RestoreActor(ctx context.Context, userId int, id int) (domain.Actor, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreActor")
+	}
+
+	var r0 domain.Actor
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Actor, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Actor); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Actor)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreFilm provides a mock function with given fields: ctx, userId, id
+func (_m *Trash) RestoreFilm(ctx context.Context, userId int, id int) (domain.Film, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreFilm")
+	}
+
+	var r0 domain.Film
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (domain.Film, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) domain.Film); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(domain.Film)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTrash creates a new instance of Trash. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTrash(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Trash {
+	mock := &Trash{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"math"
+	"sort"
+)
+
+const (
+	castWeight    = 0.6
+	genreWeight   = 0.25
+	releaseWeight = 0.15
+	releaseScale  = 10.0 // years between releases that halve the proximity score
+	highRating    = 7
+	watchedWeight = 0.5
+)
+
+// neighbours returns films sharing at least one actor or genre with the given film
+func (g *filmGraph) neighbours(filmId int) map[int]struct{} {
+	result := make(map[int]struct{})
+	for actorId := range g.filmActors[filmId] {
+		for _, id := range g.actorFilms[actorId] {
+			if id != filmId {
+				result[id] = struct{}{}
+			}
+		}
+	}
+	for genre := range g.filmGenres[filmId] {
+		for _, id := range g.genreFilms[genre] {
+			if id != filmId {
+				result[id] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// jaccard returns the Jaccard index of two sets and whether they share anything
+func jaccard[K comparable](a, b map[K]struct{}) (float64, bool) {
+	shared := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return 0, false
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared), true
+}
+
+// similarity blends the Jaccard indexes of two casts and of their genres with the proximity of release years.
+// Films with neither actors nor genres in common aren't similar
+func (g *filmGraph) similarity(a, b int) float64 {
+	cast, sharedCast := jaccard(g.filmActors[a], g.filmActors[b])
+	genres, sharedGenres := jaccard(g.filmGenres[a], g.filmGenres[b])
+	if !sharedCast && !sharedGenres {
+		return 0
+	}
+
+	var proximity float64
+	if releasedA, releasedB := g.films[a].Released, g.films[b].Released; releasedA.Valid() && releasedB.Valid() {
+		years := math.Abs(float64(releasedA.Year() - releasedB.Year()))
+		proximity = 1 / (1 + years/releaseScale)
+	}
+
+	return castWeight*cast + genreWeight*genres + releaseWeight*proximity
+}
+
+func (g *filmGraph) rank(scores map[int]float64, limit int) []domain.ScoredFilm {
+	result := make([]domain.ScoredFilm, 0, len(scores))
+	for id, score := range scores {
+		result = append(result, domain.ScoredFilm{Film: g.films[id], Score: score})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].Film.Id < result[j].Film.Id
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+type RecommendationService struct {
+	graph *graphCache
+	marks repository.UserFilm
+	log   *slog.Logger
+}
+
+func NewRecommendationService(graph *graphCache, marks repository.UserFilm, log *slog.Logger) *RecommendationService {
+	return &RecommendationService{graph: graph, marks: marks, log: log}
+}
+
+func (s *RecommendationService) SimilarFilms(ctx context.Context, filmId, limit int) ([]domain.ScoredFilm, error) {
+	g, err := s.graph.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := g.films[filmId]; !ok {
+		return nil, ErrNotFound
+	}
+
+	scores := make(map[int]float64)
+	for id := range g.neighbours(filmId) {
+		scores[id] = g.similarity(filmId, id)
+	}
+
+	return g.rank(scores, limit), nil
+}
+
+func (s *RecommendationService) Recommendations(ctx context.Context, userId, limit int) ([]domain.ScoredFilm, error) {
+	marks, err := s.marks.ListUserFilms(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.graph.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]struct{}, len(marks))
+	for _, mark := range marks {
+		seen[mark.FilmId] = struct{}{}
+	}
+
+	scores := make(map[int]float64)
+	for _, mark := range marks {
+		var weight float64
+		switch {
+		case mark.Rating != nil && *mark.Rating >= highRating:
+			weight = float64(*mark.Rating) / 10
+		case mark.Rating == nil && mark.WatchedAt != nil:
+			weight = watchedWeight
+		default:
+			continue
+		}
+
+		for id := range g.neighbours(mark.FilmId) {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			scores[id] += weight * g.similarity(mark.FilmId, id)
+		}
+	}
+
+	return g.rank(scores, limit), nil
+}
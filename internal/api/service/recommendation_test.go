@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func released(year int) domain.CustomDate {
+	return domain.NewDate(time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func prepareRecommendationTest() (*mocks.Film, *mocks.UserFilm, *RecommendationService) {
+	films := new(mocks.Film)
+	marks := new(mocks.UserFilm)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+
+	films.On("ListFilms", mock.Anything, "id", "asc", domain.FilmFilter{}).Return([]domain.Film{
+		{Id: 1, Released: released(1972)},
+		{Id: 2, Released: released(2002)},
+		{Id: 3, Released: released(1975)},
+		{Id: 4, Released: released(1980)},
+	}, nil).Once()
+	films.On("ListFilmsActors", mock.Anything, mock.Anything).Return([]domain.FilmActor{
+		{FilmId: 1, ActorId: 10}, {FilmId: 1, ActorId: 11},
+		{FilmId: 2, ActorId: 10},
+		{FilmId: 3, ActorId: 10}, {FilmId: 3, ActorId: 11},
+		{FilmId: 4, ActorId: 12},
+	}, nil).Once()
+	actors := new(mocks.Actor)
+	actors.On("ListActors", mock.Anything, -1).Return([]domain.Actor{{Id: 10}, {Id: 11}, {Id: 12}}, nil).Once()
+
+	return films, marks, NewRecommendationService(newGraphCache(films, actors), marks, log)
+}
+
+func TestRecommendationService_SimilarFilms(t *testing.T) {
+	t.Run("RankedByCastAndRelease", func(t *testing.T) {
+		films, _, s := prepareRecommendationTest()
+
+		got, err := s.SimilarFilms(context.Background(), 1, 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 2)
+		assert.Equal(t, 3, got[0].Film.Id)
+		assert.Equal(t, 2, got[1].Film.Id)
+		assert.Greater(t, got[0].Score, got[1].Score)
+
+		_, err = s.SimilarFilms(context.Background(), 3, 1)
+		assert.NoError(t, err)
+		films.AssertNumberOfCalls(t, "ListFilms", 1)
+	})
+
+	t.Run("UnknownFilm", func(t *testing.T) {
+		_, _, s := prepareRecommendationTest()
+
+		_, err := s.SimilarFilms(context.Background(), 42, 10)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestRecommendationService_Recommendations(t *testing.T) {
+	t.Run("SkipsSeenAndLowRated", func(t *testing.T) {
+		_, marks, s := prepareRecommendationTest()
+		high, low := int8(9), int8(2)
+		marks.On("ListUserFilms", mock.Anything, 5).Return([]domain.UserFilm{
+			{UserId: 5, FilmId: 1, Rating: &high},
+			{UserId: 5, FilmId: 4, Rating: &low},
+			{UserId: 5, FilmId: 3},
+		}, nil)
+
+		got, err := s.Recommendations(context.Background(), 5, 10)
+		assert.NoError(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, 2, got[0].Film.Id)
+	})
+}
+
+func TestFilmGraph_similarity(t *testing.T) {
+	g := buildFilmGraph([]domain.Film{
+		{Id: 1, Released: released(1972), Genres: domain.StringList{"Drama", "Sci-Fi"}},
+		{Id: 2, Released: released(1979), Genres: domain.StringList{"sci-fi"}},
+		{Id: 3, Released: released(1972), Genres: domain.StringList{"Comedy"}},
+		{Id: 4, Released: released(1972), Genres: domain.StringList{"Western"}},
+		{Id: 5, Released: released(1972), Genres: domain.StringList{"Drama", "Sci-Fi"}},
+	}, nil, []domain.FilmActor{{FilmId: 1, ActorId: 10}, {FilmId: 3, ActorId: 10}, {FilmId: 5, ActorId: 10}})
+
+	assert.Equal(t, map[int]struct{}{2: {}, 3: {}, 5: {}}, g.neighbours(1))
+	assert.Zero(t, g.similarity(1, 4))
+	// genres alone make films similar, both signals make them most similar
+	assert.Greater(t, g.similarity(1, 2), 0.0)
+	assert.Greater(t, g.similarity(1, 5), g.similarity(1, 3))
+	assert.Greater(t, g.similarity(1, 5), g.similarity(1, 2))
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+)
+
+type RelationService struct {
+	repos repository.Relation
+	log   *slog.Logger
+}
+
+func NewRelationService(repos repository.Relation, log *slog.Logger) *RelationService {
+	return &RelationService{repos: repos, log: log}
+}
+
+func mapRelationError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows), errors.Is(err, postgres.ErrForeign):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrUnique):
+		return ErrConflict
+	default:
+		return err
+	}
+}
+
+func (s *RelationService) CreateAlias(ctx context.Context, alias domain.FilmAlias) (int, error) {
+	id, err := s.repos.CreateAlias(ctx, alias)
+	return id, mapRelationError(err)
+}
+
+func (s *RelationService) ListAliases(ctx context.Context, filmId int) ([]domain.FilmAlias, error) {
+	return s.repos.ListAliases(ctx, filmId)
+}
+
+func (s *RelationService) DeleteAlias(ctx context.Context, id int) error {
+	return mapRelationError(s.repos.DeleteAlias(ctx, id))
+}
+
+func (s *RelationService) CreateRelation(ctx context.Context, relation domain.FilmRelation) error {
+	if relation.FilmId == relation.RelatedId {
+		return ErrBadRequest
+	}
+	return mapRelationError(s.repos.CreateRelation(ctx, relation))
+}
+
+func (s *RelationService) ListRelations(ctx context.Context, filmId int) ([]domain.FilmRelation, error) {
+	return s.repos.ListRelations(ctx, filmId)
+}
+
+func (s *RelationService) DeleteRelation(ctx context.Context, relation domain.FilmRelation) error {
+	return mapRelationError(s.repos.DeleteRelation(ctx, relation))
+}
+
+func (s *RelationService) CreateFranchise(ctx context.Context, franchise domain.Franchise) (int, error) {
+	id, err := s.repos.CreateFranchise(ctx, franchise)
+	return id, mapRelationError(err)
+}
+
+func (s *RelationService) GetFranchise(ctx context.Context, id int) (domain.Franchise, error) {
+	franchise, err := s.repos.GetFranchise(ctx, id)
+	return franchise, mapRelationError(err)
+}
+
+func (s *RelationService) ListFranchises(ctx context.Context) ([]domain.Franchise, error) {
+	return s.repos.ListFranchises(ctx)
+}
+
+func (s *RelationService) DeleteFranchise(ctx context.Context, id int) error {
+	return mapRelationError(s.repos.DeleteFranchise(ctx, id))
+}
+
+func (s *RelationService) SetFranchiseFilm(ctx context.Context, franchiseId, filmId, position int) error {
+	if position <= 0 {
+		return ErrBadRequest
+	}
+	return mapRelationError(s.repos.SetFranchiseFilm(ctx, franchiseId, filmId, position))
+}
+
+func (s *RelationService) RemoveFranchiseFilm(ctx context.Context, franchiseId, filmId int) error {
+	return mapRelationError(s.repos.RemoveFranchiseFilm(ctx, franchiseId, filmId))
+}
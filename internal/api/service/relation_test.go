@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareRelationTest() (*mocks.Relation, *RelationService) {
+	repos := new(mocks.Relation)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewRelationService(repos, log)
+}
+
+func TestRelationService_CreateRelation(t *testing.T) {
+	t.Run("SelfRelation", func(t *testing.T) {
+		repos, s := prepareRelationTest()
+
+		err := s.CreateRelation(context.Background(), domain.FilmRelation{FilmId: 1, RelatedId: 1, Kind: domain.RelationRemake})
+		assert.ErrorIs(t, err, ErrBadRequest)
+		repos.AssertNotCalled(t, "CreateRelation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		repos, s := prepareRelationTest()
+		relation := domain.FilmRelation{FilmId: 2, RelatedId: 1, Kind: domain.RelationRemake}
+		repos.On("CreateRelation", mock.Anything, relation).Return(postgres.ErrUnique)
+
+		err := s.CreateRelation(context.Background(), relation)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("MissingFilm", func(t *testing.T) {
+		repos, s := prepareRelationTest()
+		relation := domain.FilmRelation{FilmId: 2, RelatedId: 100, Kind: domain.RelationSequel}
+		repos.On("CreateRelation", mock.Anything, relation).Return(postgres.ErrForeign)
+
+		err := s.CreateRelation(context.Background(), relation)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx/types"
+	"log/slog"
+	"sort"
+)
+
+// revisionChanges lists the top level fields whose values differ between two snapshots.
+// A missing field is the same as null
+func revisionChanges(before, after types.JSONText) []domain.FieldChange {
+	var old, cur map[string]json.RawMessage
+	json.Unmarshal(before, &old)
+	json.Unmarshal(after, &cur)
+
+	seen := make(map[string]struct{}, len(cur))
+	fields := make([]string, 0, len(cur))
+	for _, m := range []map[string]json.RawMessage{old, cur} {
+		for field := range m {
+			if _, ok := seen[field]; !ok {
+				seen[field] = struct{}{}
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]domain.FieldChange, 0)
+	for _, field := range fields {
+		if compactJSON(old[field]) != compactJSON(cur[field]) {
+			changes = append(changes, domain.FieldChange{Field: field, Before: old[field], After: cur[field]})
+		}
+	}
+	return changes
+}
+
+func compactJSON(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "null"
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+type RevisionService struct {
+	repos repository.Revision
+	log   *slog.Logger
+}
+
+func NewRevisionService(repos repository.Revision, log *slog.Logger) *RevisionService {
+	return &RevisionService{repos: repos, log: log}
+}
+
+func mapRevisionError(err error) error {
+	if errors.Is(err, postgres.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *RevisionService) listRevisions(ctx context.Context, kind string, recordId int) ([]domain.Revision, error) {
+	revisions, err := s.repos.ListRevisions(ctx, kind, recordId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		revisions[i].Changes = revisionChanges(revisions[i].Before, revisions[i].After)
+	}
+	return revisions, nil
+}
+
+func (s *RevisionService) ListFilmRevisions(ctx context.Context, filmId int) ([]domain.Revision, error) {
+	return s.listRevisions(ctx, domain.KindFilm, filmId)
+}
+
+func (s *RevisionService) ListActorRevisions(ctx context.Context, actorId int) ([]domain.Revision, error) {
+	return s.listRevisions(ctx, domain.KindActor, actorId)
+}
+
+// getRevision loads the revision and decodes the record state after it into dest
+func (s *RevisionService) getRevision(ctx context.Context, kind string, recordId, id int, dest any) error {
+	revision, err := s.repos.GetRevision(ctx, id)
+	if err != nil {
+		return mapRevisionError(err)
+	}
+	if revision.Kind != kind || revision.RecordId != recordId {
+		return ErrNotFound
+	}
+	if err = revision.After.Unmarshal(dest); err != nil {
+		s.log.With(slog.Int("id", id)).Error(err.Error())
+		return ErrInternal
+	}
+	return nil
+}
+
+// FilmRevision returns the film and its cast as they were right after the revision
+func (s *RevisionService) FilmRevision(ctx context.Context, filmId, id int) (domain.FilmSnapshot, error) {
+	var snapshot domain.FilmSnapshot
+	err := s.getRevision(ctx, domain.KindFilm, filmId, id, &snapshot)
+	return snapshot, err
+}
+
+// ActorRevision returns the actor as it was right after the revision
+func (s *RevisionService) ActorRevision(ctx context.Context, actorId, id int) (domain.Actor, error) {
+	var actor domain.Actor
+	err := s.getRevision(ctx, domain.KindActor, actorId, id, &actor)
+	return actor, err
+}
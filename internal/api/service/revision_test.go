@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func prepareRevisionTest() (*mocks.Revision, *RevisionService) {
+	repos := new(mocks.Revision)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewRevisionService(repos, log)
+}
+
+func TestRevisionChanges(t *testing.T) {
+	tests := []struct {
+		Label   string
+		before  string
+		after   string
+		changes []domain.FieldChange
+	}{
+		{
+			Label: "Created",
+			after: `{"id": 1, "title": "Brother"}`,
+			changes: []domain.FieldChange{
+				{Field: "id", After: json.RawMessage(`1`)},
+				{Field: "title", After: json.RawMessage(`"Brother"`)},
+			},
+		},
+		{
+			Label:  "Cast",
+			before: `{"id": 1, "actorIds": [1, 2], "rating": null}`,
+			after:  `{"id":1,"actorIds":[2,3]}`,
+			changes: []domain.FieldChange{
+				{Field: "actorIds", Before: json.RawMessage(`[1, 2]`), After: json.RawMessage(`[2,3]`)},
+			},
+		},
+		{
+			Label:  "Deleted",
+			before: `{"id": 1}`,
+			after:  `{"id": 1, "deletedAt": "2024-05-01T10:00:00Z"}`,
+			changes: []domain.FieldChange{
+				{Field: "deletedAt", After: json.RawMessage(`"2024-05-01T10:00:00Z"`)},
+			},
+		},
+		{
+			Label:   "Unchanged",
+			before:  `{"id": 1, "countries": ["RU"]}`,
+			after:   `{"countries":["RU"],"id":1}`,
+			changes: []domain.FieldChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			got := revisionChanges(types.JSONText(tt.before), types.JSONText(tt.after))
+			assert.Equal(t, tt.changes, got)
+		})
+	}
+}
+
+func TestRevisionService_FilmRevision(t *testing.T) {
+	t.Run("OtherRecord", func(t *testing.T) {
+		repos, s := prepareRevisionTest()
+		repos.On("GetRevision", mock.Anything, 4).Return(domain.Revision{Id: 4, Kind: domain.KindActor, RecordId: 1}, nil)
+
+		_, err := s.FilmRevision(context.Background(), 1, 4)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+	t.Run("Snapshot", func(t *testing.T) {
+		repos, s := prepareRevisionTest()
+		repos.On("GetRevision", mock.Anything, 5).Return(domain.Revision{Id: 5, Kind: domain.KindFilm, RecordId: 1,
+			After: types.JSONText(`{"id": 1, "title": "Brother", "released": "1997", "actorIds": [3, 8]}`)}, nil)
+
+		got, err := s.FilmRevision(context.Background(), 1, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, "Brother", got.Title)
+		assert.Equal(t, []int{3, 8}, got.ActorIds)
+	})
+}
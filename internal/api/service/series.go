@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+)
+
+type SeriesService struct {
+	repos repository.Series
+	log   *slog.Logger
+}
+
+func NewSeriesService(repos repository.Series, log *slog.Logger) *SeriesService {
+	return &SeriesService{repos: repos, log: log}
+}
+
+func mapSeriesError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, postgres.ErrForeign):
+		return ErrBadRequest
+	case errors.Is(err, postgres.ErrUnique):
+		return ErrConflict
+	default:
+		return err
+	}
+}
+
+// uniqueIds drops repeated ids keeping the original order
+func uniqueIds(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result
+}
+
+func (s *SeriesService) CreateSeries(ctx context.Context, series domain.Series, actorIds []int) (int, error) {
+	id, err := s.repos.CreateSeries(ctx, series, uniqueIds(actorIds))
+	return id, mapSeriesError(err)
+}
+
+func (s *SeriesService) UpdateSeries(ctx context.Context, series domain.Series, actorIds []int) error {
+	return mapSeriesError(s.repos.UpdateSeries(ctx, series, uniqueIds(actorIds)))
+}
+
+func (s *SeriesService) GetSeries(ctx context.Context, id int) (domain.Series, error) {
+	series, err := s.repos.GetSeries(ctx, id)
+	return series, mapSeriesError(err)
+}
+
+func (s *SeriesService) ListSeries(ctx context.Context, sortBy, sortDir string) ([]domain.Series, error) {
+	return s.repos.ListSeries(ctx, sortBy, sortDir)
+}
+
+func (s *SeriesService) DeleteSeries(ctx context.Context, id int) error {
+	return mapSeriesError(s.repos.DeleteSeries(ctx, id))
+}
+
+func (s *SeriesService) CreateSeason(ctx context.Context, season domain.Season) (int, error) {
+	id, err := s.repos.CreateSeason(ctx, season)
+	return id, mapSeriesError(err)
+}
+
+func (s *SeriesService) DeleteSeason(ctx context.Context, id int) error {
+	return mapSeriesError(s.repos.DeleteSeason(ctx, id))
+}
+
+func (s *SeriesService) CreateEpisode(ctx context.Context, episode domain.Episode, actorIds []int) (int, error) {
+	id, err := s.repos.CreateEpisode(ctx, episode, uniqueIds(actorIds))
+	return id, mapSeriesError(err)
+}
+
+func (s *SeriesService) DeleteEpisode(ctx context.Context, id int) error {
+	return mapSeriesError(s.repos.DeleteEpisode(ctx, id))
+}
+
+func (s *SeriesService) ListCatalog(ctx context.Context, sortBy, sortDir string) ([]domain.CatalogItem, error) {
+	return s.repos.ListCatalog(ctx, sortBy, sortDir)
+}
+
+func (s *SeriesService) SearchCatalog(ctx context.Context, query string) ([]domain.CatalogItem, error) {
+	return s.repos.SearchCatalog(ctx, query)
+}
@@ -1,54 +1,257 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/apperr"
 	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"io"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 )
 
 //go:generate mockery --all --dry-run=false
 
 var (
-	ErrNotFound     = errors.New("not found")
-	ErrBadRequest   = errors.New("bad request")
-	ErrInternal     = errors.New("internal errors")
-	ErrUnauthorized = errors.New("not authorized")
+	ErrNotFound      = apperr.New(apperr.CodeNotFound, "not found")
+	ErrBadRequest    = apperr.New(apperr.CodeInvalid, "bad request")
+	ErrInternal      = apperr.New(apperr.CodeInternal, "internal errors")
+	ErrUnauthorized  = apperr.New(apperr.CodeUnauthorized, "not authorized")
+	ErrForbidden     = apperr.New(apperr.CodeForbidden, "forbidden")
+	ErrConflict      = apperr.New(apperr.CodeConflict, "conflict")
+	ErrNoPath        = apperr.New(apperr.CodeNotFound, "no path between actors")
+	ErrUnprocessable = apperr.New(apperr.CodeUnprocessable, "unprocessable entity")
+	ErrPrecondition  = apperr.New(apperr.CodePrecondition, "precondition failed")
+	ErrReviewed      = apperr.New(apperr.CodeConflict, "suggestion is already reviewed")
 )
 
+// UnknownActorsError lists referenced actor ids that don't exist
+type UnknownActorsError struct {
+	Ids []int
+}
+
+func (e *UnknownActorsError) Error() string {
+	ids := make([]string, len(e.Ids))
+	for i, id := range e.Ids {
+		ids[i] = strconv.Itoa(id)
+	}
+	return "unknown actor ids: " + strings.Join(ids, ", ")
+}
+
+func (e *UnknownActorsError) Unwrap() error {
+	return ErrUnprocessable
+}
+
 type Service struct {
 	Authorization
 	Actor
 	Film
+	Collection
+	Copy
+	UserFilm
+	Recommendation
+	Collaboration
+	Series
+	Translation
+	Relation
+	Merge
+	Trash
+	Revision
+	Audit
+	Suggestion
+	Import
+	Export
 }
 
 type Authorization interface {
-	SignUp(user domain.User) error
-	SignIn(username, password string) (string, error)
-	GetUserById(id int) (domain.User, error)
+	SignUp(ctx context.Context, user domain.User) error
+	SignIn(ctx context.Context, username, password string) (string, int, error)
+	GetUserById(ctx context.Context, id int) (domain.User, error)
+	SetUserRole(ctx context.Context, id int, role int8) (int8, error)
 }
 
 type Actor interface {
-	CreateActor(actor domain.Actor) (int, error)
-	DeleteActor(id int) error
-	UpdateActor(actor domain.Actor) error
-	PatchActor(actor domain.ActorInput) (domain.Actor, error)
-	ListActors(filmId int) ([]domain.Actor, error)
+	CreateActor(ctx context.Context, userId int, actor domain.Actor) (int, error)
+	GetActor(ctx context.Context, id int) (domain.Actor, error)
+	DeleteActor(ctx context.Context, userId, id, version int) error
+	UpdateActor(ctx context.Context, userId int, actor domain.Actor) (int, error)
+	PatchActor(ctx context.Context, userId int, actor domain.ActorInput) (domain.Actor, error)
+	RevertActor(ctx context.Context, userId int, actor domain.Actor) (int, error)
+	ListActors(ctx context.Context, filmId int) ([]domain.Actor, error)
+	StreamActors(ctx context.Context, fn func(domain.Actor) error) error
 }
 
 type Film interface {
-	CreateFilm(film domain.Film, actorIds []int) (int, error)
-	DeleteFilm(id int) error
-	UpdateFilm(film domain.Film, actorIds []int) error
-	PatchFilm(input domain.NullableFilm, actorIds []int) (domain.Film, error)
-	ListFilms(sortBy, sortDir string, actorId int) ([]domain.Film, error)
-	SearchFilm(query string) ([]domain.Film, error)
+	CreateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error)
+	GetFilm(ctx context.Context, id int) (domain.Film, error)
+	DeleteFilm(ctx context.Context, userId, id, version int) error
+	UpdateFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error)
+	PatchFilm(ctx context.Context, userId int, input domain.NullableFilm, actorIds []int) (domain.Film, error)
+	RevertFilm(ctx context.Context, userId int, film domain.Film, actorIds []int) (int, error)
+	ListFilms(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter) ([]domain.Film, error)
+	SearchFilm(ctx context.Context, query string) ([]domain.Film, error)
+	StreamFilms(ctx context.Context, sortBy, sortDir string, actorId int, filter domain.FilmFilter,
+		fn func(domain.Film) error) error
+}
+
+type Collection interface {
+	CreateCollection(ctx context.Context, userId int, collection domain.Collection) (int, error)
+	GetCollection(ctx context.Context, userId, id int) (domain.Collection, error)
+	GetSharedCollection(ctx context.Context, token string) (domain.Collection, error)
+	ListCollections(ctx context.Context, userId int) ([]domain.Collection, error)
+	ListFollowedCollections(ctx context.Context, userId int) ([]domain.Collection, error)
+	UpdateCollection(ctx context.Context, userId int, collection domain.Collection) error
+	PatchCollection(ctx context.Context, userId int, input domain.CollectionInput) (domain.Collection, error)
+	DeleteCollection(ctx context.Context, userId, id int) error
+	FollowCollection(ctx context.Context, userId, id int) error
+	UnfollowCollection(ctx context.Context, userId, id int) error
+	CloneCollection(ctx context.Context, userId, id int) (int, error)
+}
+
+type Copy interface {
+	CreateCopy(ctx context.Context, c domain.Copy) (int, error)
+	ListCopies(ctx context.Context, filmId int) ([]domain.Copy, error)
+	UpdateCopy(ctx context.Context, c domain.Copy) error
+	DeleteCopy(ctx context.Context, id int) error
+	LendCopy(ctx context.Context, loan domain.Loan) (int, error)
+	ReturnCopy(ctx context.Context, copyId int) (domain.Loan, error)
+	ListLoans(ctx context.Context, copyId int) ([]domain.Loan, error)
+	ListOverdueLoans(ctx context.Context) ([]domain.Loan, error)
+}
+
+type UserFilm interface {
+	SetUserFilm(ctx context.Context, mark domain.UserFilm) error
+	ListUserFilms(ctx context.Context, userId int) ([]domain.UserFilm, error)
+	DeleteUserFilm(ctx context.Context, userId, filmId int) error
+	ImportLetterboxd(ctx context.Context, userId int, r io.Reader, dryRun bool) (domain.LetterboxdResult, error)
+	ExportLetterboxd(ctx context.Context, userId int, w io.Writer) error
+}
+
+type Recommendation interface {
+	SimilarFilms(ctx context.Context, filmId, limit int) ([]domain.ScoredFilm, error)
+	Recommendations(ctx context.Context, userId, limit int) ([]domain.ScoredFilm, error)
+}
+
+type Collaboration interface {
+	CoStars(ctx context.Context, actorId, limit int) ([]domain.CoStar, error)
+	ActorPath(ctx context.Context, fromId, toId int) (domain.ActorPath, error)
+	ExportGraph(ctx context.Context, w io.Writer, format string) error
+}
+
+type Series interface {
+	CreateSeries(ctx context.Context, series domain.Series, actorIds []int) (int, error)
+	UpdateSeries(ctx context.Context, series domain.Series, actorIds []int) error
+	GetSeries(ctx context.Context, id int) (domain.Series, error)
+	ListSeries(ctx context.Context, sortBy, sortDir string) ([]domain.Series, error)
+	DeleteSeries(ctx context.Context, id int) error
+	CreateSeason(ctx context.Context, season domain.Season) (int, error)
+	DeleteSeason(ctx context.Context, id int) error
+	CreateEpisode(ctx context.Context, episode domain.Episode, actorIds []int) (int, error)
+	DeleteEpisode(ctx context.Context, id int) error
+	ListCatalog(ctx context.Context, sortBy, sortDir string) ([]domain.CatalogItem, error)
+	SearchCatalog(ctx context.Context, query string) ([]domain.CatalogItem, error)
+}
+
+type Translation interface {
+	SetFilmTranslation(ctx context.Context, translation domain.FilmTranslation) error
+	ListFilmTranslations(ctx context.Context, filmId int) ([]domain.FilmTranslation, error)
+	DeleteFilmTranslation(ctx context.Context, filmId int, lang string) error
+	SetActorTranslation(ctx context.Context, translation domain.ActorTranslation) error
+	ListActorTranslations(ctx context.Context, actorId int) ([]domain.ActorTranslation, error)
+	DeleteActorTranslation(ctx context.Context, actorId int, lang string) error
+	LocalizeFilms(ctx context.Context, lang string, films []domain.Film) error
+	LocalizeActors(ctx context.Context, lang string, actors []domain.Actor) error
+	Localizer(ctx context.Context, lang string) (Localizer, error)
+}
+
+type Relation interface {
+	CreateAlias(ctx context.Context, alias domain.FilmAlias) (int, error)
+	ListAliases(ctx context.Context, filmId int) ([]domain.FilmAlias, error)
+	DeleteAlias(ctx context.Context, id int) error
+	CreateRelation(ctx context.Context, relation domain.FilmRelation) error
+	ListRelations(ctx context.Context, filmId int) ([]domain.FilmRelation, error)
+	DeleteRelation(ctx context.Context, relation domain.FilmRelation) error
+	CreateFranchise(ctx context.Context, franchise domain.Franchise) (int, error)
+	GetFranchise(ctx context.Context, id int) (domain.Franchise, error)
+	ListFranchises(ctx context.Context) ([]domain.Franchise, error)
+	DeleteFranchise(ctx context.Context, id int) error
+	SetFranchiseFilm(ctx context.Context, franchiseId, filmId, position int) error
+	RemoveFranchiseFilm(ctx context.Context, franchiseId, filmId int) error
+}
+
+type Merge interface {
+	FindDuplicates(ctx context.Context) (domain.Duplicates, error)
+	MergeActors(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error)
+	MergeFilms(ctx context.Context, userId, survivorId int, duplicateIds []int) ([]domain.Merge, error)
+	ListMerges(ctx context.Context) ([]domain.Merge, error)
+}
+
+type Trash interface {
+	ListTrash(ctx context.Context) (domain.Trash, error)
+	RestoreFilm(ctx context.Context, userId, id int) (domain.Film, error)
+	RestoreActor(ctx context.Context, userId, id int) (domain.Actor, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (domain.PurgeResult, error)
+}
+
+type Revision interface {
+	ListFilmRevisions(ctx context.Context, filmId int) ([]domain.Revision, error)
+	ListActorRevisions(ctx context.Context, actorId int) ([]domain.Revision, error)
+	FilmRevision(ctx context.Context, filmId, id int) (domain.FilmSnapshot, error)
+	ActorRevision(ctx context.Context, actorId, id int) (domain.Actor, error)
+}
+
+type Audit interface {
+	RecordEvent(ctx context.Context, event domain.AuditEvent) error
+	ListAuditEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+	PurgeAuditLog(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type Suggestion interface {
+	CreateSuggestion(ctx context.Context, suggestion domain.Suggestion) (int, error)
+	GetSuggestion(ctx context.Context, id int) (domain.Suggestion, error)
+	ListSuggestions(ctx context.Context, status string) ([]domain.Suggestion, error)
+	ListUserSuggestions(ctx context.Context, userId int) ([]domain.Suggestion, error)
+	ApproveFilmSuggestion(ctx context.Context, reviewerId, id int, input domain.NullableFilm,
+		actorIds []int) (domain.Suggestion, error)
+	ApproveActorSuggestion(ctx context.Context, reviewerId, id int, input domain.ActorInput) (domain.Suggestion, error)
+	RejectSuggestion(ctx context.Context, reviewerId, id int, reason string) (domain.Suggestion, error)
+}
+
+type Import interface {
+	ImportActors(ctx context.Context, userId int, r io.Reader, format string,
+		opts domain.ImportOptions) (domain.ImportResult, error)
+	ImportFilms(ctx context.Context, userId int, r io.Reader, format string,
+		opts domain.ImportOptions) (domain.ImportResult, error)
+	ImportIMDb(ctx context.Context, userId int, files domain.IMDbFiles, opts domain.IMDbOptions) (domain.IMDbResult, error)
+}
+
+type Export interface {
+	ExportFilms(ctx context.Context, w io.Writer, format string, filter domain.FilmFilter, actorId int) error
+	ExportActors(ctx context.Context, w io.Writer, format string, filmId int) error
 }
 
 func NewService(repos *repository.Repository, log *slog.Logger) *Service {
+	graph := newGraphCache(repos, repos)
 	return &Service{
-		Authorization: NewAuthService(repos, log),
-		Actor:         NewActorService(repos, log),
-		Film:          NewFilmService(repos, log),
+		Authorization:  NewAuthService(repos, log),
+		Actor:          NewActorService(repos, graph, log),
+		Film:           NewFilmService(repos, repos, graph, log),
+		Collection:     NewCollectionService(repos, log),
+		Copy:           NewCopyService(repos, log),
+		UserFilm:       NewUserFilmService(repos, repos, log),
+		Recommendation: NewRecommendationService(graph, repos, log),
+		Collaboration:  NewCollaborationService(graph, log),
+		Series:         NewSeriesService(repos, log),
+		Translation:    NewTranslationService(repos, log),
+		Relation:       NewRelationService(repos, log),
+		Merge:          NewMergeService(repos, repos, repos, graph, log),
+		Trash:          NewTrashService(repos, graph, log),
+		Revision:       NewRevisionService(repos, log),
+		Audit:          NewAuditService(repos, log),
+		Suggestion:     NewSuggestionService(repos, repos, repos, graph, log),
+		Import:         NewImportService(repos, graph, log),
+		Export:         NewExportService(repos, log),
 	}
 }
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx/types"
+	"log/slog"
+)
+
+type SuggestionService struct {
+	repos     repository.Suggestion
+	actors    repository.Actor
+	revisions repository.Revision
+	graph     *graphCache
+	log       *slog.Logger
+}
+
+func NewSuggestionService(repos repository.Suggestion, actors repository.Actor, revisions repository.Revision,
+	graph *graphCache, log *slog.Logger) *SuggestionService {
+	return &SuggestionService{repos: repos, actors: actors, revisions: revisions, graph: graph, log: log}
+}
+
+func mapSuggestionError(err error) error {
+	if errors.Is(err, postgres.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// current returns the record a suggestion is about as JSON and whether it is in the trash
+func (s *SuggestionService) current(ctx context.Context, kind string, id int) (types.JSONText, bool, error) {
+	var record any
+	var deleted bool
+	switch kind {
+	case domain.KindFilm:
+		film, err := s.revisions.FilmSnapshot(ctx, id)
+		if err != nil {
+			return nil, false, mapSuggestionError(err)
+		}
+		record, deleted = film, film.DeletedAt != nil
+	case domain.KindActor:
+		actor, err := s.revisions.ActorSnapshot(ctx, id)
+		if err != nil {
+			return nil, false, mapSuggestionError(err)
+		}
+		record, deleted = actor, actor.DeletedAt != nil
+	default:
+		return nil, false, ErrBadRequest
+	}
+
+	data, err := json.Marshal(record)
+	return data, deleted, err
+}
+
+// proposed applies the fields a suggestion sets to the current record. A film patch keeps
+// the film fields under "film" and the cast in "actorIds", null means the field is left as is
+func proposed(kind string, current, changes types.JSONText) (types.JSONText, error) {
+	var record map[string]json.RawMessage
+	if err := json.Unmarshal(current, &record); err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if kind == domain.KindFilm {
+		var patch struct {
+			Film     map[string]json.RawMessage `json:"film"`
+			ActorIds json.RawMessage            `json:"actorIds"`
+		}
+		if err := json.Unmarshal(changes, &patch); err != nil {
+			return nil, err
+		}
+		fields = patch.Film
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+		fields["actorIds"] = patch.ActorIds
+	} else if err := json.Unmarshal(changes, &fields); err != nil {
+		return nil, err
+	}
+
+	for field, value := range fields {
+		if compactJSON(value) != "null" {
+			record[field] = value
+		}
+	}
+	return json.Marshal(record)
+}
+
+// withDiff compares pending suggestions with the current state of their records
+func (s *SuggestionService) withDiff(ctx context.Context, suggestions []domain.Suggestion) error {
+	for i := range suggestions {
+		if suggestions[i].Status != domain.SuggestionPending {
+			continue
+		}
+		current, _, err := s.current(ctx, suggestions[i].Kind, suggestions[i].RecordId)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		after, err := proposed(suggestions[i].Kind, current, suggestions[i].Changes)
+		if err != nil {
+			s.log.With(slog.Int("id", suggestions[i].Id)).Error(err.Error())
+			return ErrInternal
+		}
+		suggestions[i].Diff = revisionChanges(current, after)
+	}
+	return nil
+}
+
+// CreateSuggestion queues a change to a film or an actor that is not in the trash
+func (s *SuggestionService) CreateSuggestion(ctx context.Context, suggestion domain.Suggestion) (int, error) {
+	_, deleted, err := s.current(ctx, suggestion.Kind, suggestion.RecordId)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return 0, ErrNotFound
+	}
+	return s.repos.CreateSuggestion(ctx, suggestion)
+}
+
+func (s *SuggestionService) GetSuggestion(ctx context.Context, id int) (domain.Suggestion, error) {
+	suggestion, err := s.repos.GetSuggestion(ctx, id)
+	if err != nil {
+		return suggestion, mapSuggestionError(err)
+	}
+	suggestions := []domain.Suggestion{suggestion}
+	err = s.withDiff(ctx, suggestions)
+	return suggestions[0], err
+}
+
+func (s *SuggestionService) ListSuggestions(ctx context.Context, status string) ([]domain.Suggestion, error) {
+	suggestions, err := s.repos.ListSuggestions(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, s.withDiff(ctx, suggestions)
+}
+
+func (s *SuggestionService) ListUserSuggestions(ctx context.Context, userId int) ([]domain.Suggestion, error) {
+	suggestions, err := s.repos.ListUserSuggestions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, s.withDiff(ctx, suggestions)
+}
+
+func (s *SuggestionService) review(ctx context.Context, reviewerId, id int, status string, reason *string) (domain.Suggestion, error) {
+	suggestion, err := s.repos.ReviewSuggestion(ctx, id, reviewerId, status, reason)
+	if errors.Is(err, postgres.ErrNoRows) {
+		if _, err = s.repos.GetSuggestion(ctx, id); err != nil {
+			return suggestion, mapSuggestionError(err)
+		}
+		return suggestion, ErrConflict
+	}
+	return suggestion, err
+}
+
+// mapApproveError tells a suggestion that is no longer pending from an error about the record
+func mapApproveError(err error, mapRecordError func(error) error) error {
+	if errors.Is(err, postgres.ErrReviewed) {
+		return ErrReviewed
+	}
+	return mapRecordError(err)
+}
+
+// ApproveFilmSuggestion patches the film with a pending suggestion on behalf of the reviewer and marks it
+// approved, both or neither. nil actorIds leave the cast as is
+func (s *SuggestionService) ApproveFilmSuggestion(ctx context.Context, reviewerId, id int, input domain.NullableFilm,
+	actorIds []int) (domain.Suggestion, error) {
+	actorIds, err := checkActors(ctx, s.actors, actorIds)
+	if err != nil {
+		return domain.Suggestion{}, err
+	}
+	suggestion, err := s.repos.ApproveFilmSuggestion(ctx, id, reviewerId, input, actorIds)
+	if err != nil {
+		return suggestion, mapApproveError(err, mapFilmError)
+	}
+	s.graph.invalidate()
+	return suggestion, nil
+}
+
+// ApproveActorSuggestion patches the actor with a pending suggestion on behalf of the reviewer and marks it
+// approved, both or neither
+func (s *SuggestionService) ApproveActorSuggestion(ctx context.Context, reviewerId, id int,
+	input domain.ActorInput) (domain.Suggestion, error) {
+	suggestion, err := s.repos.ApproveActorSuggestion(ctx, id, reviewerId, input)
+	if err != nil {
+		return suggestion, mapApproveError(err, mapActorError)
+	}
+	s.graph.invalidate()
+	return suggestion, nil
+}
+
+func (s *SuggestionService) RejectSuggestion(ctx context.Context, reviewerId, id int, reason string) (domain.Suggestion, error) {
+	return s.review(ctx, reviewerId, id, domain.SuggestionRejected, &reason)
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareSuggestionTest() (*mocks.Suggestion, *mocks.Revision, *SuggestionService) {
+	repos, revisions := new(mocks.Suggestion), new(mocks.Revision)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, revisions, NewSuggestionService(repos, new(mocks.Actor), revisions, nil, log)
+}
+
+func TestSuggestionService_GetSuggestion(t *testing.T) {
+	t.Run("FilmDiff", func(t *testing.T) {
+		repos, revisions, s := prepareSuggestionTest()
+		rating := int8(7)
+		revisions.On("FilmSnapshot", mock.Anything, 5).Return(domain.FilmSnapshot{
+			Film: domain.Film{Id: 5, Title: "Brother", Rating: &rating}, ActorIds: []int{1, 2}}, nil)
+		repos.On("GetSuggestion", mock.Anything, 3).Return(domain.Suggestion{Id: 3, Kind: domain.KindFilm, RecordId: 5,
+			Status:  domain.SuggestionPending,
+			Changes: types.JSONText(`{"film": {"title": null, "rating": 8}, "actorIds": [2, 3]}`)}, nil)
+
+		got, err := s.GetSuggestion(context.Background(), 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.FieldChange{
+			{Field: "actorIds", Before: json.RawMessage(`[1,2]`), After: json.RawMessage(`[2,3]`)},
+			{Field: "rating", Before: json.RawMessage(`7`), After: json.RawMessage(`8`)},
+		}, got.Diff)
+	})
+	t.Run("Reviewed", func(t *testing.T) {
+		repos, revisions, s := prepareSuggestionTest()
+		repos.On("GetSuggestion", mock.Anything, 4).Return(domain.Suggestion{Id: 4, Kind: domain.KindActor, RecordId: 5,
+			Status: domain.SuggestionApproved, Changes: types.JSONText(`{"name": "Sergei Bodrov"}`)}, nil)
+
+		got, err := s.GetSuggestion(context.Background(), 4)
+		assert.NoError(t, err)
+		assert.Nil(t, got.Diff)
+		revisions.AssertNotCalled(t, "ActorSnapshot", mock.Anything, 5)
+	})
+}
+
+func TestSuggestionService_CreateSuggestion(t *testing.T) {
+	t.Run("Trashed", func(t *testing.T) {
+		repos, revisions, s := prepareSuggestionTest()
+		deletedAt := time.Now()
+		revisions.On("ActorSnapshot", mock.Anything, 5).Return(domain.Actor{Id: 5, DeletedAt: &deletedAt}, nil)
+
+		_, err := s.CreateSuggestion(context.Background(), domain.Suggestion{Kind: domain.KindActor, RecordId: 5})
+		assert.ErrorIs(t, err, ErrNotFound)
+		repos.AssertNotCalled(t, "CreateSuggestion", mock.Anything)
+	})
+}
+
+func TestSuggestionService_ApproveFilmSuggestion(t *testing.T) {
+	title := "Brother"
+	input := domain.NullableFilm{Id: 5, Title: &title}
+
+	t.Run("TitleOnly", func(t *testing.T) {
+		repos, _, s := prepareSuggestionTest()
+		repos.On("ApproveFilmSuggestion", mock.Anything, 8, 1, input, []int(nil)).
+			Return(domain.Suggestion{Id: 8, Status: domain.SuggestionApproved}, nil)
+
+		got, err := s.ApproveFilmSuggestion(context.Background(), 1, 8, input, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.SuggestionApproved, got.Status)
+		repos.AssertExpectations(t)
+	})
+	t.Run("AlreadyReviewed", func(t *testing.T) {
+		repos, _, s := prepareSuggestionTest()
+		repos.On("ApproveFilmSuggestion", mock.Anything, 8, 1, input, []int(nil)).
+			Return(domain.Suggestion{}, postgres.ErrReviewed)
+
+		_, err := s.ApproveFilmSuggestion(context.Background(), 1, 8, input, nil)
+		assert.ErrorIs(t, err, ErrReviewed)
+	})
+}
+
+func TestSuggestionService_RejectSuggestion(t *testing.T) {
+	t.Run("AlreadyReviewed", func(t *testing.T) {
+		repos, _, s := prepareSuggestionTest()
+		reason := "duplicate"
+		repos.On("ReviewSuggestion", mock.Anything, 3, 1, domain.SuggestionRejected, &reason).
+			Return(domain.Suggestion{}, postgres.ErrNoRows)
+		repos.On("GetSuggestion", mock.Anything, 3).Return(domain.Suggestion{Id: 3, Status: domain.SuggestionApproved}, nil)
+
+		_, err := s.RejectSuggestion(context.Background(), 1, 3, reason)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+	t.Run("NoSuchId", func(t *testing.T) {
+		repos, _, s := prepareSuggestionTest()
+		reason := "duplicate"
+		repos.On("ReviewSuggestion", mock.Anything, 9, 1, domain.SuggestionRejected, &reason).
+			Return(domain.Suggestion{}, postgres.ErrNoRows)
+		repos.On("GetSuggestion", mock.Anything, 9).Return(domain.Suggestion{}, postgres.ErrNoRows)
+
+		_, err := s.RejectSuggestion(context.Background(), 1, 9, reason)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+)
+
+type TranslationService struct {
+	repos repository.Translation
+	log   *slog.Logger
+}
+
+func NewTranslationService(repos repository.Translation, log *slog.Logger) *TranslationService {
+	return &TranslationService{repos: repos, log: log}
+}
+
+func mapTranslationError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNoRows), errors.Is(err, postgres.ErrForeign):
+		return ErrNotFound
+	default:
+		return err
+	}
+}
+
+func (s *TranslationService) SetFilmTranslation(ctx context.Context, translation domain.FilmTranslation) error {
+	return mapTranslationError(s.repos.SetFilmTranslation(ctx, translation))
+}
+
+func (s *TranslationService) ListFilmTranslations(ctx context.Context, filmId int) ([]domain.FilmTranslation, error) {
+	return s.repos.ListFilmTranslations(ctx, filmId)
+}
+
+func (s *TranslationService) DeleteFilmTranslation(ctx context.Context, filmId int, lang string) error {
+	return mapTranslationError(s.repos.DeleteFilmTranslation(ctx, filmId, lang))
+}
+
+func (s *TranslationService) SetActorTranslation(ctx context.Context, translation domain.ActorTranslation) error {
+	return mapTranslationError(s.repos.SetActorTranslation(ctx, translation))
+}
+
+func (s *TranslationService) ListActorTranslations(ctx context.Context, actorId int) ([]domain.ActorTranslation, error) {
+	return s.repos.ListActorTranslations(ctx, actorId)
+}
+
+func (s *TranslationService) DeleteActorTranslation(ctx context.Context, actorId int, lang string) error {
+	return mapTranslationError(s.repos.DeleteActorTranslation(ctx, actorId, lang))
+}
+
+// Localizer replaces titles, descriptions and names with translations to one language loaded once,
+// so that films and actors can be localized one at a time. The zero Localizer keeps everything as it is
+type Localizer struct {
+	films  map[int]domain.FilmTranslation
+	actors map[int]string
+}
+
+// Film localizes the film and its cast
+func (l Localizer) Film(film *domain.Film) {
+	localizeFilm(film, l.films)
+	for i := range film.Actors {
+		localizeActor(&film.Actors[i], l.actors)
+	}
+}
+
+// Actor localizes the actor and the titles of their films
+func (l Localizer) Actor(actor *domain.Actor) {
+	localizeActor(actor, l.actors)
+	for i := range actor.Films {
+		localizeFilm(&actor.Films[i], l.films)
+	}
+}
+
+// Localizer loads the translations to lang, an empty lang gives the zero Localizer
+func (s *TranslationService) Localizer(ctx context.Context, lang string) (Localizer, error) {
+	if lang == "" {
+		return Localizer{}, nil
+	}
+	filmNames, actorNames, err := s.load(ctx, lang)
+	if err != nil {
+		return Localizer{}, err
+	}
+	return Localizer{films: filmNames, actors: actorNames}, nil
+}
+
+// LocalizeFilms replaces titles, descriptions and cast names with their translations.
+// Fields without a translation keep the original value
+func (s *TranslationService) LocalizeFilms(ctx context.Context, lang string, films []domain.Film) error {
+	if len(films) == 0 {
+		return nil
+	}
+	l, err := s.Localizer(ctx, lang)
+	if err != nil {
+		return err
+	}
+	for i := range films {
+		l.Film(&films[i])
+	}
+	return nil
+}
+
+// LocalizeActors replaces actor names and titles of their films with translations
+func (s *TranslationService) LocalizeActors(ctx context.Context, lang string, actors []domain.Actor) error {
+	if len(actors) == 0 {
+		return nil
+	}
+	l, err := s.Localizer(ctx, lang)
+	if err != nil {
+		return err
+	}
+	for i := range actors {
+		l.Actor(&actors[i])
+	}
+	return nil
+}
+
+func (s *TranslationService) load(ctx context.Context, lang string) (map[int]domain.FilmTranslation, map[int]string, error) {
+	films, err := s.repos.ListFilmTranslationsByLang(ctx, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+	actors, err := s.repos.ListActorTranslationsByLang(ctx, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filmNames := make(map[int]domain.FilmTranslation, len(films))
+	for _, t := range films {
+		filmNames[t.FilmId] = t
+	}
+	actorNames := make(map[int]string, len(actors))
+	for _, t := range actors {
+		actorNames[t.ActorId] = t.Name
+	}
+	return filmNames, actorNames, nil
+}
+
+func localizeFilm(film *domain.Film, translations map[int]domain.FilmTranslation) {
+	t, ok := translations[film.Id]
+	if !ok {
+		return
+	}
+	film.Title = t.Title
+	if t.Description != "" {
+		film.Description = &t.Description
+	}
+}
+
+func localizeActor(actor *domain.Actor, translations map[int]string) {
+	if name, ok := translations[actor.Id]; ok {
+		actor.Name = name
+	}
+}
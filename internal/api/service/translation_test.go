@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func prepareTranslationTest() (*mocks.Translation, *TranslationService) {
+	repos := new(mocks.Translation)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewTranslationService(repos, log)
+}
+
+func TestTranslationService_LocalizeFilms(t *testing.T) {
+	t.Run("FallbackToOriginal", func(t *testing.T) {
+		repos, s := prepareTranslationTest()
+		repos.On("ListFilmTranslationsByLang", mock.Anything, "en").Return([]domain.FilmTranslation{
+			{FilmId: 1, Lang: "en", Title: "Brother"},
+		}, nil)
+		repos.On("ListActorTranslationsByLang", mock.Anything, "en").Return([]domain.ActorTranslation{
+			{ActorId: 10, Lang: "en", Name: "Sergei Bodrov"},
+		}, nil)
+
+		films := []domain.Film{
+			{Id: 1, Title: "Брат", Description: ptr("Описание"), Actors: []domain.Actor{
+				{Id: 10, Name: "Сергей Бодров"}, {Id: 11, Name: "Виктор Сухоруков"},
+			}},
+			{Id: 2, Title: "Брат 2"},
+		}
+		err := s.LocalizeFilms(context.Background(), "en", films)
+		assert.NoError(t, err)
+		assert.Equal(t, "Brother", films[0].Title)
+		assert.Equal(t, "Описание", *films[0].Description)
+		assert.Equal(t, "Sergei Bodrov", films[0].Actors[0].Name)
+		assert.Equal(t, "Виктор Сухоруков", films[0].Actors[1].Name)
+		assert.Equal(t, "Брат 2", films[1].Title)
+	})
+
+	t.Run("NoLanguage", func(t *testing.T) {
+		repos, s := prepareTranslationTest()
+
+		films := []domain.Film{{Id: 1, Title: "Брат"}}
+		err := s.LocalizeFilms(context.Background(), "", films)
+		assert.NoError(t, err)
+		assert.Equal(t, "Брат", films[0].Title)
+		repos.AssertNotCalled(t, "ListFilmTranslationsByLang", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTranslationService_SetFilmTranslation(t *testing.T) {
+	t.Run("MissingFilm", func(t *testing.T) {
+		repos, s := prepareTranslationTest()
+		translation := domain.FilmTranslation{FilmId: 5, Lang: "en", Title: "Title"}
+		repos.On("SetFilmTranslation", mock.Anything, translation).Return(postgres.ErrForeign)
+
+		err := s.SetFilmTranslation(context.Background(), translation)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
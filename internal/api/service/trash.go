@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"time"
+)
+
+type TrashService struct {
+	repos repository.Trash
+	graph *graphCache
+	log   *slog.Logger
+}
+
+func NewTrashService(repos repository.Trash, graph *graphCache, log *slog.Logger) *TrashService {
+	return &TrashService{repos: repos, graph: graph, log: log}
+}
+
+func mapTrashError(err error) error {
+	if errors.Is(err, postgres.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *TrashService) ListTrash(ctx context.Context) (domain.Trash, error) {
+	var trash domain.Trash
+	var err error
+	if trash.Films, err = s.repos.ListDeletedFilms(ctx); err != nil {
+		return trash, err
+	}
+	trash.Actors, err = s.repos.ListDeletedActors(ctx)
+	return trash, err
+}
+
+func (s *TrashService) RestoreFilm(ctx context.Context, userId, id int) (domain.Film, error) {
+	film, err := s.repos.RestoreFilm(ctx, userId, id)
+	if err != nil {
+		return film, mapTrashError(err)
+	}
+	s.graph.invalidate()
+	return film, nil
+}
+
+func (s *TrashService) RestoreActor(ctx context.Context, userId, id int) (domain.Actor, error) {
+	actor, err := s.repos.RestoreActor(ctx, userId, id)
+	if err != nil {
+		return actor, mapTrashError(err)
+	}
+	s.graph.invalidate()
+	return actor, nil
+}
+
+// PurgeTrash removes for good the records that have been in the trash longer than retention
+func (s *TrashService) PurgeTrash(ctx context.Context, retention time.Duration) (domain.PurgeResult, error) {
+	if retention <= 0 {
+		return domain.PurgeResult{}, ErrBadRequest
+	}
+	return s.repos.Purge(ctx, time.Now().Add(-retention))
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/mocks"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func prepareTrashTest() (*mocks.Trash, *TrashService) {
+	repos := new(mocks.Trash)
+	log := slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	)
+	return repos, NewTrashService(repos, nil, log)
+}
+
+func TestTrashService_RestoreActor(t *testing.T) {
+	t.Run("NotInTrash", func(t *testing.T) {
+		repos, s := prepareTrashTest()
+		repos.On("RestoreActor", mock.Anything, 1, 3).Return(domain.Actor{}, postgres.ErrNoRows)
+
+		_, err := s.RestoreActor(context.Background(), 1, 3)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestTrashService_PurgeTrash(t *testing.T) {
+	t.Run("Retention", func(t *testing.T) {
+		repos, s := prepareTrashTest()
+		retention := 30 * 24 * time.Hour
+		start := time.Now()
+		repos.On("Purge", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return !before.Before(start.Add(-retention)) && !before.After(time.Now().Add(-retention))
+		})).Return(domain.PurgeResult{Films: 1}, nil)
+
+		got, err := s.PurgeTrash(context.Background(), retention)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), got.Films)
+		repos.AssertExpectations(t)
+	})
+	t.Run("NoRetention", func(t *testing.T) {
+		repos, s := prepareTrashTest()
+
+		_, err := s.PurgeTrash(context.Background(), 0)
+		assert.ErrorIs(t, err, ErrBadRequest)
+		repos.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+	})
+}
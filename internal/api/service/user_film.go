@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+)
+
+type UserFilmService struct {
+	repos repository.UserFilm
+	films repository.Film
+	log   *slog.Logger
+}
+
+func NewUserFilmService(repos repository.UserFilm, films repository.Film, log *slog.Logger) *UserFilmService {
+	return &UserFilmService{repos: repos, films: films, log: log}
+}
+
+func (s *UserFilmService) SetUserFilm(ctx context.Context, mark domain.UserFilm) error {
+	err := s.repos.SetUserFilm(ctx, mark)
+	if errors.Is(err, postgres.ErrForeign) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *UserFilmService) ListUserFilms(ctx context.Context, userId int) ([]domain.UserFilm, error) {
+	return s.repos.ListUserFilms(ctx, userId)
+}
+
+func (s *UserFilmService) DeleteUserFilm(ctx context.Context, userId, filmId int) error {
+	err := s.repos.DeleteUserFilm(ctx, userId, filmId)
+	if errors.Is(err, postgres.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -2,26 +2,36 @@ package app
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 )
 
 type App struct {
 	httpServer *http.Server
+	cancel     context.CancelFunc
 }
 
+// Run serves the handler. The request contexts are cancelled on Shutdown
 func (s *App) Run(port string, handler http.Handler) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
 	s.httpServer = &http.Server{
 		Addr:           ":" + port,
 		MaxHeaderBytes: 1 << 20,
 		ReadTimeout:    10 * time.Second,
 		WriteTimeout:   10 * time.Second,
 		Handler:        handler,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
 	}
 
 	return s.httpServer.ListenAndServe()
 }
 
+// Shutdown waits for the active requests until ctx is done, then cancels the queries still running
 func (s *App) Shutdown(ctx context.Context) error {
+	defer s.cancel()
 	return s.httpServer.Shutdown(ctx)
 }
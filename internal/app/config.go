@@ -0,0 +1,74 @@
+package app
+
+import (
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/handler"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/api/repository/postgres"
+	"github.com/spf13/viper"
+	"io"
+	logfatal "log"
+	"log/slog"
+	"os"
+	"time"
+)
+
+const (
+	envDev  = "dev"
+	envProd = "prod"
+)
+
+// InitConfig reads the config named by APP_ENV from CONFIG_PATH
+func InitConfig() error {
+	viper.AddConfigPath(os.Getenv("CONFIG_PATH"))
+	viper.SetConfigName(os.Getenv("APP_ENV"))
+	viper.SetDefault("trash.retention_days", 30)
+	viper.SetDefault("trash.purge_interval", time.Hour)
+	viper.SetDefault("audit.retention_days", 365)
+	viper.SetDefault("audit.purge_interval", 24*time.Hour)
+	viper.SetDefault("timeouts.read", 5*time.Second)
+	viper.SetDefault("timeouts.write", 10*time.Second)
+	viper.SetDefault("timeouts.bulk", 5*time.Minute)
+	viper.SetDefault("timeouts.transfer", 0)
+	viper.SetDefault("timeouts.shutdown", 15*time.Second)
+	return viper.ReadInConfig()
+}
+
+func SetupLogger(env string, out io.Writer) *slog.Logger {
+	var log *slog.Logger
+
+	switch env {
+	case envDev:
+		log = slog.New(
+			slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		)
+	case envProd:
+		log = slog.New(
+			slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		)
+	default:
+		logfatal.Fatalf("Wrong env specified, env='%s', expected '%s' or '%s'", env, envDev, envProd)
+	}
+
+	return log
+}
+
+// PostgresConfig reads the database settings, the password comes from POSTGRES_PASSWORD
+func PostgresConfig() postgres.Config {
+	return postgres.Config{
+		Host:     viper.GetString("db.host"),
+		Port:     viper.GetString("db.port"),
+		Username: viper.GetString("db.username"),
+		Password: os.Getenv("POSTGRES_PASSWORD"),
+		DBName:   viper.GetString("db.dbname"),
+		SSLMode:  viper.GetString("db.sslmode"),
+	}
+}
+
+// RequestTimeouts reads the limits of the request queries, zero turns a limit off
+func RequestTimeouts() handler.Timeouts {
+	return handler.Timeouts{
+		Read:     viper.GetDuration("timeouts.read"),
+		Write:    viper.GetDuration("timeouts.write"),
+		Bulk:     viper.GetDuration("timeouts.bulk"),
+		Transfer: viper.GetDuration("timeouts.transfer"),
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"log/slog"
+	"time"
+)
+
+type Purger interface {
+	PurgeTrash(ctx context.Context, retention time.Duration) (domain.PurgeResult, error)
+	PurgeAuditLog(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// every calls fn right away and then every interval until ctx is done
+func every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		fn()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunPurge empties the trash of records older than retention every interval until ctx is done
+func RunPurge(ctx context.Context, log *slog.Logger, purger Purger, interval, retention time.Duration) {
+	const method = "App.RunPurge"
+	log = log.With(slog.String("method", method))
+
+	every(ctx, interval, func() {
+		result, err := purger.PurgeTrash(ctx, retention)
+		if err != nil {
+			log.With(slog.String("err", err.Error())).Error("failed to purge trash")
+		} else if result.Films > 0 || result.Actors > 0 || result.Kept > 0 {
+			log.With(
+				slog.Int64("films", result.Films),
+				slog.Int64("actors", result.Actors),
+				slog.Int64("kept", result.Kept),
+			).Info("trash purged")
+		}
+	})
+}
+
+// RunAuditPurge removes audit events older than retention every interval until ctx is done
+func RunAuditPurge(ctx context.Context, log *slog.Logger, purger Purger, interval, retention time.Duration) {
+	const method = "App.RunAuditPurge"
+	log = log.With(slog.String("method", method))
+
+	every(ctx, interval, func() {
+		count, err := purger.PurgeAuditLog(ctx, retention)
+		if err != nil {
+			log.With(slog.String("err", err.Error())).Error("failed to purge audit log")
+		} else if count > 0 {
+			log.With(slog.Int64("events", count)).Info("audit log purged")
+		}
+	})
+}
@@ -0,0 +1,54 @@
+// Package apperr holds the error model shared by repositories, services and handlers.
+// Every error carries a Code, so that handlers can pick a response without knowing where the error came from
+package apperr
+
+import "errors"
+
+type Code string
+
+const (
+	CodeInvalid       Code = "invalid_input"
+	CodeUnauthorized  Code = "unauthorized"
+	CodeForbidden     Code = "forbidden"
+	CodeNotFound      Code = "not_found"
+	CodeConflict      Code = "conflict"
+	CodeUnprocessable Code = "unprocessable"
+	CodePrecondition  Code = "precondition_failed"
+	CodeInternal      Code = "internal"
+	CodeTimeout       Code = "timeout"
+)
+
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap annotates err with a code and a message. errors.Is still matches err and its chain
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// CodeOf returns the code of the outermost Error in the chain. Errors without a code are internal
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,30 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	errNoRows := New(CodeNotFound, "no rows")
+
+	t.Run("Sentinel", func(t *testing.T) {
+		assert.Equal(t, CodeNotFound, CodeOf(errNoRows))
+	})
+	t.Run("WrappedWithFmt", func(t *testing.T) {
+		err := fmt.Errorf("film 5: %w", errNoRows)
+		assert.Equal(t, CodeNotFound, CodeOf(err))
+		assert.ErrorIs(t, err, errNoRows)
+	})
+	t.Run("OuterCodeWins", func(t *testing.T) {
+		err := Wrap(errNoRows, CodeUnprocessable, "actor doesn't exist")
+		assert.Equal(t, CodeUnprocessable, CodeOf(err))
+		assert.ErrorIs(t, err, errNoRows)
+		assert.EqualError(t, err, "actor doesn't exist: no rows")
+	})
+	t.Run("Plain", func(t *testing.T) {
+		assert.Equal(t, CodeInternal, CodeOf(errors.New("boom")))
+	})
+}
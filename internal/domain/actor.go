@@ -1,11 +1,16 @@
 package domain
 
+import "time"
+
 type Actor struct {
-	Id       int        `json:"id" db:"id"`
-	Name     string     `json:"name" db:"name" validate:"required"`
-	Gender   int        `json:"gender" db:"gender" validate:"required,oneof=0 1 2 9"` // ISO/IEC 5218
-	Birthday CustomDate `json:"birthday" db:"birthday" validate:"required"`
-	Films    []Film     `json:"films,omitempty" db:"-"`
+	Id        int        `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name" validate:"required"`
+	Gender    *int       `json:"gender" db:"gender" validate:"required,oneof=0 1 2 9"` // ISO/IEC 5218, NULL in legacy rows
+	Birthday  CustomDate `json:"birthday" db:"birthday" validate:"required"`           // NULL in legacy rows
+	Films     []Film     `json:"films,omitempty" db:"-"`
+	Version   int        `json:"-" db:"version"` // sent as ETag
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+	DeletedBy *int       `json:"deletedBy,omitempty" db:"deleted_by"`
 }
 
 type ActorInput struct {
@@ -13,4 +18,5 @@ type ActorInput struct {
 	Name     *string     `json:"name" validate:"omitempty,gt=0"`
 	Gender   *int        `json:"gender" validate:"omitempty,oneof=0 1 2 9"`
 	Birthday *CustomDate `json:"birthday" validate:"omitempty"`
+	Version  int         `json:"-"` // expected version from If-Match, 0 for any
 }
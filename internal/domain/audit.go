@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"github.com/jmoiron/sqlx/types"
+	"time"
+)
+
+const (
+	AuditSignIn       = "sign_in"
+	AuditSignInFailed = "sign_in_failed"
+	AuditSignUp       = "sign_up"
+	AuditSignUpFailed = "sign_up_failed"
+	AuditRoleChange   = "role_change"
+	AuditAdminDenied  = "admin_denied"
+)
+
+// AuditEvent is a security relevant event. UserId is who did it, when known
+type AuditEvent struct {
+	Id        int64          `json:"id" db:"id"`
+	Event     string         `json:"event" db:"event"`
+	UserId    *int           `json:"userId" db:"user_id"`
+	Username  *string        `json:"username" db:"username"`
+	IP        string         `json:"ip" db:"ip"`
+	UserAgent string         `json:"userAgent" db:"user_agent"`
+	Details   types.JSONText `json:"details,omitempty" db:"details"`
+	CreatedAt time.Time      `json:"createdAt" db:"created_at"`
+}
+
+type AuditFilter struct {
+	Event    *string    `query:"event" validate:"omitempty,oneof=sign_in sign_in_failed sign_up sign_up_failed role_change admin_denied"`
+	UserId   *int       `query:"user_id" validate:"omitempty,gt=0"`
+	Username *string    `query:"username"`
+	IP       *string    `query:"ip" validate:"omitempty,ip"`
+	From     *time.Time `query:"from"`
+	To       *time.Time `query:"to"`
+	Limit    int        `query:"limit" validate:"gte=1,lte=1000"`
+	Offset   int        `query:"offset" validate:"gte=0"`
+}
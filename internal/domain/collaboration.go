@@ -0,0 +1,17 @@
+package domain
+
+type CoStar struct {
+	Actor       Actor  `json:"actor"`
+	SharedCount int    `json:"sharedCount"`
+	Films       []Film `json:"films"`
+}
+
+type PathStep struct {
+	Actor Actor `json:"actor"`
+	Film  *Film `json:"film,omitempty"` // film connecting the actor with the next step
+}
+
+type ActorPath struct {
+	Degrees int        `json:"degrees"`
+	Steps   []PathStep `json:"steps"`
+}
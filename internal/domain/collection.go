@@ -0,0 +1,26 @@
+package domain
+
+type Collection struct {
+	Id          int              `json:"id" db:"id"`
+	OwnerId     int              `json:"ownerId" db:"owner_id"`
+	Title       string           `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	Description string           `json:"description" db:"description" validate:"lte=1000"`
+	IsPublic    bool             `json:"isPublic" db:"is_public"`
+	ShareToken  string           `json:"shareToken,omitempty" db:"share_token"`
+	ClonedFrom  *int             `json:"clonedFrom,omitempty" db:"cloned_from"`
+	Items       []CollectionItem `json:"items" db:"-" validate:"dive"`
+}
+
+type CollectionItem struct {
+	FilmId    int    `json:"filmId" db:"film_id" validate:"required"`
+	Position  int    `json:"position" db:"position"`
+	Note      string `json:"note" db:"note" validate:"lte=1000"`
+	FilmTitle string `json:"filmTitle,omitempty" db:"title"`
+}
+
+type CollectionInput struct {
+	Id          int     `json:"-"`
+	Title       *string `json:"title" validate:"omitempty,gt=0,lte=150"`
+	Description *string `json:"description" validate:"omitempty,lte=1000"`
+	IsPublic    *bool   `json:"isPublic"`
+}
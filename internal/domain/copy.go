@@ -0,0 +1,21 @@
+package domain
+
+type Copy struct {
+	Id        int    `json:"id" db:"id"`
+	FilmId    int    `json:"filmId" db:"film_id"`
+	Format    string `json:"format" db:"format" validate:"required,oneof=dvd bluray uhd vhs"`
+	Barcode   string `json:"barcode" db:"barcode" validate:"omitempty,numeric,min=8,max=14"` // EAN/UPC
+	Shelf     string `json:"shelf" db:"shelf" validate:"lte=64"`
+	Condition string `json:"condition" db:"condition" validate:"required,oneof=new good fair poor damaged"`
+	OnLoan    bool   `json:"onLoan" db:"on_loan"`
+}
+
+type Loan struct {
+	Id         int         `json:"id" db:"id"`
+	CopyId     int         `json:"copyId" db:"copy_id"`
+	UserId     *int        `json:"userId,omitempty" db:"user_id" validate:"required_without=Borrower"`
+	Borrower   string      `json:"borrower,omitempty" db:"borrower" validate:"lte=255"`
+	LentAt     CustomDate  `json:"lentAt" db:"lent_at"`
+	DueDate    CustomDate  `json:"dueDate" db:"due_date" validate:"required,fulldate"`
+	ReturnedAt *CustomDate `json:"returnedAt,omitempty" db:"returned_at"`
+}
@@ -1,28 +1,140 @@
 package domain
 
 import (
+	"bytes"
+	"database/sql/driver"
 	"fmt"
 	"time"
 )
 
-const dateFormat = "2006-01-02"
+type DatePrecision uint8
 
-type CustomDate time.Time
+const (
+	PrecisionDay DatePrecision = iota
+	PrecisionMonth
+	PrecisionYear
+)
+
+var dateLayouts = map[DatePrecision]string{
+	PrecisionDay:   "2006-01-02",
+	PrecisionMonth: "2006-01",
+	PrecisionYear:  "2006",
+}
+
+// CustomDate is a calendar date known up to a day, a month or a year: "1927-03-31", "1927-03" or "1927".
+// The zero value stands for a missing date and is stored as NULL and encoded as null.
+// Dates are kept as ISO strings in the database, so partial dates sort before full dates of the same period
+type CustomDate struct {
+	time.Time
+	Precision DatePrecision
+}
+
+// NewDate returns t as a date with day precision
+func NewDate(t time.Time) CustomDate {
+	return CustomDate{Time: time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
 
-func (d *CustomDate) UnmarshalJSON(b []byte) (err error) {
-	date, err := time.Parse(fmt.Sprintf(`"%s"`, dateFormat), string(b))
-	if err != nil {
-		return err
+// ParseDate parses "2006", "2006-01" and "2006-01-02" dates
+func ParseDate(s string) (CustomDate, error) {
+	for _, precision := range []DatePrecision{PrecisionDay, PrecisionMonth, PrecisionYear} {
+		layout := dateLayouts[precision]
+		if len(s) != len(layout) {
+			continue
+		}
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return CustomDate{}, err
+		}
+		return CustomDate{Time: t, Precision: precision}, nil
 	}
-	*d = CustomDate(date)
-	return
+	return CustomDate{}, fmt.Errorf("date %q is not in YYYY, YYYY-MM or YYYY-MM-DD format", s)
 }
 
-func (d CustomDate) MarshalJSON() ([]byte, error) {
+func (d CustomDate) Valid() bool {
+	return !d.Time.IsZero()
+}
+
+// Compare orders dates by time, a partial date goes before full dates of its period. Missing dates go last
+func (d CustomDate) Compare(other CustomDate) int {
+	switch {
+	case !d.Valid() || !other.Valid():
+		if d.Valid() == other.Valid() {
+			return 0
+		}
+		if d.Valid() {
+			return -1
+		}
+		return 1
+	case !d.Time.Equal(other.Time):
+		return d.Time.Compare(other.Time)
+	case d.Precision != other.Precision:
+		if d.Precision > other.Precision {
+			return -1
+		}
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (d CustomDate) String() string {
+	if !d.Valid() {
+		return ""
+	}
+	return d.Time.Format(dateLayouts[d.Precision])
+}
+
+func (d CustomDate) MarshalText() ([]byte, error) {
 	return []byte(d.String()), nil
 }
 
-func (ct CustomDate) String() string {
-	t := time.Time(ct)
-	return fmt.Sprintf("%q", t.Format(dateFormat))
+func (d *CustomDate) UnmarshalText(b []byte) (err error) {
+	if len(b) == 0 {
+		*d = CustomDate{}
+		return nil
+	}
+	*d, err = ParseDate(string(b))
+	return err
+}
+
+func (d CustomDate) MarshalJSON() ([]byte, error) {
+	if !d.Valid() {
+		return []byte("null"), nil
+	}
+	return []byte(fmt.Sprintf("%q", d.String())), nil
+}
+
+func (d *CustomDate) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = CustomDate{}
+		return nil
+	}
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return fmt.Errorf("date must be a string, got %s", b)
+	}
+	return d.UnmarshalText(b[1 : len(b)-1])
+}
+
+func (d *CustomDate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = CustomDate{}
+		return nil
+	case time.Time:
+		*d = NewDate(v)
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(v))
+	case []byte:
+		return d.UnmarshalText(v)
+	default:
+		return fmt.Errorf("can't scan %T into date", src)
+	}
+}
+
+func (d CustomDate) Value() (driver.Value, error) {
+	if !d.Valid() {
+		return nil, nil
+	}
+	return d.String(), nil
 }
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		Label     string
+		input     string
+		precision DatePrecision
+		expectErr bool
+	}{
+		{Label: "Day", input: "1927-03-31", precision: PrecisionDay},
+		{Label: "Month", input: "1927-03", precision: PrecisionMonth},
+		{Label: "Year", input: "1927", precision: PrecisionYear},
+		{Label: "WrongMonth", input: "1927-13", expectErr: true},
+		{Label: "WrongFormat", input: "31.03.1927", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Label, func(t *testing.T) {
+			got, err := ParseDate(tt.input)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.precision, got.Precision)
+			assert.Equal(t, tt.input, got.String())
+		})
+	}
+}
+
+func TestCustomDate_JSON(t *testing.T) {
+	var film struct {
+		Released CustomDate  `json:"released"`
+		Birthday CustomDate  `json:"birthday"`
+		Watched  *CustomDate `json:"watched"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"released":"1927-03","birthday":null}`), &film))
+	assert.Equal(t, PrecisionMonth, film.Released.Precision)
+	assert.False(t, film.Birthday.Valid())
+
+	got, err := json.Marshal(film)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"released":"1927-03","birthday":null,"watched":null}`, string(got))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"released":1927}`), &film))
+}
+
+func TestCustomDate_Scan(t *testing.T) {
+	var d CustomDate
+	require.NoError(t, d.Scan("1927"))
+	assert.Equal(t, PrecisionYear, d.Precision)
+
+	require.NoError(t, d.Scan(time.Date(1927, 3, 31, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "1927-03-31", d.String())
+
+	require.NoError(t, d.Scan(nil))
+	assert.False(t, d.Valid())
+	value, err := d.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, value)
+
+	require.NoError(t, d.Scan([]byte("1927-03")))
+	value, err = d.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1927-03", value)
+}
+
+func TestCustomDate_Compare(t *testing.T) {
+	dates := []CustomDate{{}}
+	for _, s := range []string{"1928", "1927-03-31", "1927-03", "1927", "1927-04-01"} {
+		d, err := ParseDate(s)
+		require.NoError(t, err)
+		dates = append(dates, d)
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Compare(dates[j]) < 0 })
+	got := make([]string, len(dates))
+	for i, d := range dates {
+		got[i] = d.String()
+	}
+	// the same order the database gives for the ISO strings, missing dates last
+	assert.Equal(t, []string{"1927", "1927-03", "1927-03-31", "1927-04-01", "1928", ""}, got)
+}
@@ -1,12 +1,26 @@
 package domain
 
+import "time"
+
 type Film struct {
 	Id          int        `json:"id" db:"id"`
 	Title       string     `json:"title" db:"title" validate:"required,gt=0,lte=150"`
-	Description string     `json:"description" db:"description" validate:"required,lte=1000"`
-	Released    CustomDate `json:"released" db:"released" validate:"required"`
-	Rating      int8       `json:"rating" db:"rating" validate:"gte=0,lte=10"`
+	Description *string    `json:"description" db:"description" validate:"required,lte=1000"` // NULL in legacy rows
+	Released    CustomDate `json:"released" db:"released" validate:"required"`                // NULL in legacy rows
+	Rating      *int8      `json:"rating" db:"rating" validate:"omitempty,gte=0,lte=10"`
 	Actors      []Actor    `json:"actors,omitempty" db:"-"`
+	Version     int        `json:"-" db:"version"` // sent as ETag
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+	DeletedBy   *int       `json:"deletedBy,omitempty" db:"deleted_by"`
+
+	Runtime          int        `json:"runtime" db:"runtime" validate:"gte=0,lte=1000"` // minutes
+	Countries        StringList `json:"countries" db:"countries" validate:"dive,iso3166_1_alpha2"`
+	OriginalLanguage string     `json:"originalLanguage" db:"original_language" validate:"omitempty,len=2,lowercase,bcp47_language_tag"` // ISO 639-1
+	Languages        StringList `json:"languages" db:"languages" validate:"dive,len=2,lowercase,bcp47_language_tag"`
+	AgeRating        string     `json:"ageRating" db:"age_rating" validate:"omitempty,oneof=0+ 6+ 12+ 16+ 18+ G PG PG-13 R NC-17"`
+	Budget           int64      `json:"budget" db:"budget" validate:"gte=0"`        // USD
+	BoxOffice        int64      `json:"boxOffice" db:"box_office" validate:"gte=0"` // USD
+	Genres           StringList `json:"genres" db:"genres" validate:"dive,gt=0,lte=32"`
 }
 
 type NullableFilm struct {
@@ -16,4 +30,26 @@ type NullableFilm struct {
 	Released    *CustomDate `json:"released" db:"released" validate:"omitempty"`
 	Rating      *int8       `json:"rating" db:"rating" validate:"omitempty,gte=0,lte=10"`
 	ActorIds    []int       `json:"actorIds" db:"-"`
+	Version     int         `json:"-" db:"-"` // expected version from If-Match, 0 for any
+
+	Runtime          *int        `json:"runtime" db:"runtime" validate:"omitempty,gte=0,lte=1000"`
+	Countries        *StringList `json:"countries" db:"countries" validate:"omitempty,dive,iso3166_1_alpha2"`
+	OriginalLanguage *string     `json:"originalLanguage" db:"original_language" validate:"omitempty,len=2,lowercase,bcp47_language_tag"`
+	Languages        *StringList `json:"languages" db:"languages" validate:"omitempty,dive,len=2,lowercase,bcp47_language_tag"`
+	AgeRating        *string     `json:"ageRating" db:"age_rating" validate:"omitempty,oneof=0+ 6+ 12+ 16+ 18+ G PG PG-13 R NC-17"`
+	Budget           *int64      `json:"budget" db:"budget" validate:"omitempty,gte=0"`
+	BoxOffice        *int64      `json:"boxOffice" db:"box_office" validate:"omitempty,gte=0"`
+	Genres           *StringList `json:"genres" db:"genres" validate:"omitempty,dive,gt=0,lte=32"`
+}
+
+type FilmFilter struct {
+	Country      *string `query:"country" validate:"omitempty,iso3166_1_alpha2"`
+	Language     *string `query:"language" validate:"omitempty,len=2,lowercase"`
+	AgeRating    *string `query:"age_rating" validate:"omitempty,oneof=0+ 6+ 12+ 16+ 18+ G PG PG-13 R NC-17"`
+	RuntimeMin   *int    `query:"runtime_min" validate:"omitempty,gte=0"`
+	RuntimeMax   *int    `query:"runtime_max" validate:"omitempty,gte=0"`
+	BudgetMin    *int64  `query:"budget_min" validate:"omitempty,gte=0"`
+	BudgetMax    *int64  `query:"budget_max" validate:"omitempty,gte=0"`
+	BoxOfficeMin *int64  `query:"box_office_min" validate:"omitempty,gte=0"`
+	BoxOfficeMax *int64  `query:"box_office_max" validate:"omitempty,gte=0"`
 }
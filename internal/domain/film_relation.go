@@ -0,0 +1,40 @@
+package domain
+
+const (
+	AliasWorking         = "working"
+	AliasRegional        = "regional"
+	AliasTransliteration = "transliteration"
+
+	RelationSequel  = "sequel"
+	RelationPrequel = "prequel"
+	RelationRemake  = "remake"
+)
+
+type FilmAlias struct {
+	Id     int    `json:"id" db:"id"`
+	FilmId int    `json:"filmId" db:"film_id"`
+	Title  string `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	Kind   string `json:"kind" db:"kind" validate:"required,oneof=working regional transliteration"`
+	Region string `json:"region" db:"region" validate:"required_if=Kind regional,omitempty,iso3166_1_alpha2"`
+}
+
+// FilmRelation reads as "film is a <kind> of related film", e.g. Solaris (2002) is a remake of Solaris (1972)
+type FilmRelation struct {
+	FilmId       int    `json:"filmId" db:"film_id"`
+	FilmTitle    string `json:"filmTitle" db:"film_title"`
+	RelatedId    int    `json:"relatedId" db:"related_id" validate:"required,gt=0"`
+	RelatedTitle string `json:"relatedTitle" db:"related_title"`
+	Kind         string `json:"kind" db:"kind" validate:"required,oneof=sequel prequel remake"`
+}
+
+type Franchise struct {
+	Id          int             `json:"id" db:"id"`
+	Title       string          `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	Description string          `json:"description" db:"description" validate:"lte=1000"`
+	Films       []FranchiseFilm `json:"films,omitempty" db:"-"`
+}
+
+type FranchiseFilm struct {
+	Position int `json:"position" db:"position"`
+	Film     `json:"film"`
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "io"
+
+// SourceIMDb is the namespace of IMDb ids (tconst and nconst) kept in external keys
+const SourceIMDb = "imdb"
+
+// IMDbFiles are the title.basics, title.principals and name.basics datasets, plain or gzipped TSV
+type IMDbFiles struct {
+	Titles     io.Reader
+	Principals io.Reader
+	Names      io.Reader
+}
+
+// IMDbOptions pick what to take from the datasets: titles of TitleTypes and people of Categories
+type IMDbOptions struct {
+	TitleTypes []string
+	Categories []string
+}
+
+type IMDbTitle struct {
+	Tconst   string
+	Title    string
+	Released CustomDate
+	Runtime  int
+	Genres   []string
+}
+
+type IMDbPrincipal struct {
+	Tconst string
+	Nconst string
+}
+
+type IMDbName struct {
+	Nconst   string
+	Name     string
+	Birthday CustomDate
+}
+
+// IMDbResult counts the rows an IMDb import has read and written
+type IMDbResult struct {
+	Titles        int   `json:"titles"`
+	Principals    int   `json:"principals"`
+	Names         int   `json:"names"`
+	Skipped       int   `json:"skipped"`
+	FilmsCreated  int64 `json:"filmsCreated"`
+	FilmsUpdated  int64 `json:"filmsUpdated"`
+	ActorsCreated int64 `json:"actorsCreated"`
+	ActorsUpdated int64 `json:"actorsUpdated"`
+	CastAdded     int64 `json:"castAdded"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+const (
+	ImportAtomic     = "atomic"
+	ImportBestEffort = "best_effort"
+
+	// SourceImport is the namespace of the keys given to records in bulk imports
+	SourceImport = "import"
+)
+
+// ImportOptions control a bulk import. An atomic import writes nothing unless every row succeeds,
+// a best effort one keeps the rows that succeeded. A dry run does all the work and rolls it back
+type ImportOptions struct {
+	DryRun bool   `query:"dry_run"`
+	Mode   string `query:"mode" validate:"oneof=atomic best_effort"`
+}
+
+// CastRef points to an actor by its import key or by name and birthday
+type CastRef struct {
+	Key      string     `json:"key,omitempty" validate:"lte=64"`
+	Name     string     `json:"name,omitempty" validate:"required_without=Key"`
+	Birthday CustomDate `json:"birthday"`
+}
+
+// ActorRecord is one row of an actor import. Row is its line in the imported file
+type ActorRecord struct {
+	Row int    `json:"-"`
+	Key string `json:"key"`
+	Actor
+}
+
+// FilmRecord is one row of a film import. A nil Cast leaves the cast of an existing film as it is
+type FilmRecord struct {
+	Row int    `json:"-"`
+	Key string `json:"key"`
+	Film
+	Cast []CastRef `json:"cast"`
+}
+
+// ImportRowError tells why a row wasn't imported
+type ImportRowError struct {
+	Row     int          `json:"row"`
+	Key     string       `json:"key,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// ImportResult sums up a bulk import. Created and Updated count the rows written,
+// or that would be written by a dry run. Committed tells whether anything was saved
+type ImportResult struct {
+	Rows      int              `json:"rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Failed    int              `json:"failed"`
+	Committed bool             `json:"committed"`
+	Errors    []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+// LetterboxdEntry is a film from a Letterboxd diary, ratings or watchlist file
+type LetterboxdEntry struct {
+	Row       int         `json:"-"`
+	Title     string      `json:"title"`
+	Year      *int        `json:"year,omitempty"`
+	Rating    *int8       `json:"rating,omitempty"` // out of 10
+	WatchedAt *CustomDate `json:"watchedAt,omitempty"`
+}
+
+// LetterboxdMatch tells which film a row was matched to when the title differs from the catalog one
+type LetterboxdMatch struct {
+	Row        int     `json:"row"`
+	Title      string  `json:"title"`
+	FilmId     int     `json:"filmId"`
+	FilmTitle  string  `json:"filmTitle"`
+	Similarity float64 `json:"similarity"`
+}
+
+// LetterboxdRowError is a row that wasn't imported: it couldn't be read or no single film matched it
+type LetterboxdRowError struct {
+	Row     int          `json:"row"`
+	Title   string       `json:"title,omitempty"`
+	Year    *int         `json:"year,omitempty"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+	FilmIds []int        `json:"filmIds,omitempty"` // films an ambiguous title matches
+}
+
+type LetterboxdResult struct {
+	Rows      int                  `json:"rows"`
+	Matched   int                  `json:"matched"`
+	Marked    int                  `json:"marked"` // films whose rating or watch date changed
+	Committed bool                 `json:"committed"`
+	Fuzzy     []LetterboxdMatch    `json:"fuzzy"`
+	Unmatched []LetterboxdRowError `json:"unmatched"`
+}
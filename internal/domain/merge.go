@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"github.com/jmoiron/sqlx/types"
+	"time"
+)
+
+const KindActor = "actor"
+
+type ActorDuplicate struct {
+	First      Actor   `json:"first"`
+	Second     Actor   `json:"second"`
+	Similarity float64 `json:"similarity"`
+}
+
+type FilmDuplicate struct {
+	First      Film    `json:"first"`
+	Second     Film    `json:"second"`
+	Similarity float64 `json:"similarity"`
+}
+
+type Duplicates struct {
+	Actors []ActorDuplicate `json:"actors"`
+	Films  []FilmDuplicate  `json:"films"`
+}
+
+// Merge records a record removed by merging it into the survivor, Snapshot keeps the removed row
+type Merge struct {
+	Id         int            `json:"id" db:"id"`
+	Kind       string         `json:"kind" db:"kind"`
+	SurvivorId int            `json:"survivorId" db:"survivor_id"`
+	MergedId   int            `json:"mergedId" db:"merged_id"`
+	Snapshot   types.JSONText `json:"snapshot" db:"snapshot"`
+	UserId     *int           `json:"userId" db:"user_id"`
+	MergedAt   time.Time      `json:"mergedAt" db:"merged_at"`
+}
+
+type MergeInput struct {
+	DuplicateIds []int `json:"duplicateIds" validate:"required,min=1,dive,gt=0"`
+}
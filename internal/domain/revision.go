@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"github.com/jmoiron/sqlx/types"
+	"time"
+)
+
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionPatch   = "patch"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionRevert  = "revert"
+	// ActionImport is a record created or updated by an import, Before is empty for a created one
+	ActionImport = "import"
+	// ActionMerge is a survivor that took over the references of its duplicates, or a duplicate
+	// merged into it: the After of a duplicate is its last state with mergedInto set
+	ActionMerge = "merge"
+)
+
+// Revision records one change of a film or an actor. Before and After keep the record as it was
+// around the change, Before is empty for a created record
+type Revision struct {
+	Id        int            `json:"id" db:"id"`
+	Kind      string         `json:"kind" db:"kind"`
+	RecordId  int            `json:"recordId" db:"record_id"`
+	Action    string         `json:"action" db:"action"`
+	Before    types.JSONText `json:"before,omitempty" db:"before"`
+	After     types.JSONText `json:"after" db:"after"`
+	Changes   []FieldChange  `json:"changes" db:"-"`
+	UserId    *int           `json:"userId" db:"user_id"`
+	CreatedAt time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// FieldChange is a field whose value differs between Before and After of a revision
+type FieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// FilmSnapshot is the state of a film kept in its revisions, cast included
+type FilmSnapshot struct {
+	Film
+	ActorIds []int `json:"actorIds"`
+}
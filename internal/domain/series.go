@@ -0,0 +1,44 @@
+package domain
+
+const (
+	KindFilm   = "film"
+	KindSeries = "series"
+)
+
+type Series struct {
+	Id          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	Description string     `json:"description" db:"description" validate:"required,lte=1000"`
+	Released    CustomDate `json:"released" db:"released" validate:"required"`
+	Rating      int8       `json:"rating" db:"rating" validate:"gte=0,lte=10"`
+	Actors      []Actor    `json:"actors,omitempty" db:"-"`
+	Seasons     []Season   `json:"seasons,omitempty" db:"-"`
+}
+
+type Season struct {
+	Id       int       `json:"id" db:"id"`
+	SeriesId int       `json:"seriesId" db:"series_id"`
+	Number   int       `json:"number" db:"number" validate:"required,gt=0"`
+	Title    string    `json:"title" db:"title" validate:"lte=150"`
+	Episodes []Episode `json:"episodes,omitempty" db:"-"`
+}
+
+type Episode struct {
+	Id       int        `json:"id" db:"id"`
+	SeasonId int        `json:"seasonId" db:"season_id"`
+	Number   int        `json:"number" db:"number" validate:"required,gt=0"`
+	Title    string     `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	AirDate  CustomDate `json:"airDate" db:"air_date" validate:"required"`
+	Runtime  int        `json:"runtime" db:"runtime" validate:"gte=0"` // minutes
+	Actors   []Actor    `json:"actors,omitempty" db:"-"`
+}
+
+// CatalogItem is a film or a series in mixed listings
+type CatalogItem struct {
+	Kind        string     `json:"kind" db:"kind"`
+	Id          int        `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title"`
+	Description *string    `json:"description" db:"description"`
+	Released    CustomDate `json:"released" db:"released"`
+	Rating      *int8      `json:"rating" db:"rating"`
+}
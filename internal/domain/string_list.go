@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringList maps a postgres array of plain codes (ISO country or language codes) to a slice
+type StringList []string
+
+func (l *StringList) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", src)
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	if s == "" {
+		*l = StringList{}
+		return nil
+	}
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+func (l StringList) Value() (driver.Value, error) {
+	return "{" + strings.Join(l, ",") + "}", nil
+}
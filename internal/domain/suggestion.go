@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"github.com/jmoiron/sqlx/types"
+	"time"
+)
+
+const (
+	SuggestionPending  = "pending"
+	SuggestionApproved = "approved"
+	SuggestionRejected = "rejected"
+)
+
+// Suggestion is a change to a film or an actor proposed by a user and waiting for moderation.
+// Changes has the shape of a film or actor patch, Diff compares a pending one with the current record
+type Suggestion struct {
+	Id         int            `json:"id" db:"id"`
+	Kind       string         `json:"kind" db:"kind"`
+	RecordId   int            `json:"recordId" db:"record_id"`
+	UserId     *int           `json:"userId" db:"user_id"`
+	Changes    types.JSONText `json:"changes" db:"changes"`
+	Comment    string         `json:"comment" db:"comment"`
+	Status     string         `json:"status" db:"status"`
+	ReviewerId *int           `json:"reviewerId,omitempty" db:"reviewer_id"`
+	Reason     *string        `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time      `json:"createdAt" db:"created_at"`
+	ReviewedAt *time.Time     `json:"reviewedAt,omitempty" db:"reviewed_at"`
+	Diff       []FieldChange  `json:"diff,omitempty" db:"-"`
+}
+
+type SuggestionRejection struct {
+	Reason string `json:"reason" validate:"required,lte=1000"`
+}
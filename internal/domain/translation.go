@@ -0,0 +1,14 @@
+package domain
+
+type FilmTranslation struct {
+	FilmId      int    `json:"filmId" db:"film_id"`
+	Lang        string `json:"lang" db:"lang" validate:"required,len=2,lowercase,bcp47_language_tag"` // ISO 639-1
+	Title       string `json:"title" db:"title" validate:"required,gt=0,lte=150"`
+	Description string `json:"description" db:"description" validate:"lte=1000"`
+}
+
+type ActorTranslation struct {
+	ActorId int    `json:"actorId" db:"actor_id"`
+	Lang    string `json:"lang" db:"lang" validate:"required,len=2,lowercase,bcp47_language_tag"`
+	Name    string `json:"name" db:"name" validate:"required,gt=0,lte=255"`
+}
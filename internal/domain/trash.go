@@ -0,0 +1,15 @@
+package domain
+
+// Trash holds soft deleted records until they are restored or purged
+type Trash struct {
+	Films  []Film  `json:"films"`
+	Actors []Actor `json:"actors"`
+}
+
+// PurgeResult counts records removed from the trash for good. Kept counts expired films
+// left in the trash because they still have copies or are in collections
+type PurgeResult struct {
+	Films  int64 `json:"films"`
+	Actors int64 `json:"actors"`
+	Kept   int64 `json:"kept"`
+}
@@ -7,3 +7,7 @@ type User struct {
 	PasswordHash string `json:"-" db:"password_hash"`
 	Role         int8   `json:"-" db:"role"`
 }
+
+type RoleInput struct {
+	Role int8 `json:"role" validate:"required,oneof=1 2"`
+}
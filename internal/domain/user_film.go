@@ -0,0 +1,18 @@
+package domain
+
+type UserFilm struct {
+	UserId    int         `json:"-" db:"user_id"`
+	FilmId    int         `json:"filmId" db:"film_id"`
+	Rating    *int8       `json:"rating,omitempty" db:"rating" validate:"omitempty,gte=0,lte=10"`
+	WatchedAt *CustomDate `json:"watchedAt,omitempty" db:"watched_at" validate:"omitempty,fulldate"`
+}
+
+type FilmActor struct {
+	FilmId  int `db:"film_id"`
+	ActorId int `db:"actor_id"`
+}
+
+type ScoredFilm struct {
+	Film  Film    `json:"film"`
+	Score float64 `json:"score"`
+}
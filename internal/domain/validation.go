@@ -0,0 +1,8 @@
+package domain
+
+// FieldError describes one failed validation rule
+type FieldError struct {
+	Field   string `json:"field" example:"film.title"`
+	Rule    string `json:"rule" example:"required"`
+	Message string `json:"message" example:"is required"`
+}
@@ -0,0 +1,98 @@
+// Package validation holds the input rules shared by the http api and the command line tools
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Warh40k/vk-intern-filmotecka/internal/domain"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// New returns a validator that reports fields by their json or query parameter names
+func New() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if name := field.Tag.Get("query"); name != "" {
+			return name
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// dates are validated as strings so that required rejects missing dates
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		date := field.Interface().(domain.CustomDate)
+		if !date.Valid() {
+			return nil
+		}
+		return date.String()
+	}, domain.CustomDate{})
+	validate.RegisterValidation("fulldate", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(time.DateOnly, fl.Field().String())
+		return err == nil
+	})
+	return validate
+}
+
+func isLength(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+}
+
+func ruleMessage(e validator.FieldError) string {
+	subject := "must be"
+	if isLength(e.Kind()) {
+		subject = "length must be"
+	}
+
+	switch e.Tag() {
+	case "required", "required_if", "required_without":
+		return "is required"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(e.Param(), " ", ", ")
+	case "gt":
+		return fmt.Sprintf("%s greater than %s", subject, e.Param())
+	case "gte", "min":
+		return fmt.Sprintf("%s at least %s", subject, e.Param())
+	case "lt":
+		return fmt.Sprintf("%s less than %s", subject, e.Param())
+	case "lte", "max":
+		return fmt.Sprintf("%s at most %s", subject, e.Param())
+	case "len":
+		return fmt.Sprintf("%s exactly %s", subject, e.Param())
+	case "numeric":
+		return "must contain only digits"
+	case "lowercase":
+		return "must be lowercase"
+	case "iso3166_1_alpha2":
+		return "must be an ISO 3166-1 alpha-2 country code"
+	case "bcp47_language_tag":
+		return "must be a BCP 47 language tag"
+	case "fulldate":
+		return "must be a full date in YYYY-MM-DD format"
+	default:
+		return fmt.Sprintf("failed %q rule", e.Tag())
+	}
+}
+
+// FieldErrors converts validator errors into json paths without the root struct name
+func FieldErrors(err error) []domain.FieldError {
+	var vErr validator.ValidationErrors
+	if !errors.As(err, &vErr) {
+		return nil
+	}
+
+	result := make([]domain.FieldError, 0, len(vErr))
+	for _, e := range vErr {
+		field := e.Namespace()
+		if i := strings.Index(field, "."); i != -1 {
+			field = field[i+1:]
+		}
+		result = append(result, domain.FieldError{Field: field, Rule: e.Tag(), Message: ruleMessage(e)})
+	}
+	return result
+}
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.collections_followers;
+DROP TABLE IF EXISTS public.collections_items;
+DROP TABLE IF EXISTS public.collections;
+
+END;
\ No newline at end of file
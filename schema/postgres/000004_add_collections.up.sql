@@ -0,0 +1,30 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.collections
+(
+    id serial primary key,
+    owner_id int NOT NULL references users(id) on delete cascade,
+    title character varying(150) NOT NULL,
+    description character varying(1000) NOT NULL DEFAULT '',
+    is_public boolean NOT NULL DEFAULT false,
+    share_token character varying(64) NOT NULL UNIQUE,
+    cloned_from int references collections(id) on delete set null
+);
+
+CREATE TABLE IF NOT EXISTS public.collections_items
+(
+    collection_id int references collections(id) on delete cascade,
+    film_id int references films(id) on delete cascade,
+    position int NOT NULL,
+    note character varying(1000) NOT NULL DEFAULT '',
+    PRIMARY KEY (collection_id, film_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.collections_followers
+(
+    collection_id int references collections(id) on delete cascade,
+    user_id int references users(id) on delete cascade,
+    PRIMARY KEY (collection_id, user_id)
+);
+
+END;
\ No newline at end of file
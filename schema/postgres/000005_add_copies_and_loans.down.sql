@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.loans;
+DROP TABLE IF EXISTS public.copies;
+
+END;
\ No newline at end of file
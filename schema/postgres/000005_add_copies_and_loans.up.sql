@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.copies
+(
+    id serial primary key,
+    film_id int NOT NULL references films(id) on delete restrict,
+    format character varying(16) NOT NULL,
+    barcode character varying(14) NOT NULL DEFAULT '',
+    shelf character varying(64) NOT NULL DEFAULT '',
+    condition character varying(16) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.loans
+(
+    id serial primary key,
+    copy_id int NOT NULL references copies(id) on delete restrict,
+    user_id int references users(id) on delete set null,
+    borrower character varying(255) NOT NULL DEFAULT '',
+    lent_at date NOT NULL DEFAULT CURRENT_DATE,
+    due_date date NOT NULL,
+    returned_at date
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS loans_active_copy_idx ON public.loans (copy_id) WHERE returned_at IS NULL;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.users_films;
+
+END;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.users_films
+(
+    user_id int references users(id) on delete cascade,
+    film_id int references films(id) on delete cascade,
+    rating smallint,
+    watched_at date,
+    PRIMARY KEY (user_id, film_id)
+);
+
+END;
\ No newline at end of file
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.episodes_actors;
+DROP TABLE IF EXISTS public.series_actors;
+DROP TABLE IF EXISTS public.episodes;
+DROP TABLE IF EXISTS public.seasons;
+DROP TABLE IF EXISTS public.series;
+
+END;
\ No newline at end of file
@@ -0,0 +1,46 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.series
+(
+    id serial primary key,
+    title character varying(150) NOT NULL,
+    description character varying(1000) NOT NULL,
+    released date NOT NULL,
+    rating smallint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.seasons
+(
+    id serial primary key,
+    series_id int NOT NULL references series(id) on delete cascade,
+    number int NOT NULL,
+    title character varying(150) NOT NULL DEFAULT '',
+    UNIQUE (series_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS public.episodes
+(
+    id serial primary key,
+    season_id int NOT NULL references seasons(id) on delete cascade,
+    number int NOT NULL,
+    title character varying(150) NOT NULL,
+    air_date date NOT NULL,
+    runtime int NOT NULL DEFAULT 0,
+    UNIQUE (season_id, number)
+);
+
+CREATE TABLE IF NOT EXISTS public.series_actors
+(
+    series_id int references series(id) on delete cascade,
+    actor_id int references actors(id) on delete cascade,
+    PRIMARY KEY (series_id, actor_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.episodes_actors
+(
+    episode_id int references episodes(id) on delete cascade,
+    actor_id int references actors(id) on delete cascade,
+    PRIMARY KEY (episode_id, actor_id)
+);
+
+END;
\ No newline at end of file
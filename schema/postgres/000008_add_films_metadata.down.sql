@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.films_languages_idx;
+DROP INDEX IF EXISTS public.films_countries_idx;
+
+ALTER TABLE public.films
+    DROP COLUMN IF EXISTS box_office,
+    DROP COLUMN IF EXISTS budget,
+    DROP COLUMN IF EXISTS age_rating,
+    DROP COLUMN IF EXISTS languages,
+    DROP COLUMN IF EXISTS original_language,
+    DROP COLUMN IF EXISTS countries,
+    DROP COLUMN IF EXISTS runtime;
+
+END;
\ No newline at end of file
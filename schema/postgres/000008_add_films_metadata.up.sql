@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE public.films
+    ADD COLUMN runtime int NOT NULL DEFAULT 0 CHECK (runtime >= 0),
+    ADD COLUMN countries varchar(2)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN original_language varchar(2) NOT NULL DEFAULT '',
+    ADD COLUMN languages varchar(2)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN age_rating varchar(8) NOT NULL DEFAULT '',
+    ADD COLUMN budget bigint NOT NULL DEFAULT 0 CHECK (budget >= 0),
+    ADD COLUMN box_office bigint NOT NULL DEFAULT 0 CHECK (box_office >= 0);
+
+CREATE INDEX films_countries_idx ON public.films USING gin (countries);
+CREATE INDEX films_languages_idx ON public.films USING gin (languages);
+
+END;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.actors_translations;
+DROP TABLE IF EXISTS public.films_translations;
+
+END;
\ No newline at end of file
@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.films_translations
+(
+    film_id int NOT NULL references films(id) on delete cascade,
+    lang character varying(2) NOT NULL,
+    title character varying(150) NOT NULL,
+    description character varying(1000) NOT NULL DEFAULT '',
+    primary key (film_id, lang)
+);
+
+CREATE TABLE IF NOT EXISTS public.actors_translations
+(
+    actor_id int NOT NULL references actors(id) on delete cascade,
+    lang character varying(2) NOT NULL,
+    name character varying(255) NOT NULL,
+    primary key (actor_id, lang)
+);
+
+CREATE INDEX films_translations_lang_idx ON public.films_translations (lang);
+CREATE INDEX actors_translations_lang_idx ON public.actors_translations (lang);
+
+END;
\ No newline at end of file
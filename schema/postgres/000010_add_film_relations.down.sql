@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.franchises_films;
+DROP TABLE IF EXISTS public.franchises;
+DROP TABLE IF EXISTS public.films_relations;
+DROP TABLE IF EXISTS public.films_aliases;
+
+END;
\ No newline at end of file
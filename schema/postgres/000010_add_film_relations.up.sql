@@ -0,0 +1,40 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.films_aliases
+(
+    id serial primary key,
+    film_id int NOT NULL references films(id) on delete cascade,
+    title character varying(150) NOT NULL,
+    kind character varying(16) NOT NULL,
+    region character varying(2) NOT NULL DEFAULT '',
+    UNIQUE (film_id, title)
+);
+
+CREATE TABLE IF NOT EXISTS public.films_relations
+(
+    film_id int NOT NULL references films(id) on delete cascade,
+    related_id int NOT NULL references films(id) on delete cascade,
+    kind character varying(16) NOT NULL,
+    primary key (film_id, related_id, kind),
+    CHECK (film_id <> related_id)
+);
+
+CREATE TABLE IF NOT EXISTS public.franchises
+(
+    id serial primary key,
+    title character varying(150) NOT NULL UNIQUE,
+    description character varying(1000) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS public.franchises_films
+(
+    franchise_id int NOT NULL references franchises(id) on delete cascade,
+    film_id int NOT NULL references films(id) on delete cascade,
+    position int NOT NULL,
+    primary key (franchise_id, film_id),
+    UNIQUE (franchise_id, position)
+);
+
+CREATE INDEX films_relations_related_idx ON public.films_relations (related_id);
+
+END;
\ No newline at end of file
@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.merges
+(
+    id serial primary key,
+    kind character varying(16) NOT NULL,
+    survivor_id int NOT NULL,
+    merged_id int NOT NULL,
+    snapshot jsonb NOT NULL,
+    user_id int references users(id) on delete set null,
+    merged_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX merges_survivor_idx ON public.merges (kind, survivor_id);
+
+END;
\ No newline at end of file
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE public.episodes DROP CONSTRAINT IF EXISTS episodes_air_date_check;
+ALTER TABLE public.episodes ALTER COLUMN air_date TYPE date USING to_date(rpad(air_date, 10, '-01'), 'YYYY-MM-DD');
+
+ALTER TABLE public.series DROP CONSTRAINT IF EXISTS series_released_check;
+ALTER TABLE public.series ALTER COLUMN released TYPE date USING to_date(rpad(released, 10, '-01'), 'YYYY-MM-DD');
+
+ALTER TABLE public.actors DROP CONSTRAINT IF EXISTS actors_birthday_check;
+ALTER TABLE public.actors ALTER COLUMN birthday TYPE date USING to_date(rpad(birthday, 10, '-01'), 'YYYY-MM-DD');
+
+ALTER TABLE public.films DROP CONSTRAINT IF EXISTS films_released_check;
+ALTER TABLE public.films ALTER COLUMN released TYPE date USING to_date(rpad(released, 10, '-01'), 'YYYY-MM-DD');
+
+END;
\ No newline at end of file
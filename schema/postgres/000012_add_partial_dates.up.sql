@@ -0,0 +1,20 @@
+BEGIN;
+
+-- partial dates are kept as ISO strings: YYYY, YYYY-MM or YYYY-MM-DD
+ALTER TABLE public.films ALTER COLUMN released TYPE character varying(10) USING to_char(released, 'YYYY-MM-DD');
+ALTER TABLE public.films ADD CONSTRAINT films_released_check
+    CHECK (released ~ '^\d{4}(-\d{2}(-\d{2})?)?$');
+
+ALTER TABLE public.actors ALTER COLUMN birthday TYPE character varying(10) USING to_char(birthday, 'YYYY-MM-DD');
+ALTER TABLE public.actors ADD CONSTRAINT actors_birthday_check
+    CHECK (birthday ~ '^\d{4}(-\d{2}(-\d{2})?)?$');
+
+ALTER TABLE public.series ALTER COLUMN released TYPE character varying(10) USING to_char(released, 'YYYY-MM-DD');
+ALTER TABLE public.series ADD CONSTRAINT series_released_check
+    CHECK (released ~ '^\d{4}(-\d{2}(-\d{2})?)?$');
+
+ALTER TABLE public.episodes ALTER COLUMN air_date TYPE character varying(10) USING to_char(air_date, 'YYYY-MM-DD');
+ALTER TABLE public.episodes ADD CONSTRAINT episodes_air_date_check
+    CHECK (air_date ~ '^\d{4}(-\d{2}(-\d{2})?)?$');
+
+END;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE public.films DROP COLUMN IF EXISTS version;
+ALTER TABLE public.actors DROP COLUMN IF EXISTS version;
+
+END;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE public.films ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE public.actors ADD COLUMN version int NOT NULL DEFAULT 1;
+
+END;
\ No newline at end of file
@@ -0,0 +1,12 @@
+BEGIN;
+
+DELETE FROM public.films WHERE deleted_at IS NOT NULL;
+DELETE FROM public.actors WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS public.films_deleted_at_idx;
+DROP INDEX IF EXISTS public.actors_deleted_at_idx;
+
+ALTER TABLE public.films DROP COLUMN IF EXISTS deleted_at, DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE public.actors DROP COLUMN IF EXISTS deleted_at, DROP COLUMN IF EXISTS deleted_by;
+
+END;
\ No newline at end of file
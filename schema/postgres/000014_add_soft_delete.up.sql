@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE public.films
+    ADD COLUMN deleted_at timestamp,
+    ADD COLUMN deleted_by int references users(id) on delete set null;
+ALTER TABLE public.actors
+    ADD COLUMN deleted_at timestamp,
+    ADD COLUMN deleted_by int references users(id) on delete set null;
+
+CREATE INDEX films_deleted_at_idx ON public.films (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX actors_deleted_at_idx ON public.actors (deleted_at) WHERE deleted_at IS NOT NULL;
+
+END;
\ No newline at end of file
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.revisions;
+
+END;
\ No newline at end of file
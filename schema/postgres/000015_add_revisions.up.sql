@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.revisions
+(
+    id serial primary key,
+    kind character varying(16) NOT NULL,
+    record_id int NOT NULL,
+    action character varying(16) NOT NULL,
+    before jsonb,
+    after jsonb NOT NULL,
+    user_id int references users(id) on delete set null,
+    created_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX revisions_record_idx ON public.revisions (kind, record_id);
+
+END;
\ No newline at end of file
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.audit_events;
+DROP FUNCTION IF EXISTS public.audit_events_append_only();
+
+END;
\ No newline at end of file
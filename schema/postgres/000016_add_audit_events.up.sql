@@ -0,0 +1,27 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.audit_events
+(
+    id bigserial primary key,
+    event character varying(32) NOT NULL,
+    user_id int,
+    username character varying(255),
+    ip character varying(64) NOT NULL,
+    user_agent character varying(512) NOT NULL,
+    details jsonb,
+    created_at timestamp NOT NULL DEFAULT now()
+);
+
+CREATE INDEX audit_events_created_at_idx ON public.audit_events (created_at);
+CREATE INDEX audit_events_user_idx ON public.audit_events (user_id);
+
+CREATE OR REPLACE FUNCTION public.audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit events can not be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only BEFORE UPDATE ON public.audit_events
+    FOR EACH ROW EXECUTE FUNCTION public.audit_events_append_only();
+
+END;
\ No newline at end of file
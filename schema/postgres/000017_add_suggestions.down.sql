@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.suggestions;
+
+END;
\ No newline at end of file
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.suggestions
+(
+    id serial primary key,
+    kind character varying(16) NOT NULL,
+    record_id int NOT NULL,
+    user_id int references users(id) on delete set null,
+    changes jsonb NOT NULL,
+    comment character varying(1000) NOT NULL DEFAULT '',
+    status character varying(16) NOT NULL DEFAULT 'pending',
+    reviewer_id int references users(id) on delete set null,
+    reason character varying(1000),
+    created_at timestamp NOT NULL DEFAULT now(),
+    reviewed_at timestamp
+);
+
+CREATE INDEX suggestions_status_idx ON public.suggestions (status, created_at);
+CREATE INDEX suggestions_user_idx ON public.suggestions (user_id);
+
+END;
\ No newline at end of file
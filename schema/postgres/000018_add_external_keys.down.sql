@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS public.external_keys;
+
+END;
\ No newline at end of file
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS public.external_keys
+(
+    kind character varying(16) NOT NULL,
+    source character varying(16) NOT NULL,
+    key character varying(64) NOT NULL,
+    record_id int NOT NULL,
+    primary key (kind, source, key)
+);
+
+CREATE INDEX external_keys_record_idx ON public.external_keys (kind, record_id);
+
+END;
\ No newline at end of file
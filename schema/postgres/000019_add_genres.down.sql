@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS public.films_genres_idx;
+
+ALTER TABLE public.films
+    DROP COLUMN IF EXISTS genres;
+
+END;
\ No newline at end of file
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE public.films
+    ADD COLUMN genres varchar(32)[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX films_genres_idx ON public.films USING gin (genres);
+
+END;
\ No newline at end of file